@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"monolith/internal/agent"
+	"monolith/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	configPath := flag.String("config", "agent.yaml", "path to the agent configuration")
+	flag.Parse()
+
+	cfg, err := agent.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+	defer stop()
+
+	zapLog := logger.New(logger.Config{
+		LogLevel:    cfg.LogLevel,
+		ServiceName: "monolith-agent",
+		LogPath:     cfg.LogPath,
+	})
+
+	if err := agent.Run(ctx, cfg, zapLog); err != nil {
+		zapLog.Error("agent.Run", zap.Error(err))
+		os.Exit(1)
+	}
+}
@@ -15,6 +15,13 @@ type Config struct {
 	Server   ServerConfig
 	Postgres PostgresConfig
 	Service  ServiceConfig
+	Syslog   SyslogConfig
+	SNMP     SNMPConfig
+	GRPC     GRPCConfig
+
+	DeviceChecker DeviceCheckerConfig
+	Discovery     DiscoveryConfig
+	SMTP          SMTPConfig
 }
 
 type PostgresConfig struct {
@@ -38,10 +45,93 @@ type ServerConfig struct {
 	Addr          string        `env:"SERVER_ADDR,required"`
 	TokenLifeTime time.Duration `env:"SERVER_TOKEN_LIFE_TIME,required"`
 	LogQuerys     bool          `env:"SERVER_LOG_QUERYS"`
+
+	// IngestAuth controls device tokens on /messages: "off", "optional" or
+	// "required". IngestStrictIP additionally binds a token to the address
+	// of its device.
+	IngestAuth     string `env:"SERVER_INGEST_AUTH"      envDefault:"off"`
+	IngestStrictIP bool   `env:"SERVER_INGEST_STRICT_IP" envDefault:"false"`
+
+	// OTLPAddressAttr is the OTLP resource attribute that identifies the
+	// sending device on /v1/logs.
+	OTLPAddressAttr string `env:"SERVER_OTLP_ADDRESS_ATTRIBUTE" envDefault:"host.ip"`
 }
 
 type ServiceConfig struct {
 	NotificationPeriod time.Duration `env:"SERVICE_NOTIFICATION_PERIOD,required"`
+
+	IngestQueueSize     int           `env:"SERVICE_INGEST_QUEUE_SIZE"     envDefault:"10000"`
+	IngestWriters       int           `env:"SERVICE_INGEST_WRITERS"        envDefault:"4"`
+	IngestFlushSize     int           `env:"SERVICE_INGEST_FLUSH_SIZE"     envDefault:"500"`
+	IngestFlushInterval time.Duration `env:"SERVICE_INGEST_FLUSH_INTERVAL" envDefault:"1s"`
+
+	// IdempotencyWindow is how long a message_id is remembered per device.
+	IdempotencyWindow time.Duration `env:"SERVICE_IDEMPOTENCY_WINDOW" envDefault:"24h"`
+
+	// RollupInterval is how often the daily message rollups are updated;
+	// a day is rolled up once RollupLateWindow has passed since its end.
+	RollupInterval   time.Duration `env:"SERVICE_ROLLUP_INTERVAL"    envDefault:"5m"`
+	RollupLateWindow time.Duration `env:"SERVICE_ROLLUP_LATE_WINDOW" envDefault:"1h"`
+
+	// TimestampSkewPolicy is "accept", "clamp" or "reject" and applies to
+	// device timestamps outside of the allowed skew from the receive time.
+	TimestampSkewPolicy    string        `env:"SERVICE_TIMESTAMP_SKEW_POLICY"     envDefault:"clamp"`
+	TimestampMaxFutureSkew time.Duration `env:"SERVICE_TIMESTAMP_MAX_FUTURE_SKEW" envDefault:"5m"`
+	TimestampMaxPastSkew   time.Duration `env:"SERVICE_TIMESTAMP_MAX_PAST_SKEW"   envDefault:"168h"`
+
+	// UnknownAutoRegister registers unknown senders as devices of
+	// UnknownAutoRegisterType instead of keeping them in quarantine.
+	UnknownAutoRegister     bool   `env:"SERVICE_UNKNOWN_AUTO_REGISTER"      envDefault:"false"`
+	UnknownAutoRegisterType string `env:"SERVICE_UNKNOWN_AUTO_REGISTER_TYPE" envDefault:"auto"`
+}
+
+// SyslogConfig holds the listen addresses of the built-in syslog receiver.
+// A listener is disabled when its address is empty.
+type SyslogConfig struct {
+	UDPAddr string `env:"SYSLOG_UDP_ADDR"`
+	TCPAddr string `env:"SYSLOG_TCP_ADDR"`
+}
+
+// SNMPConfig configures the SNMP trap receiver. It is disabled when Addr is
+// empty.
+type SNMPConfig struct {
+	Addr         string `env:"SNMP_TRAP_ADDR"`
+	Community    string `env:"SNMP_TRAP_COMMUNITY"`
+	OIDNamesPath string `env:"SNMP_OID_NAMES_PATH"`
+}
+
+// GRPCConfig configures the gRPC API. It is disabled when Addr is empty.
+type GRPCConfig struct {
+	Addr string `env:"GRPC_ADDR"`
+}
+
+// DeviceCheckerConfig bounds the number of device probes running at the
+// same time. A probe goes down after FailureThreshold failures in a row and
+// back up after RecoveryThreshold successes in a row. The hostnames of
+// devices are resolved again every ResolveInterval.
+type DeviceCheckerConfig struct {
+	Workers           int           `env:"DEVICE_CHECKER_WORKERS"            envDefault:"8"`
+	FailureThreshold  int           `env:"DEVICE_CHECKER_FAILURE_THRESHOLD"  envDefault:"3"`
+	RecoveryThreshold int           `env:"DEVICE_CHECKER_RECOVERY_THRESHOLD" envDefault:"2"`
+	ResolveInterval   time.Duration `env:"DEVICE_CHECKER_RESOLVE_INTERVAL"   envDefault:"5m"`
+}
+
+// DiscoveryConfig bounds the number of hosts a discovery job probes at the
+// same time, the time of a single probe and the size of a job range.
+type DiscoveryConfig struct {
+	Workers  int           `env:"DISCOVERY_WORKERS"   envDefault:"32"`
+	Timeout  time.Duration `env:"DISCOVERY_TIMEOUT"   envDefault:"1s"`
+	MaxHosts int           `env:"DISCOVERY_MAX_HOSTS" envDefault:"4096"`
+}
+
+// SMTPConfig configures mail notifications. Without Host notifications are
+// only logged.
+type SMTPConfig struct {
+	Host     string `env:"SMTP_HOST"`
+	Port     string `env:"SMTP_PORT"     envDefault:"25"`
+	User     string `env:"SMTP_USER"`
+	Password string `env:"SMTP_PASSWORD"`
+	From     string `env:"SMTP_FROM"`
 }
 
 var (
@@ -0,0 +1,56 @@
+// Package agent forwards lines of local log files to the monolith.
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Run tails the configured files until ctx is done.
+func Run(ctx context.Context, cfg Config, log *zap.Logger) error {
+	st, err := loadState(cfg.StatePath)
+	if err != nil {
+		return fmt.Errorf("loadState: %w", err)
+	}
+
+	lines := make(chan line, cfg.BufferSize)
+
+	var wg sync.WaitGroup
+	for _, file := range cfg.Files {
+		t := &tailer{
+			cfg:          file,
+			address:      cfg.Address,
+			state:        st,
+			out:          lines,
+			pollInterval: cfg.PollInterval,
+			log:          log,
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.run(ctx)
+		}()
+	}
+
+	s := &sender{
+		url:             cfg.URL,
+		token:           cfg.Token,
+		client:          &http.Client{Timeout: cfg.RequestTimeout},
+		batchSize:       cfg.BatchSize,
+		flushInterval:   cfg.FlushInterval,
+		maxRetryBackoff: cfg.MaxRetryBackoff,
+		in:              lines,
+		state:           st,
+		log:             log,
+	}
+	s.run(ctx)
+
+	wg.Wait()
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultStatePath       = "agent-state.json"
+	defaultBatchSize       = 500
+	defaultFlushInterval   = time.Second
+	defaultBufferSize      = 10000
+	defaultPollInterval    = time.Second
+	defaultRequestTimeout  = 10 * time.Second
+	defaultMaxRetryBackoff = 30 * time.Second
+	defaultLogLevel        = "info"
+
+	// maxBatchSize matches the limit of /messages/send_batch.
+	maxBatchSize = 10000
+)
+
+// Config is the YAML configuration of the agent.
+type Config struct {
+	// URL is the base of the v1 HTTP API, e.g.
+	// http://monolith:13693/monolith/v1.
+	URL string `yaml:"url"`
+	// Token is the ingest token of the device. Without a token the server
+	// identifies the device by Address.
+	Token   string `yaml:"token"`
+	Address string `yaml:"address"`
+
+	// StatePath is where read offsets are kept between restarts.
+	StatePath string `yaml:"state_path"`
+
+	BatchSize     int           `yaml:"batch_size"`
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	// BufferSize bounds the lines held in memory while the server is
+	// unreachable. Once it is full the files are not read any further, so
+	// nothing is lost, the files themselves become the buffer.
+	BufferSize      int           `yaml:"buffer_size"`
+	PollInterval    time.Duration `yaml:"poll_interval"`
+	RequestTimeout  time.Duration `yaml:"request_timeout"`
+	MaxRetryBackoff time.Duration `yaml:"max_retry_backoff"`
+
+	// MessageType and Component are used for files that do not set their
+	// own.
+	MessageType string `yaml:"message_type"`
+	Component   string `yaml:"component"`
+
+	LogLevel string `yaml:"log_level"`
+	LogPath  string `yaml:"log_path"`
+
+	Files []FileConfig `yaml:"files"`
+}
+
+type FileConfig struct {
+	Path        string `yaml:"path"`
+	MessageType string `yaml:"message_type"`
+	Component   string `yaml:"component"`
+	// FromBeginning reads a file that has no saved offset from its start
+	// instead of only following new lines.
+	FromBeginning bool `yaml:"from_beginning"`
+}
+
+func LoadConfig(path string) (Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return Config{}, fmt.Errorf("yaml.Unmarshal: %w", err)
+	}
+
+	cfg.setDefaults()
+	if err := cfg.validate(); err != nil {
+		return Config{}, fmt.Errorf("cfg.validate: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) setDefaults() {
+	c.URL = strings.TrimSuffix(c.URL, "/")
+
+	if c.StatePath == "" {
+		c.StatePath = defaultStatePath
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultBatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = defaultFlushInterval
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = defaultBufferSize
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaultPollInterval
+	}
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = defaultRequestTimeout
+	}
+	if c.MaxRetryBackoff <= 0 {
+		c.MaxRetryBackoff = defaultMaxRetryBackoff
+	}
+	if c.LogLevel == "" {
+		c.LogLevel = defaultLogLevel
+	}
+
+	for i := range c.Files {
+		if c.Files[i].MessageType == "" {
+			c.Files[i].MessageType = c.MessageType
+		}
+		if c.Files[i].Component == "" {
+			c.Files[i].Component = c.Component
+		}
+	}
+}
+
+func (c *Config) validate() error {
+	if c.URL == "" {
+		return errors.New("url is required")
+	}
+
+	if c.Token == "" {
+		if _, err := netip.ParseAddr(c.Address); err != nil {
+			return fmt.Errorf("address must be an IP address without a token: %w", err)
+		}
+	}
+
+	if c.BatchSize > maxBatchSize {
+		return fmt.Errorf("batch_size %d exceeds limit %d", c.BatchSize, maxBatchSize)
+	}
+
+	if len(c.Files) == 0 {
+		return errors.New("no files configured")
+	}
+
+	paths := make(map[string]struct{}, len(c.Files))
+	for _, file := range c.Files {
+		if file.Path == "" {
+			return errors.New("file path is required")
+		}
+		if _, ok := paths[file.Path]; ok {
+			return fmt.Errorf("file %s is configured twice", file.Path)
+		}
+		paths[file.Path] = struct{}{}
+
+		if file.MessageType == "" || file.Component == "" {
+			return fmt.Errorf("file %s: message_type and component are required", file.Path)
+		}
+	}
+
+	return nil
+}
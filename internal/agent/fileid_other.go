@@ -0,0 +1,16 @@
+//go:build !unix
+
+package agent
+
+import "os"
+
+// fileID is not available on this platform, so rotation is only detected by
+// truncation.
+type fileID struct {
+	Dev   uint64 `json:"dev"`
+	Inode uint64 `json:"inode"`
+}
+
+func idOf(_ os.FileInfo) fileID {
+	return fileID{}
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package agent
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID identifies a file independently of its name, so a rotated file is
+// recognised after it was renamed.
+type fileID struct {
+	Dev   uint64 `json:"dev"`
+	Inode uint64 `json:"inode"`
+}
+
+func idOf(fi os.FileInfo) fileID {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}
+	}
+
+	return fileID{
+		Dev:   uint64(st.Dev), //nolint:unconvert,gosec
+		Inode: uint64(st.Ino), //nolint:unconvert
+	}
+}
@@ -0,0 +1,196 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+)
+
+const (
+	sendBatchPath         = "/messages/send_batch"
+	mimeApplicationNDJSON = "application/x-ndjson"
+
+	minRetryBackoff = time.Second
+)
+
+// message is one item of /messages/send_batch.
+type message struct {
+	Message     string     `json:"message"`
+	MessageType string     `json:"message_type"`
+	Component   string     `json:"component"`
+	Address     string     `json:"address,omitempty"`
+	MessageID   string     `json:"message_id"`
+	Timestamp   *time.Time `json:"timestamp,omitempty"`
+}
+
+type (
+	sendBatchItemResult struct {
+		Index  int    `json:"index"`
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+	}
+
+	sendBatchResp struct {
+		Data struct {
+			Accepted int                   `json:"accepted"`
+			Rejected int                   `json:"rejected"`
+			Results  []sendBatchItemResult `json:"results"`
+		} `json:"data"`
+	}
+)
+
+// errPermanent marks a batch the server will never accept. It is dropped
+// instead of being retried forever.
+var errPermanent = errors.New("batch rejected")
+
+// sender batches lines and delivers them in order. A batch is retried until
+// the server accepts it; meanwhile the tailers block on the full channel.
+type sender struct {
+	url             string
+	token           string
+	client          *http.Client
+	batchSize       int
+	flushInterval   time.Duration
+	maxRetryBackoff time.Duration
+	in              <-chan line
+	state           *state
+	log             *zap.Logger
+}
+
+func (s *sender) run(ctx context.Context) {
+	batch := make([]line, 0, s.batchSize)
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Unsent lines are read again on the next start, since their
+			// offsets were never saved.
+			return
+		case l := <-s.in:
+			batch = append(batch, l)
+			if len(batch) >= s.batchSize {
+				s.send(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) != 0 {
+				s.send(ctx, batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func (s *sender) send(ctx context.Context, batch []line) {
+	backoff := minRetryBackoff
+	for {
+		err := s.post(ctx, batch)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, errPermanent) {
+			s.log.Error("s.post: dropping batch", zap.Error(err), zap.Int("lines", len(batch)))
+			break
+		}
+
+		s.log.Warn("s.post", zap.Error(err), zap.Duration("retry_in", backoff))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, s.maxRetryBackoff) //nolint:mnd
+	}
+
+	for _, l := range batch {
+		s.state.set(l.path, fileState{ID: l.id, Offset: l.offset})
+	}
+	if err := s.state.save(); err != nil {
+		s.log.Error("s.state.save", zap.Error(err))
+	}
+}
+
+// post sends the batch as NDJSON. Items refused for good, e.g. because of
+// validation, are logged and count as delivered; a server side failure of
+// any item makes the whole batch be sent again, which is safe because every
+// line carries a message_id.
+func (s *sender) post(ctx context.Context, batch []line) error {
+	var body bytes.Buffer
+	stream := jsoniter.NewStream(jsoniter.ConfigDefault, &body, 0)
+	for _, l := range batch {
+		stream.WriteVal(l.msg)
+		stream.WriteRaw("\n")
+	}
+	if err := stream.Flush(); err != nil {
+		return fmt.Errorf("stream.Flush: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+sendBatchPath, &body)
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	req.Header.Set("Content-Type", mimeApplicationNDJSON)
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("s.client.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("io.ReadAll: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case retryableStatus(resp.StatusCode):
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, raw)
+	default:
+		return fmt.Errorf("%w: status %d: %s", errPermanent, resp.StatusCode, raw)
+	}
+
+	var result sendBatchResp
+	if err := jsoniter.Unmarshal(raw, &result); err != nil {
+		return fmt.Errorf("jsoniter.Unmarshal: %w", err)
+	}
+
+	for _, item := range result.Data.Results {
+		if item.Status == http.StatusAccepted {
+			continue
+		}
+		if retryableStatus(item.Status) {
+			return fmt.Errorf("line %d: status %d: %s", item.Index, item.Status, item.Error)
+		}
+		if item.Index >= 0 && item.Index < len(batch) {
+			s.log.Warn("line rejected",
+				zap.String("path", batch[item.Index].path),
+				zap.Int("status", item.Status),
+				zap.String("error", item.Error),
+			)
+		}
+	}
+
+	return nil
+}
+
+// retryableStatus covers server failures and authentication errors, which
+// are expected to go away once the server or the token is fixed.
+func retryableStatus(status int) bool {
+	return status >= http.StatusInternalServerError ||
+		status == http.StatusUnauthorized ||
+		status == http.StatusForbidden ||
+		status == http.StatusTooManyRequests
+}
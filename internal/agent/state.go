@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// fileState is the position after the last line of a file that the server
+// has acknowledged.
+type fileState struct {
+	ID     fileID `json:"id"`
+	Offset int64  `json:"offset"`
+}
+
+// state keeps read offsets on disk. It is written only after the server has
+// acknowledged a batch, so a restart resumes right after the last line that
+// was delivered.
+type state struct {
+	path string
+
+	mu    sync.Mutex
+	files map[string]fileState
+}
+
+func loadState(path string) (*state, error) {
+	s := &state{
+		path:  path,
+		files: map[string]fileState{},
+	}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	if err := jsoniter.Unmarshal(raw, &s.files); err != nil {
+		return nil, fmt.Errorf("jsoniter.Unmarshal: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *state) get(path string) (fileState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fs, ok := s.files[path]
+	return fs, ok
+}
+
+func (s *state) set(path string, fs fileState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.files[path] = fs
+}
+
+// save replaces the state file atomically, so a crash never leaves a
+// half-written file behind.
+func (s *state) save() error {
+	s.mu.Lock()
+	raw, err := jsoniter.Marshal(s.files)
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("jsoniter.Marshal: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("tmp.Write: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("tmp.Sync: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("tmp.Close: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,283 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxLineSize splits longer lines, so a file without newlines cannot grow
+// the buffer without bound.
+const maxLineSize = 64 * 1024
+
+// line is one line read from a file together with the position right after
+// it, which becomes the saved offset once the line is delivered.
+type line struct {
+	path   string
+	id     fileID
+	offset int64
+	msg    message
+}
+
+// tailer follows one file. Rotation by rename is detected by a new file
+// identity at the configured path and the rest of the old file is read
+// before switching; rotation by truncation is detected by the file
+// shrinking below the read offset.
+type tailer struct {
+	cfg          FileConfig
+	address      string
+	state        *state
+	out          chan<- line
+	pollInterval time.Duration
+	log          *zap.Logger
+
+	file    *os.File
+	id      fileID
+	offset  int64
+	reader  *bufio.Reader
+	partial []byte
+}
+
+func (t *tailer) run(ctx context.Context) {
+	defer t.close()
+
+	if err := t.resume(ctx); err != nil {
+		t.log.Error("t.resume", zap.Error(err), zap.String("path", t.cfg.Path))
+	}
+
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if t.file != nil {
+			if err := t.readLines(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				t.log.Error("t.readLines", zap.Error(err), zap.String("path", t.cfg.Path))
+			}
+		}
+
+		if err := t.checkRotation(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			t.log.Error("t.checkRotation", zap.Error(err), zap.String("path", t.cfg.Path))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// resume opens the file at the saved offset. If the file was rotated while
+// the agent was stopped, the rest of the rotated file is read first when it
+// can still be found next to the current one.
+func (t *tailer) resume(ctx context.Context) error {
+	fi, err := os.Stat(t.cfg.Path)
+	if os.IsNotExist(err) {
+		// The file is picked up by checkRotation once it appears.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("os.Stat: %w", err)
+	}
+
+	saved, ok := t.state.get(t.cfg.Path)
+	switch {
+	case !ok:
+		offset := fi.Size()
+		if t.cfg.FromBeginning {
+			offset = 0
+		}
+		return t.open(offset)
+	case saved.ID == idOf(fi):
+		return t.open(saved.Offset)
+	}
+
+	if rotated := t.findRotated(saved.ID); rotated != "" {
+		t.log.Info("reading rest of rotated file", zap.String("path", rotated))
+		if err := t.openPath(rotated, saved.Offset); err != nil {
+			return err
+		}
+		if err := t.readLines(ctx); err != nil {
+			return err
+		}
+		t.close()
+	}
+
+	return t.open(0)
+}
+
+// findRotated looks for the file with the given identity in the directory
+// of the configured path.
+func (t *tailer) findRotated(id fileID) string {
+	if id == (fileID{}) {
+		return ""
+	}
+
+	dir := filepath.Dir(t.cfg.Path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	for _, entry := range entries {
+		fi, err := entry.Info()
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		if idOf(fi) == id {
+			return filepath.Join(dir, entry.Name())
+		}
+	}
+
+	return ""
+}
+
+func (t *tailer) checkRotation(ctx context.Context) error {
+	fi, err := os.Stat(t.cfg.Path)
+	if os.IsNotExist(err) {
+		// Renamed away and not recreated yet: keep reading the old file.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("os.Stat: %w", err)
+	}
+
+	if t.file == nil {
+		return t.open(0)
+	}
+
+	if idOf(fi) != t.id {
+		// Lines written to the old file right before the rename are still
+		// read before switching.
+		if err := t.readLines(ctx); err != nil {
+			return err
+		}
+		t.close()
+		t.log.Info("file rotated", zap.String("path", t.cfg.Path))
+		return t.open(0)
+	}
+
+	if fi.Size() < t.offset {
+		t.log.Info("file truncated", zap.String("path", t.cfg.Path))
+		return t.seek(0)
+	}
+
+	return nil
+}
+
+func (t *tailer) open(offset int64) error {
+	return t.openPath(t.cfg.Path, offset)
+}
+
+func (t *tailer) openPath(path string, offset int64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("os.Open: %w", err)
+	}
+
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("file.Stat: %w", err)
+	}
+
+	t.file = file
+	t.id = idOf(fi)
+	if offset > fi.Size() {
+		offset = 0
+	}
+
+	return t.seek(offset)
+}
+
+func (t *tailer) seek(offset int64) error {
+	if _, err := t.file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("t.file.Seek: %w", err)
+	}
+
+	t.offset = offset
+	t.reader = bufio.NewReader(t.file)
+	t.partial = t.partial[:0]
+
+	return nil
+}
+
+func (t *tailer) close() {
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
+
+// readLines reads up to the end of the file. A trailing line without a
+// newline is kept until the rest of it is written.
+func (t *tailer) readLines(ctx context.Context) error {
+	for {
+		chunk, err := t.reader.ReadSlice('\n')
+		t.partial = append(t.partial, chunk...)
+
+		if errors.Is(err, bufio.ErrBufferFull) && len(t.partial) < maxLineSize {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil && !errors.Is(err, bufio.ErrBufferFull) {
+			return fmt.Errorf("t.reader.ReadSlice: %w", err)
+		}
+
+		t.offset += int64(len(t.partial))
+		text := string(bytes.TrimSpace(t.partial))
+		t.partial = t.partial[:0]
+		if text == "" {
+			continue
+		}
+
+		select {
+		case t.out <- t.line(text):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (t *tailer) line(text string) line {
+	now := time.Now()
+
+	return line{
+		path:   t.cfg.Path,
+		id:     t.id,
+		offset: t.offset,
+		msg: message{
+			Message:     text,
+			MessageType: t.cfg.MessageType,
+			Component:   t.cfg.Component,
+			Address:     t.address,
+			MessageID:   messageID(t.cfg.Path, t.id, t.offset, text),
+			Timestamp:   &now,
+		},
+	}
+}
+
+// messageID is stable for a line at a position of a file, so the server
+// drops a line that is sent again after a restart or a retry.
+func messageID(path string, id fileID, offset int64, text string) string {
+	sum := sha256.New()
+	sum.Write([]byte(path))
+	sum.Write([]byte(strconv.FormatUint(id.Dev, 10) + ":" + strconv.FormatUint(id.Inode, 10)))
+	sum.Write([]byte(strconv.FormatInt(offset, 10)))
+	sum.Write([]byte(text))
+
+	return hex.EncodeToString(sum.Sum(nil))
+}
@@ -10,7 +10,12 @@ import (
 
 	"monolith/internal/repo/pg"
 	"monolith/internal/services"
+	"monolith/internal/transport/grpc"
 	"monolith/internal/transport/http"
+	"monolith/internal/transport/http/v1/devicechecker"
+	smtpsender "monolith/internal/transport/smtp"
+	"monolith/internal/transport/snmp"
+	"monolith/internal/transport/syslog"
 	"monolith/pkg/closer"
 	"monolith/pkg/logger"
 	"monolith/pkg/postgres"
@@ -48,18 +53,119 @@ func Run(ctx context.Context, cfg *config.Config, stop context.CancelFunc) {
 	tagsRepo := pg.NewTagsRepo(postgresDB, log)
 	devicesRepo := pg.NewDevicesRepo(postgresDB, log)
 	messagesRepo := pg.NewMessagesRepo(postgresDB, log)
+	unknownSourcesRepo := pg.NewUnknownSourcesRepo(postgresDB, log)
+	deviceCredentialsRepo := pg.NewDeviceCredentialsRepo(postgresDB, log)
+	probesRepo := pg.NewProbesRepo(postgresDB, log)
+	deviceStatusRepo := pg.NewDeviceStatusRepo(postgresDB, log)
+	deviceDependenciesRepo := pg.NewDeviceDependenciesRepo(postgresDB, log)
+	deviceGroupsRepo := pg.NewDeviceGroupsRepo(postgresDB, log)
+	discoveryRepo := pg.NewDiscoveryRepo(postgresDB, log)
+	deviceAddressesRepo := pg.NewDeviceAddressesRepo(postgresDB, log)
+	reportSubscriptionsRepo := pg.NewReportSubscriptionsRepo(postgresDB, log)
 
 	authService := services.NewAuthService(authRepo)
 	devicesService := services.NewDevicesService(devicesRepo)
-	messagesService := services.NewMessagesService(services.MessagesServiceConfig{
-		MessageRepo:        messagesRepo,
-		TagRepo:            tagsRepo,
-		DevicesRepo:        devicesRepo,
-		Log:                log,
-		NotificationPeriod: cfg.Service.NotificationPeriod,
+	deviceDependenciesService := services.NewDeviceDependenciesService(deviceDependenciesRepo, log)
+
+	notificationConfig := services.NotificationServiceConfig{
+		DevicesRepo: devicesRepo,
+		AuthRepo:    authRepo,
+		Log:         log,
+	}
+	reportSubscriptionsConfig := services.ReportSubscriptionsServiceConfig{
+		Repo: reportSubscriptionsRepo,
+		Log:  log,
+	}
+	if cfg.SMTP.Host != "" {
+		sender := smtpsender.New(smtpsender.Config{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			User:     cfg.SMTP.User,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+		})
+		notificationConfig.Mailer = &sender
+		reportSubscriptionsConfig.Mailer = &sender
+	}
+	notificationService := services.NewNotificationService(notificationConfig)
+
+	deviceChecker := services.NewDeviceHandler(services.DeviceHandlerConfig{
+		Devices:         devicesRepo,
+		Addresses:       deviceAddressesRepo,
+		ResolveInterval: cfg.DeviceChecker.ResolveInterval,
+		Log:             log,
+	})
+	messagesService, err := services.NewMessagesService(services.MessagesServiceConfig{
+		MessageRepo:         messagesRepo,
+		TagRepo:             tagsRepo,
+		DevicesRepo:         devicesRepo,
+		DeviceHandler:       deviceChecker,
+		Log:                 log,
+		NotificationPeriod:  cfg.Service.NotificationPeriod,
+		Notifier:            notificationService,
+		Upstream:            deviceDependenciesService,
+		IngestQueueSize:     cfg.Service.IngestQueueSize,
+		IngestWriters:       cfg.Service.IngestWriters,
+		IngestFlushSize:     cfg.Service.IngestFlushSize,
+		IngestFlushInterval: cfg.Service.IngestFlushInterval,
+		IdempotencyWindow:   cfg.Service.IdempotencyWindow,
+		RollupInterval:      cfg.Service.RollupInterval,
+		RollupLateWindow:    cfg.Service.RollupLateWindow,
+
+		TimestampSkewPolicy:    cfg.Service.TimestampSkewPolicy,
+		TimestampMaxFutureSkew: cfg.Service.TimestampMaxFutureSkew,
+		TimestampMaxPastSkew:   cfg.Service.TimestampMaxPastSkew,
 	})
+	if err != nil {
+		log.Fatal("init messages service error", zap.Error(err))
+	}
 	tagsService := services.NewTagsService(tagsRepo, messagesService)
-	deviceChecker := services.NewDeviceHandler(devicesRepo)
+	deviceAddressesService := services.NewDeviceAddressesService(deviceAddressesRepo)
+	deviceCredentialsService := services.NewDeviceCredentialsService(deviceCredentialsRepo, log)
+	probesService := services.NewProbesService(probesRepo, log)
+	deviceGroupsService := services.NewDeviceGroupsService(deviceGroupsRepo)
+	deviceStatusService, err := services.NewDeviceStatusService(services.DeviceStatusServiceConfig{
+		Repo:              deviceStatusRepo,
+		Probes:            probesService,
+		Messages:          messagesService,
+		Notifier:          notificationService,
+		Topology:          deviceDependenciesService,
+		FailureThreshold:  cfg.DeviceChecker.FailureThreshold,
+		RecoveryThreshold: cfg.DeviceChecker.RecoveryThreshold,
+		Log:               log,
+	})
+	if err != nil {
+		log.Fatal("init device status service error", zap.Error(err))
+	}
+	unknownSourcesService, err := services.NewUnknownSourcesService(services.UnknownSourcesServiceConfig{
+		Repo:             unknownSourcesRepo,
+		DeviceHandler:    deviceChecker,
+		Messages:         messagesService,
+		AutoRegister:     cfg.Service.UnknownAutoRegister,
+		AutoRegisterType: cfg.Service.UnknownAutoRegisterType,
+		Log:              log,
+	})
+	if err != nil {
+		log.Fatal("init unknown sources service error", zap.Error(err))
+	}
+	discoveryService, err := services.NewDiscoveryService(services.DiscoveryServiceConfig{
+		Repo:          discoveryRepo,
+		Scanner:       devicechecker.NewHostScanner(cfg.Discovery.Timeout),
+		DeviceHandler: deviceChecker,
+		Messages:      messagesService,
+		Workers:       cfg.Discovery.Workers,
+		MaxHosts:      cfg.Discovery.MaxHosts,
+		Log:           log,
+	})
+	if err != nil {
+		log.Fatal("init discovery service error", zap.Error(err))
+	}
+	reportSubscriptionsConfig.Messages = messagesService
+	reportSubscriptionsConfig.DeviceStatus = deviceStatusService
+	reportSubscriptionsService, err := services.NewReportSubscriptionsService(reportSubscriptionsConfig)
+	if err != nil {
+		log.Fatal("init report subscriptions service error", zap.Error(err))
+	}
 
 	httpServer := http.NewServer(http.Config{
 		Log:             log,
@@ -72,6 +178,18 @@ func Run(ctx context.Context, cfg *config.Config, stop context.CancelFunc) {
 		TagsHandler:     tagsService,
 		ReportsHandler:  messagesService,
 		DeviceChecker:   deviceChecker,
+		UnknownSources:  unknownSourcesService,
+		Credentials:     deviceCredentialsService,
+		Probes:          probesService,
+		DeviceStatus:    deviceStatusService,
+		Dependencies:    deviceDependenciesService,
+		DeviceGroups:    deviceGroupsService,
+		Discovery:       discoveryService,
+		DeviceAddresses: deviceAddressesService,
+		Subscriptions:   reportSubscriptionsService,
+		IngestAuth:      cfg.Server.IngestAuth,
+		IngestStrictIP:  cfg.Server.IngestStrictIP,
+		OTLPAddressAttr: cfg.Server.OTLPAddressAttr,
 	})
 
 	go func() {
@@ -91,6 +209,99 @@ func Run(ctx context.Context, cfg *config.Config, stop context.CancelFunc) {
 
 	log.Info("start http server", zap.String("listen_on", cfg.Server.Addr))
 
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Addr != "" {
+		grpcServer = grpc.NewServer(grpc.Config{
+			Log:             log,
+			JwtKey:          cfg.Server.JwtKey,
+			TokenLifeTime:   cfg.Server.TokenLifeTime,
+			Addr:            cfg.GRPC.Addr,
+			AuthHandlers:    authService,
+			DevicesHandlers: devicesService,
+			TagsHandler:     tagsService,
+			ReportsHandler:  messagesService,
+			DeviceChecker:   deviceChecker,
+			UnknownSources:  unknownSourcesService,
+			Credentials:     deviceCredentialsService,
+			Probes:          probesService,
+			Dependencies:    deviceDependenciesService,
+			IngestAuth:      cfg.Server.IngestAuth,
+			IngestStrictIP:  cfg.Server.IngestStrictIP,
+		})
+
+		go func() {
+			if err := grpcServer.Run(); err != nil {
+				log.Error(fmt.Sprintf("error occurred while running gRPC server: %v", err))
+				stop()
+			}
+		}()
+
+		log.Info("start grpc server", zap.String("listen_on", cfg.GRPC.Addr))
+	}
+
+	deviceCheckerHandler := devicechecker.NewDeviceCheckerHandler(&devicechecker.Config{
+		Probes:  probesService,
+		Status:  deviceStatusService,
+		Workers: cfg.DeviceChecker.Workers,
+		Logger:  log,
+	})
+	deviceCheckerHandler.Start()
+
+	var syslogServer *syslog.Server
+	if cfg.Syslog.UDPAddr != "" || cfg.Syslog.TCPAddr != "" {
+		syslogServer = syslog.NewServer(syslog.Config{
+			UDPAddr:        cfg.Syslog.UDPAddr,
+			TCPAddr:        cfg.Syslog.TCPAddr,
+			Messages:       messagesService,
+			UnknownSources: unknownSourcesService,
+			Log:            log,
+		})
+
+		go func() {
+			if err := syslogServer.Run(); err != nil {
+				log.Error(fmt.Sprintf("error occurred while running syslog server: %v", err))
+				stop()
+			}
+		}()
+
+		log.Info("start syslog server",
+			zap.String("udp_listen_on", cfg.Syslog.UDPAddr),
+			zap.String("tcp_listen_on", cfg.Syslog.TCPAddr),
+		)
+	}
+
+	var snmpServer *snmp.Server
+	if cfg.SNMP.Addr != "" {
+		var oidNames map[string]string
+		if cfg.SNMP.OIDNamesPath != "" {
+			oidNames, err = snmp.LoadOIDNames(cfg.SNMP.OIDNamesPath)
+			if err != nil {
+				log.Fatal("load snmp oid names error", zap.Error(err))
+			}
+		}
+
+		snmpServer = snmp.NewServer(snmp.Config{
+			Addr:           cfg.SNMP.Addr,
+			Community:      cfg.SNMP.Community,
+			OIDNames:       oidNames,
+			Messages:       messagesService,
+			UnknownSources: unknownSourcesService,
+			Log:            log,
+		})
+
+		go func() {
+			if err := snmpServer.Run(); err != nil {
+				log.Error(fmt.Sprintf("error occurred while running snmp trap server: %v", err))
+				stop()
+			}
+		}()
+
+		log.Info("start snmp trap server",
+			zap.String("listen_on", cfg.SNMP.Addr),
+			zap.Int("oid_names", len(oidNames)),
+		)
+	}
+
 	// Shutdown
 	<-ctx.Done()
 
@@ -99,6 +310,22 @@ func Run(ctx context.Context, cfg *config.Config, stop context.CancelFunc) {
 	closer := closer.Closer{}
 
 	closer.Add(httpServer.Stop)
+	if grpcServer != nil {
+		closer.Add(grpcServer.Stop)
+	}
+	if syslogServer != nil {
+		closer.Add(syslogServer.Stop)
+	}
+	if snmpServer != nil {
+		closer.Add(snmpServer.Stop)
+	}
+	closer.Add(deviceCheckerHandler.Stop)
+	closer.Add(discoveryService.Close)
+	closer.Add(reportSubscriptionsService.Close)
+	closer.Add(deviceChecker.Close)
+	closer.Add(unknownSourcesService.Close)
+	// Drain the ingest queue only after every source has stopped.
+	closer.Add(messagesService.Close)
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
 	defer cancel()
@@ -24,8 +24,12 @@ type Device struct {
 	DeviceType  string           `db:"device_type"`
 	Address     string           `db:"address"`
 	Responsible SqlJsonbIntArray `db:"responsible"`
-	CreatedAt   *time.Time       `db:"created_at"`
-	UpdatedAt   *time.Time       `db:"updated_at"`
+	// Attributes describe the device (vendor, model, serial, ...), Labels
+	// group devices for selectors (env, tier, ...). Both are free-form.
+	Attributes SqlJsonbStringMap `db:"attributes"`
+	Labels     SqlJsonbStringMap `db:"labels"`
+	CreatedAt  *time.Time        `db:"created_at"`
+	UpdatedAt  *time.Time        `db:"updated_at"`
 }
 
 type SqlJsonbIntArray []int32
@@ -44,6 +48,43 @@ func (arr *SqlJsonbIntArray) Scan(value interface{}) error {
 	return err
 }
 
+type SqlJsonbStringMap map[string]string
+
+// Value stores a nil map as an empty object.
+func (m SqlJsonbStringMap) Value() (driver.Value, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	res, err := json.Marshal(map[string]string(m))
+	return res, err
+}
+
+func (m *SqlJsonbStringMap) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("SqlJsonbStringMap: unexpected type %T", value)
+	}
+	return json.Unmarshal(b, (*map[string]string)(m))
+}
+
+// Operators of a SelectorRequirement.
+const (
+	SelectorOpEquals    = "="
+	SelectorOpNotEquals = "!="
+	SelectorOpIn        = "in"
+	SelectorOpNotIn     = "notin"
+	SelectorOpExists    = "exists"
+	SelectorOpNotExists = "!exists"
+)
+
+// SelectorRequirement is one term of a label or attribute selector such as
+// "env=prod" or "vendor in (cisco,juniper)".
+type SelectorRequirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
 type Tag struct {
 	ID            int32      `db:"id"`
 	Name          string     `db:"name"`
@@ -65,15 +106,155 @@ func (t Tag) Compare(val string) bool {
 	return t.CompareFunc(val, t.Value)
 }
 
+// Time bases of reports: the time a message was received (got_at) or the
+// time the device says it happened (event_at, falling back to got_at).
+const (
+	TimeBasisReceived = "received"
+	TimeBasisEvent    = "event"
+)
+
+// Orders of a message search: best match or newest first.
+const (
+	SearchOrderRank = "rank"
+	SearchOrderTime = "time"
+)
+
 type Message struct {
-	Id            int32     `db:"id"`
-	GotAt         time.Time `db:"got_at"`
-	DeviceId      int32     `db:"device_id"`
-	Message       string    `db:"message"`
-	MessageType   string    `db:"message_type"`
-	SeverityLevel string    `db:"severity_level"`
-	Component     string    `db:"component"`
-	DeviceIP      string    `db:"-"`
+	Id            int32      `db:"id"`
+	GotAt         time.Time  `db:"got_at"`
+	EventAt       *time.Time `db:"event_at"`
+	DeviceId      int32      `db:"device_id"`
+	Message       string     `db:"message"`
+	MessageType   string     `db:"message_type"`
+	SeverityLevel string     `db:"severity_level"`
+	Component     string     `db:"component"`
+	DeviceIP      string     `db:"device_ip"`
+	MessageID     string     `db:"-"`
+}
+
+// MessageSearchHit is a message found by a search. Snippet is HTML: the
+// message text around the matches, escaped, with the matches in <mark>.
+type MessageSearchHit struct {
+	Message
+	Rank    float32 `db:"rank"`
+	Snippet string  `db:"snippet"`
+}
+
+const (
+	UnknownSourceStatusPending  = "pending"
+	UnknownSourceStatusBlocked  = "blocked"
+	UnknownSourceStatusPromoted = "promoted"
+)
+
+type UnknownSource struct {
+	ID           int32     `db:"id"`
+	Address      string    `db:"address"`
+	FirstSeen    time.Time `db:"first_seen"`
+	LastSeen     time.Time `db:"last_seen"`
+	MessageCount int64     `db:"message_count"`
+	Status       string    `db:"status"`
+	DeviceID     *int32    `db:"device_id"`
+}
+
+// DeviceCredential is an ingest token of a device. Only the SHA-256 hash of
+// the token is stored; DeviceAddress is filled by reads joined with devices.
+type DeviceCredential struct {
+	ID            int32      `db:"id"`
+	DeviceID      int32      `db:"device_id"`
+	TokenHash     []byte     `db:"token_hash"`
+	CreatedAt     time.Time  `db:"created_at"`
+	RevokedAt     *time.Time `db:"revoked_at"`
+	DeviceAddress string     `db:"address"`
+}
+
+const (
+	ProbeTypeHTTP = "http"
+	ProbeTypeTCP  = "tcp"
+	ProbeTypeDNS  = "dns"
+)
+
+// Probe is a periodic availability check of a device. Target depends on the
+// type: a URL for http, a port or host:port for tcp and a name to resolve
+// through the device for dns; an empty target falls back to the device
+// address. DeviceAddress is filled by reads joined with devices.
+type Probe struct {
+	ID             int32      `db:"id"`
+	DeviceID       int32      `db:"device_id"`
+	ProbeType      string     `db:"probe_type"`
+	Target         string     `db:"target"`
+	ExpectedStatus int32      `db:"expected_status"`
+	BodyRegexp     string     `db:"body_regexp"`
+	IntervalSec    int32      `db:"interval_sec"`
+	TimeoutMs      int32      `db:"timeout_ms"`
+	Enabled        bool       `db:"enabled"`
+	CreatedAt      *time.Time `db:"created_at"`
+	UpdatedAt      *time.Time `db:"updated_at"`
+	DeviceAddress  string     `db:"address"`
+
+	CompiledRegexp *regexp.Regexp `json:"-" db:"-"`
+}
+
+const (
+	DeviceStatusUnknown  = "unknown"
+	DeviceStatusUp       = "up"
+	DeviceStatusDegraded = "degraded"
+	DeviceStatusDown     = "down"
+)
+
+// DeviceStatusChange is a transition of a device between statuses. The
+// current status of a device is its latest change.
+type DeviceStatusChange struct {
+	ID             int32     `db:"id"`
+	DeviceID       int32     `db:"device_id"`
+	Status         string    `db:"status"`
+	PreviousStatus string    `db:"previous_status"`
+	Reason         string    `db:"reason"`
+	ChangedAt      time.Time `db:"changed_at"`
+	// Suppressed is set when an upstream device was down at the time of
+	// the change, so nobody was notified.
+	Suppressed bool `db:"suppressed"`
+}
+
+const (
+	DeviceGroupTypeSite  = "site"
+	DeviceGroupTypeRoom  = "room"
+	DeviceGroupTypeRack  = "rack"
+	DeviceGroupTypeGroup = "group"
+)
+
+// DeviceGroup is a node of the site, room and rack tree. Roots have no
+// ParentID. A device may be a member of several groups.
+type DeviceGroup struct {
+	ID        int32            `db:"id"`
+	Name      string           `db:"name"`
+	GroupType string           `db:"group_type"`
+	ParentID  *int32           `db:"parent_id"`
+	Devices   SqlJsonbIntArray `db:"devices"`
+	CreatedAt *time.Time       `db:"created_at"`
+	UpdatedAt *time.Time       `db:"updated_at"`
+}
+
+// DeviceDependency makes ParentID upstream of DeviceID: the device is not
+// reachable while the parent is down. Dependencies form a DAG.
+type DeviceDependency struct {
+	DeviceID  int32     `db:"device_id"`
+	ParentID  int32     `db:"parent_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// AvailabilityReportRow covers the part of a period in which the status of
+// the device was known. Degraded time counts as available. Outages are
+// clipped to the period; MTTRSec averages outages that ended within it.
+type AvailabilityReportRow struct {
+	DeviceID         int32    `db:"device_id"`
+	UptimePercent    *float64 `db:"-"`
+	MonitoredSec     float64  `db:"monitored_sec"`
+	UpSec            float64  `db:"up_sec"`
+	DegradedSec      float64  `db:"degraded_sec"`
+	DownSec          float64  `db:"down_sec"`
+	Outages          int32    `db:"outages"`
+	LongestOutageSec float64  `db:"longest_outage_sec"`
+	MTTRSec          *float64 `db:"mttr_sec"`
 }
 
 type CountByDeviceID struct {
@@ -81,6 +262,17 @@ type CountByDeviceID struct {
 	Count    int32 `db:"count"`
 }
 
+// SuppressedAlert is a tag alert that was not notified because a device
+// upstream of its device was down.
+type SuppressedAlert struct {
+	ID               int32     `db:"id"`
+	DeviceID         int32     `db:"device_id"`
+	UpstreamDeviceID int32     `db:"upstream_device_id"`
+	Subject          string    `db:"subject"`
+	Message          string    `db:"message"`
+	SuppressedAt     time.Time `db:"suppressed_at"`
+}
+
 type SendedNotification struct {
 	Message   string
 	DeviceId  int32
@@ -105,3 +297,185 @@ type MonthReportRow struct {
 	CriticalPercentage     float64         `db:"critical_percentage"`
 	OverallVolumeRank      int32           `db:"overall_volume_rank"`
 }
+
+// Dimensions a period report can be grouped by.
+const (
+	ReportDimensionDevice      = "device"
+	ReportDimensionMessageType = "message_type"
+	ReportDimensionComponent   = "component"
+)
+
+// PeriodReportRow is a group of messages of a period report. The columns of
+// dimensions the report is not grouped by are null; a null Component of a
+// report grouped by component stands for messages without one.
+type PeriodReportRow struct {
+	DeviceID               sql.NullInt32   `db:"device_id"`
+	MessageType            sql.NullString  `db:"message_type"`
+	Component              sql.NullString  `db:"component"`
+	ActiveDays             int32           `db:"active_days"`
+	TotalMessages          int64           `db:"total_messages"`
+	AvgDailyMessages       float64         `db:"avg_daily_messages"`
+	MaxDailyMessages       int64           `db:"max_daily_messages"`
+	MedianDailyMessages    float64         `db:"median_daily_messages"`
+	TotalCritical          int64           `db:"total_critical"`
+	MaxDailyCritical       int64           `db:"max_daily_critical"`
+	MaxDailyComponents     int32           `db:"max_daily_components"`
+	MostActiveComponent    sql.NullString  `db:"most_active_component"`
+	FirstCriticalTime      sql.NullTime    `db:"first_critical_time"`
+	LastCriticalTime       sql.NullTime    `db:"last_critical_time"`
+	AvgCriticalIntervalSec sql.NullFloat64 `db:"avg_critical_interval_sec"`
+	CriticalPercentage     float64         `db:"critical_percentage"`
+	OverallVolumeRank      int32           `db:"overall_volume_rank"`
+}
+
+// MessageRollupState is the range of receive days in
+// [FirstDay, CompleteUntil) that the daily message rollups cover. Messages
+// received before FirstDay or from CompleteUntil on are only in messages.
+type MessageRollupState struct {
+	FirstDay      time.Time `db:"first_day"`
+	CompleteUntil time.Time `db:"complete_until"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+const (
+	DiscoveryJobStatusPending = "pending"
+	DiscoveryJobStatusRunning = "running"
+	DiscoveryJobStatusDone    = "done"
+	DiscoveryJobStatusFailed  = "failed"
+)
+
+// DiscoveryJob scans the hosts of CIDR on Ports and, with Healthcheck, on
+// the /healthcheck endpoint the device checker uses.
+type DiscoveryJob struct {
+	ID              int32            `db:"id"`
+	CIDR            string           `db:"cidr"`
+	Ports           SqlJsonbIntArray `db:"ports"`
+	Healthcheck     bool             `db:"healthcheck"`
+	Status          string           `db:"status"`
+	HostsTotal      int32            `db:"hosts_total"`
+	HostsScanned    int32            `db:"hosts_scanned"`
+	CandidatesFound int32            `db:"candidates_found"`
+	Error           *string          `db:"error"`
+	CreatedAt       time.Time        `db:"created_at"`
+	StartedAt       *time.Time       `db:"started_at"`
+	FinishedAt      *time.Time       `db:"finished_at"`
+}
+
+const (
+	DiscoveryCandidateStatusPending   = "pending"
+	DiscoveryCandidateStatusPromoted  = "promoted"
+	DiscoveryCandidateStatusDismissed = "dismissed"
+)
+
+// DiscoveryCandidate is a responsive host that is not a device. JobID is
+// the job that saw it last; a dismissed candidate stays dismissed when
+// later jobs see it again.
+type DiscoveryCandidate struct {
+	ID          int32            `db:"id"`
+	JobID       int32            `db:"job_id"`
+	Address     string           `db:"address"`
+	OpenPorts   SqlJsonbIntArray `db:"open_ports"`
+	Healthcheck bool             `db:"healthcheck"`
+	Status      string           `db:"status"`
+	DeviceID    *int32           `db:"device_id"`
+	FirstSeen   time.Time        `db:"first_seen"`
+	LastSeen    time.Time        `db:"last_seen"`
+}
+
+const (
+	DeviceAddressTypeIP       = "ip"
+	DeviceAddressTypeCIDR     = "cidr"
+	DeviceAddressTypeHostname = "hostname"
+)
+
+// DeviceAddress is an extra address of a device besides Device.Address: an
+// IP, a CIDR range the device sends from (e.g. behind NAT) or a DNS
+// hostname. Value holds the IP or the range in canonical form, or the
+// hostname.
+type DeviceAddress struct {
+	ID          int32     `db:"id"`
+	DeviceID    int32     `db:"device_id"`
+	AddressType string    `db:"address_type"`
+	Value       string    `db:"value"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+const (
+	ReportMessagesByPeriod   = "messages_by_period"
+	ReportCountByMessageType = "count_by_message_type"
+	ReportMonth              = "month_report"
+	ReportAvailability       = "availability"
+)
+
+const (
+	ReportFormatCSV  = "csv"
+	ReportFormatXLSX = "xlsx"
+)
+
+const (
+	ReportRunStatusOK     = "ok"
+	ReportRunStatusFailed = "failed"
+)
+
+// ReportSubscription mails Report to Recipients on Schedule, a standard
+// five-field cron expression. The last run is recorded whether it was
+// scheduled or started by hand.
+type ReportSubscription struct {
+	ID         int32                    `db:"id"`
+	Name       string                   `db:"name"`
+	Report     string                   `db:"report"`
+	Params     ReportSubscriptionParams `db:"params"`
+	Schedule   string                   `db:"schedule"`
+	Recipients SqlJsonbStringArray      `db:"recipients"`
+	Format     string                   `db:"format"`
+	Enabled    bool                     `db:"enabled"`
+	LastRunAt  *time.Time               `db:"last_run_at"`
+	LastStatus *string                  `db:"last_status"`
+	LastError  *string                  `db:"last_error"`
+	CreatedAt  *time.Time               `db:"created_at"`
+	UpdatedAt  *time.Time               `db:"updated_at"`
+}
+
+// ReportSubscriptionParams are the parameters of the subscribed report; each
+// report reads only its own. Period is a duration such as "24h" or "168h":
+// the report covers the period that ends at the run.
+type ReportSubscriptionParams struct {
+	Period      string   `json:"period,omitempty"`
+	TimeBasis   string   `json:"time_basis,omitempty"`
+	GroupID     int32    `json:"group_id,omitempty"`
+	DeviceID    int32    `json:"device_id,omitempty"`
+	MessageType string   `json:"message_type,omitempty"`
+	Severities  []string `json:"severities,omitempty"`
+}
+
+func (p ReportSubscriptionParams) Value() (driver.Value, error) {
+	res, err := json.Marshal(p)
+	return res, err
+}
+
+func (p *ReportSubscriptionParams) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("ReportSubscriptionParams: unexpected type %T", value)
+	}
+	return json.Unmarshal(b, p)
+}
+
+type SqlJsonbStringArray []string
+
+// Value stores a nil slice as an empty array.
+func (arr SqlJsonbStringArray) Value() (driver.Value, error) {
+	if arr == nil {
+		return []byte("[]"), nil
+	}
+	res, err := json.Marshal([]string(arr))
+	return res, err
+}
+
+func (arr *SqlJsonbStringArray) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("SqlJsonbStringArray: unexpected type %T", value)
+	}
+	return json.Unmarshal(b, (*[]string)(arr))
+}
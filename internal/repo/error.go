@@ -8,4 +8,32 @@ var (
 
 	ErrDeviceExists   = errors.New("device already exists")
 	ErrDeviceNotFound = errors.New("device not found")
+
+	ErrUnknownSourceNotFound = errors.New("unknown source not found")
+	ErrUnknownSourcePromoted = errors.New("unknown source is already promoted")
+
+	ErrDeviceCredentialExists   = errors.New("device already has an active credential")
+	ErrDeviceCredentialNotFound = errors.New("device credential not found")
+
+	ErrProbeNotFound = errors.New("probe not found")
+
+	ErrDependencyExists   = errors.New("dependency already exists")
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrDependencyCycle    = errors.New("dependency would create a cycle")
+
+	ErrDeviceGroupExists         = errors.New("device group already exists")
+	ErrDeviceGroupNotFound       = errors.New("device group not found")
+	ErrDeviceGroupCycle          = errors.New("device group would be its own subgroup")
+	ErrDeviceGroupNotEmpty       = errors.New("device group has subgroups")
+	ErrDeviceGroupMemberNotFound = errors.New("device is not a member of the group")
+
+	ErrDeviceAddressExists   = errors.New("address is already assigned to a device")
+	ErrDeviceAddressNotFound = errors.New("device address not found")
+
+	ErrDiscoveryJobNotFound        = errors.New("discovery job not found")
+	ErrDiscoveryCandidateNotFound  = errors.New("discovery candidate not found")
+	ErrDiscoveryCandidateProcessed = errors.New("discovery candidate is already promoted or dismissed")
+
+	ErrReportSubscriptionExists   = errors.New("report subscription already exists")
+	ErrReportSubscriptionNotFound = errors.New("report subscription not found")
 )
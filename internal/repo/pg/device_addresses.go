@@ -0,0 +1,173 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/pkg/postgres"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type deviceAddressesRepo struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+
+	log *zap.Logger
+}
+
+func NewDeviceAddressesRepo(p *postgres.Postgres, log *zap.Logger) repo.DeviceAddresses {
+	return &deviceAddressesRepo{
+		db:  p.DB,
+		log: log,
+	}
+}
+
+func (r deviceAddressesRepo) BeginTx(ctx context.Context) (repo.DeviceAddresses, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{
+		Isolation: 0,
+		ReadOnly:  false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("r.db.BeginTx: %w", err)
+	}
+
+	r.tx = tx
+
+	return r, nil
+}
+
+func (r deviceAddressesRepo) Commit() error {
+	err := r.tx.Commit()
+	if err != nil {
+		return fmt.Errorf("r.tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r deviceAddressesRepo) Rollback() error {
+	err := r.tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("r.tx.Rollback: %w", err)
+	}
+
+	return nil
+}
+
+// deviceAddressesRepoValue renders the address of an ip entry without the
+// prefix length.
+const deviceAddressesRepoValue = `
+case when a.address_type = 'ip' then host(a.address)
+	else coalesce(cast(a.address as text), a.hostname) end as value`
+
+// deviceAddressesRepoQueryAdd returns no rows when the device does not
+// exist.
+const deviceAddressesRepoQueryAdd = `
+with inserted as (
+	insert into device_addresses (device_id, address_type, address, hostname, created_at)
+	select d.id, :address_type, cast(:address as cidr), :hostname, :created_at
+	from devices d
+	where d.id = :device_id and d.deleted_at is null
+	returning id, device_id, address_type, address, hostname, created_at
+)
+select a.id, a.device_id, a.address_type, ` + deviceAddressesRepoValue + `, a.created_at
+from inserted a;
+`
+
+func (r deviceAddressesRepo) Add(ctx context.Context, opts models.DeviceAddress) (models.DeviceAddress, error) {
+	var address, hostname *string
+	if opts.AddressType == models.DeviceAddressTypeHostname {
+		hostname = &opts.Value
+	} else {
+		address = &opts.Value
+	}
+
+	query, args, err := sqlx.Named(deviceAddressesRepoQueryAdd,
+		map[string]any{
+			"device_id":    opts.DeviceID,
+			"address_type": opts.AddressType,
+			"address":      address,
+			"hostname":     hostname,
+			"created_at":   opts.CreatedAt,
+		},
+	)
+	if err != nil {
+		return models.DeviceAddress{}, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	var ret models.DeviceAddress
+	err = r.tx.GetContext(ctx, &ret, query, args...)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) && pgerr.Code == pgErrCodeUniqueViolation {
+			return models.DeviceAddress{}, repo.ErrDeviceAddressExists
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DeviceAddress{}, repo.ErrDeviceNotFound
+		}
+		return models.DeviceAddress{}, fmt.Errorf("r.tx.GetContext: %w", err)
+	}
+
+	return ret, nil
+}
+
+const deviceAddressesRepoQueryRead = `
+select a.id, a.device_id, a.address_type, ` + deviceAddressesRepoValue + `, a.created_at
+from device_addresses a
+join devices d on d.id = a.device_id and d.deleted_at is null
+where :device_id = 0 or a.device_id = :device_id
+order by a.device_id, a.id;
+`
+
+func (r deviceAddressesRepo) Read(ctx context.Context, deviceID int32) ([]models.DeviceAddress, error) {
+	addresses := make([]models.DeviceAddress, 0)
+
+	query, args, err := sqlx.Named(deviceAddressesRepoQueryRead, map[string]any{
+		"device_id": deviceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	err = r.tx.SelectContext(ctx, &addresses, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return addresses, nil
+}
+
+const deviceAddressesRepoQueryDelete = `
+delete from device_addresses
+where id = :id;
+`
+
+func (r deviceAddressesRepo) Delete(ctx context.Context, id int32) error {
+	res, err := r.tx.NamedExecContext(ctx, deviceAddressesRepoQueryDelete,
+		map[string]any{
+			"id": id,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if deleted == 0 {
+		return repo.ErrDeviceAddressNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/pkg/postgres"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type deviceCredentialsRepo struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+
+	log *zap.Logger
+}
+
+func NewDeviceCredentialsRepo(p *postgres.Postgres, log *zap.Logger) repo.DeviceCredentials {
+	return &deviceCredentialsRepo{
+		db:  p.DB,
+		log: log,
+	}
+}
+
+func (r deviceCredentialsRepo) BeginTx(ctx context.Context) (repo.DeviceCredentials, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{
+		Isolation: 0,
+		ReadOnly:  false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("r.db.BeginTx: %w", err)
+	}
+
+	r.tx = tx
+
+	return r, nil
+}
+
+func (r deviceCredentialsRepo) Commit() error {
+	err := r.tx.Commit()
+	if err != nil {
+		return fmt.Errorf("r.tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r deviceCredentialsRepo) Rollback() error {
+	err := r.tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("r.tx.Rollback: %w", err)
+	}
+
+	return nil
+}
+
+// deviceCredentialsRepoQueryInsert returns no rows for a missing or deleted
+// device, which is reported as repo.ErrDeviceNotFound.
+const deviceCredentialsRepoQueryInsert = `
+insert into device_credentials (device_id, token_hash, created_at)
+select d.id, :token_hash, :created_at
+from devices d
+where d.id = :device_id and d.deleted_at is null
+returning id, device_id, token_hash, created_at, revoked_at;
+`
+
+func (r deviceCredentialsRepo) Issue(
+	ctx context.Context,
+	opts repo.IssueDeviceCredentialOpts,
+) (models.DeviceCredential, error) {
+	if opts.Rotate {
+		if err := r.revoke(ctx, opts.DeviceID); err != nil {
+			return models.DeviceCredential{}, err
+		}
+	}
+
+	rows, err := r.tx.NamedQuery(deviceCredentialsRepoQueryInsert,
+		map[string]any{
+			"device_id":  opts.DeviceID,
+			"token_hash": opts.TokenHash,
+			"created_at": time.Now(),
+		},
+	)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) {
+			if pgerr.Code == pgErrCodeUniqueViolation {
+				return models.DeviceCredential{}, repo.ErrDeviceCredentialExists
+			}
+		}
+
+		return models.DeviceCredential{}, fmt.Errorf("r.tx.NamedQuery: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.log.Error("failed to closing rows", zap.Error(err))
+		}
+	}()
+
+	if !rows.Next() {
+		return models.DeviceCredential{}, repo.ErrDeviceNotFound
+	}
+	var credential models.DeviceCredential
+	err = rows.StructScan(&credential)
+	if err != nil {
+		return models.DeviceCredential{}, fmt.Errorf("rows.StructScan: %w", err)
+	}
+
+	return credential, nil
+}
+
+const deviceCredentialsRepoQueryRevoke = `
+update device_credentials
+set revoked_at = :revoked_at
+where device_id = :device_id and revoked_at is null;
+`
+
+func (r deviceCredentialsRepo) Revoke(ctx context.Context, deviceID int32) error {
+	res, err := r.tx.NamedExecContext(ctx, deviceCredentialsRepoQueryRevoke,
+		map[string]any{
+			"device_id":  deviceID,
+			"revoked_at": time.Now(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	revoked, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if revoked == 0 {
+		return repo.ErrDeviceCredentialNotFound
+	}
+
+	return nil
+}
+
+func (r deviceCredentialsRepo) revoke(ctx context.Context, deviceID int32) error {
+	err := r.Revoke(ctx, deviceID)
+	if err != nil && !errors.Is(err, repo.ErrDeviceCredentialNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+const deviceCredentialsRepoQueryReadActive = `
+select c.id, c.device_id, c.token_hash, c.created_at, c.revoked_at, d.address
+from device_credentials c
+join devices d on d.id = c.device_id
+where c.revoked_at is null and d.deleted_at is null;
+`
+
+func (r deviceCredentialsRepo) ReadActive(ctx context.Context) ([]models.DeviceCredential, error) {
+	credentials := make([]models.DeviceCredential, 0)
+
+	err := r.tx.SelectContext(ctx, &credentials, deviceCredentialsRepoQueryReadActive)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return credentials, nil
+}
@@ -0,0 +1,206 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/pkg/postgres"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type deviceDependenciesRepo struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+
+	log *zap.Logger
+}
+
+func NewDeviceDependenciesRepo(p *postgres.Postgres, log *zap.Logger) repo.DeviceDependencies {
+	return &deviceDependenciesRepo{
+		db:  p.DB,
+		log: log,
+	}
+}
+
+func (r deviceDependenciesRepo) BeginTx(ctx context.Context) (repo.DeviceDependencies, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{
+		Isolation: 0,
+		ReadOnly:  false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("r.db.BeginTx: %w", err)
+	}
+
+	r.tx = tx
+
+	return r, nil
+}
+
+func (r deviceDependenciesRepo) Commit() error {
+	err := r.tx.Commit()
+	if err != nil {
+		return fmt.Errorf("r.tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r deviceDependenciesRepo) Rollback() error {
+	err := r.tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("r.tx.Rollback: %w", err)
+	}
+
+	return nil
+}
+
+// deviceDependenciesRepoQueryLock serializes additions, so two concurrent
+// edges cannot close a cycle that neither of them sees alone.
+const deviceDependenciesRepoQueryLock = `
+lock table device_dependencies in share row exclusive mode;
+`
+
+// deviceDependenciesRepoQueryCreatesCycle walks up from the new parent; the
+// edge closes a cycle when the device is among its ancestors.
+const deviceDependenciesRepoQueryCreatesCycle = `
+with recursive ancestors as (
+	select parent_id from device_dependencies
+	where device_id = :parent_id
+	union
+	select dd.parent_id from device_dependencies dd
+	join ancestors a on dd.device_id = a.parent_id
+)
+select exists(select 1 from ancestors where parent_id = :device_id);
+`
+
+// deviceDependenciesRepoQueryInsert returns no rows when either device is
+// missing or deleted, which is reported as repo.ErrDeviceNotFound.
+const deviceDependenciesRepoQueryInsert = `
+insert into device_dependencies (device_id, parent_id, created_at)
+select d.id, p.id, :created_at
+from devices d
+join devices p on p.id = :parent_id and p.deleted_at is null
+where d.id = :device_id and d.deleted_at is null
+returning device_id, parent_id, created_at;
+`
+
+func (r deviceDependenciesRepo) Add(ctx context.Context, deviceID, parentID int32) (models.DeviceDependency, error) {
+	if deviceID == parentID {
+		return models.DeviceDependency{}, repo.ErrDependencyCycle
+	}
+
+	if _, err := r.tx.ExecContext(ctx, deviceDependenciesRepoQueryLock); err != nil {
+		return models.DeviceDependency{}, fmt.Errorf("r.tx.ExecContext: %w", err)
+	}
+
+	args := map[string]any{
+		"device_id":  deviceID,
+		"parent_id":  parentID,
+		"created_at": time.Now(),
+	}
+
+	query, queryArgs, err := sqlx.Named(deviceDependenciesRepoQueryCreatesCycle, args)
+	if err != nil {
+		return models.DeviceDependency{}, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	var cycle bool
+	if err := r.tx.GetContext(ctx, &cycle, query, queryArgs...); err != nil {
+		return models.DeviceDependency{}, fmt.Errorf("r.tx.GetContext: %w", err)
+	}
+	if cycle {
+		return models.DeviceDependency{}, repo.ErrDependencyCycle
+	}
+
+	rows, err := r.tx.NamedQuery(deviceDependenciesRepoQueryInsert, args)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) {
+			if pgerr.Code == pgErrCodeUniqueViolation {
+				return models.DeviceDependency{}, repo.ErrDependencyExists
+			}
+		}
+
+		return models.DeviceDependency{}, fmt.Errorf("r.tx.NamedQuery: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.log.Error("failed to closing rows", zap.Error(err))
+		}
+	}()
+
+	if !rows.Next() {
+		// The unique violation is only reported once the rows are read.
+		if err := rows.Err(); err != nil {
+			var pgerr *pgconn.PgError
+			if errors.As(err, &pgerr) && pgerr.Code == pgErrCodeUniqueViolation {
+				return models.DeviceDependency{}, repo.ErrDependencyExists
+			}
+			return models.DeviceDependency{}, fmt.Errorf("rows.Err: %w", err)
+		}
+		return models.DeviceDependency{}, repo.ErrDeviceNotFound
+	}
+	var dependency models.DeviceDependency
+	err = rows.StructScan(&dependency)
+	if err != nil {
+		return models.DeviceDependency{}, fmt.Errorf("rows.StructScan: %w", err)
+	}
+
+	return dependency, nil
+}
+
+const deviceDependenciesRepoQueryDelete = `
+delete from device_dependencies
+where device_id = :device_id and parent_id = :parent_id;
+`
+
+func (r deviceDependenciesRepo) Remove(ctx context.Context, deviceID, parentID int32) error {
+	res, err := r.tx.NamedExecContext(ctx, deviceDependenciesRepoQueryDelete,
+		map[string]any{
+			"device_id": deviceID,
+			"parent_id": parentID,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if deleted == 0 {
+		return repo.ErrDependencyNotFound
+	}
+
+	return nil
+}
+
+const deviceDependenciesRepoQueryRead = `
+select dd.device_id, dd.parent_id, dd.created_at
+from device_dependencies dd
+join devices d on d.id = dd.device_id and d.deleted_at is null
+join devices p on p.id = dd.parent_id and p.deleted_at is null
+order by dd.device_id, dd.parent_id;
+`
+
+func (r deviceDependenciesRepo) Read(ctx context.Context) ([]models.DeviceDependency, error) {
+	dependencies := make([]models.DeviceDependency, 0)
+
+	err := r.tx.SelectContext(ctx, &dependencies, deviceDependenciesRepoQueryRead)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return dependencies, nil
+}
@@ -0,0 +1,375 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/pkg/postgres"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// deviceGroupsRepoQuerySubtreeDevices selects the members of the group
+// given as %[1]s and of all its subgroups. Other queries embed it to filter
+// by group.
+const deviceGroupsRepoQuerySubtreeDevices = `
+with recursive subtree as (
+	select id from device_groups where id = %[1]s
+	union
+	select g.id from device_groups g
+	join subtree s on g.parent_id = s.id
+)
+select m.device_id from device_group_members m
+join subtree s on s.id = m.group_id
+`
+
+func deviceGroupsRepoSubtreeDevices(param string) string {
+	return fmt.Sprintf(deviceGroupsRepoQuerySubtreeDevices, param)
+}
+
+type deviceGroupsRepo struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+
+	log *zap.Logger
+}
+
+func NewDeviceGroupsRepo(p *postgres.Postgres, log *zap.Logger) repo.DeviceGroups {
+	return &deviceGroupsRepo{
+		db:  p.DB,
+		log: log,
+	}
+}
+
+func (r deviceGroupsRepo) BeginTx(ctx context.Context) (repo.DeviceGroups, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{
+		Isolation: 0,
+		ReadOnly:  false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("r.db.BeginTx: %w", err)
+	}
+
+	r.tx = tx
+
+	return r, nil
+}
+
+func (r deviceGroupsRepo) Commit() error {
+	err := r.tx.Commit()
+	if err != nil {
+		return fmt.Errorf("r.tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r deviceGroupsRepo) Rollback() error {
+	err := r.tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("r.tx.Rollback: %w", err)
+	}
+
+	return nil
+}
+
+// deviceGroupsRepoQueryInsert returns no rows when the parent does not
+// exist.
+const deviceGroupsRepoQueryInsert = `
+insert into device_groups ("name", group_type, parent_id, created_at)
+select :name, :group_type, :parent_id, :created_at
+where cast(:parent_id as int) is null or exists(select 1 from device_groups where id = :parent_id)
+returning id, "name", group_type, parent_id, created_at, updated_at, cast('[]' as jsonb) as devices;
+`
+
+func (r deviceGroupsRepo) Create(ctx context.Context, opts models.DeviceGroup) (models.DeviceGroup, error) {
+	rows, err := r.tx.NamedQuery(deviceGroupsRepoQueryInsert,
+		map[string]any{
+			"name":       opts.Name,
+			"group_type": opts.GroupType,
+			"parent_id":  opts.ParentID,
+			"created_at": time.Now(),
+		},
+	)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) && pgerr.Code == pgErrCodeUniqueViolation {
+			return models.DeviceGroup{}, repo.ErrDeviceGroupExists
+		}
+		return models.DeviceGroup{}, fmt.Errorf("r.tx.NamedQuery: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.log.Error("failed to closing rows", zap.Error(err))
+		}
+	}()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			var pgerr *pgconn.PgError
+			if errors.As(err, &pgerr) && pgerr.Code == pgErrCodeUniqueViolation {
+				return models.DeviceGroup{}, repo.ErrDeviceGroupExists
+			}
+			return models.DeviceGroup{}, fmt.Errorf("rows.Err: %w", err)
+		}
+		return models.DeviceGroup{}, repo.ErrDeviceGroupNotFound
+	}
+	var group models.DeviceGroup
+	err = rows.StructScan(&group)
+	if err != nil {
+		return models.DeviceGroup{}, fmt.Errorf("rows.StructScan: %w", err)
+	}
+
+	return group, nil
+}
+
+const deviceGroupsRepoQueryRead = `
+select g.id, g."name", g.group_type, g.parent_id, g.created_at, g.updated_at,
+	coalesce((
+		select jsonb_agg(m.device_id order by m.device_id)
+		from device_group_members m
+		join devices d on d.id = m.device_id and d.deleted_at is null
+		where m.group_id = g.id
+	), '[]') as devices
+from device_groups g
+order by g.id;
+`
+
+func (r deviceGroupsRepo) Read(ctx context.Context) ([]models.DeviceGroup, error) {
+	groups := make([]models.DeviceGroup, 0)
+
+	err := r.tx.SelectContext(ctx, &groups, deviceGroupsRepoQueryRead)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return groups, nil
+}
+
+// deviceGroupsRepoQueryLock serializes moves, so two concurrent moves cannot
+// make a cycle that neither of them sees alone.
+const deviceGroupsRepoQueryLock = `
+lock table device_groups in share row exclusive mode;
+`
+
+// deviceGroupsRepoQueryCheckParent reports whether the new parent exists
+// and whether it lies in the subtree of the moved group.
+const deviceGroupsRepoQueryCheckParent = `
+with recursive subtree as (
+	select id from device_groups where id = :id
+	union
+	select g.id from device_groups g
+	join subtree s on g.parent_id = s.id
+)
+select exists(select 1 from device_groups where id = :parent_id) as found,
+	exists(select 1 from subtree where id = :parent_id) as cycle;
+`
+
+const deviceGroupsRepoQueryUpdate = `
+update device_groups
+set "name" = coalesce(:name, "name"),
+	group_type = coalesce(:group_type, group_type),
+	parent_id = case when :move then :parent_id else parent_id end,
+	updated_at = :updated_at
+where id = :id;
+`
+
+func (r deviceGroupsRepo) Update(ctx context.Context, opts repo.UpdateDeviceGroupOpts) error {
+	var parentID *int32
+	if opts.ParentID != nil && *opts.ParentID != 0 {
+		parentID = opts.ParentID
+
+		if _, err := r.tx.ExecContext(ctx, deviceGroupsRepoQueryLock); err != nil {
+			return fmt.Errorf("r.tx.ExecContext: %w", err)
+		}
+
+		query, args, err := sqlx.Named(deviceGroupsRepoQueryCheckParent,
+			map[string]any{
+				"id":        opts.ID,
+				"parent_id": *parentID,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("sqlx.Named: %w", err)
+		}
+		query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+		var check struct {
+			Found bool `db:"found"`
+			Cycle bool `db:"cycle"`
+		}
+		if err := r.tx.GetContext(ctx, &check, query, args...); err != nil {
+			return fmt.Errorf("r.tx.GetContext: %w", err)
+		}
+		if !check.Found {
+			return repo.ErrDeviceGroupNotFound
+		}
+		if check.Cycle {
+			return repo.ErrDeviceGroupCycle
+		}
+	}
+
+	res, err := r.tx.NamedExecContext(ctx, deviceGroupsRepoQueryUpdate,
+		map[string]any{
+			"id":         opts.ID,
+			"name":       opts.Name,
+			"group_type": opts.GroupType,
+			"move":       opts.ParentID != nil,
+			"parent_id":  parentID,
+			"updated_at": time.Now(),
+		},
+	)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) && pgerr.Code == pgErrCodeUniqueViolation {
+			return repo.ErrDeviceGroupExists
+		}
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if updated == 0 {
+		return repo.ErrDeviceGroupNotFound
+	}
+
+	return nil
+}
+
+const deviceGroupsRepoQueryHasChildren = `
+select exists(select 1 from device_groups where parent_id = :id);
+`
+
+const deviceGroupsRepoQueryDelete = `
+delete from device_groups
+where id = :id;
+`
+
+const deviceGroupsRepoQueryDeleteMembers = `
+delete from device_group_members
+where group_id = :id;
+`
+
+func (r deviceGroupsRepo) Delete(ctx context.Context, id int32) error {
+	args := map[string]any{
+		"id": id,
+	}
+
+	query, queryArgs, err := sqlx.Named(deviceGroupsRepoQueryHasChildren, args)
+	if err != nil {
+		return fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	var hasChildren bool
+	if err := r.tx.GetContext(ctx, &hasChildren, query, queryArgs...); err != nil {
+		return fmt.Errorf("r.tx.GetContext: %w", err)
+	}
+	if hasChildren {
+		return repo.ErrDeviceGroupNotEmpty
+	}
+
+	res, err := r.tx.NamedExecContext(ctx, deviceGroupsRepoQueryDelete, args)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if deleted == 0 {
+		return repo.ErrDeviceGroupNotFound
+	}
+
+	_, err = r.tx.NamedExecContext(ctx, deviceGroupsRepoQueryDeleteMembers, args)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	return nil
+}
+
+// deviceGroupsRepoQueryAddDevice is a no-op for a device that is already a
+// member. It reports which of the two was found.
+const deviceGroupsRepoQueryAddDevice = `
+with g as (
+	select id from device_groups where id = :group_id
+),
+d as (
+	select id from devices where id = :device_id and deleted_at is null
+),
+inserted as (
+	insert into device_group_members (group_id, device_id, created_at)
+	select g.id, d.id, :created_at from g, d
+	on conflict do nothing
+)
+select exists(select 1 from g) as group_found, exists(select 1 from d) as device_found;
+`
+
+func (r deviceGroupsRepo) AddDevice(ctx context.Context, groupID, deviceID int32) error {
+	query, args, err := sqlx.Named(deviceGroupsRepoQueryAddDevice,
+		map[string]any{
+			"group_id":   groupID,
+			"device_id":  deviceID,
+			"created_at": time.Now(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	var found struct {
+		Group  bool `db:"group_found"`
+		Device bool `db:"device_found"`
+	}
+	if err := r.tx.GetContext(ctx, &found, query, args...); err != nil {
+		return fmt.Errorf("r.tx.GetContext: %w", err)
+	}
+	if !found.Group {
+		return repo.ErrDeviceGroupNotFound
+	}
+	if !found.Device {
+		return repo.ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+const deviceGroupsRepoQueryRemoveDevice = `
+delete from device_group_members
+where group_id = :group_id and device_id = :device_id;
+`
+
+func (r deviceGroupsRepo) RemoveDevice(ctx context.Context, groupID, deviceID int32) error {
+	res, err := r.tx.NamedExecContext(ctx, deviceGroupsRepoQueryRemoveDevice,
+		map[string]any{
+			"group_id":  groupID,
+			"device_id": deviceID,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if deleted == 0 {
+		return repo.ErrDeviceGroupMemberNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/pkg/postgres"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type deviceStatusRepo struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+
+	log *zap.Logger
+}
+
+func NewDeviceStatusRepo(p *postgres.Postgres, log *zap.Logger) repo.DeviceStatus {
+	return &deviceStatusRepo{
+		db:  p.DB,
+		log: log,
+	}
+}
+
+func (r deviceStatusRepo) BeginTx(ctx context.Context) (repo.DeviceStatus, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{
+		Isolation: 0,
+		ReadOnly:  false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("r.db.BeginTx: %w", err)
+	}
+
+	r.tx = tx
+
+	return r, nil
+}
+
+func (r deviceStatusRepo) Commit() error {
+	err := r.tx.Commit()
+	if err != nil {
+		return fmt.Errorf("r.tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r deviceStatusRepo) Rollback() error {
+	err := r.tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("r.tx.Rollback: %w", err)
+	}
+
+	return nil
+}
+
+const deviceStatusRepoQueryInsert = `
+insert into device_status_history (device_id, status, previous_status, reason, changed_at, suppressed)
+values
+(:device_id, :status, :previous_status, :reason, :changed_at, :suppressed);
+`
+
+func (r deviceStatusRepo) AddChange(ctx context.Context, change models.DeviceStatusChange) error {
+	_, err := r.tx.NamedExecContext(ctx, deviceStatusRepoQueryInsert,
+		map[string]any{
+			"device_id":       change.DeviceID,
+			"status":          change.Status,
+			"previous_status": change.PreviousStatus,
+			"reason":          change.Reason,
+			"changed_at":      change.ChangedAt,
+			"suppressed":      change.Suppressed,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	return nil
+}
+
+const deviceStatusRepoQueryReadCurrent = `
+select distinct on (h.device_id) h.id, h.device_id, h.status, h.previous_status, h.reason, h.changed_at, h.suppressed
+from device_status_history h
+join devices d on d.id = h.device_id
+where d.deleted_at is null
+order by h.device_id, h.changed_at desc, h.id desc;
+`
+
+func (r deviceStatusRepo) ReadCurrent(ctx context.Context) ([]models.DeviceStatusChange, error) {
+	changes := make([]models.DeviceStatusChange, 0)
+
+	err := r.tx.SelectContext(ctx, &changes, deviceStatusRepoQueryReadCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return changes, nil
+}
+
+// deviceStatusRepoQueryAvailability turns the history into intervals, each
+// lasting until the next change or until now, and clips them to the
+// period. Unknown intervals are left out of the monitored time.
+const deviceStatusRepoQueryAvailability = `
+with intervals as (
+	select h.device_id, h.status, h.changed_at as started_at,
+		lead(h.changed_at) over (partition by h.device_id order by h.changed_at, h.id) as ended_at
+	from device_status_history h
+	where :device_id = 0 or h.device_id = :device_id
+),
+clipped as (
+	select device_id, status,
+		extract(epoch from least(coalesce(ended_at, :now), :end_time) - greatest(started_at, :start_time)) as seconds,
+		ended_at is not null and ended_at <= :end_time as ended
+	from intervals
+	where started_at < :end_time and coalesce(ended_at, :now) > :start_time
+)
+select d.id as device_id,
+	coalesce(sum(c.seconds) filter (where c.status <> 'unknown'), 0) as monitored_sec,
+	coalesce(sum(c.seconds) filter (where c.status = 'up'), 0) as up_sec,
+	coalesce(sum(c.seconds) filter (where c.status = 'degraded'), 0) as degraded_sec,
+	coalesce(sum(c.seconds) filter (where c.status = 'down'), 0) as down_sec,
+	count(c.status) filter (where c.status = 'down') as outages,
+	coalesce(max(c.seconds) filter (where c.status = 'down'), 0) as longest_outage_sec,
+	avg(c.seconds) filter (where c.status = 'down' and c.ended) as mttr_sec
+from devices d
+left join clipped c on c.device_id = d.id and c.seconds > 0
+where d.deleted_at is null and (:device_id = 0 or d.id = :device_id)
+group by d.id
+order by d.id;
+`
+
+func (r deviceStatusRepo) Availability(
+	ctx context.Context,
+	opts repo.AvailabilityReportOpts,
+) ([]models.AvailabilityReportRow, error) {
+	query, args, err := sqlx.Named(deviceStatusRepoQueryAvailability,
+		map[string]any{
+			"start_time": opts.StartTime,
+			"end_time":   opts.EndTime,
+			"now":        opts.Now,
+			"device_id":  opts.DeviceID,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	rows := make([]models.AvailabilityReportRow, 0)
+	err = r.tx.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return rows, nil
+}
@@ -3,8 +3,11 @@ package pg
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"monolith/internal/models"
@@ -66,10 +69,10 @@ func (r devicesRepo) Rollback() error {
 }
 
 const devicesRepoQueryInsertPerson = `
-insert into devices (device_type, "name", address, responsible, created_at)
+insert into devices (device_type, "name", address, responsible, attributes, labels, created_at)
 values
-(:device_type, :name, :address, :responsible, :created_at)
-returning id, device_type, "name", address, responsible, created_at, updated_at;
+(:device_type, :name, :address, :responsible, :attributes, :labels, :created_at)
+returning id, device_type, "name", address, responsible, attributes, labels, created_at, updated_at;
 `
 
 func (r devicesRepo) Create(opts models.Device) (models.Device, error) {
@@ -79,6 +82,8 @@ func (r devicesRepo) Create(opts models.Device) (models.Device, error) {
 			"device_type": opts.DeviceType,
 			"address":     opts.Address,
 			"responsible": opts.Responsible,
+			"attributes":  opts.Attributes,
+			"labels":      opts.Labels,
 			"created_at":  time.Now(),
 		},
 	)
@@ -111,8 +116,50 @@ func (r devicesRepo) Create(opts models.Device) (models.Device, error) {
 	return device, nil
 }
 
+// insertDevice lets other repos create a device in their own transaction.
+func insertDevice(tx *sqlx.Tx, log *zap.Logger, opts models.Device) (models.Device, error) {
+	rows, err := tx.NamedQuery(devicesRepoQueryInsertPerson,
+		map[string]any{
+			"name":        opts.Name,
+			"device_type": opts.DeviceType,
+			"address":     opts.Address,
+			"responsible": opts.Responsible,
+			"attributes":  opts.Attributes,
+			"labels":      opts.Labels,
+			"created_at":  time.Now(),
+		},
+	)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) {
+			if pgerr.Code == pgErrCodeUniqueViolation {
+				return models.Device{}, repo.ErrDeviceExists
+			}
+		}
+
+		return models.Device{}, fmt.Errorf("tx.NamedQuery: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Error("failed to closing rows", zap.Error(err))
+		}
+	}()
+
+	if !rows.Next() {
+		return models.Device{}, repo.ErrDeviceNotFound
+	}
+	var device models.Device
+	err = rows.StructScan(&device)
+	if err != nil {
+		return models.Device{}, fmt.Errorf("rows.StructScan: %w", err)
+	}
+
+	return device, nil
+}
+
 const devicesRepoQueryRead = `
-select id, device_type, "name", address, responsible, created_at, updated_at from devices
+select id, device_type, "name", address, responsible, attributes, labels, created_at, updated_at from devices
 where deleted_at is null;
 `
 
@@ -126,12 +173,50 @@ func (r devicesRepo) Read(ctx context.Context) (repo.ReadDevicesResult, error) {
 	return result, nil
 }
 
+// devicesRepoQueryReadFiltered takes the filter conditions as %[1]s. They
+// are bound positionally, since the ? of the jsonb key operator would be
+// taken for a placeholder by sqlx.
+const devicesRepoQueryReadFiltered = `
+select id, device_type, "name", address, responsible, attributes, labels, created_at, updated_at from devices
+where deleted_at is null%[1]s
+order by id;
+`
+
+func (r devicesRepo) ReadFiltered(ctx context.Context, opts repo.ReadDevicesOpts) (repo.ReadDevicesResult, error) {
+	var f devicesRepoFilter
+	if opts.GroupID != 0 {
+		f.add("id in (" + deviceGroupsRepoSubtreeDevices(f.arg(opts.GroupID)) + ")")
+	}
+	for _, req := range opts.Labels {
+		if err := f.addSelector("labels", req); err != nil {
+			return repo.ReadDevicesResult{}, err
+		}
+	}
+	for _, req := range opts.Attributes {
+		if err := f.addSelector("attributes", req); err != nil {
+			return repo.ReadDevicesResult{}, err
+		}
+	}
+
+	result := repo.ReadDevicesResult{
+		Devices: make([]models.Device, 0),
+	}
+	err := r.tx.SelectContext(ctx, &result.Devices, fmt.Sprintf(devicesRepoQueryReadFiltered, f.where()), f.args...)
+	if err != nil {
+		return repo.ReadDevicesResult{}, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return result, nil
+}
+
 const devicesRepoQueryUpdate = `
 update devices 
 set name = coalesce(:name, name),
     device_type = coalesce(:device_type, device_type),
 	address = coalesce(:address, address),
 	responsible = coalesce(:responsible, responsible),
+	attributes = coalesce(:attributes, attributes),
+	labels = coalesce(:labels, labels),
 	updated_at = :updated_at
 where id = :id and deleted_at is null;
 `
@@ -141,6 +226,13 @@ func (r devicesRepo) Update(ctx context.Context, opts repo.UpdateDeviceOpts) err
 	if len(opts.Responsible) != 0 {
 		responsible = opts.Responsible
 	}
+	var attributes, labels any
+	if opts.Attributes != nil {
+		attributes = models.SqlJsonbStringMap(opts.Attributes)
+	}
+	if opts.Labels != nil {
+		labels = models.SqlJsonbStringMap(opts.Labels)
+	}
 	_, err := r.tx.NamedExecContext(ctx, devicesRepoQueryUpdate,
 		struct {
 			ID          int32     `db:"id"`
@@ -148,6 +240,8 @@ func (r devicesRepo) Update(ctx context.Context, opts repo.UpdateDeviceOpts) err
 			DeviceType  *string   `db:"device_type"`
 			Address     *string   `db:"address"`
 			Responsible []int32   `db:"responsible"`
+			Attributes  any       `db:"attributes"`
+			Labels      any       `db:"labels"`
 			UpdatedAt   time.Time `db:"updated_at"`
 		}{
 			ID:          opts.ID,
@@ -155,6 +249,8 @@ func (r devicesRepo) Update(ctx context.Context, opts repo.UpdateDeviceOpts) err
 			DeviceType:  opts.DeviceType,
 			Address:     opts.Address,
 			Responsible: responsible,
+			Attributes:  attributes,
+			Labels:      labels,
 			UpdatedAt:   time.Now(),
 		},
 	)
@@ -164,7 +260,13 @@ func (r devicesRepo) Update(ctx context.Context, opts repo.UpdateDeviceOpts) err
 	return nil
 }
 
+// devicesRepoQueryDelete frees the extra addresses of the device so they
+// can be assigned to another one.
 const devicesRepoQueryDelete = `
+with addresses as (
+	delete from device_addresses
+	where device_id = :id
+)
 delete from devices
 where id = :id and deleted_at is null;
 `
@@ -210,3 +312,135 @@ func (r devicesRepo) GetResponsible(ctx context.Context, deviceID int32) ([]int3
 
 	return responsibleIds, nil
 }
+
+// devicesRepoFilter collects the conditions of ReadFiltered with their
+// positional arguments.
+type devicesRepoFilter struct {
+	conditions []string
+	args       []any
+}
+
+func (f *devicesRepoFilter) arg(value any) string {
+	f.args = append(f.args, value)
+	return "$" + strconv.Itoa(len(f.args))
+}
+
+func (f *devicesRepoFilter) add(condition string) {
+	f.conditions = append(f.conditions, condition)
+}
+
+func (f *devicesRepoFilter) where() string {
+	if len(f.conditions) == 0 {
+		return ""
+	}
+	return "\n\tand " + strings.Join(f.conditions, "\n\tand ")
+}
+
+// addSelector turns the requirement into containment and key existence
+// tests on the jsonb column, which the GIN index on it serves. A value
+// test against a missing key behaves as in Kubernetes: != and notin match
+// it.
+func (f *devicesRepoFilter) addSelector(column string, req models.SelectorRequirement) error {
+	contains := func(value string) (string, error) {
+		doc, err := json.Marshal(map[string]string{req.Key: value})
+		if err != nil {
+			return "", fmt.Errorf("json.Marshal: %w", err)
+		}
+		return column + " @> " + f.arg(string(doc)), nil
+	}
+
+	anyOf := func() (string, error) {
+		terms := make([]string, 0, len(req.Values))
+		for _, v := range req.Values {
+			term, err := contains(v)
+			if err != nil {
+				return "", err
+			}
+			terms = append(terms, term)
+		}
+		return "(" + strings.Join(terms, " or ") + ")", nil
+	}
+
+	switch req.Operator {
+	case models.SelectorOpEquals, models.SelectorOpIn:
+		cond, err := anyOf()
+		if err != nil {
+			return err
+		}
+		f.add(cond)
+	case models.SelectorOpNotEquals, models.SelectorOpNotIn:
+		cond, err := anyOf()
+		if err != nil {
+			return err
+		}
+		f.add("not " + cond)
+	case models.SelectorOpExists:
+		f.add(column + " ? " + f.arg(req.Key))
+	case models.SelectorOpNotExists:
+		f.add("not " + column + " ? " + f.arg(req.Key))
+	default:
+		return fmt.Errorf("unknown selector operator %q", req.Operator)
+	}
+
+	return nil
+}
+
+const devicesRepoQueryLock = `
+lock table devices in share row exclusive mode;
+`
+
+func (r devicesRepo) Lock(ctx context.Context) error {
+	_, err := r.tx.ExecContext(ctx, devicesRepoQueryLock)
+	if err != nil {
+		return fmt.Errorf("r.tx.ExecContext: %w", err)
+	}
+
+	return nil
+}
+
+// devicesRepoQueryReadConflicts matches names against deleted devices too,
+// since devices_name_unique covers them.
+const devicesRepoQueryReadConflicts = `
+select id, device_type, "name", address, responsible, attributes, labels, created_at, updated_at from devices
+where "name" in (select jsonb_array_elements_text(cast(:names as jsonb)))
+	or (deleted_at is null
+		and address in (select cast(jsonb_array_elements_text(cast(:addresses as jsonb)) as inet)))
+order by id;
+`
+
+func (r devicesRepo) ReadConflicts(ctx context.Context, names, addresses []string) ([]models.Device, error) {
+	if names == nil {
+		names = []string{}
+	}
+	if addresses == nil {
+		addresses = []string{}
+	}
+
+	namesDoc, err := json.Marshal(names)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+	addressesDoc, err := json.Marshal(addresses)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	query, args, err := sqlx.Named(devicesRepoQueryReadConflicts,
+		map[string]any{
+			"names":     string(namesDoc),
+			"addresses": string(addressesDoc),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	devices := make([]models.Device, 0)
+	err = r.tx.SelectContext(ctx, &devices, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return devices, nil
+}
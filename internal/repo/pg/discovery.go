@@ -0,0 +1,357 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/pkg/postgres"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type discoveryRepo struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+
+	log *zap.Logger
+}
+
+func NewDiscoveryRepo(p *postgres.Postgres, log *zap.Logger) repo.Discovery {
+	return &discoveryRepo{
+		db:  p.DB,
+		log: log,
+	}
+}
+
+func (r discoveryRepo) BeginTx(ctx context.Context) (repo.Discovery, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{
+		Isolation: 0,
+		ReadOnly:  false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("r.db.BeginTx: %w", err)
+	}
+
+	r.tx = tx
+
+	return r, nil
+}
+
+func (r discoveryRepo) Commit() error {
+	err := r.tx.Commit()
+	if err != nil {
+		return fmt.Errorf("r.tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r discoveryRepo) Rollback() error {
+	err := r.tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("r.tx.Rollback: %w", err)
+	}
+
+	return nil
+}
+
+const discoveryRepoJobColumns = `id, cidr, ports, healthcheck, status, hosts_total, hosts_scanned,
+	candidates_found, error, created_at, started_at, finished_at`
+
+const discoveryRepoQueryCreateJob = `
+insert into discovery_jobs (cidr, ports, healthcheck, hosts_total, created_at)
+values
+(cast(:cidr as cidr), :ports, :healthcheck, :hosts_total, :created_at)
+returning ` + discoveryRepoJobColumns + `;
+`
+
+func (r discoveryRepo) CreateJob(ctx context.Context, opts models.DiscoveryJob) (models.DiscoveryJob, error) {
+	rows, err := r.tx.NamedQuery(discoveryRepoQueryCreateJob,
+		map[string]any{
+			"cidr":        opts.CIDR,
+			"ports":       opts.Ports,
+			"healthcheck": opts.Healthcheck,
+			"hosts_total": opts.HostsTotal,
+			"created_at":  opts.CreatedAt,
+		},
+	)
+	if err != nil {
+		return models.DiscoveryJob{}, fmt.Errorf("r.tx.NamedQuery: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.log.Error("failed to closing rows", zap.Error(err))
+		}
+	}()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return models.DiscoveryJob{}, fmt.Errorf("rows.Err: %w", err)
+		}
+		return models.DiscoveryJob{}, repo.ErrDiscoveryJobNotFound
+	}
+	var job models.DiscoveryJob
+	err = rows.StructScan(&job)
+	if err != nil {
+		return models.DiscoveryJob{}, fmt.Errorf("rows.StructScan: %w", err)
+	}
+
+	return job, nil
+}
+
+const discoveryRepoQueryReadJobs = `
+select ` + discoveryRepoJobColumns + `
+from discovery_jobs
+order by id desc;
+`
+
+func (r discoveryRepo) ReadJobs(ctx context.Context) ([]models.DiscoveryJob, error) {
+	jobs := make([]models.DiscoveryJob, 0)
+
+	err := r.tx.SelectContext(ctx, &jobs, discoveryRepoQueryReadJobs)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return jobs, nil
+}
+
+const discoveryRepoQueryUpdateJob = `
+update discovery_jobs
+set status = coalesce(:status, status),
+	hosts_total = coalesce(:hosts_total, hosts_total),
+	hosts_scanned = coalesce(:hosts_scanned, hosts_scanned),
+	candidates_found = coalesce(:candidates_found, candidates_found),
+	error = coalesce(:error, error),
+	started_at = coalesce(:started_at, started_at),
+	finished_at = coalesce(:finished_at, finished_at)
+where id = :id;
+`
+
+func (r discoveryRepo) UpdateJob(ctx context.Context, opts repo.UpdateDiscoveryJobOpts) error {
+	res, err := r.tx.NamedExecContext(ctx, discoveryRepoQueryUpdateJob,
+		map[string]any{
+			"id":               opts.ID,
+			"status":           opts.Status,
+			"hosts_total":      opts.HostsTotal,
+			"hosts_scanned":    opts.HostsScanned,
+			"candidates_found": opts.CandidatesFound,
+			"error":            opts.Error,
+			"started_at":       opts.StartedAt,
+			"finished_at":      opts.FinishedAt,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if updated == 0 {
+		return repo.ErrDiscoveryJobNotFound
+	}
+
+	return nil
+}
+
+// discoveryRepoQueryRecordCandidate keeps a dismissed candidate dismissed.
+// A promoted one is back to pending: the host is seen while no device has
+// its address, so the device was deleted.
+const discoveryRepoQueryRecordCandidate = `
+insert into discovery_candidates (job_id, address, open_ports, healthcheck, first_seen, last_seen)
+select :job_id, cast(:address as inet), :open_ports, :healthcheck, :seen_at, :seen_at
+where not exists(select 1 from devices where address = cast(:address as inet) and deleted_at is null)
+on conflict (address) do update
+set job_id = excluded.job_id,
+	open_ports = excluded.open_ports,
+	healthcheck = excluded.healthcheck,
+	last_seen = greatest(discovery_candidates.last_seen, excluded.last_seen),
+	status = case when discovery_candidates.status = 'promoted' then 'pending' else discovery_candidates.status end,
+	device_id = case when discovery_candidates.status = 'promoted' then null else discovery_candidates.device_id end;
+`
+
+func (r discoveryRepo) RecordCandidates(ctx context.Context, jobID int32, hosts []repo.DiscoveredHost) (int64, error) {
+	var recorded int64
+	for _, host := range hosts {
+		res, err := r.tx.NamedExecContext(ctx, discoveryRepoQueryRecordCandidate,
+			map[string]any{
+				"job_id":      jobID,
+				"address":     host.Address,
+				"open_ports":  models.SqlJsonbIntArray(host.OpenPorts),
+				"healthcheck": host.Healthcheck,
+				"seen_at":     host.SeenAt,
+			},
+		)
+		if err != nil {
+			return 0, fmt.Errorf("r.tx.NamedExecContext: %w", err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("res.RowsAffected: %w", err)
+		}
+		recorded += affected
+	}
+
+	return recorded, nil
+}
+
+const discoveryRepoCandidateColumns = `id, job_id, address, open_ports, healthcheck, status, device_id, first_seen, last_seen`
+
+const discoveryRepoQueryReadCandidates = `
+select ` + discoveryRepoCandidateColumns + `
+from discovery_candidates
+where (:job_id = 0 or job_id = :job_id)
+	and (:status = '' or status = :status)
+order by address;
+`
+
+func (r discoveryRepo) ReadCandidates(
+	ctx context.Context,
+	opts repo.ReadDiscoveryCandidatesOpts,
+) ([]models.DiscoveryCandidate, error) {
+	candidates := make([]models.DiscoveryCandidate, 0)
+
+	query, args, err := sqlx.Named(discoveryRepoQueryReadCandidates, map[string]any{
+		"job_id": opts.JobID,
+		"status": opts.Status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	err = r.tx.SelectContext(ctx, &candidates, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return candidates, nil
+}
+
+const discoveryRepoQueryLockCandidate = `
+select ` + discoveryRepoCandidateColumns + `
+from discovery_candidates
+where id = :id
+for update;
+`
+
+const discoveryRepoQueryDeviceExists = `
+select exists(select 1 from devices where address = cast(:address as inet) and deleted_at is null);
+`
+
+const discoveryRepoQueryMarkCandidate = `
+update discovery_candidates
+set status = :status, device_id = :device_id
+where id = :id;
+`
+
+func (r discoveryRepo) PromoteCandidate(
+	ctx context.Context,
+	opts repo.PromoteDiscoveryCandidateOpts,
+) (models.Device, error) {
+	candidate, err := r.lockCandidate(ctx, opts.ID)
+	if err != nil {
+		return models.Device{}, err
+	}
+
+	query, args, err := sqlx.Named(discoveryRepoQueryDeviceExists, map[string]any{
+		"address": candidate.Address,
+	})
+	if err != nil {
+		return models.Device{}, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	var exists bool
+	if err := r.tx.GetContext(ctx, &exists, query, args...); err != nil {
+		return models.Device{}, fmt.Errorf("r.tx.GetContext: %w", err)
+	}
+	if exists {
+		return models.Device{}, repo.ErrDeviceExists
+	}
+
+	responsible := opts.Responsible
+	if responsible == nil {
+		responsible = []int32{}
+	}
+
+	device, err := insertDevice(r.tx, r.log, models.Device{
+		Name:        opts.Name,
+		DeviceType:  opts.DeviceType,
+		Address:     candidate.Address,
+		Responsible: responsible,
+	})
+	if err != nil {
+		return models.Device{}, err
+	}
+
+	_, err = r.tx.NamedExecContext(ctx, discoveryRepoQueryMarkCandidate,
+		map[string]any{
+			"id":        candidate.ID,
+			"status":    models.DiscoveryCandidateStatusPromoted,
+			"device_id": device.ID,
+		},
+	)
+	if err != nil {
+		return models.Device{}, fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	return device, nil
+}
+
+func (r discoveryRepo) DismissCandidate(ctx context.Context, id int32) (models.DiscoveryCandidate, error) {
+	candidate, err := r.lockCandidate(ctx, id)
+	if err != nil {
+		return models.DiscoveryCandidate{}, err
+	}
+
+	_, err = r.tx.NamedExecContext(ctx, discoveryRepoQueryMarkCandidate,
+		map[string]any{
+			"id":        candidate.ID,
+			"status":    models.DiscoveryCandidateStatusDismissed,
+			"device_id": nil,
+		},
+	)
+	if err != nil {
+		return models.DiscoveryCandidate{}, fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	candidate.Status = models.DiscoveryCandidateStatusDismissed
+
+	return candidate, nil
+}
+
+// lockCandidate returns a pending candidate. Promoted and dismissed ones
+// yield ErrDiscoveryCandidateProcessed.
+func (r discoveryRepo) lockCandidate(ctx context.Context, id int32) (models.DiscoveryCandidate, error) {
+	query, args, err := sqlx.Named(discoveryRepoQueryLockCandidate, map[string]any{
+		"id": id,
+	})
+	if err != nil {
+		return models.DiscoveryCandidate{}, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	var candidate models.DiscoveryCandidate
+	err = r.tx.GetContext(ctx, &candidate, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DiscoveryCandidate{}, repo.ErrDiscoveryCandidateNotFound
+		}
+		return models.DiscoveryCandidate{}, fmt.Errorf("r.tx.GetContext: %w", err)
+	}
+	if candidate.Status != models.DiscoveryCandidateStatusPending {
+		return models.DiscoveryCandidate{}, repo.ErrDiscoveryCandidateProcessed
+	}
+
+	return candidate, nil
+}
@@ -3,20 +3,31 @@ package pg
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"net/netip"
+	"strings"
 	"time"
+	"unicode"
 
 	"monolith/internal/models"
 	"monolith/internal/repo"
 	"monolith/pkg/postgres"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
 	"go.uber.org/zap"
 )
 
 type messagesRepo struct {
 	db *sqlx.DB
-	tx *sqlx.Tx
+	// conn is the connection of tx, on which CopyFrom takes part in it.
+	conn *sqlx.Conn
+	tx   *sqlx.Tx
 
 	log *zap.Logger
 }
@@ -29,14 +40,21 @@ func NewMessagesRepo(p *postgres.Postgres, log *zap.Logger) repo.Messages {
 }
 
 func (r messagesRepo) BeginTx(ctx context.Context) (repo.Messages, error) {
-	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{
+	conn, err := r.db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("r.db.Connx: %w", err)
+	}
+
+	tx, err := conn.BeginTxx(ctx, &sql.TxOptions{
 		Isolation: 0,
 		ReadOnly:  false,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("r.db.BeginTx: %w", err)
+		conn.Close()
+		return nil, fmt.Errorf("conn.BeginTx: %w", err)
 	}
 
+	r.conn = conn
 	r.tx = tx
 
 	return r, nil
@@ -44,6 +62,7 @@ func (r messagesRepo) BeginTx(ctx context.Context) (repo.Messages, error) {
 
 func (r messagesRepo) Commit() error {
 	err := r.tx.Commit()
+	r.conn.Close()
 	if err != nil {
 		return fmt.Errorf("r.tx.Commit: %w", err)
 	}
@@ -53,6 +72,7 @@ func (r messagesRepo) Commit() error {
 
 func (r messagesRepo) Rollback() error {
 	err := r.tx.Rollback()
+	r.conn.Close()
 	if err != nil {
 		return fmt.Errorf("r.tx.Rollback: %w", err)
 	}
@@ -61,20 +81,22 @@ func (r messagesRepo) Rollback() error {
 }
 
 const messagesRepoQueryInsert = `
-insert into messages (got_at, device_id, message, message_type, severity_level, component)
+insert into messages (got_at, event_at, device_id, message, message_type, severity_level, component, device_ip)
 values
-(:got_at, :device_id, :message, :message_type, :severity_level, :component)
+(:got_at, :event_at, :device_id, :message, :message_type, :severity_level, :component, CAST(NULLIF(:device_ip, '') AS inet))
 `
 
 func (r messagesRepo) Create(opts models.Message) error {
 	_, err := r.tx.NamedExec(messagesRepoQueryInsert,
 		map[string]any{
 			"got_at":         time.Now(),
+			"event_at":       opts.EventAt,
 			"device_id":      opts.DeviceId,
 			"message":        opts.Message,
 			"message_type":   opts.MessageType,
 			"severity_level": opts.SeverityLevel,
 			"component":      opts.Component,
+			"device_ip":      opts.DeviceIP,
 		},
 	)
 	if err != nil {
@@ -84,20 +106,124 @@ func (r messagesRepo) Create(opts models.Message) error {
 	return nil
 }
 
+var messagesRepoCopyColumns = []string{
+	"got_at", "event_at", "device_id", "message", "message_type", "severity_level", "component", "device_ip",
+}
+
+// CopyFrom bulk loads messages with the COPY protocol. Within a transaction
+// it runs on the connection of the transaction and is part of it; outside
+// of one it runs on a pooled connection, COPY being atomic on its own.
+// Unlike Create it keeps the GotAt of every message, which is the time it
+// was received.
+func (r messagesRepo) CopyFrom(ctx context.Context, opts []models.Message) (int64, error) {
+	conn := r.conn
+	if conn == nil {
+		var err error
+		conn, err = r.db.Connx(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("r.db.Connx: %w", err)
+		}
+		defer conn.Close()
+	}
+
+	var copied int64
+	var err error
+	err = conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("driverConn.(*stdlib.Conn): unexpected driver")
+		}
+
+		copied, err = stdlibConn.Conn().CopyFrom(ctx,
+			pgx.Identifier{"messages"},
+			messagesRepoCopyColumns,
+			pgx.CopyFromSlice(len(opts), func(i int) ([]any, error) {
+				return []any{
+					opts[i].GotAt,
+					opts[i].EventAt,
+					opts[i].DeviceId,
+					opts[i].Message,
+					opts[i].MessageType,
+					opts[i].SeverityLevel,
+					opts[i].Component,
+					copyInet(opts[i].DeviceIP),
+				}, nil
+			}),
+		)
+		return err //nolint:wrapcheck
+	})
+	if err != nil {
+		return 0, fmt.Errorf("conn.CopyFrom: %w", err)
+	}
+
+	return copied, nil
+}
+
+// copyInet converts an address for the binary COPY protocol, which does not
+// accept text for inet columns. Addresses that do not parse are stored as NULL.
+func copyInet(address string) any {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return nil
+	}
+	return addr
+}
+
+// messagesRepoTimeColumn is the time a report is based on. Messages without
+// an event time fall back to the time they were received.
+func messagesRepoTimeColumn(timeBasis string) string {
+	if timeBasis == models.TimeBasisEvent {
+		return "coalesce(event_at, got_at)"
+	}
+	return "got_at"
+}
+
+const messagesRepoReportColumns = `id, got_at, event_at, device_id, message, message_type,
+	severity_level, coalesce(component, '') as component`
+
+// messagesRepoReportFilter applies repo.MessagesFilter. An empty list is
+// passed as '[]' and matches every message.
+const messagesRepoReportFilter = `
+	and (jsonb_array_length(cast(:device_ids as jsonb)) = 0
+		or device_id in (select cast(jsonb_array_elements_text(cast(:device_ids as jsonb)) as int)))
+	and (jsonb_array_length(cast(:message_types as jsonb)) = 0
+		or message_type in (select jsonb_array_elements_text(cast(:message_types as jsonb))))
+	and (jsonb_array_length(cast(:severities as jsonb)) = 0
+		or severity_level in (select jsonb_array_elements_text(cast(:severities as jsonb))))
+	and (:component = '' or component = :component)
+	and (:text = '' or strpos(lower(message), lower(:text)) > 0)`
+
+// messagesRepoReportPage takes the time column as %[1]s. Ties on the time
+// are broken by id, so a page never skips or repeats a message. A null
+// limit returns every row.
+const messagesRepoReportPage = `
+	and (:after_id = 0 or (%[1]s, id) < (cast(:after_time as timestamp), :after_id))
+order by %[1]s desc, id desc
+limit nullif(:limit, 0)`
+
+// messagesRepoQueryGetAllByPeriod takes the time column as %[1]s.
 const messagesRepoQueryGetAllByPeriod = `
-select got_at, device_id, message, message_type 
+select ` + messagesRepoReportColumns + `
 from messages
-Where got_at between :start and :end
-order by got_at desc
-`
+where %[1]s between :start and :end` + messagesRepoReportFilter + messagesRepoReportPage
+
+// messagesRepoQueryCountByPeriod takes the time column as %[1]s.
+const messagesRepoQueryCountByPeriod = `
+select count(*)
+from messages
+where %[1]s between :start and :end` + messagesRepoReportFilter
 
 func (r messagesRepo) GetAllByPeriod(opts repo.MessagesGetAllByPeriodOpts) ([]models.Message, error) {
 	messages := make([]models.Message, 0)
 
-	query, args, err := sqlx.Named(messagesRepoQueryGetAllByPeriod, map[string]any{
-		"start": opts.StartTime,
-		"end":   opts.EndTime,
-	})
+	params, err := messagesRepoReportParams(opts.Filter, opts.After, opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+	params["start"] = opts.StartTime
+	params["end"] = opts.EndTime
+
+	query, args, err := sqlx.Named(fmt.Sprintf(messagesRepoQueryGetAllByPeriod, messagesRepoTimeColumn(opts.TimeBasis)), params)
 	if err != nil {
 		return nil, fmt.Errorf("sqlx.Named: %w", err)
 	}
@@ -112,19 +238,51 @@ func (r messagesRepo) GetAllByPeriod(opts repo.MessagesGetAllByPeriodOpts) ([]mo
 	return messages, nil
 }
 
-const messagesRepoQueryGetAllByDeviceId = `
-select got_at, device_id, message, message_type
+func (r messagesRepo) CountByPeriod(opts repo.MessagesGetAllByPeriodOpts) (int64, error) {
+	params, err := messagesRepoReportParams(opts.Filter, nil, 0)
+	if err != nil {
+		return 0, err
+	}
+	params["start"] = opts.StartTime
+	params["end"] = opts.EndTime
+
+	query, args, err := sqlx.Named(fmt.Sprintf(messagesRepoQueryCountByPeriod, messagesRepoTimeColumn(opts.TimeBasis)), params)
+	if err != nil {
+		return 0, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	var count int64
+	err = r.tx.Get(&count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("r.tx.Get: %w", err)
+	}
+
+	return count, nil
+}
+
+var (
+	messagesRepoQueryGetAllByDeviceId = fmt.Sprintf(`
+select `+messagesRepoReportColumns+`
 from messages
-where device_id = :device_id
-order by got_at desc
-`
+where device_id = :device_id`+messagesRepoReportFilter+messagesRepoReportPage, "got_at")
 
-func (r messagesRepo) GetAllByDeviceId(deviceID int32) ([]models.Message, error) {
+	messagesRepoQueryCountByDeviceId = `
+select count(*)
+from messages
+where device_id = :device_id` + messagesRepoReportFilter
+)
+
+func (r messagesRepo) GetAllByDeviceId(opts repo.MessagesGetAllByDeviceIdOpts) ([]models.Message, error) {
 	messages := make([]models.Message, 0)
 
-	query, args, err := sqlx.Named(messagesRepoQueryGetAllByDeviceId, map[string]any{
-		"device_id": deviceID,
-	})
+	params, err := messagesRepoReportParams(opts.Filter, opts.After, opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+	params["device_id"] = opts.DeviceID
+
+	query, args, err := sqlx.Named(messagesRepoQueryGetAllByDeviceId, params)
 	if err != nil {
 		return nil, fmt.Errorf("sqlx.Named: %w", err)
 	}
@@ -137,21 +295,97 @@ func (r messagesRepo) GetAllByDeviceId(deviceID int32) ([]models.Message, error)
 	return messages, nil
 }
 
+func (r messagesRepo) CountByDeviceId(opts repo.MessagesGetAllByDeviceIdOpts) (int64, error) {
+	params, err := messagesRepoReportParams(opts.Filter, nil, 0)
+	if err != nil {
+		return 0, err
+	}
+	params["device_id"] = opts.DeviceID
+
+	query, args, err := sqlx.Named(messagesRepoQueryCountByDeviceId, params)
+	if err != nil {
+		return 0, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	var count int64
+	err = r.tx.Get(&count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("r.tx.Get: %w", err)
+	}
+
+	return count, nil
+}
+
+// messagesRepoReportParams binds messagesRepoReportFilter and
+// messagesRepoReportPage. The count queries do not reference the page
+// parameters; sqlx ignores the unused ones.
+func messagesRepoReportParams(filter repo.MessagesFilter, after *repo.MessagesCursor, limit int) (map[string]any, error) {
+	deviceIDs, err := json.Marshal(lo.Ternary(filter.DeviceIDs == nil, []int32{}, filter.DeviceIDs))
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+	messageTypes, err := json.Marshal(lo.Ternary(filter.MessageTypes == nil, []string{}, filter.MessageTypes))
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+	severities, err := json.Marshal(lo.Ternary(filter.Severities == nil, []string{}, filter.Severities))
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	cursor := repo.MessagesCursor{
+		Time: time.Time{},
+		ID:   0,
+	}
+	if after != nil {
+		cursor = *after
+	}
+
+	return map[string]any{
+		"device_ids":    string(deviceIDs),
+		"message_types": string(messageTypes),
+		"severities":    string(severities),
+		"component":     filter.Component,
+		"text":          filter.Text,
+		"after_time":    cursor.Time,
+		"after_id":      cursor.ID,
+		"limit":         limit,
+	}, nil
+}
+
+// messagesRepoQueryGetCountByMessageType takes the group filter as %[1]s.
+// Days the rollups cover are counted from them, the rest from messages.
 const messagesRepoQueryGetCountByMessageType = `
-select device_id, count(*) as count
-from messages
-where message_type = :message_type
+select device_id, cast(sum(count) as int) as count
+from (
+	select r.device_id, sum(r.message_count) as count
+	from message_rollups r
+	join message_rollup_state s on r.day >= s.first_day and r.day < s.complete_until
+	where r.message_type = :message_type
+	group by r.device_id
+	union all
+	select m.device_id, count(*) as count
+	from messages m
+	join message_rollup_state s on m.got_at < s.first_day or m.got_at >= s.complete_until
+	where m.message_type = :message_type
+	group by m.device_id
+) counts
+where :group_id = 0 or device_id in (%[1]s)
 group by device_id
 `
 
-func (r messagesRepo) GetCountByMessageType(messageType string) (repo.GetCountByMessageTypeResult, error) {
+func (r messagesRepo) GetCountByMessageType(messageType string, groupID int32) (repo.GetCountByMessageTypeResult, error) {
 	counts := make([]models.CountByDeviceID, 0)
 
-	query, args, err := sqlx.Named(messagesRepoQueryGetCountByMessageType,
+	query, args, err := sqlx.Named(
+		fmt.Sprintf(messagesRepoQueryGetCountByMessageType, deviceGroupsRepoSubtreeDevices(":group_id")),
 		struct {
 			MessageType string `db:"message_type"`
+			GroupID     int32  `db:"group_id"`
 		}{
 			MessageType: messageType,
+			GroupID:     groupID,
 		})
 	if err != nil {
 		return repo.GetCountByMessageTypeResult{}, fmt.Errorf("sqlx.Named: %w", err)
@@ -167,116 +401,589 @@ func (r messagesRepo) GetCountByMessageType(messageType string) (repo.GetCountBy
 	}, nil
 }
 
-const messagesRepoQueryMonthReport = `
-SELECT 
-    device_id,
-    message_type,
-    active_days,
-    total_messages,
-    avg_daily_messages,
-    max_daily_messages,
-    median_daily_messages,
-    total_critical,
-    max_daily_critical,
-    max_daily_components,
-    most_active_component,
-    first_critical_time,
-    last_critical_time,
-    avg_critical_interval_sec,
-    critical_percentage,
-    overall_volume_rank
-FROM (
-    SELECT 
-        *,
-        DENSE_RANK() OVER (ORDER BY total_messages DESC) AS overall_volume_rank
-    FROM (
-        SELECT
-            m.device_id,
-            m.message_type,
-            m.active_days,
-            m.total_messages,
-            m.avg_daily_messages,
-            m.max_daily_messages,
-            m.median_daily_messages,
-            m.total_critical,
-            m.max_daily_critical,
-            m.max_daily_components,
-            m.most_active_component,
-            e.first_critical_time,
-            e.last_critical_time,
-            e.avg_critical_interval_sec,
-            ROUND(100.0 * m.total_critical / NULLIF(m.total_messages, 0), 2) AS critical_percentage
-        FROM (
-            SELECT
-                d.device_id,
-                d.message_type,
-                COUNT(DISTINCT d.day) AS active_days,
-                SUM(d.total_messages) AS total_messages,
-                AVG(d.total_messages) AS avg_daily_messages,
-                MAX(d.total_messages) AS max_daily_messages,
-                PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY d.total_messages) AS median_daily_messages,
-                SUM(d.critical_count) AS total_critical,
-                MAX(d.critical_count) AS max_daily_critical,
-                MAX(d.unique_components) AS max_daily_components,
-                (SELECT component FROM (
-                    SELECT component, COUNT(*) 
-                    FROM messages 
-                    WHERE device_id = d.device_id 
-                      AND message_type = d.message_type 
-                      AND component IS NOT NULL
-                    GROUP BY component
-                    ORDER BY COUNT(*) DESC 
-                    LIMIT 1
-                ) sub_comp) AS most_active_component
-            FROM (
-                SELECT 
-                    device_id,
-                    DATE_TRUNC('day', got_at) AS day,
-                    message_type,
-                    COUNT(*) AS total_messages,
-                    SUM(CASE severity_level WHEN 'critical' THEN 1 ELSE 0 END) AS critical_count,
-                    COUNT(DISTINCT component) AS unique_components
-                FROM messages
-                WHERE got_at BETWEEN (NOW() - '30 days'::INTERVAL) AND NOW()
-                GROUP BY device_id, DATE_TRUNC('day', got_at), message_type
-            ) d
-            GROUP BY d.device_id, d.message_type
-        ) m
-        LEFT JOIN (
-            SELECT
-                device_id,
-                message_type,
-                MIN(got_at) AS first_critical_time,
-                MAX(got_at) AS last_critical_time,
-                AVG(time_diff) AS avg_critical_interval_sec
-            FROM (
-                SELECT 
-                    device_id,
-                    message_type,
-                    got_at,
-                    EXTRACT(EPOCH FROM (got_at - LAG(got_at) OVER (
-                        PARTITION BY device_id, message_type 
-                        ORDER BY got_at
-                    ))) AS time_diff
-                FROM messages
-                WHERE severity_level = 'critical'
-                  AND got_at > (CURRENT_DATE - 30)
-            ) critical_intervals
-            GROUP BY device_id, message_type
-        ) e ON m.device_id = e.device_id AND m.message_type = e.message_type
-        WHERE m.total_messages > 100
-    ) combined_data
-) final_data
-ORDER BY device_id, total_messages DESC;
+// messagesRepoReportDimensions are the columns of the dimensions a period
+// report can be grouped by, in the order they are reported.
+var messagesRepoReportDimensions = []struct {
+	dimension string
+	column    string
+	nullType  string
+	// equal compares the column of two groups; component is nullable.
+	equal string
+}{
+	{models.ReportDimensionDevice, "device_id", "int", "%[1]s.device_id = t.device_id"},
+	{models.ReportDimensionMessageType, "message_type", "text", "%[1]s.message_type = t.message_type"},
+	{models.ReportDimensionComponent, "component", "text", "%[1]s.component is not distinct from t.component"},
+}
+
+// messagesRepoQueryPeriodReport takes the messages source as %[1]s, see
+// messagesRepoTimeSource, the grouping columns as %[2]s, the reported
+// dimension columns as %[3]s, the joins on the grouping columns as %[4]s
+// and %[5]s and the devices of the group as %[6]s. Days and critical
+// intervals are counted within the period.
+//
+// Whole days of the period that the rollups cover are read from them and
+// the rest from the source, both in the shape of a rollup row; with
+// :use_rollups false everything is read from the source. The average
+// interval between criticals is their span over the number of intervals.
+const messagesRepoQueryPeriodReport = `
+with coverage as (
+	select greatest(s.first_day,
+			cast(date_trunc('day', cast(:start_time as timestamp) - interval '1 microsecond') + interval '1 day' as date)) as from_day,
+		least(s.complete_until, cast(date_trunc('day', cast(:end_time as timestamp)) as date)) as until_day
+	from message_rollup_state s
+	where :use_rollups
+),
+rollups as (
+	select r.day, r.device_id, r.message_type, r.severity_level, r.component, r.message_count, r.first_at, r.last_at
+	from message_rollups r
+	join coverage c on r.day >= c.from_day and r.day < c.until_day
+	union all
+	select cast(date_trunc('day', got_at) as date), device_id, message_type, severity_level, coalesce(component, ''),
+		count(*), min(got_at), max(got_at)
+	from %[1]s
+	where got_at >= :start_time and got_at < :end_time
+		and not exists (select 1 from coverage c where got_at >= c.from_day and got_at < c.until_day)
+	group by 1, 2, 3, 4, 5
+),
+src as (
+	select day, device_id, message_type, nullif(component, '') as component, message_count, first_at, last_at,
+		severity_level in (select jsonb_array_elements_text(cast(:critical_severities as jsonb))) as critical
+	from rollups
+	where (jsonb_array_length(cast(:device_ids as jsonb)) = 0
+			or device_id in (select cast(jsonb_array_elements_text(cast(:device_ids as jsonb)) as int)))
+		and (:group_id = 0 or device_id in (%[6]s))
+),
+daily as (
+	select %[2]s,
+		day,
+		cast(sum(message_count) as bigint) as total_messages,
+		cast(coalesce(sum(message_count) filter (where critical), 0) as bigint) as critical_count,
+		count(distinct component) as unique_components
+	from src
+	group by %[2]s, day
+),
+totals as (
+	select %[2]s,
+		count(*) as active_days,
+		cast(sum(total_messages) as bigint) as total_messages,
+		avg(total_messages) as avg_daily_messages,
+		max(total_messages) as max_daily_messages,
+		percentile_cont(0.5) within group (order by total_messages) as median_daily_messages,
+		cast(sum(critical_count) as bigint) as total_critical,
+		max(critical_count) as max_daily_critical,
+		max(unique_components) as max_daily_components
+	from daily
+	group by %[2]s
+),
+components as (
+	select distinct on (%[2]s) %[2]s, component
+	from src
+	where component is not null
+	group by %[2]s, component
+	order by %[2]s, sum(message_count) desc, component
+),
+criticals as (
+	select %[2]s,
+		min(first_at) as first_critical_time,
+		max(last_at) as last_critical_time,
+		case when sum(message_count) > 1
+			then extract(epoch from (max(last_at) - min(first_at))) / (sum(message_count) - 1)
+		end as avg_critical_interval_sec
+	from src
+	where critical
+	group by %[2]s
+)
+select %[3]s,
+	t.active_days,
+	t.total_messages,
+	t.avg_daily_messages,
+	t.max_daily_messages,
+	t.median_daily_messages,
+	t.total_critical,
+	t.max_daily_critical,
+	t.max_daily_components,
+	c.component as most_active_component,
+	e.first_critical_time,
+	e.last_critical_time,
+	e.avg_critical_interval_sec,
+	round(100.0 * t.total_critical / nullif(t.total_messages, 0), 2) as critical_percentage,
+	dense_rank() over (order by t.total_messages desc) as overall_volume_rank
+from totals t
+left join components c on %[4]s
+left join criticals e on %[5]s
+where t.total_messages >= :min_messages
+order by %[2]s, t.total_messages desc;
 `
 
-func (r messagesRepo) MonthReport() ([]models.MonthReportRow, error) {
-	result := make([]models.MonthReportRow, 0)
+// messagesRepoTimeSource exposes the report time as got_at, so queries
+// written against messages can run on event time unchanged.
+func messagesRepoTimeSource(timeBasis string) string {
+	if timeBasis == models.TimeBasisEvent {
+		return `(SELECT id, coalesce(event_at, got_at) AS got_at, device_id, message, message_type,
+            severity_level, component FROM messages) AS messages`
+	}
+	return "messages"
+}
+
+func (r messagesRepo) PeriodReport(opts repo.PeriodReportOpts) ([]models.PeriodReportRow, error) {
+	var groupBy, columns, componentsOn, criticalsOn []string
+	for _, d := range messagesRepoReportDimensions {
+		if !lo.Contains(opts.GroupBy, d.dimension) {
+			columns = append(columns, fmt.Sprintf("cast(null as %s) as %s", d.nullType, d.column))
+			continue
+		}
+		groupBy = append(groupBy, d.column)
+		columns = append(columns, "t."+d.column)
+		componentsOn = append(componentsOn, fmt.Sprintf(d.equal, "c"))
+		criticalsOn = append(criticalsOn, fmt.Sprintf(d.equal, "e"))
+	}
+	if len(groupBy) == 0 {
+		return nil, errors.New("period report is not grouped by any dimension")
+	}
+
+	deviceIDs, err := json.Marshal(lo.Ternary(opts.DeviceIDs == nil, []int32{}, opts.DeviceIDs))
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+	severities, err := json.Marshal(lo.Ternary(opts.CriticalSeverities == nil, []string{}, opts.CriticalSeverities))
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
 
-	err := r.tx.Select(&result, messagesRepoQueryMonthReport)
+	query, args, err := sqlx.Named(fmt.Sprintf(messagesRepoQueryPeriodReport,
+		messagesRepoTimeSource(opts.TimeBasis),
+		strings.Join(groupBy, ", "),
+		strings.Join(columns, ", "),
+		strings.Join(componentsOn, " and "),
+		strings.Join(criticalsOn, " and "),
+		deviceGroupsRepoSubtreeDevices(":group_id"),
+	), map[string]any{
+		"start_time":          opts.StartTime,
+		"end_time":            opts.EndTime,
+		"device_ids":          string(deviceIDs),
+		"group_id":            opts.GroupID,
+		"min_messages":        opts.MinMessages,
+		"critical_severities": string(severities),
+		"use_rollups":         opts.TimeBasis != models.TimeBasisEvent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	result := make([]models.PeriodReportRow, 0)
+	err = r.tx.Select(&result, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("r.tx.Select: %w", err)
 	}
 
 	return result, nil
 }
+
+// messagesRepoSearchFrom takes the time column as %[1]s. A zero start or
+// end time is passed as null and leaves that side of the period open.
+const messagesRepoSearchFrom = `
+from messages
+where message_tsv @@ to_tsquery('simple', :query)
+	and (cast(:start as timestamp) is null or %[1]s >= cast(:start as timestamp))
+	and (cast(:end as timestamp) is null or %[1]s <= cast(:end as timestamp))` + messagesRepoReportFilter
+
+// messagesRepoQuerySearchMessages takes the time column as %[1]s, the
+// cursor condition as %[2]s and the order as %[3]s, see
+// messagesRepoSearchOrders. The headline is costly, so it is only made for
+// the hits of the page; postgres evaluates it after the limit.
+const messagesRepoQuerySearchMessages = `
+select id, got_at, event_at, device_id, message, message_type, severity_level, component, rank,
+	ts_headline('simple', translate(message, chr(2) || chr(3), ''), to_tsquery('simple', :query),
+		:headline_options) as snippet
+from (
+	select ` + messagesRepoReportColumns + `, %[1]s as report_time,
+		ts_rank(message_tsv, to_tsquery('simple', :query)) as rank` + messagesRepoSearchFrom + `
+) hits
+where :after_id = 0 or %[2]s
+order by %[3]s
+limit nullif(:limit, 0)
+`
+
+const messagesRepoQueryCountSearchMessages = `
+select count(*)` + messagesRepoSearchFrom
+
+// messagesRepoSearchOrders are the cursor conditions and orders of a
+// search. Ties are broken by id, as in the message reports.
+var messagesRepoSearchOrders = map[string]struct {
+	after string
+	order string
+}{
+	models.SearchOrderRank: {
+		after: "(rank, id) < (cast(:after_rank as real), :after_id)",
+		order: "rank desc, id desc",
+	},
+	models.SearchOrderTime: {
+		after: "(report_time, id) < (cast(:after_time as timestamp), :after_id)",
+		order: "report_time desc, id desc",
+	},
+}
+
+// messagesRepoHeadlineOptions enclose the matches of a snippet in STX and
+// ETX, which messagesRepoSnippetHTML turns into <mark> once the snippet is
+// escaped. The characters are removed from the message beforehand.
+const messagesRepoHeadlineOptions = "StartSel=\x02, StopSel=\x03, MaxFragments=2, MinWords=5, MaxWords=20, " +
+	`FragmentDelimiter=" … "`
+
+func (r messagesRepo) SearchMessages(opts repo.SearchMessagesOpts) ([]models.MessageSearchHit, error) {
+	order, ok := messagesRepoSearchOrders[opts.OrderBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown search order %q", opts.OrderBy)
+	}
+
+	params, err := messagesRepoSearchParams(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := sqlx.Named(fmt.Sprintf(messagesRepoQuerySearchMessages,
+		messagesRepoTimeColumn(opts.TimeBasis),
+		order.after,
+		order.order,
+	), params)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	hits := make([]models.MessageSearchHit, 0)
+	err = r.tx.Select(&hits, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.Select: %w", err)
+	}
+
+	for i := range hits {
+		hits[i].Snippet = messagesRepoSnippetHTML(hits[i].Snippet)
+	}
+
+	return hits, nil
+}
+
+func (r messagesRepo) CountSearchMessages(opts repo.SearchMessagesOpts) (int64, error) {
+	opts.After = nil
+	params, err := messagesRepoSearchParams(opts)
+	if err != nil {
+		return 0, err
+	}
+
+	query, args, err := sqlx.Named(fmt.Sprintf(messagesRepoQueryCountSearchMessages,
+		messagesRepoTimeColumn(opts.TimeBasis),
+	), params)
+	if err != nil {
+		return 0, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	var count int64
+	err = r.tx.Get(&count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("r.tx.Get: %w", err)
+	}
+
+	return count, nil
+}
+
+// messagesRepoSearchParams binds the search on top of
+// messagesRepoReportParams.
+func messagesRepoSearchParams(opts repo.SearchMessagesOpts) (map[string]any, error) {
+	cursor := repo.SearchMessagesCursor{
+		Rank: 0,
+		Time: time.Time{},
+		ID:   0,
+	}
+	if opts.After != nil {
+		cursor = *opts.After
+	}
+
+	params, err := messagesRepoReportParams(opts.Filter, &repo.MessagesCursor{
+		Time: cursor.Time,
+		ID:   cursor.ID,
+	}, opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+	params["query"] = messagesRepoSearchQuery(opts.Query)
+	params["start"] = lo.Ternary[any](opts.StartTime.IsZero(), nil, opts.StartTime)
+	params["end"] = lo.Ternary[any](opts.EndTime.IsZero(), nil, opts.EndTime)
+	params["after_rank"] = cursor.Rank
+	params["headline_options"] = messagesRepoHeadlineOptions
+
+	return params, nil
+}
+
+// messagesRepoSearchQuery turns a search into a to_tsquery expression.
+// Words must all match, "quoted words" match as a phrase, a trailing *
+// matches a word as a prefix, a leading - excludes a word or a phrase and
+// OR between two terms matches either of them; OR binds tighter than the
+// implied AND. Every word is quoted, so no search is a tsquery syntax
+// error. A search without words matches nothing.
+func messagesRepoSearchQuery(search string) string {
+	var groups [][]string
+	or := false
+
+	for rest := strings.TrimSpace(search); rest != ""; rest = strings.TrimSpace(rest) {
+		negate := rest[0] == '-'
+		if negate {
+			rest = rest[1:]
+		}
+
+		var term string
+		if strings.HasPrefix(rest, `"`) {
+			var phrase string
+			phrase, rest, _ = strings.Cut(rest[1:], `"`)
+			term = messagesRepoSearchPhrase(phrase)
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			word := rest[:end]
+			rest = rest[end:]
+
+			if !negate && strings.EqualFold(word, "or") {
+				or = len(groups) > 0
+				continue
+			}
+			term = messagesRepoSearchPhrase(word)
+		}
+		if term == "" {
+			continue
+		}
+
+		if negate {
+			term = "!(" + term + ")"
+		}
+		if or {
+			groups[len(groups)-1] = append(groups[len(groups)-1], term)
+		} else {
+			groups = append(groups, []string{term})
+		}
+		or = false
+	}
+
+	terms := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if len(group) == 1 {
+			terms = append(terms, group[0])
+			continue
+		}
+		terms = append(terms, "("+strings.Join(group, " | ")+")")
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+// messagesRepoSearchReplacer drops the characters that would end or escape
+// the quoting of a word.
+var messagesRepoSearchReplacer = strings.NewReplacer("'", " ", `\`, " ")
+
+// messagesRepoSearchPhrase quotes the words of a phrase and joins them with
+// the followed-by operator.
+func messagesRepoSearchPhrase(phrase string) string {
+	words := strings.Fields(phrase)
+	lexemes := make([]string, 0, len(words))
+	for _, word := range words {
+		prefix := strings.HasSuffix(word, "*")
+		word = messagesRepoSearchReplacer.Replace(strings.TrimRight(word, "*"))
+		if strings.TrimSpace(word) == "" {
+			continue
+		}
+
+		lexeme := "'" + word + "'"
+		if prefix {
+			lexeme += ":*"
+		}
+		lexemes = append(lexemes, lexeme)
+	}
+
+	return strings.Join(lexemes, " <-> ")
+}
+
+var messagesRepoSnippetReplacer = strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>")
+
+func messagesRepoSnippetHTML(snippet string) string {
+	return messagesRepoSnippetReplacer.Replace(html.EscapeString(snippet))
+}
+
+// messagesRepoQueryClaimMessageIDs returns only the keys it claimed. A key
+// that is already present is claimed again only when it was recorded before
+// $4, i.e. outside the deduplication window. Keys are passed as arrays since
+// a named batch insert cannot bind a parameter outside of VALUES.
+const messagesRepoQueryClaimMessageIDs = `
+insert into message_ids (sender, message_id, received_at)
+select k.sender, k.message_id, $3
+from unnest($1::varchar[], $2::varchar[]) as k(sender, message_id)
+on conflict (sender, message_id) do update
+set received_at = excluded.received_at
+where message_ids.received_at < $4
+returning sender, message_id
+`
+
+func (r messagesRepo) ClaimMessageIDs(ctx context.Context, opts repo.ClaimMessageIDsOpts) ([]repo.MessageIDKey, error) {
+	// One statement must not touch a key twice, so repeats inside a batch are
+	// left to the caller to report as duplicates.
+	keys := lo.Uniq(opts.Keys)
+	claimed := make([]repo.MessageIDKey, 0, len(keys))
+	if len(keys) == 0 {
+		return claimed, nil
+	}
+
+	senders := make([]string, 0, len(keys))
+	messageIDs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		senders = append(senders, k.Sender)
+		messageIDs = append(messageIDs, k.MessageID)
+	}
+
+	err := r.tx.SelectContext(ctx, &claimed, messagesRepoQueryClaimMessageIDs,
+		senders, messageIDs, opts.ReceivedAt, opts.Since)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return claimed, nil
+}
+
+const messagesRepoQueryPurgeMessageIDs = `
+delete from message_ids
+where received_at < :before
+`
+
+func (r messagesRepo) PurgeMessageIDs(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.tx.NamedExecContext(ctx, messagesRepoQueryPurgeMessageIDs,
+		map[string]any{
+			"before": before,
+		},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+
+	return purged, nil
+}
+
+const messagesRepoQueryRollupState = `
+select first_day, complete_until, updated_at
+from message_rollup_state
+for update
+`
+
+func (r messagesRepo) RollupState(ctx context.Context) (models.MessageRollupState, error) {
+	var state models.MessageRollupState
+
+	err := r.tx.GetContext(ctx, &state, messagesRepoQueryRollupState)
+	if err != nil {
+		return models.MessageRollupState{}, fmt.Errorf("r.tx.GetContext: %w", err)
+	}
+
+	return state, nil
+}
+
+const messagesRepoQueryDeleteRollups = `
+delete from message_rollups
+where day >= cast(:from_day as date) and day < cast(:until_day as date)
+`
+
+// messagesRepoQueryInsertRollups stores messages without a component under
+// an empty one, since the component is part of the primary key.
+const messagesRepoQueryInsertRollups = `
+insert into message_rollups (day, device_id, message_type, severity_level, component, message_count, first_at, last_at)
+select cast(date_trunc('day', got_at) as date), device_id, message_type, severity_level, coalesce(component, ''),
+	count(*), min(got_at), max(got_at)
+from messages
+where got_at >= cast(:from_day as date) and got_at < cast(:until_day as date)
+group by 1, 2, 3, 4, 5
+`
+
+func (r messagesRepo) RollupDays(ctx context.Context, from, until time.Time) (int64, error) {
+	arg := map[string]any{
+		"from_day":  from,
+		"until_day": until,
+	}
+
+	_, err := r.tx.NamedExecContext(ctx, messagesRepoQueryDeleteRollups, arg)
+	if err != nil {
+		return 0, fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	res, err := r.tx.NamedExecContext(ctx, messagesRepoQueryInsertRollups, arg)
+	if err != nil {
+		return 0, fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	written, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+
+	return written, nil
+}
+
+const messagesRepoQuerySetRollupCompleteUntil = `
+update message_rollup_state
+set complete_until = cast(:until_day as date),
+	updated_at = :updated_at
+`
+
+func (r messagesRepo) SetRollupCompleteUntil(ctx context.Context, until time.Time) error {
+	_, err := r.tx.NamedExecContext(ctx, messagesRepoQuerySetRollupCompleteUntil,
+		map[string]any{
+			"until_day":  until,
+			"updated_at": time.Now(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	return nil
+}
+
+const messagesRepoQueryTakeDirtyRollupDays = `
+delete from message_rollup_dirty_days
+returning day
+`
+
+func (r messagesRepo) TakeDirtyRollupDays(ctx context.Context) ([]time.Time, error) {
+	days := make([]time.Time, 0)
+
+	err := r.tx.SelectContext(ctx, &days, messagesRepoQueryTakeDirtyRollupDays)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return days, nil
+}
+
+const messagesRepoQueryAddSuppressedAlert = `
+insert into suppressed_alerts (device_id, upstream_device_id, subject, message, suppressed_at)
+values
+(:device_id, :upstream_device_id, :subject, :message, :suppressed_at);
+`
+
+func (r messagesRepo) AddSuppressedAlert(ctx context.Context, alert models.SuppressedAlert) error {
+	_, err := r.tx.NamedExecContext(ctx, messagesRepoQueryAddSuppressedAlert,
+		map[string]any{
+			"device_id":          alert.DeviceID,
+			"upstream_device_id": alert.UpstreamDeviceID,
+			"subject":            alert.Subject,
+			"message":            alert.Message,
+			"suppressed_at":      alert.SuppressedAt,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package pg
+
+import "testing"
+
+func TestMessagesRepoSearchQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		search string
+		want   string
+	}{
+		{name: "empty", search: "  ", want: ""},
+		{name: "words", search: "link down", want: "'link' & 'down'"},
+		{name: "phrase", search: `"link down" eth0`, want: "'link' <-> 'down' & 'eth0'"},
+		{name: "unterminated phrase", search: `"link down`, want: "'link' <-> 'down'"},
+		{name: "prefix", search: "eth*", want: "'eth':*"},
+		{name: "lone star", search: "*", want: ""},
+		{name: "excluded word", search: "-debug error", want: "!('debug') & 'error'"},
+		{name: "excluded phrase", search: `-"power supply"`, want: "!('power' <-> 'supply')"},
+		{name: "or", search: "error OR warning disk", want: "('error' | 'warning') & 'disk'"},
+		{name: "or chain", search: "a or b OR c", want: "('a' | 'b' | 'c')"},
+		{name: "or with excluded", search: "a OR -b", want: "('a' | !('b'))"},
+		{name: "leading or", search: "OR error", want: "'error'"},
+		{name: "trailing or", search: "error OR", want: "'error'"},
+		{name: "excluded or is a word", search: "a -or", want: "'a' & !('or')"},
+		{name: "quote and backslash", search: `it's back\slash`, want: "'it s' & 'back slash'"},
+		{name: "tsquery operators are quoted", search: "a&b !c", want: "'a&b' & '!c'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messagesRepoSearchQuery(tt.search); got != tt.want {
+				t.Errorf("messagesRepoSearchQuery(%q) = %q, want %q", tt.search, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessagesRepoSnippetHTML(t *testing.T) {
+	got := messagesRepoSnippetHTML("port \x02<down>\x03 & up")
+	want := "port <mark>&lt;down&gt;</mark> &amp; up"
+	if got != want {
+		t.Errorf("messagesRepoSnippetHTML = %q, want %q", got, want)
+	}
+}
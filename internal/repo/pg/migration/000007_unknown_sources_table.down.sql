@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS messages_unknown_device_ip_idx;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS device_ip;
+
+drop table if exists unknown_sources;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS unknown_sources (
+		id int GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+		address inet NOT NULL,
+		first_seen timestamp NOT NULL,
+		last_seen timestamp NOT NULL,
+		message_count bigint NOT NULL DEFAULT 0,
+		status varchar(10) NOT NULL DEFAULT 'pending',
+		device_id int NULL,
+		CONSTRAINT unknown_sources_pk PRIMARY KEY (id),
+		CONSTRAINT unknown_sources_address_unique UNIQUE (address)
+	);
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS device_ip inet NULL;
+
+CREATE INDEX IF NOT EXISTS messages_unknown_device_ip_idx ON messages (device_ip) WHERE device_id = 0;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS device_credentials (
+		id int GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+		device_id int NOT NULL,
+		token_hash bytea NOT NULL,
+		created_at timestamp without time zone NOT NULL,
+		revoked_at timestamp without time zone NULL,
+		CONSTRAINT device_credentials_pk PRIMARY KEY (id),
+		CONSTRAINT device_credentials_token_hash_unique UNIQUE (token_hash)
+	);
+
+CREATE UNIQUE INDEX IF NOT EXISTS device_credentials_active_idx ON device_credentials (device_id) WHERE revoked_at IS NULL;
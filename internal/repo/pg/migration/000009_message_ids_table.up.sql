@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS message_ids (
+		sender varchar(64) NOT NULL,
+		message_id varchar(128) NOT NULL,
+		received_at timestamp NOT NULL,
+		CONSTRAINT message_ids_pk PRIMARY KEY (sender, message_id)
+	);
+
+CREATE INDEX IF NOT EXISTS message_ids_received_at_idx ON message_ids (received_at);
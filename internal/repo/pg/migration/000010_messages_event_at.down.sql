@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS messages_event_time_idx;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS event_at;
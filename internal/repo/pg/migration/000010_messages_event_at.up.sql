@@ -0,0 +1,3 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS event_at timestamp NULL;
+
+CREATE INDEX IF NOT EXISTS messages_event_time_idx ON messages ((coalesce(event_at, got_at)));
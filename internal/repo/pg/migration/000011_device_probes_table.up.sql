@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS device_probes (
+		id int GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+		device_id int NOT NULL,
+		probe_type varchar(10) NOT NULL,
+		target varchar NOT NULL DEFAULT '',
+		expected_status int NOT NULL DEFAULT 200,
+		body_regexp varchar NOT NULL DEFAULT '',
+		interval_sec int NOT NULL DEFAULT 60,
+		timeout_ms int NOT NULL DEFAULT 5000,
+		enabled bool NOT NULL DEFAULT true,
+		created_at timestamp without time zone NOT NULL,
+		updated_at timestamp without time zone NULL,
+		deleted_at timestamp without time zone NULL,
+		CONSTRAINT device_probes_pk PRIMARY KEY (id),
+		CONSTRAINT device_probes_type_check CHECK (probe_type IN ('http', 'tcp', 'dns')),
+		CONSTRAINT device_probes_interval_check CHECK (interval_sec > 0),
+		CONSTRAINT device_probes_timeout_check CHECK (timeout_ms > 0)
+	);
+
+CREATE INDEX IF NOT EXISTS device_probes_device_id_idx ON device_probes (device_id) WHERE deleted_at IS NULL;
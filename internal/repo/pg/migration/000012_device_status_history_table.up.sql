@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS device_status_history (
+		id int GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+		device_id int NOT NULL,
+		status varchar(10) NOT NULL,
+		previous_status varchar(10) NOT NULL,
+		reason varchar NOT NULL DEFAULT '',
+		changed_at timestamp without time zone NOT NULL,
+		CONSTRAINT device_status_history_pk PRIMARY KEY (id),
+		CONSTRAINT device_status_history_status_check CHECK (status IN ('up', 'down', 'degraded', 'unknown'))
+	);
+
+CREATE INDEX IF NOT EXISTS device_status_history_device_id_changed_at_idx ON device_status_history (device_id, changed_at);
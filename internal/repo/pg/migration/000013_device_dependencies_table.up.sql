@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS device_dependencies (
+		device_id int NOT NULL,
+		parent_id int NOT NULL,
+		created_at timestamp without time zone NOT NULL,
+		CONSTRAINT device_dependencies_pk PRIMARY KEY (device_id, parent_id),
+		CONSTRAINT device_dependencies_self_check CHECK (device_id <> parent_id)
+	);
+
+CREATE INDEX IF NOT EXISTS device_dependencies_parent_id_idx ON device_dependencies (parent_id);
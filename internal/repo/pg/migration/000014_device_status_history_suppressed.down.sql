@@ -0,0 +1 @@
+ALTER TABLE device_status_history DROP COLUMN IF EXISTS suppressed;
@@ -0,0 +1 @@
+ALTER TABLE device_status_history ADD COLUMN IF NOT EXISTS suppressed bool NOT NULL DEFAULT false;
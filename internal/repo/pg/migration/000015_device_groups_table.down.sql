@@ -0,0 +1,2 @@
+drop table if exists device_group_members;
+drop table if exists device_groups;
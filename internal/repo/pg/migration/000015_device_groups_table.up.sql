@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS device_groups (
+		id int GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+		"name" varchar NOT NULL,
+		group_type varchar(10) NOT NULL DEFAULT 'group',
+		parent_id int NULL,
+		created_at timestamp without time zone NOT NULL,
+		updated_at timestamp without time zone NULL,
+		CONSTRAINT device_groups_pk PRIMARY KEY (id),
+		CONSTRAINT device_groups_type_check CHECK (group_type IN ('site', 'room', 'rack', 'group')),
+		CONSTRAINT device_groups_parent_check CHECK (parent_id <> id)
+	);
+
+CREATE UNIQUE INDEX IF NOT EXISTS device_groups_parent_name_idx ON device_groups (coalesce(parent_id, 0), "name");
+
+CREATE TABLE IF NOT EXISTS device_group_members (
+		group_id int NOT NULL,
+		device_id int NOT NULL,
+		created_at timestamp without time zone NOT NULL,
+		CONSTRAINT device_group_members_pk PRIMARY KEY (group_id, device_id)
+	);
+
+CREATE INDEX IF NOT EXISTS device_group_members_device_id_idx ON device_group_members (device_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS devices_labels_idx;
+DROP INDEX IF EXISTS devices_attributes_idx;
+
+ALTER TABLE devices DROP COLUMN IF EXISTS labels;
+ALTER TABLE devices DROP COLUMN IF EXISTS attributes;
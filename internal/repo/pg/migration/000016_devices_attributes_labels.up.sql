@@ -0,0 +1,5 @@
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS attributes jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS labels jsonb NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS devices_attributes_idx ON devices USING gin (attributes);
+CREATE INDEX IF NOT EXISTS devices_labels_idx ON devices USING gin (labels);
@@ -0,0 +1,2 @@
+drop table if exists discovery_candidates;
+drop table if exists discovery_jobs;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS discovery_jobs (
+		id int GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+		cidr cidr NOT NULL,
+		ports jsonb NOT NULL DEFAULT '[]',
+		healthcheck bool NOT NULL DEFAULT true,
+		status varchar(10) NOT NULL DEFAULT 'pending',
+		hosts_total int NOT NULL DEFAULT 0,
+		hosts_scanned int NOT NULL DEFAULT 0,
+		candidates_found int NOT NULL DEFAULT 0,
+		error text NULL,
+		created_at timestamp without time zone NOT NULL,
+		started_at timestamp without time zone NULL,
+		finished_at timestamp without time zone NULL,
+		CONSTRAINT discovery_jobs_pk PRIMARY KEY (id),
+		CONSTRAINT discovery_jobs_status_check CHECK (status IN ('pending', 'running', 'done', 'failed'))
+	);
+
+CREATE TABLE IF NOT EXISTS discovery_candidates (
+		id int GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+		job_id int NOT NULL,
+		address inet NOT NULL,
+		open_ports jsonb NOT NULL DEFAULT '[]',
+		healthcheck bool NOT NULL DEFAULT false,
+		status varchar(10) NOT NULL DEFAULT 'pending',
+		device_id int NULL,
+		first_seen timestamp without time zone NOT NULL,
+		last_seen timestamp without time zone NOT NULL,
+		CONSTRAINT discovery_candidates_pk PRIMARY KEY (id),
+		CONSTRAINT discovery_candidates_address_unique UNIQUE (address),
+		CONSTRAINT discovery_candidates_status_check CHECK (status IN ('pending', 'promoted', 'dismissed'))
+	);
+
+CREATE INDEX IF NOT EXISTS discovery_candidates_job_id_idx ON discovery_candidates (job_id);
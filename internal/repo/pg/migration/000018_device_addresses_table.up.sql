@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS device_addresses (
+		id int GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+		device_id int NOT NULL,
+		address_type varchar(10) NOT NULL,
+		address cidr NULL,
+		hostname varchar NULL,
+		created_at timestamp without time zone NOT NULL,
+		CONSTRAINT device_addresses_pk PRIMARY KEY (id),
+		CONSTRAINT device_addresses_type_check CHECK (address_type IN ('ip', 'cidr', 'hostname')),
+		CONSTRAINT device_addresses_value_check CHECK (
+			(address_type = 'hostname' AND hostname IS NOT NULL AND address IS NULL)
+			OR (address_type <> 'hostname' AND address IS NOT NULL AND hostname IS NULL)
+		)
+	);
+
+CREATE UNIQUE INDEX IF NOT EXISTS device_addresses_address_idx ON device_addresses (address) WHERE address IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS device_addresses_hostname_idx ON device_addresses (lower(hostname)) WHERE hostname IS NOT NULL;
+CREATE INDEX IF NOT EXISTS device_addresses_device_id_idx ON device_addresses (device_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS messages_device_id_got_at_id_idx;
+DROP INDEX IF EXISTS messages_event_time_id_idx;
+DROP INDEX IF EXISTS messages_got_at_id_idx;
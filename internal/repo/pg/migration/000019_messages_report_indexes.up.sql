@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS messages_got_at_id_idx ON messages (got_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS messages_event_time_id_idx ON messages ((coalesce(event_at, got_at)) DESC, id DESC);
+CREATE INDEX IF NOT EXISTS messages_device_id_got_at_id_idx ON messages (device_id, got_at DESC, id DESC);
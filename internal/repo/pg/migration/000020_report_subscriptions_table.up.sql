@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS report_subscriptions (
+		id int GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+		name varchar(255) NOT NULL,
+		report varchar(30) NOT NULL,
+		params jsonb NOT NULL DEFAULT '{}',
+		schedule varchar(100) NOT NULL,
+		recipients jsonb NOT NULL DEFAULT '[]',
+		format varchar(10) NOT NULL DEFAULT 'csv',
+		enabled bool NOT NULL DEFAULT true,
+		last_run_at timestamp without time zone NULL,
+		last_status varchar(10) NULL,
+		last_error text NULL,
+		created_at timestamp without time zone NOT NULL,
+		updated_at timestamp without time zone NOT NULL,
+		CONSTRAINT report_subscriptions_pk PRIMARY KEY (id),
+		CONSTRAINT report_subscriptions_name_unique UNIQUE (name),
+		CONSTRAINT report_subscriptions_report_check CHECK (report IN ('messages_by_period', 'count_by_message_type', 'month_report', 'availability')),
+		CONSTRAINT report_subscriptions_format_check CHECK (format IN ('csv', 'xlsx')),
+		CONSTRAINT report_subscriptions_last_status_check CHECK (last_status IN ('ok', 'failed'))
+	);
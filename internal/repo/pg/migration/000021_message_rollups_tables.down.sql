@@ -0,0 +1,3 @@
+drop table if exists message_rollup_dirty_days;
+drop table if exists message_rollup_state;
+drop table if exists message_rollups;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS message_rollups (
+		day date NOT NULL,
+		device_id int NOT NULL,
+		message_type varchar NOT NULL,
+		severity_level varchar(10) NOT NULL,
+		component varchar(30) NOT NULL DEFAULT '',
+		message_count bigint NOT NULL,
+		first_at timestamp without time zone NOT NULL,
+		last_at timestamp without time zone NOT NULL,
+		CONSTRAINT message_rollups_pk PRIMARY KEY (day, device_id, message_type, severity_level, component)
+	);
+
+CREATE INDEX IF NOT EXISTS message_rollups_message_type_day_idx ON message_rollups (message_type, day);
+
+CREATE TABLE IF NOT EXISTS message_rollup_state (
+		id int NOT NULL DEFAULT 1,
+		first_day date NOT NULL,
+		complete_until date NOT NULL,
+		updated_at timestamp without time zone NOT NULL,
+		CONSTRAINT message_rollup_state_pk PRIMARY KEY (id),
+		CONSTRAINT message_rollup_state_single_row CHECK (id = 1),
+		CONSTRAINT message_rollup_state_range_check CHECK (complete_until >= first_day)
+	);
+
+INSERT INTO message_rollup_state (first_day, complete_until, updated_at)
+SELECT d.day, d.day, now()
+FROM (SELECT cast(coalesce(min(got_at), now()) AS date) AS day FROM messages) d
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS message_rollup_dirty_days (
+		day date NOT NULL,
+		CONSTRAINT message_rollup_dirty_days_pk PRIMARY KEY (day)
+	);
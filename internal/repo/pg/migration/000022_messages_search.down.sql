@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS messages_message_tsv_idx;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS message_tsv;
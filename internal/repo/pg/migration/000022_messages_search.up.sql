@@ -0,0 +1,4 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS message_tsv tsvector
+		GENERATED ALWAYS AS (to_tsvector('simple', message)) STORED;
+
+CREATE INDEX IF NOT EXISTS messages_message_tsv_idx ON messages USING gin (message_tsv);
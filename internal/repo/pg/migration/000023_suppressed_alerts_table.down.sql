@@ -0,0 +1 @@
+drop table if exists suppressed_alerts;
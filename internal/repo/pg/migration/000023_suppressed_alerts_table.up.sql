@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS suppressed_alerts (
+		id int GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+		device_id int NOT NULL,
+		upstream_device_id int NOT NULL,
+		subject varchar NOT NULL,
+		message text NOT NULL,
+		suppressed_at timestamp without time zone NOT NULL,
+		CONSTRAINT suppressed_alerts_pk PRIMARY KEY (id)
+	);
+
+CREATE INDEX IF NOT EXISTS suppressed_alerts_device_id_suppressed_at_idx ON suppressed_alerts (device_id, suppressed_at);
@@ -0,0 +1,245 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/pkg/postgres"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type probesRepo struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+
+	log *zap.Logger
+}
+
+func NewProbesRepo(p *postgres.Postgres, log *zap.Logger) repo.Probes {
+	return &probesRepo{
+		db:  p.DB,
+		log: log,
+	}
+}
+
+func (r probesRepo) BeginTx(ctx context.Context) (repo.Probes, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{
+		Isolation: 0,
+		ReadOnly:  false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("r.db.BeginTx: %w", err)
+	}
+
+	r.tx = tx
+
+	return r, nil
+}
+
+func (r probesRepo) Commit() error {
+	err := r.tx.Commit()
+	if err != nil {
+		return fmt.Errorf("r.tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r probesRepo) Rollback() error {
+	err := r.tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("r.tx.Rollback: %w", err)
+	}
+
+	return nil
+}
+
+// probesRepoQueryInsert returns no rows for a missing or deleted device,
+// which is reported as repo.ErrDeviceNotFound.
+const probesRepoQueryInsert = `
+with d as (
+	select id, address from devices
+	where id = :device_id and deleted_at is null
+), p as (
+	insert into device_probes (device_id, probe_type, target, expected_status, body_regexp, interval_sec, timeout_ms, enabled, created_at)
+	select d.id, :probe_type, :target, :expected_status, :body_regexp, :interval_sec, :timeout_ms, :enabled, :created_at
+	from d
+	returning id, device_id, probe_type, target, expected_status, body_regexp, interval_sec, timeout_ms, enabled, created_at, updated_at
+)
+select p.*, d.address from p
+join d on d.id = p.device_id;
+`
+
+func (r probesRepo) Create(ctx context.Context, opts models.Probe) (models.Probe, error) {
+	rows, err := r.tx.NamedQuery(probesRepoQueryInsert,
+		map[string]any{
+			"device_id":       opts.DeviceID,
+			"probe_type":      opts.ProbeType,
+			"target":          opts.Target,
+			"expected_status": opts.ExpectedStatus,
+			"body_regexp":     opts.BodyRegexp,
+			"interval_sec":    opts.IntervalSec,
+			"timeout_ms":      opts.TimeoutMs,
+			"enabled":         opts.Enabled,
+			"created_at":      time.Now(),
+		},
+	)
+	if err != nil {
+		return models.Probe{}, fmt.Errorf("r.tx.NamedQuery: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.log.Error("failed to closing rows", zap.Error(err))
+		}
+	}()
+
+	if !rows.Next() {
+		return models.Probe{}, repo.ErrDeviceNotFound
+	}
+	var probe models.Probe
+	err = rows.StructScan(&probe)
+	if err != nil {
+		return models.Probe{}, fmt.Errorf("rows.StructScan: %w", err)
+	}
+
+	return probe, nil
+}
+
+const probesRepoQueryRead = `
+select p.id, p.device_id, p.probe_type, p.target, p.expected_status, p.body_regexp, p.interval_sec, p.timeout_ms,
+	p.enabled, p.created_at, p.updated_at, d.address
+from device_probes p
+join devices d on d.id = p.device_id
+where p.deleted_at is null and d.deleted_at is null
+order by p.id;
+`
+
+func (r probesRepo) Read(ctx context.Context) (repo.ReadProbesResult, error) {
+	result := repo.ReadProbesResult{
+		Probes: make([]models.Probe, 0),
+	}
+
+	err := r.tx.SelectContext(ctx, &result.Probes, probesRepoQueryRead)
+	if err != nil {
+		return repo.ReadProbesResult{}, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return result, nil
+}
+
+const probesRepoQueryGet = `
+select p.id, p.device_id, p.probe_type, p.target, p.expected_status, p.body_regexp, p.interval_sec, p.timeout_ms,
+	p.enabled, p.created_at, p.updated_at, d.address
+from device_probes p
+join devices d on d.id = p.device_id
+where p.id = :id and p.deleted_at is null
+for update of p;
+`
+
+// Get locks the probe until the end of the transaction.
+func (r probesRepo) Get(ctx context.Context, id int32) (models.Probe, error) {
+	query, args, err := sqlx.Named(probesRepoQueryGet, map[string]any{
+		"id": id,
+	})
+	if err != nil {
+		return models.Probe{}, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	var probe models.Probe
+	err = r.tx.GetContext(ctx, &probe, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Probe{}, repo.ErrProbeNotFound
+	}
+	if err != nil {
+		return models.Probe{}, fmt.Errorf("r.tx.GetContext: %w", err)
+	}
+
+	return probe, nil
+}
+
+const probesRepoQueryUpdate = `
+update device_probes
+set probe_type = coalesce(:probe_type, probe_type),
+	target = coalesce(:target, target),
+	expected_status = coalesce(:expected_status, expected_status),
+	body_regexp = coalesce(:body_regexp, body_regexp),
+	interval_sec = coalesce(:interval_sec, interval_sec),
+	timeout_ms = coalesce(:timeout_ms, timeout_ms),
+	enabled = coalesce(:enabled, enabled),
+	updated_at = :updated_at
+where id = :id and deleted_at is null;
+`
+
+func (r probesRepo) Update(ctx context.Context, opts repo.UpdateProbeOpts) error {
+	res, err := r.tx.NamedExecContext(ctx, probesRepoQueryUpdate,
+		struct {
+			ID             int32     `db:"id"`
+			ProbeType      *string   `db:"probe_type"`
+			Target         *string   `db:"target"`
+			ExpectedStatus *int32    `db:"expected_status"`
+			BodyRegexp     *string   `db:"body_regexp"`
+			IntervalSec    *int32    `db:"interval_sec"`
+			TimeoutMs      *int32    `db:"timeout_ms"`
+			Enabled        *bool     `db:"enabled"`
+			UpdatedAt      time.Time `db:"updated_at"`
+		}{
+			ID:             opts.ID,
+			ProbeType:      opts.ProbeType,
+			Target:         opts.Target,
+			ExpectedStatus: opts.ExpectedStatus,
+			BodyRegexp:     opts.BodyRegexp,
+			IntervalSec:    opts.IntervalSec,
+			TimeoutMs:      opts.TimeoutMs,
+			Enabled:        opts.Enabled,
+			UpdatedAt:      time.Now(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if updated == 0 {
+		return repo.ErrProbeNotFound
+	}
+
+	return nil
+}
+
+const probesRepoQueryDelete = `
+delete from device_probes
+where id = :id and deleted_at is null;
+`
+
+func (r probesRepo) Delete(ctx context.Context, id int32) error {
+	res, err := r.tx.NamedExecContext(ctx, probesRepoQueryDelete,
+		map[string]any{
+			"id": id,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if deleted == 0 {
+		return repo.ErrProbeNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/pkg/postgres"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type reportSubscriptionsRepo struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+
+	log *zap.Logger
+}
+
+func NewReportSubscriptionsRepo(p *postgres.Postgres, log *zap.Logger) repo.ReportSubscriptions {
+	return &reportSubscriptionsRepo{
+		db:  p.DB,
+		log: log,
+	}
+}
+
+func (r reportSubscriptionsRepo) BeginTx(ctx context.Context) (repo.ReportSubscriptions, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{
+		Isolation: 0,
+		ReadOnly:  false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("r.db.BeginTx: %w", err)
+	}
+
+	r.tx = tx
+
+	return r, nil
+}
+
+func (r reportSubscriptionsRepo) Commit() error {
+	err := r.tx.Commit()
+	if err != nil {
+		return fmt.Errorf("r.tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r reportSubscriptionsRepo) Rollback() error {
+	err := r.tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("r.tx.Rollback: %w", err)
+	}
+
+	return nil
+}
+
+const reportSubscriptionsRepoColumns = `id, name, report, params, schedule, recipients, format, enabled,
+	last_run_at, last_status, last_error, created_at, updated_at`
+
+const reportSubscriptionsRepoQueryCreate = `
+insert into report_subscriptions (name, report, params, schedule, recipients, format, enabled, created_at, updated_at)
+values
+(:name, :report, :params, :schedule, :recipients, :format, :enabled, :created_at, :created_at)
+returning ` + reportSubscriptionsRepoColumns + `;
+`
+
+func (r reportSubscriptionsRepo) Create(
+	ctx context.Context,
+	opts models.ReportSubscription,
+) (models.ReportSubscription, error) {
+	return r.queryOne(ctx, reportSubscriptionsRepoQueryCreate,
+		map[string]any{
+			"name":       opts.Name,
+			"report":     opts.Report,
+			"params":     opts.Params,
+			"schedule":   opts.Schedule,
+			"recipients": opts.Recipients,
+			"format":     opts.Format,
+			"enabled":    opts.Enabled,
+			"created_at": time.Now(),
+		},
+	)
+}
+
+const reportSubscriptionsRepoQueryRead = `
+select ` + reportSubscriptionsRepoColumns + `
+from report_subscriptions
+order by id;
+`
+
+func (r reportSubscriptionsRepo) Read(ctx context.Context) ([]models.ReportSubscription, error) {
+	subscriptions := make([]models.ReportSubscription, 0)
+
+	err := r.tx.SelectContext(ctx, &subscriptions, reportSubscriptionsRepoQueryRead)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+const reportSubscriptionsRepoQueryGet = `
+select ` + reportSubscriptionsRepoColumns + `
+from report_subscriptions
+where id = :id;
+`
+
+func (r reportSubscriptionsRepo) Get(ctx context.Context, id int32) (models.ReportSubscription, error) {
+	return r.queryOne(ctx, reportSubscriptionsRepoQueryGet,
+		map[string]any{
+			"id": id,
+		},
+	)
+}
+
+const reportSubscriptionsRepoQueryUpdate = `
+update report_subscriptions
+set name = :name,
+	report = :report,
+	params = :params,
+	schedule = :schedule,
+	recipients = :recipients,
+	format = :format,
+	enabled = :enabled,
+	updated_at = :updated_at
+where id = :id
+returning ` + reportSubscriptionsRepoColumns + `;
+`
+
+func (r reportSubscriptionsRepo) Update(
+	ctx context.Context,
+	opts models.ReportSubscription,
+) (models.ReportSubscription, error) {
+	return r.queryOne(ctx, reportSubscriptionsRepoQueryUpdate,
+		map[string]any{
+			"id":         opts.ID,
+			"name":       opts.Name,
+			"report":     opts.Report,
+			"params":     opts.Params,
+			"schedule":   opts.Schedule,
+			"recipients": opts.Recipients,
+			"format":     opts.Format,
+			"enabled":    opts.Enabled,
+			"updated_at": time.Now(),
+		},
+	)
+}
+
+const reportSubscriptionsRepoQueryDelete = `
+delete from report_subscriptions
+where id = :id;
+`
+
+func (r reportSubscriptionsRepo) Delete(ctx context.Context, id int32) error {
+	res, err := r.tx.NamedExecContext(ctx, reportSubscriptionsRepoQueryDelete,
+		map[string]any{
+			"id": id,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if deleted == 0 {
+		return repo.ErrReportSubscriptionNotFound
+	}
+
+	return nil
+}
+
+const reportSubscriptionsRepoQuerySetLastRun = `
+update report_subscriptions
+set last_run_at = :last_run_at,
+	last_status = :last_status,
+	last_error = :last_error
+where id = :id;
+`
+
+func (r reportSubscriptionsRepo) SetLastRun(ctx context.Context, opts repo.SetReportSubscriptionRunOpts) error {
+	res, err := r.tx.NamedExecContext(ctx, reportSubscriptionsRepoQuerySetLastRun,
+		map[string]any{
+			"id":          opts.ID,
+			"last_run_at": opts.RunAt,
+			"last_status": opts.Status,
+			"last_error":  opts.Error,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if updated == 0 {
+		return repo.ErrReportSubscriptionNotFound
+	}
+
+	return nil
+}
+
+// queryOne runs a query returning one subscription. No row means the
+// subscription does not exist.
+func (r reportSubscriptionsRepo) queryOne(
+	ctx context.Context,
+	query string,
+	arg map[string]any,
+) (models.ReportSubscription, error) {
+	rows, err := r.tx.NamedQuery(query, arg)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) && pgerr.Code == pgErrCodeUniqueViolation {
+			return models.ReportSubscription{}, repo.ErrReportSubscriptionExists
+		}
+		return models.ReportSubscription{}, fmt.Errorf("r.tx.NamedQuery: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.log.Error("failed to closing rows", zap.Error(err))
+		}
+	}()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			var pgerr *pgconn.PgError
+			if errors.As(err, &pgerr) && pgerr.Code == pgErrCodeUniqueViolation {
+				return models.ReportSubscription{}, repo.ErrReportSubscriptionExists
+			}
+			return models.ReportSubscription{}, fmt.Errorf("rows.Err: %w", err)
+		}
+		return models.ReportSubscription{}, repo.ErrReportSubscriptionNotFound
+	}
+	var subscription models.ReportSubscription
+	err = rows.StructScan(&subscription)
+	if err != nil {
+		return models.ReportSubscription{}, fmt.Errorf("rows.StructScan: %w", err)
+	}
+
+	return subscription, nil
+}
@@ -0,0 +1,285 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/pkg/postgres"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type unknownSourcesRepo struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+
+	log *zap.Logger
+}
+
+func NewUnknownSourcesRepo(p *postgres.Postgres, log *zap.Logger) repo.UnknownSources {
+	return &unknownSourcesRepo{
+		db:  p.DB,
+		log: log,
+	}
+}
+
+func (r unknownSourcesRepo) BeginTx(ctx context.Context) (repo.UnknownSources, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{
+		Isolation: 0,
+		ReadOnly:  false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("r.db.BeginTx: %w", err)
+	}
+
+	r.tx = tx
+
+	return r, nil
+}
+
+func (r unknownSourcesRepo) Commit() error {
+	err := r.tx.Commit()
+	if err != nil {
+		return fmt.Errorf("r.tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r unknownSourcesRepo) Rollback() error {
+	err := r.tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("r.tx.Rollback: %w", err)
+	}
+
+	return nil
+}
+
+// unknownSourcesRepoQueryTrack never overwrites the status of a known
+// source: a blocked or promoted sender stays so however often it shows up.
+const unknownSourcesRepoQueryTrack = `
+insert into unknown_sources (address, first_seen, last_seen, message_count)
+values
+(CAST(:address AS inet), :first_seen, :last_seen, :message_count)
+on conflict (address) do update
+set first_seen = least(unknown_sources.first_seen, excluded.first_seen),
+	last_seen = greatest(unknown_sources.last_seen, excluded.last_seen),
+	message_count = unknown_sources.message_count + excluded.message_count
+`
+
+func (r unknownSourcesRepo) Track(ctx context.Context, hits []repo.UnknownSourceHit) error {
+	if len(hits) == 0 {
+		return nil
+	}
+
+	rows := make([]map[string]any, 0, len(hits))
+	for _, hit := range hits {
+		rows = append(rows, map[string]any{
+			"address":       hit.Address,
+			"first_seen":    hit.FirstSeen,
+			"last_seen":     hit.LastSeen,
+			"message_count": hit.MessageCount,
+		})
+	}
+
+	_, err := r.tx.NamedExecContext(ctx, unknownSourcesRepoQueryTrack, rows)
+	if err != nil {
+		return fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	return nil
+}
+
+const unknownSourcesRepoQueryRead = `
+select id, address, first_seen, last_seen, message_count, status, device_id
+from unknown_sources
+where :status = '' or status = :status
+order by last_seen desc
+`
+
+func (r unknownSourcesRepo) Read(ctx context.Context, status string) ([]models.UnknownSource, error) {
+	sources := make([]models.UnknownSource, 0)
+
+	query, args, err := sqlx.Named(unknownSourcesRepoQueryRead, map[string]any{
+		"status": status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	err = r.tx.SelectContext(ctx, &sources, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("r.tx.SelectContext: %w", err)
+	}
+
+	return sources, nil
+}
+
+const unknownSourcesRepoQueryLock = `
+select id, address, first_seen, last_seen, message_count, status, device_id
+from unknown_sources
+where id = :id or address = CAST(NULLIF(:address, '') AS inet)
+for update
+`
+
+const unknownSourcesRepoQueryAssignMessages = `
+update messages
+set device_id = :device_id
+where device_id = 0 and device_ip = CAST(:address AS inet)
+`
+
+// unknownSourcesRepoQueryMarkRollupsDirty marks the days of the messages
+// moved to the new device, which only has those, for the rollups to be
+// recomputed.
+const unknownSourcesRepoQueryMarkRollupsDirty = `
+insert into message_rollup_dirty_days (day)
+select distinct cast(got_at as date)
+from messages
+where device_id = :device_id
+on conflict (day) do nothing
+`
+
+const unknownSourcesRepoQueryMarkPromoted = `
+update unknown_sources
+set status = 'promoted', device_id = :device_id
+where id = :id
+`
+
+// Promote registers the source as a device and moves the messages it has
+// sent so far from the unknown device to the new one.
+func (r unknownSourcesRepo) Promote(
+	ctx context.Context,
+	opts repo.PromoteUnknownSourceOpts,
+) (repo.PromoteUnknownSourceResult, error) {
+	source, err := r.lock(ctx, opts.ID, opts.Address)
+	if err != nil {
+		return repo.PromoteUnknownSourceResult{}, err
+	}
+	if source.Status == models.UnknownSourceStatusPromoted {
+		return repo.PromoteUnknownSourceResult{}, repo.ErrUnknownSourcePromoted
+	}
+
+	responsible := opts.Responsible
+	if responsible == nil {
+		responsible = []int32{}
+	}
+
+	device, err := insertDevice(r.tx, r.log, models.Device{
+		Name:        opts.Name,
+		DeviceType:  opts.DeviceType,
+		Address:     source.Address,
+		Responsible: responsible,
+	})
+	if err != nil {
+		return repo.PromoteUnknownSourceResult{}, err
+	}
+
+	assigned, err := r.AssignMessages(ctx, device.ID, source.Address)
+	if err != nil {
+		return repo.PromoteUnknownSourceResult{}, err
+	}
+
+	_, err = r.tx.NamedExecContext(ctx, unknownSourcesRepoQueryMarkPromoted,
+		map[string]any{
+			"id":        source.ID,
+			"device_id": device.ID,
+		},
+	)
+	if err != nil {
+		return repo.PromoteUnknownSourceResult{}, fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	return repo.PromoteUnknownSourceResult{
+		Device:           device,
+		MessagesAssigned: assigned,
+	}, nil
+}
+
+// AssignMessages moves the messages the address has sent from the unknown
+// device to the device.
+func (r unknownSourcesRepo) AssignMessages(ctx context.Context, deviceID int32, address string) (int64, error) {
+	res, err := r.tx.NamedExecContext(ctx, unknownSourcesRepoQueryAssignMessages,
+		map[string]any{
+			"device_id": deviceID,
+			"address":   address,
+		},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+	assigned, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+
+	if assigned > 0 {
+		_, err = r.tx.NamedExecContext(ctx, unknownSourcesRepoQueryMarkRollupsDirty,
+			map[string]any{
+				"device_id": deviceID,
+			},
+		)
+		if err != nil {
+			return 0, fmt.Errorf("r.tx.NamedExecContext: %w", err)
+		}
+	}
+
+	return assigned, nil
+}
+
+const unknownSourcesRepoQueryBlock = `
+update unknown_sources
+set status = 'blocked'
+where id = :id
+`
+
+func (r unknownSourcesRepo) Block(ctx context.Context, id int32) (models.UnknownSource, error) {
+	source, err := r.lock(ctx, id, "")
+	if err != nil {
+		return models.UnknownSource{}, err
+	}
+	if source.Status == models.UnknownSourceStatusPromoted {
+		return models.UnknownSource{}, repo.ErrUnknownSourcePromoted
+	}
+
+	_, err = r.tx.NamedExecContext(ctx, unknownSourcesRepoQueryBlock,
+		map[string]any{
+			"id": id,
+		},
+	)
+	if err != nil {
+		return models.UnknownSource{}, fmt.Errorf("r.tx.NamedExecContext: %w", err)
+	}
+
+	source.Status = models.UnknownSourceStatusBlocked
+
+	return source, nil
+}
+
+// lock selects a source either by id or, when id is zero, by address.
+func (r unknownSourcesRepo) lock(ctx context.Context, id int32, address string) (models.UnknownSource, error) {
+	query, args, err := sqlx.Named(unknownSourcesRepoQueryLock, map[string]any{
+		"id":      id,
+		"address": address,
+	})
+	if err != nil {
+		return models.UnknownSource{}, fmt.Errorf("sqlx.Named: %w", err)
+	}
+	query = sqlx.Rebind(sqlx.BindType(r.tx.DriverName()), query)
+
+	var source models.UnknownSource
+	err = r.tx.GetContext(ctx, &source, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.UnknownSource{}, repo.ErrUnknownSourceNotFound
+		}
+		return models.UnknownSource{}, fmt.Errorf("r.tx.GetContext: %w", err)
+	}
+
+	return source, nil
+}
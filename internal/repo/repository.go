@@ -3,6 +3,7 @@ package repo
 import (
 	"context"
 	"monolith/internal/models"
+	"time"
 )
 
 type Auth interface {
@@ -25,9 +26,16 @@ type Devices interface {
 
 	Create(opts models.Device) (models.Device, error)
 	Read(ctx context.Context) (ReadDevicesResult, error)
+	ReadFiltered(ctx context.Context, opts ReadDevicesOpts) (ReadDevicesResult, error)
 	Update(ctx context.Context, opts UpdateDeviceOpts) error
 	Delete(ctx context.Context, id int32) error
 	GetResponsible(ctx context.Context, deviceID int32) ([]int32, error)
+	// Lock holds off device writes of other transactions until this one
+	// ends.
+	Lock(ctx context.Context) error
+	// ReadConflicts returns the devices that take one of the names or the
+	// addresses. Deleted devices keep their names taken.
+	ReadConflicts(ctx context.Context, names, addresses []string) ([]models.Device, error)
 }
 
 type Tags interface {
@@ -47,8 +55,156 @@ type Messages interface {
 	Rollback() error
 
 	Create(opts models.Message) error
+	CopyFrom(ctx context.Context, opts []models.Message) (int64, error)
+	// GetAllByPeriod and GetAllByDeviceId return the messages newest first.
+	// The counts ignore After and Limit.
 	GetAllByPeriod(opts MessagesGetAllByPeriodOpts) ([]models.Message, error)
-	GetAllByDeviceId(deviceID int32) ([]models.Message, error)
-	GetCountByMessageType(messageType string) (GetCountByMessageTypeResult, error)
-	MonthReport() ([]models.MonthReportRow, error)
+	CountByPeriod(opts MessagesGetAllByPeriodOpts) (int64, error)
+	GetAllByDeviceId(opts MessagesGetAllByDeviceIdOpts) ([]models.Message, error)
+	CountByDeviceId(opts MessagesGetAllByDeviceIdOpts) (int64, error)
+	// GetCountByMessageType is limited to the devices of the group and its
+	// subgroups unless groupID is zero.
+	GetCountByMessageType(messageType string, groupID int32) (GetCountByMessageTypeResult, error)
+	PeriodReport(opts PeriodReportOpts) ([]models.PeriodReportRow, error)
+	// SearchMessages returns the best matches first or the newest first, as
+	// opts.OrderBy says. The count ignores After and Limit.
+	SearchMessages(opts SearchMessagesOpts) ([]models.MessageSearchHit, error)
+	CountSearchMessages(opts SearchMessagesOpts) (int64, error)
+
+	// RollupState locks the rollup state until the end of the transaction.
+	RollupState(ctx context.Context) (models.MessageRollupState, error)
+	// RollupDays recomputes the rollups of the receive days in [from, until)
+	// and returns how many rollup rows were written.
+	RollupDays(ctx context.Context, from, until time.Time) (int64, error)
+	SetRollupCompleteUntil(ctx context.Context, until time.Time) error
+	// TakeDirtyRollupDays returns and forgets the days whose rollups went
+	// stale after their messages were changed.
+	TakeDirtyRollupDays(ctx context.Context) ([]time.Time, error)
+
+	ClaimMessageIDs(ctx context.Context, opts ClaimMessageIDsOpts) ([]MessageIDKey, error)
+	PurgeMessageIDs(ctx context.Context, before time.Time) (int64, error)
+
+	AddSuppressedAlert(ctx context.Context, alert models.SuppressedAlert) error
+}
+
+type UnknownSources interface {
+	BeginTx(ctx context.Context) (UnknownSources, error)
+	Commit() error
+	Rollback() error
+
+	Track(ctx context.Context, hits []UnknownSourceHit) error
+	Read(ctx context.Context, status string) ([]models.UnknownSource, error)
+	Promote(ctx context.Context, opts PromoteUnknownSourceOpts) (PromoteUnknownSourceResult, error)
+	// AssignMessages moves the messages the address has sent from the
+	// unknown device to the device and returns how many were moved.
+	AssignMessages(ctx context.Context, deviceID int32, address string) (int64, error)
+	Block(ctx context.Context, id int32) (models.UnknownSource, error)
+}
+
+type DeviceCredentials interface {
+	BeginTx(ctx context.Context) (DeviceCredentials, error)
+	Commit() error
+	Rollback() error
+
+	Issue(ctx context.Context, opts IssueDeviceCredentialOpts) (models.DeviceCredential, error)
+	Revoke(ctx context.Context, deviceID int32) error
+	ReadActive(ctx context.Context) ([]models.DeviceCredential, error)
+}
+
+type Probes interface {
+	BeginTx(ctx context.Context) (Probes, error)
+	Commit() error
+	Rollback() error
+
+	Create(ctx context.Context, opts models.Probe) (models.Probe, error)
+	Read(ctx context.Context) (ReadProbesResult, error)
+	// Get locks the probe until the end of the transaction.
+	Get(ctx context.Context, id int32) (models.Probe, error)
+	Update(ctx context.Context, opts UpdateProbeOpts) error
+	Delete(ctx context.Context, id int32) error
+}
+
+type DeviceStatus interface {
+	BeginTx(ctx context.Context) (DeviceStatus, error)
+	Commit() error
+	Rollback() error
+
+	AddChange(ctx context.Context, change models.DeviceStatusChange) error
+	// ReadCurrent returns the latest change of every device.
+	ReadCurrent(ctx context.Context) ([]models.DeviceStatusChange, error)
+	Availability(ctx context.Context, opts AvailabilityReportOpts) ([]models.AvailabilityReportRow, error)
+}
+
+type DeviceDependencies interface {
+	BeginTx(ctx context.Context) (DeviceDependencies, error)
+	Commit() error
+	Rollback() error
+
+	// Add fails with ErrDependencyCycle when the parent already depends on
+	// the device.
+	Add(ctx context.Context, deviceID, parentID int32) (models.DeviceDependency, error)
+	Remove(ctx context.Context, deviceID, parentID int32) error
+	Read(ctx context.Context) ([]models.DeviceDependency, error)
+}
+
+type DeviceGroups interface {
+	BeginTx(ctx context.Context) (DeviceGroups, error)
+	Commit() error
+	Rollback() error
+
+	Create(ctx context.Context, opts models.DeviceGroup) (models.DeviceGroup, error)
+	Read(ctx context.Context) ([]models.DeviceGroup, error)
+	// Update fails with ErrDeviceGroupCycle when the group is moved under
+	// itself or one of its subgroups.
+	Update(ctx context.Context, opts UpdateDeviceGroupOpts) error
+	// Delete fails with ErrDeviceGroupNotEmpty while the group has
+	// subgroups. Memberships of the group are deleted with it.
+	Delete(ctx context.Context, id int32) error
+	AddDevice(ctx context.Context, groupID, deviceID int32) error
+	RemoveDevice(ctx context.Context, groupID, deviceID int32) error
+}
+
+type DeviceAddresses interface {
+	BeginTx(ctx context.Context) (DeviceAddresses, error)
+	Commit() error
+	Rollback() error
+
+	Add(ctx context.Context, opts models.DeviceAddress) (models.DeviceAddress, error)
+	// Read returns the addresses of the device, or of all devices when
+	// deviceID is zero. Addresses of deleted devices are left out.
+	Read(ctx context.Context, deviceID int32) ([]models.DeviceAddress, error)
+	Delete(ctx context.Context, id int32) error
+}
+
+type Discovery interface {
+	BeginTx(ctx context.Context) (Discovery, error)
+	Commit() error
+	Rollback() error
+
+	CreateJob(ctx context.Context, opts models.DiscoveryJob) (models.DiscoveryJob, error)
+	ReadJobs(ctx context.Context) ([]models.DiscoveryJob, error)
+	UpdateJob(ctx context.Context, opts UpdateDiscoveryJobOpts) error
+	// RecordCandidates skips hosts that are devices and returns how many
+	// candidates were added or seen again.
+	RecordCandidates(ctx context.Context, jobID int32, hosts []DiscoveredHost) (int64, error)
+	ReadCandidates(ctx context.Context, opts ReadDiscoveryCandidatesOpts) ([]models.DiscoveryCandidate, error)
+	// PromoteCandidate fails with ErrDeviceExists when the address became a
+	// device in the meantime.
+	PromoteCandidate(ctx context.Context, opts PromoteDiscoveryCandidateOpts) (models.Device, error)
+	DismissCandidate(ctx context.Context, id int32) (models.DiscoveryCandidate, error)
+}
+
+type ReportSubscriptions interface {
+	BeginTx(ctx context.Context) (ReportSubscriptions, error)
+	Commit() error
+	Rollback() error
+
+	Create(ctx context.Context, opts models.ReportSubscription) (models.ReportSubscription, error)
+	Read(ctx context.Context) ([]models.ReportSubscription, error)
+	Get(ctx context.Context, id int32) (models.ReportSubscription, error)
+	// Update replaces the definition of the subscription and keeps its last
+	// run.
+	Update(ctx context.Context, opts models.ReportSubscription) (models.ReportSubscription, error)
+	Delete(ctx context.Context, id int32) error
+	SetLastRun(ctx context.Context, opts SetReportSubscriptionRunOpts) error
 }
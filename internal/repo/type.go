@@ -39,12 +39,24 @@ type UpdateDeviceOpts struct {
 	DeviceType  *string
 	Address     *string
 	Responsible []int32
+	// Attributes and Labels replace the stored maps unless nil.
+	Attributes map[string]string
+	Labels     map[string]string
 }
 
 type ReadDevicesResult struct {
 	Devices []models.Device
 }
 
+// ReadDevicesOpts limits the devices to the members of GroupID and its
+// subgroups unless GroupID is zero, and to the devices matching every
+// label and attribute requirement.
+type ReadDevicesOpts struct {
+	GroupID    int32
+	Labels     []models.SelectorRequirement
+	Attributes []models.SelectorRequirement
+}
+
 type UpdateTagsOpts struct {
 	ID            int32
 	Name          *string
@@ -61,11 +73,202 @@ type ReadTagsResult struct {
 	Tags []models.Tag
 }
 
+// MessagesFilter narrows a message report. Empty fields match every
+// message; Text is a case-insensitive substring of the message.
+type MessagesFilter struct {
+	DeviceIDs    []int32
+	MessageTypes []string
+	Severities   []string
+	Component    string
+	Text         string
+}
+
+// MessagesCursor is the position of a message in a report: its report time
+// and id.
+type MessagesCursor struct {
+	Time time.Time
+	ID   int32
+}
+
 type MessagesGetAllByPeriodOpts struct {
 	StartTime time.Time
 	EndTime   time.Time
+	TimeBasis string
+	Filter    MessagesFilter
+	// After continues the report past the given message.
+	After *MessagesCursor
+	// Limit of zero returns every message.
+	Limit int
+}
+
+// SearchMessagesCursor is the position of a hit in a search: its rank, its
+// report time and its message id. Only the fields of the order are used.
+type SearchMessagesCursor struct {
+	Rank float32
+	Time time.Time
+	ID   int32
+}
+
+// SearchMessagesOpts finds the messages whose text matches Query, made of
+// words, "phrases", prefix* words, -excluded terms and OR, within
+// [StartTime, EndTime] of the TimeBasis; a zero time leaves that side open.
+// Filter.Text also applies.
+type SearchMessagesOpts struct {
+	Query     string
+	StartTime time.Time
+	EndTime   time.Time
+	TimeBasis string
+	Filter    MessagesFilter
+	// OrderBy is models.SearchOrderRank or models.SearchOrderTime.
+	OrderBy string
+	After   *SearchMessagesCursor
+	// Limit of zero returns every hit.
+	Limit int
+}
+
+type MessagesGetAllByDeviceIdOpts struct {
+	DeviceID int32
+	Filter   MessagesFilter
+	After    *MessagesCursor
+	Limit    int
+}
+
+// MessageIDKey identifies a client supplied message id. Sender is the device
+// id for known devices and the source address for unknown ones.
+type MessageIDKey struct {
+	Sender    string `db:"sender"`
+	MessageID string `db:"message_id"`
+}
+
+// ClaimMessageIDsOpts claims keys that were not seen after Since. Keys
+// recorded before Since are outside the deduplication window and are
+// claimed again.
+type ClaimMessageIDsOpts struct {
+	Keys       []MessageIDKey
+	ReceivedAt time.Time
+	Since      time.Time
 }
 
 type GetCountByMessageTypeResult struct {
 	Count []models.CountByDeviceID
 }
+
+type UnknownSourceHit struct {
+	Address      string
+	FirstSeen    time.Time
+	LastSeen     time.Time
+	MessageCount int64
+}
+
+// PromoteUnknownSourceOpts selects the source by ID, or by Address when ID
+// is zero.
+type PromoteUnknownSourceOpts struct {
+	ID          int32
+	Address     string
+	Name        string
+	DeviceType  string
+	Responsible []int32
+}
+
+type PromoteUnknownSourceResult struct {
+	Device           models.Device
+	MessagesAssigned int64
+}
+
+// IssueDeviceCredentialOpts with Rotate revokes the active credential of the
+// device in the same transaction instead of failing with
+// ErrDeviceCredentialExists.
+type IssueDeviceCredentialOpts struct {
+	DeviceID  int32
+	TokenHash []byte
+	Rotate    bool
+}
+
+type ReadProbesResult struct {
+	Probes []models.Probe
+}
+
+type UpdateProbeOpts struct {
+	ID             int32
+	ProbeType      *string
+	Target         *string
+	ExpectedStatus *int32
+	BodyRegexp     *string
+	IntervalSec    *int32
+	TimeoutMs      *int32
+	Enabled        *bool
+}
+
+// AvailabilityReportOpts limits the report to DeviceID unless it is zero.
+// The status in effect at Now is extended up to Now.
+type AvailabilityReportOpts struct {
+	StartTime time.Time
+	EndTime   time.Time
+	Now       time.Time
+	DeviceID  int32
+}
+
+// UpdateDeviceGroupOpts moves the group to the root when ParentID points to
+// zero.
+type UpdateDeviceGroupOpts struct {
+	ID        int32
+	Name      *string
+	GroupType *string
+	ParentID  *int32
+}
+
+// UpdateDiscoveryJobOpts leaves nil fields as they are.
+type UpdateDiscoveryJobOpts struct {
+	ID              int32
+	Status          *string
+	HostsTotal      *int32
+	HostsScanned    *int32
+	CandidatesFound *int32
+	Error           *string
+	StartedAt       *time.Time
+	FinishedAt      *time.Time
+}
+
+type DiscoveredHost struct {
+	Address     string
+	OpenPorts   []int32
+	Healthcheck bool
+	SeenAt      time.Time
+}
+
+// ReadDiscoveryCandidatesOpts limits the candidates to JobID and Status
+// unless they are zero.
+type ReadDiscoveryCandidatesOpts struct {
+	JobID  int32
+	Status string
+}
+
+type PromoteDiscoveryCandidateOpts struct {
+	ID          int32
+	Name        string
+	DeviceType  string
+	Responsible []int32
+}
+
+// SetReportSubscriptionRunOpts clears the last error when Error is nil.
+type SetReportSubscriptionRunOpts struct {
+	ID     int32
+	RunAt  time.Time
+	Status string
+	Error  *string
+}
+
+// PeriodReportOpts groups the messages received, or sent with event time,
+// in [StartTime, EndTime) by the GroupBy dimensions. Groups with fewer than
+// MinMessages messages are left out. DeviceIDs and GroupID narrow the
+// devices unless they are empty or zero.
+type PeriodReportOpts struct {
+	StartTime          time.Time
+	EndTime            time.Time
+	TimeBasis          string
+	DeviceIDs          []int32
+	GroupID            int32
+	MinMessages        int64
+	CriticalSeverities []string
+	GroupBy            []string
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"monolith/internal/models"
+
+	"go.uber.org/zap"
+)
+
+const defaultAlertsQueueSize = 256
+
+type alert struct {
+	deviceID int32
+	subject  string
+	text     string
+	// upstreamDeviceID is set when the alert is suppressed; it is recorded
+	// instead of notified.
+	upstreamDeviceID int32
+}
+
+type alertKey struct {
+	deviceID int32
+	subject  string
+}
+
+func (ms *MessagesService) initAlerts(cfg MessagesServiceConfig) {
+	ms.notifier = cfg.Notifier
+	ms.upstream = cfg.Upstream
+	ms.notificationPeriod = cfg.NotificationPeriod
+	ms.alerts = make(chan alert, defaultAlertsQueueSize)
+	ms.alertsDone = make(chan struct{})
+	ms.sent = map[alertKey]models.SendedNotification{}
+
+	go ms.runAlerts()
+}
+
+// suppress reports whether an alert of the device is not to be notified
+// because a device it depends on is down, and which device that is.
+func (ms *MessagesService) suppress(deviceID int32) (int32, bool) {
+	if ms.upstream == nil {
+		return 0, false
+	}
+
+	upstream, ok := ms.upstream.DownUpstream(deviceID)
+	if ok {
+		ms.log.Info(SuppressedUpstreamDown,
+			zap.Int32("device_id", deviceID),
+			zap.Int32("upstream_device_id", upstream),
+		)
+	}
+
+	return upstream, ok
+}
+
+// alert hands the tag alerts of stored messages over to the notifier, and
+// the suppressed ones over to be recorded. It never blocks the ingest: when
+// the queue is full the alert is dropped.
+func (ms *MessagesService) alert(responses ...handleMessageResponse) {
+	ms.alertsMutex.RLock()
+	defer ms.alertsMutex.RUnlock()
+
+	if ms.alertsClosed || ms.notifier == nil {
+		return
+	}
+
+	for _, resp := range responses {
+		if !resp.NeedNotify && !resp.Suppressed {
+			continue
+		}
+
+		select {
+		case ms.alerts <- alert{
+			deviceID:         resp.Message.DeviceId,
+			subject:          resp.Subject,
+			text:             resp.Text,
+			upstreamDeviceID: resp.UpstreamDeviceID,
+		}:
+		default:
+			ms.log.Warn("alerts queue is full", zap.Int32("device_id", resp.Message.DeviceId))
+		}
+	}
+}
+
+// runAlerts notifies a subject of a device at most once per notification
+// period. Suppressed alerts are all recorded, so operators can see what
+// was held back, and do not count as notified.
+func (ms *MessagesService) runAlerts() {
+	defer close(ms.alertsDone)
+
+	for a := range ms.alerts {
+		now := time.Now()
+		if a.upstreamDeviceID != 0 {
+			ms.recordSuppressed(a, now)
+			continue
+		}
+
+		key := alertKey{deviceID: a.deviceID, subject: a.subject}
+		if sent, ok := ms.sent[key]; ok && now.Before(sent.ExpiredAt) {
+			continue
+		}
+		ms.sent[key] = models.SendedNotification{
+			Message:   a.text,
+			DeviceId:  a.deviceID,
+			ExpiredAt: now.Add(ms.notificationPeriod),
+		}
+		for k, sent := range ms.sent {
+			if !now.Before(sent.ExpiredAt) {
+				delete(ms.sent, k)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		if err := ms.notifier.Notify(ctx, a.deviceID, a.subject, a.text); err != nil {
+			ms.log.Error("ms.notifier.Notify", zap.Error(err), zap.Int32("device_id", a.deviceID))
+		}
+		cancel()
+	}
+}
+
+func (ms *MessagesService) recordSuppressed(a alert, now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	tx, err := ms.messageRepo.BeginTx(ctx)
+	if err != nil {
+		ms.log.Error("tx.BeginTx", zap.Error(err))
+		return
+	}
+	defer tx.Rollback()
+
+	err = tx.AddSuppressedAlert(ctx, models.SuppressedAlert{
+		DeviceID:         a.deviceID,
+		UpstreamDeviceID: a.upstreamDeviceID,
+		Subject:          a.subject,
+		Message:          a.text,
+		SuppressedAt:     now,
+	})
+	if err != nil {
+		ms.log.Error("tx.AddSuppressedAlert", zap.Error(err), zap.Int32("device_id", a.deviceID))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		ms.log.Error("tx.Commit", zap.Error(err))
+	}
+}
+
+// closeAlerts stops accepting alerts and waits until the queued ones are
+// notified.
+func (ms *MessagesService) closeAlerts(ctx context.Context) error {
+	ms.alertsMutex.Lock()
+	if !ms.alertsClosed {
+		ms.alertsClosed = true
+		close(ms.alerts)
+	}
+	ms.alertsMutex.Unlock()
+
+	select {
+	case <-ms.alertsDone:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("alerts drain: %w (%d alerts left)", ctx.Err(), len(ms.alerts))
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+)
+
+var ErrInvalidDeviceAddress = errors.New("invalid device address")
+
+type DeviceAddresses interface {
+	Add(ctx context.Context, params models.DeviceAddress) (models.DeviceAddress, error)
+	// Read returns the addresses of the device, or of all devices when
+	// deviceID is zero.
+	Read(ctx context.Context, deviceID int32) ([]models.DeviceAddress, error)
+	Delete(ctx context.Context, id int32) error
+}
+
+type DeviceAddressesService struct {
+	repo repo.DeviceAddresses
+}
+
+func NewDeviceAddressesService(r repo.DeviceAddresses) *DeviceAddressesService {
+	return &DeviceAddressesService{
+		repo: r,
+	}
+}
+
+// Add stores the value in canonical form, so the same address written
+// differently is still detected as taken.
+func (s *DeviceAddressesService) Add(ctx context.Context, params models.DeviceAddress) (models.DeviceAddress, error) {
+	value, err := normalizeDeviceAddress(params.AddressType, params.Value)
+	if err != nil {
+		return models.DeviceAddress{}, err
+	}
+	params.Value = value
+	params.CreatedAt = time.Now()
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return models.DeviceAddress{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Add(ctx, params)
+	if err != nil {
+		return models.DeviceAddress{}, fmt.Errorf("tx.Add: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.DeviceAddress{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+func (s *DeviceAddressesService) Read(ctx context.Context, deviceID int32) ([]models.DeviceAddress, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Read(ctx, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("tx.Read: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+func (s *DeviceAddressesService) Delete(ctx context.Context, id int32) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("tx.Delete: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeDeviceAddress unmaps IPv4-mapped IPv6 addresses, zeroes the host
+// bits of a range and lowercases a hostname.
+func normalizeDeviceAddress(addressType, value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	switch addressType {
+	case models.DeviceAddressTypeIP:
+		addr, err := netip.ParseAddr(value)
+		if err != nil || addr.Zone() != "" {
+			return "", fmt.Errorf("%w: %q is not an IP address", ErrInvalidDeviceAddress, value)
+		}
+		return addr.Unmap().String(), nil
+	case models.DeviceAddressTypeCIDR:
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q is not a CIDR range", ErrInvalidDeviceAddress, value)
+		}
+		return prefix.Masked().String(), nil
+	case models.DeviceAddressTypeHostname:
+		hostname := strings.ToLower(strings.TrimSuffix(value, "."))
+		if !validHostname(hostname) {
+			return "", fmt.Errorf("%w: %q is not a hostname", ErrInvalidDeviceAddress, value)
+		}
+		return hostname, nil
+	default:
+		return "", fmt.Errorf("%w: unknown type %q", ErrInvalidDeviceAddress, addressType)
+	}
+}
+
+// validHostname follows RFC 1123 and rejects IP literals, which belong to
+// the ip type.
+func validHostname(hostname string) bool {
+	if hostname == "" || len(hostname) > 253 {
+		return false
+	}
+	if _, err := netip.ParseAddr(hostname); err == nil {
+		return false
+	}
+
+	for _, label := range strings.Split(hostname, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+
+	"go.uber.org/zap"
+)
+
+const (
+	deviceTokenPrefix = "dvc_"
+	deviceTokenBytes  = 32
+)
+
+type DeviceCredentials interface {
+	// Issue fails with repo.ErrDeviceCredentialExists when the device
+	// already has an active token; Rotate replaces it instead.
+	Issue(ctx context.Context, deviceID int32) (IssueDeviceCredentialResult, error)
+	Rotate(ctx context.Context, deviceID int32) (IssueDeviceCredentialResult, error)
+	Revoke(ctx context.Context, deviceID int32) error
+	// Authenticate resolves an ingest token to the credential it belongs to.
+	Authenticate(token string) (models.DeviceCredential, bool)
+	UpdateCredentials()
+}
+
+type DeviceCredentialsService struct {
+	repo repo.DeviceCredentials
+
+	credentialsMutex sync.RWMutex
+	credentials      map[string]models.DeviceCredential
+
+	log *zap.Logger
+}
+
+func NewDeviceCredentialsService(r repo.DeviceCredentials, log *zap.Logger) *DeviceCredentialsService {
+	service := &DeviceCredentialsService{
+		repo:        r,
+		credentials: map[string]models.DeviceCredential{},
+		log:         log,
+	}
+
+	service.UpdateCredentials()
+	return service
+}
+
+type IssueDeviceCredentialResult struct {
+	DeviceID  int32
+	Token     string
+	CreatedAt time.Time
+}
+
+func (s *DeviceCredentialsService) Issue(ctx context.Context, deviceID int32) (IssueDeviceCredentialResult, error) {
+	return s.issue(ctx, deviceID, false)
+}
+
+func (s *DeviceCredentialsService) Rotate(ctx context.Context, deviceID int32) (IssueDeviceCredentialResult, error) {
+	return s.issue(ctx, deviceID, true)
+}
+
+// issue returns the plain token only once; just its hash is persisted.
+func (s *DeviceCredentialsService) issue(
+	ctx context.Context,
+	deviceID int32,
+	rotate bool,
+) (IssueDeviceCredentialResult, error) {
+	token, err := newDeviceToken()
+	if err != nil {
+		return IssueDeviceCredentialResult{}, fmt.Errorf("newDeviceToken: %w", err)
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return IssueDeviceCredentialResult{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Issue(ctx, repo.IssueDeviceCredentialOpts{
+		DeviceID:  deviceID,
+		TokenHash: hashDeviceToken(token),
+		Rotate:    rotate,
+	})
+	if err != nil {
+		return IssueDeviceCredentialResult{}, fmt.Errorf("tx.Issue: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return IssueDeviceCredentialResult{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.UpdateCredentials()
+
+	return IssueDeviceCredentialResult{
+		DeviceID:  ret.DeviceID,
+		Token:     token,
+		CreatedAt: ret.CreatedAt,
+	}, nil
+}
+
+func (s *DeviceCredentialsService) Revoke(ctx context.Context, deviceID int32) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.Revoke(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("tx.Revoke: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.UpdateCredentials()
+
+	return nil
+}
+
+func (s *DeviceCredentialsService) Authenticate(token string) (models.DeviceCredential, bool) {
+	if !strings.HasPrefix(token, deviceTokenPrefix) {
+		return models.DeviceCredential{}, false
+	}
+
+	s.credentialsMutex.RLock()
+	defer s.credentialsMutex.RUnlock()
+
+	credential, ok := s.credentials[string(hashDeviceToken(token))]
+	return credential, ok
+}
+
+// UpdateCredentials reloads the active credentials Authenticate looks the
+// tokens up in.
+func (s *DeviceCredentialsService) UpdateCredentials() {
+	tx, err := s.repo.BeginTx(context.Background())
+	if err != nil {
+		s.log.Error("tx.BeginTx", zap.Error(err))
+		return
+	}
+	defer tx.Rollback()
+
+	active, err := tx.ReadActive(context.Background())
+	if err != nil {
+		s.log.Error("tx.ReadActive", zap.Error(err))
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		s.log.Error("tx.Commit", zap.Error(err))
+		return
+	}
+
+	credentials := make(map[string]models.DeviceCredential, len(active))
+	for _, c := range active {
+		credentials[string(c.TokenHash)] = c
+	}
+
+	s.credentialsMutex.Lock()
+	s.credentials = credentials
+	s.credentialsMutex.Unlock()
+}
+
+func newDeviceToken() (string, error) {
+	b := make([]byte, deviceTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+
+	return deviceTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashDeviceToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+
+	"go.uber.org/zap"
+)
+
+// SuppressedUpstreamDown marks alerts that were not notified because a
+// device upstream was down at the time.
+const SuppressedUpstreamDown = "suppressed: upstream down"
+
+// UpstreamChecker tells whether alerts of a device are to be suppressed.
+type UpstreamChecker interface {
+	// DownUpstream returns the nearest down device the device depends on.
+	DownUpstream(deviceID int32) (int32, bool)
+}
+
+type DeviceDependencies interface {
+	UpstreamChecker
+
+	Add(ctx context.Context, deviceID, parentID int32) (models.DeviceDependency, error)
+	Remove(ctx context.Context, deviceID, parentID int32) error
+	Read(ctx context.Context) ([]models.DeviceDependency, error)
+	// Impact returns the devices that depend on the device, directly or not.
+	Impact(deviceID int32) ImpactNode
+	// SetStatus records the current status of a device.
+	SetStatus(deviceID int32, status string)
+	UpdateDependencies()
+}
+
+// DeviceDependenciesService keeps the dependency graph and the statuses of
+// the devices in memory, so alerts can be checked against it without a
+// query per message.
+type DeviceDependenciesService struct {
+	repo repo.DeviceDependencies
+
+	mutex    sync.RWMutex
+	parents  map[int32][]int32
+	children map[int32][]int32
+	statuses map[int32]string
+
+	log *zap.Logger
+}
+
+func NewDeviceDependenciesService(r repo.DeviceDependencies, log *zap.Logger) *DeviceDependenciesService {
+	service := &DeviceDependenciesService{
+		repo:     r,
+		parents:  map[int32][]int32{},
+		children: map[int32][]int32{},
+		statuses: map[int32]string{},
+		log:      log,
+	}
+
+	service.UpdateDependencies()
+	return service
+}
+
+// Add fails with repo.ErrDependencyCycle when the parent already depends on
+// the device.
+func (s *DeviceDependenciesService) Add(ctx context.Context, deviceID, parentID int32) (models.DeviceDependency, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return models.DeviceDependency{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Add(ctx, deviceID, parentID)
+	if err != nil {
+		return models.DeviceDependency{}, fmt.Errorf("tx.Add: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.DeviceDependency{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.UpdateDependencies()
+
+	return ret, nil
+}
+
+func (s *DeviceDependenciesService) Remove(ctx context.Context, deviceID, parentID int32) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.Remove(ctx, deviceID, parentID)
+	if err != nil {
+		return fmt.Errorf("tx.Remove: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.UpdateDependencies()
+
+	return nil
+}
+
+func (s *DeviceDependenciesService) Read(ctx context.Context) ([]models.DeviceDependency, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tx.Read: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+func (s *DeviceDependenciesService) UpdateDependencies() {
+	dependencies, err := s.Read(context.Background())
+	if err != nil {
+		s.log.Error("s.Read", zap.Error(err))
+		return
+	}
+
+	parents := make(map[int32][]int32)
+	children := make(map[int32][]int32)
+	for _, d := range dependencies {
+		parents[d.DeviceID] = append(parents[d.DeviceID], d.ParentID)
+		children[d.ParentID] = append(children[d.ParentID], d.DeviceID)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.parents = parents
+	s.children = children
+}
+
+func (s *DeviceDependenciesService) SetStatus(deviceID int32, status string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.statuses[deviceID] = status
+}
+
+// DownUpstream walks the ancestors breadth first, so the nearest down
+// device is returned when there are several.
+func (s *DeviceDependenciesService) DownUpstream(deviceID int32) (int32, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	visited := map[int32]struct{}{deviceID: {}}
+	queue := append([]int32(nil), s.parents[deviceID]...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[id]; ok {
+			continue
+		}
+		visited[id] = struct{}{}
+
+		if s.statuses[id] == models.DeviceStatusDown {
+			return id, true
+		}
+		queue = append(queue, s.parents[id]...)
+	}
+
+	return 0, false
+}
+
+type ImpactNode struct {
+	DeviceID int32  `json:"device_id"`
+	Status   string `json:"status"`
+	// Suppressed is set for a dependent device whose alerts are suppressed
+	// because of a down device upstream.
+	Suppressed bool         `json:"suppressed"`
+	Children   []ImpactNode `json:"children"`
+}
+
+// Impact builds the tree of the dependent devices. A device that depends
+// on the root through several paths appears under each of them.
+func (s *DeviceDependenciesService) Impact(deviceID int32) ImpactNode {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.impact(deviceID, map[int32]struct{}{}, false)
+}
+
+// impact guards against cycles through path, which the cache may briefly
+// hold while it is being refreshed.
+func (s *DeviceDependenciesService) impact(deviceID int32, path map[int32]struct{}, upstreamDown bool) ImpactNode {
+	status, ok := s.statuses[deviceID]
+	if !ok {
+		status = models.DeviceStatusUnknown
+	}
+
+	node := ImpactNode{
+		DeviceID:   deviceID,
+		Status:     status,
+		Suppressed: upstreamDown,
+		Children:   []ImpactNode{},
+	}
+
+	path[deviceID] = struct{}{}
+	defer delete(path, deviceID)
+
+	children := append([]int32(nil), s.children[deviceID]...)
+	sort.Slice(children, func(i, j int) bool { return children[i] < children[j] })
+	for _, child := range children {
+		if _, ok := path[child]; ok {
+			continue
+		}
+		node.Children = append(node.Children,
+			s.impact(child, path, upstreamDown || status == models.DeviceStatusDown))
+	}
+
+	return node
+}
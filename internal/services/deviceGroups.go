@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+)
+
+var ErrInvalidDeviceGroup = errors.New("invalid device group")
+
+type DeviceGroups interface {
+	Create(ctx context.Context, params models.DeviceGroup) (models.DeviceGroup, error)
+	Read(ctx context.Context) ([]models.DeviceGroup, error)
+	// Tree returns the root groups with their subgroups nested.
+	Tree(ctx context.Context) ([]DeviceGroupNode, error)
+	Update(ctx context.Context, params UpdateDeviceGroupParams) error
+	Delete(ctx context.Context, id int32) error
+	AddDevice(ctx context.Context, groupID, deviceID int32) error
+	RemoveDevice(ctx context.Context, groupID, deviceID int32) error
+}
+
+type DeviceGroupsService struct {
+	repo repo.DeviceGroups
+}
+
+func NewDeviceGroupsService(r repo.DeviceGroups) *DeviceGroupsService {
+	return &DeviceGroupsService{
+		repo: r,
+	}
+}
+
+// Create makes a plain group when no type is given. A ParentID pointing to
+// zero makes a root group.
+func (s *DeviceGroupsService) Create(ctx context.Context, params models.DeviceGroup) (models.DeviceGroup, error) {
+	if params.GroupType == "" {
+		params.GroupType = models.DeviceGroupTypeGroup
+	}
+	if err := validateDeviceGroupType(params.GroupType); err != nil {
+		return models.DeviceGroup{}, err
+	}
+	if params.ParentID != nil && *params.ParentID == 0 {
+		params.ParentID = nil
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return models.DeviceGroup{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Create(ctx, params)
+	if err != nil {
+		return models.DeviceGroup{}, fmt.Errorf("tx.Create: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.DeviceGroup{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+func (s *DeviceGroupsService) Read(ctx context.Context) ([]models.DeviceGroup, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tx.Read: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+type DeviceGroupNode struct {
+	models.DeviceGroup
+	Children []DeviceGroupNode
+}
+
+func (s *DeviceGroupsService) Tree(ctx context.Context) ([]DeviceGroupNode, error) {
+	groups, err := s.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.Read: %w", err)
+	}
+
+	children := make(map[int32][]models.DeviceGroup)
+	for _, g := range groups {
+		var parentID int32
+		if g.ParentID != nil {
+			parentID = *g.ParentID
+		}
+		children[parentID] = append(children[parentID], g)
+	}
+
+	var build func(parentID int32) []DeviceGroupNode
+	build = func(parentID int32) []DeviceGroupNode {
+		nodes := make([]DeviceGroupNode, 0, len(children[parentID]))
+		for _, g := range children[parentID] {
+			nodes = append(nodes, DeviceGroupNode{
+				DeviceGroup: g,
+				Children:    build(g.ID),
+			})
+		}
+		return nodes
+	}
+
+	return build(0), nil
+}
+
+type UpdateDeviceGroupParams struct {
+	ID        int32
+	Name      *string
+	GroupType *string
+	// ParentID pointing to zero moves the group to the root.
+	ParentID *int32
+}
+
+func (s *DeviceGroupsService) Update(ctx context.Context, params UpdateDeviceGroupParams) error {
+	if params.GroupType != nil {
+		if err := validateDeviceGroupType(*params.GroupType); err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.Update(ctx, repo.UpdateDeviceGroupOpts{
+		ID:        params.ID,
+		Name:      params.Name,
+		GroupType: params.GroupType,
+		ParentID:  params.ParentID,
+	})
+	if err != nil {
+		return fmt.Errorf("tx.Update: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DeviceGroupsService) Delete(ctx context.Context, id int32) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("tx.Delete: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DeviceGroupsService) AddDevice(ctx context.Context, groupID, deviceID int32) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.AddDevice(ctx, groupID, deviceID)
+	if err != nil {
+		return fmt.Errorf("tx.AddDevice: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DeviceGroupsService) RemoveDevice(ctx context.Context, groupID, deviceID int32) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.RemoveDevice(ctx, groupID, deviceID)
+	if err != nil {
+		return fmt.Errorf("tx.RemoveDevice: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func validateDeviceGroupType(groupType string) error {
+	switch groupType {
+	case models.DeviceGroupTypeSite, models.DeviceGroupTypeRoom,
+		models.DeviceGroupTypeRack, models.DeviceGroupTypeGroup:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidDeviceGroup, groupType)
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"monolith/internal/models"
+)
+
+type ImportDevicesResult struct {
+	// Conflicts maps the index of a device to the reasons it cannot be
+	// created.
+	Conflicts map[int][]string
+	// Created is empty on a dry run and when any device conflicts.
+	Created []models.Device
+}
+
+// Import creates all the devices or none of them. A device conflicts when
+// its name or address is taken by a stored device or by an earlier device
+// of the same import. On a dry run only the conflicts are reported.
+func (s *DeviceService) Import(ctx context.Context, devices []models.Device, dryRun bool) (ImportDevicesResult, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return ImportDevicesResult{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if !dryRun {
+		if err := tx.Lock(ctx); err != nil {
+			return ImportDevicesResult{}, fmt.Errorf("tx.Lock: %w", err)
+		}
+	}
+
+	names := make([]string, 0, len(devices))
+	addresses := make([]string, 0, len(devices))
+	for _, d := range devices {
+		names = append(names, d.Name)
+		addresses = append(addresses, d.Address)
+	}
+
+	existing, err := tx.ReadConflicts(ctx, names, addresses)
+	if err != nil {
+		return ImportDevicesResult{}, fmt.Errorf("tx.ReadConflicts: %w", err)
+	}
+
+	takenNames := make(map[string]int32, len(existing))
+	takenAddresses := make(map[string]int32, len(existing))
+	for _, d := range existing {
+		takenNames[d.Name] = d.ID
+		takenAddresses[normalizeAddress(d.Address)] = d.ID
+	}
+
+	result := ImportDevicesResult{
+		Conflicts: make(map[int][]string),
+		Created:   make([]models.Device, 0),
+	}
+	importedNames := make(map[string]struct{}, len(devices))
+	importedAddresses := make(map[string]struct{}, len(devices))
+	for i, d := range devices {
+		address := normalizeAddress(d.Address)
+
+		if id, ok := takenNames[d.Name]; ok {
+			result.Conflicts[i] = append(result.Conflicts[i], fmt.Sprintf("name %q is taken by device %d", d.Name, id))
+		} else if _, ok := importedNames[d.Name]; ok {
+			result.Conflicts[i] = append(result.Conflicts[i], fmt.Sprintf("name %q repeats in the import", d.Name))
+		}
+		if id, ok := takenAddresses[address]; ok {
+			result.Conflicts[i] = append(result.Conflicts[i], fmt.Sprintf("address %s is used by device %d", d.Address, id))
+		} else if _, ok := importedAddresses[address]; ok {
+			result.Conflicts[i] = append(result.Conflicts[i], fmt.Sprintf("address %s repeats in the import", d.Address))
+		}
+
+		importedNames[d.Name] = struct{}{}
+		importedAddresses[address] = struct{}{}
+	}
+
+	if dryRun || len(result.Conflicts) != 0 {
+		return result, nil
+	}
+
+	for _, d := range devices {
+		created, err := tx.Create(d)
+		if err != nil {
+			return ImportDevicesResult{}, fmt.Errorf("tx.Create: %w", err)
+		}
+		result.Created = append(result.Created, created)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ImportDevicesResult{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return result, nil
+}
+
+// normalizeAddress makes equal addresses compare equal whatever their
+// spelling, e.g. the /32 postgres may add to a host address.
+func normalizeAddress(address string) string {
+	if ip, _, err := net.ParseCIDR(address); err == nil {
+		return ip.String()
+	}
+	if ip := net.ParseIP(address); ip != nil {
+		return ip.String()
+	}
+
+	return address
+}
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultFailureThreshold  = 3
+	defaultRecoveryThreshold = 2
+
+	deviceStatusComponent = "Availability"
+	notifyTimeout         = 30 * time.Second
+)
+
+type DeviceStatus interface {
+	// Observe feeds the result of a probe run into the status of its device.
+	Observe(probe models.Probe, address string, probeErr error)
+	// Current returns the latest change of every device that has one.
+	Current(ctx context.Context) ([]models.DeviceStatusChange, error)
+	Availability(ctx context.Context, params AvailabilityParams) ([]models.AvailabilityReportRow, error)
+}
+
+// probeState counts consecutive results of a probe. A probe goes down after
+// failureThreshold failures in a row and back up after recoveryThreshold
+// successes in a row.
+type probeState struct {
+	deviceID  int32
+	status    string
+	failures  int
+	successes int
+	lastError string
+}
+
+// DeviceStatusService derives the status of a device from the states of its
+// probes: up when all of them are up, down when all are down and degraded
+// otherwise. Probes without enough results yet are not taken into account.
+// Every transition is stored, written as a message and notified; repeated
+// results of the same status are not. A failure of a device behind a down
+// device is stored as suppressed and not notified, and neither is its
+// recovery.
+type DeviceStatusService struct {
+	repo     repo.DeviceStatus
+	probes   Probes
+	messages Messages
+	notifier Notifier
+	topology DeviceDependencies
+
+	failureThreshold  int
+	recoveryThreshold int
+
+	mutex       sync.Mutex
+	probeStates map[int32]*probeState
+	current     map[int32]string
+	suppressed  map[int32]bool
+
+	log *zap.Logger
+}
+
+type DeviceStatusServiceConfig struct {
+	Repo     repo.DeviceStatus
+	Probes   Probes
+	Messages Messages
+	Notifier Notifier
+	Topology DeviceDependencies
+
+	FailureThreshold  int
+	RecoveryThreshold int
+
+	Log *zap.Logger
+}
+
+func NewDeviceStatusService(cfg DeviceStatusServiceConfig) (*DeviceStatusService, error) {
+	service := &DeviceStatusService{
+		repo:              cfg.Repo,
+		probes:            cfg.Probes,
+		messages:          cfg.Messages,
+		notifier:          cfg.Notifier,
+		topology:          cfg.Topology,
+		failureThreshold:  cfg.FailureThreshold,
+		recoveryThreshold: cfg.RecoveryThreshold,
+		probeStates:       map[int32]*probeState{},
+		current:           map[int32]string{},
+		suppressed:        map[int32]bool{},
+		log:               cfg.Log,
+	}
+	if service.failureThreshold <= 0 {
+		service.failureThreshold = defaultFailureThreshold
+	}
+	if service.recoveryThreshold <= 0 {
+		service.recoveryThreshold = defaultRecoveryThreshold
+	}
+
+	// The status from before a restart is kept, so a device that stayed
+	// down is neither notified again nor recovers on its first success.
+	current, err := service.Current(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("service.Current: %w", err)
+	}
+	for _, c := range current {
+		service.current[c.DeviceID] = c.Status
+		service.suppressed[c.DeviceID] = c.Suppressed
+		service.topology.SetStatus(c.DeviceID, c.Status)
+	}
+
+	return service, nil
+}
+
+func (s *DeviceStatusService) Observe(probe models.Probe, address string, probeErr error) {
+	change, ok := s.observe(probe, probeErr)
+	if !ok {
+		return
+	}
+
+	s.log.Info("device status changed",
+		zap.Int32("device_id", change.DeviceID),
+		zap.String("status", change.Status),
+		zap.String("previous_status", change.PreviousStatus),
+		zap.Bool("suppressed", change.Suppressed),
+	)
+
+	if err := s.addChange(change); err != nil {
+		s.log.Error("s.addChange", zap.Error(err), zap.Int32("device_id", change.DeviceID))
+	}
+
+	text := fmt.Sprintf("device %s is %s, was %s", address, change.Status, change.PreviousStatus)
+	if change.Reason != "" {
+		text += ": " + change.Reason
+	}
+	if change.Suppressed {
+		text = SuppressedUpstreamDown + ": " + text
+	}
+
+	err := s.messages.Enqueue(models.Message{
+		DeviceId:    change.DeviceID,
+		DeviceIP:    address,
+		Message:     text,
+		MessageType: deviceStatusMessageType(change.Status),
+		Component:   deviceStatusComponent,
+		GotAt:       change.ChangedAt,
+	})
+	if err != nil {
+		s.log.Error("s.messages.Enqueue", zap.Error(err), zap.Int32("device_id", change.DeviceID))
+	}
+
+	if change.Suppressed {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	subject := fmt.Sprintf("Device %s is %s", address, change.Status)
+	if err := s.notifier.Notify(ctx, change.DeviceID, subject, text); err != nil {
+		s.log.Error("s.notifier.Notify", zap.Error(err), zap.Int32("device_id", change.DeviceID))
+	}
+}
+
+// observe updates the probe and returns the change of its device, if any.
+// The change time is taken under the lock, so history stays ordered even
+// when changes are stored out of order.
+func (s *DeviceStatusService) observe(probe models.Probe, probeErr error) (models.DeviceStatusChange, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, ok := s.current[probe.DeviceID]
+	if !ok {
+		previous = models.DeviceStatusUnknown
+	}
+
+	state, ok := s.probeStates[probe.ID]
+	if !ok || state.deviceID != probe.DeviceID {
+		state = &probeState{
+			deviceID: probe.DeviceID,
+			status:   models.DeviceStatusUnknown,
+		}
+		if previous == models.DeviceStatusUp || previous == models.DeviceStatusDown {
+			state.status = previous
+		}
+		s.probeStates[probe.ID] = state
+	}
+
+	s.updateProbeState(state, probe, probeErr)
+
+	status, reason := s.deviceStatus(probe.DeviceID)
+	if status == models.DeviceStatusUnknown || status == previous {
+		return models.DeviceStatusChange{}, false
+	}
+
+	s.current[probe.DeviceID] = status
+	s.topology.SetStatus(probe.DeviceID, status)
+
+	// A recovery is suppressed along with the failure it ends, otherwise
+	// responsibles would get an up without the down before it.
+	suppressed := status == models.DeviceStatusUp && s.suppressed[probe.DeviceID]
+	if status != models.DeviceStatusUp {
+		if upstream, ok := s.topology.DownUpstream(probe.DeviceID); ok {
+			suppressed = true
+			reason += fmt.Sprintf("; upstream device %d is down", upstream)
+		}
+	}
+	s.suppressed[probe.DeviceID] = suppressed
+
+	return models.DeviceStatusChange{
+		DeviceID:       probe.DeviceID,
+		Status:         status,
+		PreviousStatus: previous,
+		Reason:         reason,
+		ChangedAt:      time.Now(),
+		Suppressed:     suppressed,
+	}, true
+}
+
+func (s *DeviceStatusService) updateProbeState(state *probeState, probe models.Probe, probeErr error) {
+	if probeErr != nil {
+		state.failures++
+		state.successes = 0
+		state.lastError = fmt.Sprintf("%s probe %d: %v", probe.ProbeType, probe.ID, probeErr)
+		if state.failures >= s.failureThreshold {
+			state.status = models.DeviceStatusDown
+		}
+		return
+	}
+
+	state.successes++
+	state.failures = 0
+	state.lastError = ""
+	// A probe without history is up on its first success; the recovery
+	// threshold only guards against flapping back from down.
+	if state.status == models.DeviceStatusUnknown || state.successes >= s.recoveryThreshold {
+		state.status = models.DeviceStatusUp
+	}
+}
+
+// deviceStatus aggregates the enabled probes of the device. States of
+// probes that were deleted or disabled are dropped on the way.
+func (s *DeviceStatusService) deviceStatus(deviceID int32) (string, string) {
+	enabled := map[int32]struct{}{}
+	for _, p := range s.probes.Enabled() {
+		if p.DeviceID == deviceID {
+			enabled[p.ID] = struct{}{}
+		}
+	}
+
+	var (
+		up, down int
+		reasons  []string
+	)
+	for id, state := range s.probeStates {
+		if state.deviceID != deviceID {
+			continue
+		}
+		if _, ok := enabled[id]; !ok {
+			delete(s.probeStates, id)
+			continue
+		}
+
+		switch state.status {
+		case models.DeviceStatusUp:
+			up++
+		case models.DeviceStatusDown:
+			down++
+			reasons = append(reasons, state.lastError)
+		}
+	}
+	sort.Strings(reasons)
+
+	switch {
+	case up > 0 && down > 0:
+		return models.DeviceStatusDegraded, strings.Join(reasons, "; ")
+	case down > 0:
+		return models.DeviceStatusDown, strings.Join(reasons, "; ")
+	case up > 0:
+		return models.DeviceStatusUp, ""
+	default:
+		return models.DeviceStatusUnknown, ""
+	}
+}
+
+func (s *DeviceStatusService) addChange(change models.DeviceStatusChange) error {
+	tx, err := s.repo.BeginTx(context.Background())
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := tx.AddChange(context.Background(), change); err != nil {
+		return fmt.Errorf("tx.AddChange: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DeviceStatusService) Current(ctx context.Context) ([]models.DeviceStatusChange, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.ReadCurrent(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tx.ReadCurrent: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+type AvailabilityParams struct {
+	StartTime time.Time
+	EndTime   time.Time
+	// DeviceID limits the report to one device unless it is zero.
+	DeviceID int32
+}
+
+func (s *DeviceStatusService) Availability(
+	ctx context.Context,
+	params AvailabilityParams,
+) ([]models.AvailabilityReportRow, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Availability(ctx, repo.AvailabilityReportOpts{
+		StartTime: params.StartTime,
+		EndTime:   params.EndTime,
+		Now:       time.Now(),
+		DeviceID:  params.DeviceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("tx.Availability: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	for i := range rows {
+		if rows[i].MonitoredSec > 0 {
+			uptime := (rows[i].UpSec + rows[i].DegradedSec) / rows[i].MonitoredSec * 100 //nolint:mnd
+			rows[i].UptimePercent = &uptime
+		}
+	}
+
+	return rows, nil
+}
+
+func deviceStatusMessageType(status string) string {
+	switch status {
+	case models.DeviceStatusDown:
+		return "error"
+	case models.DeviceStatusDegraded:
+		return "warning"
+	default:
+		return "info"
+	}
+}
@@ -11,9 +11,11 @@ import (
 type Devices interface {
 	Create(ctx context.Context, params models.Device) (models.Device, error)
 	Read(ctx context.Context) (ReadDevicesResult, error)
+	ReadFiltered(ctx context.Context, params ReadDevicesParams) (ReadDevicesResult, error)
 	Update(ctx context.Context, params UpdateDeviceParams) error
 	Delete(ctx context.Context, deviceID int32) error
 	GetResponsible(ctx context.Context, deviceID int32) ([]int32, error)
+	Import(ctx context.Context, devices []models.Device, dryRun bool) (ImportDevicesResult, error)
 }
 
 type DeviceService struct {
@@ -74,6 +76,54 @@ func (s *DeviceService) Read(ctx context.Context) (ReadDevicesResult, error) {
 	}, nil
 }
 
+type (
+	// ReadDevicesParams limits the devices to the members of GroupID and
+	// its subgroups unless GroupID is zero. Labels and Attributes are
+	// selectors in the syntax of ParseSelector.
+	ReadDevicesParams struct {
+		GroupID    int32
+		Labels     string
+		Attributes string
+	}
+)
+
+// ReadFiltered fails with ErrInvalidSelector when a selector does not
+// parse.
+func (s *DeviceService) ReadFiltered(ctx context.Context, params ReadDevicesParams) (ReadDevicesResult, error) {
+	labels, err := ParseSelector(params.Labels)
+	if err != nil {
+		return ReadDevicesResult{}, fmt.Errorf("labels: %w", err)
+	}
+	attributes, err := ParseSelector(params.Attributes)
+	if err != nil {
+		return ReadDevicesResult{}, fmt.Errorf("attributes: %w", err)
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return ReadDevicesResult{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.ReadFiltered(ctx, repo.ReadDevicesOpts{
+		GroupID:    params.GroupID,
+		Labels:     labels,
+		Attributes: attributes,
+	})
+	if err != nil {
+		return ReadDevicesResult{}, fmt.Errorf("tx.ReadFiltered: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ReadDevicesResult{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ReadDevicesResult{
+		Devices: ret.Devices,
+	}, nil
+}
+
 type (
 	UpdateDeviceParams struct {
 		ID          int32
@@ -81,6 +131,9 @@ type (
 		DeviceType  *string
 		Address     *string
 		Responsible []int32
+		// Attributes and Labels replace the stored maps unless nil.
+		Attributes map[string]string
+		Labels     map[string]string
 	}
 )
 
@@ -97,6 +150,8 @@ func (s *DeviceService) Update(ctx context.Context, params UpdateDeviceParams) e
 		DeviceType:  params.DeviceType,
 		Address:     params.Address,
 		Responsible: params.Responsible,
+		Attributes:  params.Attributes,
+		Labels:      params.Labels,
 	})
 	if err != nil {
 		return fmt.Errorf("s.repo.Update: %w", err)
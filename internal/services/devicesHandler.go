@@ -2,69 +2,352 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"monolith/internal/models"
 	"monolith/internal/repo"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
 	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultResolveInterval = 5 * time.Minute
+	resolveTimeout         = 2 * time.Second
+	resolveWorkers         = 16
 )
 
 type DevicesHandler interface {
 	UpdateDevices()
+	// GetDeviceIDByIp returns the device an address belongs to. An IP is
+	// matched against the exact addresses first and then against the CIDR
+	// ranges, the longest prefix winning. Any other value is looked up as
+	// a hostname.
 	GetDeviceIDByIp(address string) (int32, bool)
-	GetDevicesIPs() []string
 }
 
 type DeviceHandler struct {
-	repo repo.Devices
+	repo      repo.Devices
+	addresses repo.DeviceAddresses
+	resolver  *net.Resolver
+
+	// updateMutex serialises the rebuilds of the table, so a slow rebuild
+	// cannot replace the result of a later one. It also guards hostnames
+	// and resolved.
+	updateMutex sync.Mutex
+	// hostnames are the hostname addresses of the last rebuild and resolved
+	// their addresses as of the last lookup.
+	hostnames map[string]int32
+	resolved  map[string][]netip.Addr
+
+	resolveInterval time.Duration
+	// resolveNow asks run for a lookup before the next tick, when a
+	// rebuild finds a hostname that was not looked up yet.
+	resolveNow chan struct{}
+	stop       chan struct{}
+	done       chan struct{}
+
+	log *zap.Logger
+}
+
+type DeviceHandlerConfig struct {
+	Devices   repo.Devices
+	Addresses repo.DeviceAddresses
+
+	// ResolveInterval is how often the hostnames of devices are resolved
+	// again.
+	ResolveInterval time.Duration
+
+	Log *zap.Logger
 }
 
-func NewDeviceHandler(devices repo.Devices) *DeviceHandler {
+func NewDeviceHandler(cfg DeviceHandlerConfig) *DeviceHandler {
+	resolveInterval := cfg.ResolveInterval
+	if resolveInterval <= 0 {
+		resolveInterval = defaultResolveInterval
+	}
+
 	deviceHandler := DeviceHandler{
-		repo: devices,
+		repo:            cfg.Devices,
+		addresses:       cfg.Addresses,
+		resolver:        net.DefaultResolver,
+		resolved:        map[string][]netip.Addr{},
+		resolveInterval: resolveInterval,
+		resolveNow:      make(chan struct{}, 1),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+		log:             cfg.Log,
 	}
 
 	deviceHandler.UpdateDevices()
+	go deviceHandler.run()
+
 	return &deviceHandler
 }
 
+// addressTable maps addresses to device ids. Prefix lengths are kept in
+// descending order so the first match is the longest one.
+type addressTable struct {
+	byIP       map[netip.Addr]int32
+	byPrefix   map[int]map[netip.Prefix]int32
+	prefixBits []int
+	byHostname map[string]int32
+}
+
 var (
-	devicesMutex sync.Mutex
-	devicesByIp  = map[string]models.Device{}
+	devicesMutex    sync.RWMutex
+	deviceAddresses = addressTable{}
 )
 
+// UpdateDevices rebuilds the lookup table from the primary addresses of the
+// devices and from their extra addresses. A primary address wins over an
+// extra one of another device. Hostnames get the addresses of their last
+// lookup; DNS is only queried by run, so a change of the devices never
+// waits for it.
 func (ds *DeviceHandler) UpdateDevices() {
-	tx, err := ds.repo.BeginTx(context.Background())
+	ds.updateMutex.Lock()
+	defer ds.updateMutex.Unlock()
+
+	ds.rebuild()
+}
+
+// rebuild must be called with updateMutex held.
+func (ds *DeviceHandler) rebuild() {
+	ctx := context.Background()
+
+	tx, err := ds.repo.BeginTx(ctx)
 	if err != nil {
+		ds.log.Error("ds.repo.BeginTx", zap.Error(err))
 		return
 	}
 	defer tx.Rollback()
 
-	devices, err := tx.Read(context.Background())
+	devices, err := tx.Read(ctx)
 	if err != nil {
+		ds.log.Error("tx.Read", zap.Error(err))
 		return
 	}
 	if err := tx.Commit(); err != nil {
+		ds.log.Error("tx.Commit", zap.Error(err))
 		return
 	}
 
-	devicesMutex.Lock()
-	defer devicesMutex.Unlock()
+	addressesTx, err := ds.addresses.BeginTx(ctx)
+	if err != nil {
+		ds.log.Error("ds.addresses.BeginTx", zap.Error(err))
+		return
+	}
+	defer addressesTx.Rollback()
+
+	addresses, err := addressesTx.Read(ctx, 0)
+	if err != nil {
+		ds.log.Error("addressesTx.Read", zap.Error(err))
+		return
+	}
+	if err := addressesTx.Commit(); err != nil {
+		ds.log.Error("addressesTx.Commit", zap.Error(err))
+		return
+	}
+
+	table := addressTable{
+		byIP:       make(map[netip.Addr]int32, len(devices.Devices)+len(addresses)),
+		byPrefix:   map[int]map[netip.Prefix]int32{},
+		byHostname: map[string]int32{},
+	}
+
+	hostnames := make(map[string]int32)
+	unresolved := false
+	for _, a := range addresses {
+		if a.AddressType == models.DeviceAddressTypeHostname {
+			table.byHostname[a.Value] = a.DeviceID
+			hostnames[a.Value] = a.DeviceID
+			if _, ok := ds.resolved[a.Value]; !ok {
+				unresolved = true
+			}
+			continue
+		}
+		table.addAddress(a.Value, a.DeviceID)
+	}
+
+	// Resolved hostnames yield to the addresses written down explicitly.
+	for hostname, deviceID := range hostnames {
+		for _, ip := range ds.resolved[hostname] {
+			if _, ok := table.byIP[ip]; !ok {
+				table.byIP[ip] = deviceID
+			}
+		}
+	}
 
-	devicesByIp = make(map[string]models.Device, len(devices.Devices))
 	for _, d := range devices.Devices {
-		devicesByIp[d.Address] = d
+		table.addAddress(d.Address, d.ID)
+	}
+
+	for bits := range table.byPrefix {
+		table.prefixBits = append(table.prefixBits, bits)
 	}
+	slices.Sort(table.prefixBits)
+	slices.Reverse(table.prefixBits)
+
+	ds.hostnames = hostnames
+
+	devicesMutex.Lock()
+	deviceAddresses = table
+	devicesMutex.Unlock()
+
+	if unresolved {
+		select {
+		case ds.resolveNow <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// refresh looks the hostnames up and rebuilds the table with the result.
+// The lookups run without updateMutex, so UpdateDevices does not wait for
+// them.
+func (ds *DeviceHandler) refresh() {
+	ds.updateMutex.Lock()
+	hostnames := ds.hostnames
+	ds.updateMutex.Unlock()
+
+	resolved := ds.resolve(hostnames)
+	// A hostname that did not resolve is kept as looked up, so it does not
+	// ask for another lookup before the next tick.
+	for hostname := range hostnames {
+		if _, ok := resolved[hostname]; !ok {
+			resolved[hostname] = nil
+		}
+	}
+
+	ds.updateMutex.Lock()
+	defer ds.updateMutex.Unlock()
+
+	ds.resolved = resolved
+	ds.rebuild()
+}
+
+// addAddress accepts an address with or without a prefix length; a prefix
+// covering a single address is stored as that address.
+func (t *addressTable) addAddress(address string, deviceID int32) {
+	if prefix, err := netip.ParsePrefix(address); err == nil {
+		if prefix.Addr().Is4In6() {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), max(prefix.Bits()-96, 0))
+		}
+		prefix = prefix.Masked()
+		if prefix.IsSingleIP() {
+			t.byIP[prefix.Addr()] = deviceID
+			return
+		}
+		if t.byPrefix[prefix.Bits()] == nil {
+			t.byPrefix[prefix.Bits()] = map[netip.Prefix]int32{}
+		}
+		t.byPrefix[prefix.Bits()][prefix] = deviceID
+		return
+	}
+
+	if addr, err := netip.ParseAddr(address); err == nil {
+		t.byIP[addr.Unmap()] = deviceID
+	}
+}
+
+// resolve looks the hostnames up concurrently. A hostname that does not
+// resolve is left out, it is still matched by name.
+func (ds *DeviceHandler) resolve(hostnames map[string]int32) map[string][]netip.Addr {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		resolved = make(map[string][]netip.Addr, len(hostnames))
+		sem      = make(chan struct{}, resolveWorkers)
+	)
+
+	for hostname := range hostnames {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+			defer cancel()
+
+			ips, err := ds.resolver.LookupNetIP(ctx, "ip", hostname)
+			if err != nil {
+				ds.log.Debug("ds.resolver.LookupNetIP", zap.Error(err), zap.String("hostname", hostname))
+				return
+			}
+			for i := range ips {
+				ips[i] = ips[i].Unmap()
+			}
+
+			mu.Lock()
+			resolved[hostname] = ips
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return resolved
 }
 
 func (ds *DeviceHandler) GetDeviceIDByIp(address string) (int32, bool) {
-	device, ok := devicesByIp[address]
-	return device.ID, ok
+	devicesMutex.RLock()
+	defer devicesMutex.RUnlock()
+
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		id, ok := deviceAddresses.byHostname[strings.ToLower(strings.TrimSuffix(address, "."))]
+		return id, ok
+	}
+	addr = addr.WithZone("").Unmap()
+
+	if id, ok := deviceAddresses.byIP[addr]; ok {
+		return id, true
+	}
+
+	for _, bits := range deviceAddresses.prefixBits {
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		if id, ok := deviceAddresses.byPrefix[bits][prefix]; ok {
+			return id, true
+		}
+	}
+
+	return 0, false
 }
 
-func (ds *DeviceHandler) GetDevicesIPs() []string {
-	res := make([]string, 0, len(devicesByIp))
-	for ip := range devicesByIp {
-		res = append(res, ip)
+// run looks the hostnames up every resolveInterval, and as soon as a new one
+// shows up, so the devices follow changes of their DNS records.
+func (ds *DeviceHandler) run() {
+	defer close(ds.done)
+
+	ticker := time.NewTicker(ds.resolveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ds.stop:
+			return
+		case <-ticker.C:
+			ds.refresh()
+		case <-ds.resolveNow:
+			ds.refresh()
+		}
 	}
+}
 
-	return res
+func (ds *DeviceHandler) Close(ctx context.Context) error {
+	close(ds.stop)
+
+	select {
+	case <-ds.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("device handler: %w", ctx.Err())
+	}
 }
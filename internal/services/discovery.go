@@ -0,0 +1,608 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidDiscoveryJob = errors.New("invalid discovery job")
+
+const (
+	defaultDiscoveryWorkers  = 32
+	defaultDiscoveryMaxHosts = 4096
+
+	// discoveryFlushInterval is how often a running job stores the hosts
+	// found so far and its progress.
+	discoveryFlushInterval = 5 * time.Second
+
+	maxPort = 65535
+)
+
+// HostScanner probes a single host for discovery.
+type HostScanner interface {
+	// Scan returns the ports that accept a TCP connection and, with
+	// healthcheck, whether the /healthcheck endpoint answers.
+	Scan(ctx context.Context, host string, ports []int32, healthcheck bool) ([]int32, bool)
+}
+
+type Discovery interface {
+	// CreateJob queues a scan; jobs run one at a time in the order they
+	// were created.
+	CreateJob(ctx context.Context, params CreateDiscoveryJobParams) (models.DiscoveryJob, error)
+	ReadJobs(ctx context.Context) ([]models.DiscoveryJob, error)
+	ReadCandidates(ctx context.Context, jobID int32, status string) ([]models.DiscoveryCandidate, error)
+	Promote(ctx context.Context, params PromoteDiscoveryCandidateParams) (models.Device, error)
+	Dismiss(ctx context.Context, id int32) (models.DiscoveryCandidate, error)
+	Close(ctx context.Context) error
+}
+
+// DiscoveryService runs the queued jobs. The queue is the pending jobs in
+// the table, so jobs survive a restart; a job interrupted by a shutdown is
+// put back to pending and scanned again from the start.
+type DiscoveryService struct {
+	repo          repo.Discovery
+	scanner       HostScanner
+	deviceHandler DevicesHandler
+	messages      Messages
+
+	workers  int
+	maxHosts int
+
+	wake   chan struct{}
+	stop   chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	log *zap.Logger
+}
+
+type DiscoveryServiceConfig struct {
+	Repo          repo.Discovery
+	Scanner       HostScanner
+	DeviceHandler DevicesHandler
+	Messages      Messages
+
+	// Workers bounds the number of hosts probed at the same time.
+	Workers int
+	// MaxHosts bounds the size of the range of a job.
+	MaxHosts int
+
+	Log *zap.Logger
+}
+
+func NewDiscoveryService(cfg DiscoveryServiceConfig) (*DiscoveryService, error) {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = defaultDiscoveryWorkers
+	}
+	maxHosts := cfg.MaxHosts
+	if maxHosts <= 0 {
+		maxHosts = defaultDiscoveryMaxHosts
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	service := &DiscoveryService{
+		repo:          cfg.Repo,
+		scanner:       cfg.Scanner,
+		deviceHandler: cfg.DeviceHandler,
+		messages:      cfg.Messages,
+		workers:       workers,
+		maxHosts:      maxHosts,
+		wake:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		cancel:        cancel,
+		done:          make(chan struct{}),
+		log:           cfg.Log,
+	}
+
+	jobs, err := service.ReadJobs(context.Background())
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("service.ReadJobs: %w", err)
+	}
+	for _, job := range jobs {
+		if job.Status != models.DiscoveryJobStatusRunning {
+			continue
+		}
+		if err := service.requeue(context.Background(), job.ID); err != nil {
+			cancel()
+			return nil, fmt.Errorf("service.requeue: %w", err)
+		}
+	}
+
+	go service.run(ctx)
+
+	return service, nil
+}
+
+type CreateDiscoveryJobParams struct {
+	CIDR        string
+	Ports       []int32
+	Healthcheck bool
+}
+
+// CreateJob fails with ErrInvalidDiscoveryJob for a malformed range, a
+// range of more than MaxHosts hosts, ports out of range or nothing to
+// probe.
+func (s *DiscoveryService) CreateJob(ctx context.Context, params CreateDiscoveryJobParams) (models.DiscoveryJob, error) {
+	prefix, err := netip.ParsePrefix(params.CIDR)
+	if err != nil {
+		return models.DiscoveryJob{}, fmt.Errorf("%w: %w", ErrInvalidDiscoveryJob, err)
+	}
+	prefix = prefix.Masked()
+
+	total, ok := discoveryHostsCount(prefix, s.maxHosts)
+	if !ok {
+		return models.DiscoveryJob{}, fmt.Errorf("%w: %s has more than %d hosts", ErrInvalidDiscoveryJob, prefix, s.maxHosts)
+	}
+
+	ports := slices.Clone(params.Ports)
+	slices.Sort(ports)
+	ports = slices.Compact(ports)
+	for _, port := range ports {
+		if port < 1 || port > maxPort {
+			return models.DiscoveryJob{}, fmt.Errorf("%w: port %d", ErrInvalidDiscoveryJob, port)
+		}
+	}
+	if len(ports) == 0 && !params.Healthcheck {
+		return models.DiscoveryJob{}, fmt.Errorf("%w: no ports and no healthcheck", ErrInvalidDiscoveryJob)
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return models.DiscoveryJob{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.CreateJob(ctx, models.DiscoveryJob{
+		CIDR:        prefix.String(),
+		Ports:       ports,
+		Healthcheck: params.Healthcheck,
+		HostsTotal:  total,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		return models.DiscoveryJob{}, fmt.Errorf("tx.CreateJob: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.DiscoveryJob{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return ret, nil
+}
+
+func (s *DiscoveryService) ReadJobs(ctx context.Context) ([]models.DiscoveryJob, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.ReadJobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tx.ReadJobs: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+// ReadCandidates lists the candidates of the job, or of all jobs when
+// jobID is zero, with the status unless it is empty.
+func (s *DiscoveryService) ReadCandidates(
+	ctx context.Context,
+	jobID int32,
+	status string,
+) ([]models.DiscoveryCandidate, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.ReadCandidates(ctx, repo.ReadDiscoveryCandidatesOpts{
+		JobID:  jobID,
+		Status: status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("tx.ReadCandidates: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+type PromoteDiscoveryCandidateParams struct {
+	ID          int32
+	Name        string
+	DeviceType  string
+	Responsible []int32
+}
+
+// Promote registers the candidate as a device.
+func (s *DiscoveryService) Promote(ctx context.Context, params PromoteDiscoveryCandidateParams) (models.Device, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return models.Device{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.PromoteCandidate(ctx, repo.PromoteDiscoveryCandidateOpts{
+		ID:          params.ID,
+		Name:        params.Name,
+		DeviceType:  params.DeviceType,
+		Responsible: params.Responsible,
+	})
+	if err != nil {
+		return models.Device{}, fmt.Errorf("tx.PromoteCandidate: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Device{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.deviceHandler.UpdateDevices()
+	s.messages.UpdateDevices()
+
+	return ret, nil
+}
+
+// Dismiss hides the candidate from later jobs as well.
+func (s *DiscoveryService) Dismiss(ctx context.Context, id int32) (models.DiscoveryCandidate, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return models.DiscoveryCandidate{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.DismissCandidate(ctx, id)
+	if err != nil {
+		return models.DiscoveryCandidate{}, fmt.Errorf("tx.DismissCandidate: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.DiscoveryCandidate{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+// Close interrupts the running job and waits for it to be put back to
+// pending.
+func (s *DiscoveryService) Close(ctx context.Context) error {
+	close(s.stop)
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("discovery stop: %w", ctx.Err())
+	}
+}
+
+func (s *DiscoveryService) run(ctx context.Context) {
+	defer close(s.done)
+
+	for {
+		job, ok, err := s.nextJob(ctx)
+		if err != nil {
+			s.log.Error("s.nextJob", zap.Error(err))
+		}
+
+		if ok {
+			s.runJob(ctx, job)
+			continue
+		}
+
+		select {
+		case <-s.wake:
+		case <-time.After(discoveryFlushInterval):
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// nextJob returns the oldest pending job.
+func (s *DiscoveryService) nextJob(ctx context.Context) (models.DiscoveryJob, bool, error) {
+	select {
+	case <-s.stop:
+		return models.DiscoveryJob{}, false, nil
+	default:
+	}
+
+	jobs, err := s.ReadJobs(ctx)
+	if err != nil {
+		return models.DiscoveryJob{}, false, err
+	}
+
+	for i := len(jobs) - 1; i >= 0; i-- {
+		if jobs[i].Status == models.DiscoveryJobStatusPending {
+			return jobs[i], true, nil
+		}
+	}
+
+	return models.DiscoveryJob{}, false, nil
+}
+
+type discoveryScan struct {
+	host repo.DiscoveredHost
+	// responsive is false for hosts that answered no probe.
+	responsive bool
+}
+
+func (s *DiscoveryService) runJob(ctx context.Context, job models.DiscoveryJob) {
+	log := s.log.With(zap.Int32("job_id", job.ID), zap.String("cidr", job.CIDR))
+
+	prefix, err := netip.ParsePrefix(job.CIDR)
+	if err != nil {
+		s.finish(job.ID, models.DiscoveryJobStatusFailed, fmt.Errorf("netip.ParsePrefix: %w", err), log)
+		return
+	}
+
+	now := time.Now()
+	err = s.updateJob(ctx, repo.UpdateDiscoveryJobOpts{
+		ID:        job.ID,
+		Status:    lo.ToPtr(models.DiscoveryJobStatusRunning),
+		StartedAt: &now,
+	})
+	if err != nil {
+		log.Error("s.updateJob", zap.Error(err))
+		return
+	}
+	log.Info("discovery job started")
+
+	hosts := make(chan netip.Addr)
+	results := make(chan discoveryScan)
+
+	var wg sync.WaitGroup
+	for range s.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for addr := range hosts {
+				results <- s.scan(ctx, addr, job)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(hosts)
+		for addr := range discoveryHosts(prefix) {
+			select {
+			case hosts <- addr:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	ticker := time.NewTicker(discoveryFlushInterval)
+	defer ticker.Stop()
+
+	var (
+		found      []repo.DiscoveredHost
+		scanned    int32
+		candidates int32
+	)
+	flush := func() error {
+		recorded, err := s.recordCandidates(ctx, job.ID, found)
+		if err != nil {
+			return err
+		}
+		found = found[:0]
+		candidates += int32(recorded)
+
+		return s.updateJob(ctx, repo.UpdateDiscoveryJobOpts{
+			ID:              job.ID,
+			HostsScanned:    &scanned,
+			CandidatesFound: &candidates,
+		})
+	}
+
+	var flushErr error
+	for results != nil {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			scanned++
+			if result.responsive {
+				found = append(found, result.host)
+			}
+		case <-ticker.C:
+			if flushErr == nil {
+				flushErr = flush()
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		if err := s.requeue(context.Background(), job.ID); err != nil {
+			log.Error("s.requeue", zap.Error(err))
+		}
+		log.Info("discovery job interrupted")
+		return
+	}
+
+	if flushErr == nil {
+		flushErr = flush()
+	}
+	if flushErr != nil {
+		s.finish(job.ID, models.DiscoveryJobStatusFailed, flushErr, log)
+		return
+	}
+
+	s.finish(job.ID, models.DiscoveryJobStatusDone, nil, log)
+	log.Info("discovery job done",
+		zap.Int32("hosts_scanned", scanned),
+		zap.Int32("candidates_found", candidates),
+	)
+}
+
+// scan skips the hosts that are devices already.
+func (s *DiscoveryService) scan(ctx context.Context, addr netip.Addr, job models.DiscoveryJob) discoveryScan {
+	host := addr.String()
+	if _, ok := s.deviceHandler.GetDeviceIDByIp(host); ok {
+		return discoveryScan{}
+	}
+
+	openPorts, healthy := s.scanner.Scan(ctx, host, job.Ports, job.Healthcheck)
+
+	return discoveryScan{
+		host: repo.DiscoveredHost{
+			Address:     host,
+			OpenPorts:   openPorts,
+			Healthcheck: healthy,
+			SeenAt:      time.Now(),
+		},
+		responsive: len(openPorts) != 0 || healthy,
+	}
+}
+
+func (s *DiscoveryService) finish(id int32, status string, jobErr error, log *zap.Logger) {
+	now := time.Now()
+	opts := repo.UpdateDiscoveryJobOpts{
+		ID:         id,
+		Status:     &status,
+		FinishedAt: &now,
+	}
+	if jobErr != nil {
+		opts.Error = lo.ToPtr(jobErr.Error())
+		log.Error("discovery job failed", zap.Error(jobErr))
+	}
+
+	if err := s.updateJob(context.Background(), opts); err != nil {
+		log.Error("s.updateJob", zap.Error(err))
+	}
+}
+
+func (s *DiscoveryService) requeue(ctx context.Context, id int32) error {
+	zero := int32(0)
+
+	return s.updateJob(ctx, repo.UpdateDiscoveryJobOpts{
+		ID:              id,
+		Status:          lo.ToPtr(models.DiscoveryJobStatusPending),
+		HostsScanned:    &zero,
+		CandidatesFound: &zero,
+	})
+}
+
+func (s *DiscoveryService) recordCandidates(ctx context.Context, jobID int32, hosts []repo.DiscoveredHost) (int64, error) {
+	if len(hosts) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.RecordCandidates(ctx, jobID, hosts)
+	if err != nil {
+		return 0, fmt.Errorf("tx.RecordCandidates: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+func (s *DiscoveryService) updateJob(ctx context.Context, opts repo.UpdateDiscoveryJobOpts) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.UpdateJob(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("tx.UpdateJob: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// discoveryHostsCount returns the number of hosts discoveryHosts yields
+// for the prefix, or false when it exceeds limit.
+func discoveryHostsCount(prefix netip.Prefix, limit int) (int32, bool) {
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits > 30 { //nolint:mnd
+		return 0, false
+	}
+
+	count := 1 << hostBits
+	if skipsNetworkAndBroadcast(prefix) {
+		count -= 2
+	}
+	if count > limit {
+		return 0, false
+	}
+
+	return int32(count), true
+}
+
+// discoveryHosts yields the addresses of the prefix. The network and
+// broadcast addresses of IPv4 ranges larger than /31 are left out.
+func discoveryHosts(prefix netip.Prefix) func(yield func(netip.Addr) bool) {
+	return func(yield func(netip.Addr) bool) {
+		skip := skipsNetworkAndBroadcast(prefix)
+		for addr := prefix.Addr(); addr.IsValid() && prefix.Contains(addr); addr = addr.Next() {
+			if skip && (addr == prefix.Addr() || !prefix.Contains(addr.Next())) {
+				continue
+			}
+			if !yield(addr) {
+				return
+			}
+		}
+	}
+}
+
+func skipsNetworkAndBroadcast(prefix netip.Prefix) bool {
+	return prefix.Addr().Is4() && prefix.Bits() < 31 //nolint:mnd
+}
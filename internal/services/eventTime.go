@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrTimestampSkew     = errors.New("timestamp is out of the allowed clock skew")
+	ErrInvalidSkewPolicy = errors.New("invalid timestamp skew policy")
+)
+
+// Policies for device timestamps that are further from the receive time
+// than the allowed skew.
+const (
+	SkewPolicyAccept = "accept"
+	SkewPolicyClamp  = "clamp"
+	SkewPolicyReject = "reject"
+)
+
+const dropReasonTimestampSkew = "timestamp_skew"
+
+type eventTimePolicy struct {
+	policy    string
+	maxFuture time.Duration
+	maxPast   time.Duration
+}
+
+// newEventTimePolicy checks the policy, so that a typo does not silently
+// reject every message out of the skew. An empty policy is SkewPolicyClamp,
+// the default of the configuration.
+func newEventTimePolicy(policy string, maxFuture, maxPast time.Duration) (eventTimePolicy, error) {
+	switch policy {
+	case "":
+		policy = SkewPolicyClamp
+	case SkewPolicyAccept, SkewPolicyClamp, SkewPolicyReject:
+	default:
+		return eventTimePolicy{}, fmt.Errorf("%w: %q, want %q, %q or %q",
+			ErrInvalidSkewPolicy, policy, SkewPolicyAccept, SkewPolicyClamp, SkewPolicyReject)
+	}
+
+	return eventTimePolicy{
+		policy:    policy,
+		maxFuture: maxFuture,
+		maxPast:   maxPast,
+	}, nil
+}
+
+// apply returns the event time to store for a message received at
+// receivedAt. Times are converted to local time, since the timestamp
+// columns store wall clock time just like got_at.
+func (p eventTimePolicy) apply(eventAt *time.Time, receivedAt time.Time) (*time.Time, error) {
+	if eventAt == nil || eventAt.IsZero() {
+		return nil, nil //nolint:nilnil
+	}
+
+	ts := eventAt.Local()
+	earliest := receivedAt.Add(-p.maxPast)
+	latest := receivedAt.Add(p.maxFuture)
+	if !ts.Before(earliest) && !ts.After(latest) {
+		return &ts, nil
+	}
+
+	switch p.policy {
+	case SkewPolicyAccept:
+		return &ts, nil
+	case SkewPolicyClamp:
+		if ts.Before(earliest) {
+			return &earliest, nil
+		}
+		return &latest, nil
+	default:
+		return nil, fmt.Errorf("%w: %s received at %s",
+			ErrTimestampSkew, ts.Format(time.RFC3339), receivedAt.Format(time.RFC3339))
+	}
+}
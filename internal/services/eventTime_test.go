@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewEventTimePolicy(t *testing.T) {
+	tests := []struct {
+		policy  string
+		want    string
+		wantErr error
+	}{
+		{policy: "", want: SkewPolicyClamp},
+		{policy: SkewPolicyAccept, want: SkewPolicyAccept},
+		{policy: SkewPolicyClamp, want: SkewPolicyClamp},
+		{policy: SkewPolicyReject, want: SkewPolicyReject},
+		{policy: "clip", wantErr: ErrInvalidSkewPolicy},
+		{policy: "Reject", wantErr: ErrInvalidSkewPolicy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			got, err := newEventTimePolicy(tt.policy, time.Minute, time.Hour)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("newEventTimePolicy(%q) error = %v, want %v", tt.policy, err, tt.wantErr)
+			}
+			if got.policy != tt.want {
+				t.Errorf("newEventTimePolicy(%q).policy = %q, want %q", tt.policy, got.policy, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventTimePolicyApply(t *testing.T) {
+	receivedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	inSkew := receivedAt.Add(-30 * time.Minute)
+	future := receivedAt.Add(time.Hour)
+	past := receivedAt.Add(-2 * time.Hour)
+
+	tests := []struct {
+		name    string
+		policy  string
+		eventAt *time.Time
+		want    *time.Time
+		wantErr error
+	}{
+		{name: "no timestamp", policy: SkewPolicyReject},
+		{name: "within skew", policy: SkewPolicyReject, eventAt: &inSkew, want: &inSkew},
+		{name: "accept", policy: SkewPolicyAccept, eventAt: &future, want: &future},
+		{name: "clamp future", policy: SkewPolicyClamp, eventAt: &future, want: ptr(receivedAt.Add(time.Minute))},
+		{name: "clamp past", policy: SkewPolicyClamp, eventAt: &past, want: ptr(receivedAt.Add(-time.Hour))},
+		{name: "reject", policy: SkewPolicyReject, eventAt: &past, wantErr: ErrTimestampSkew},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := newEventTimePolicy(tt.policy, time.Minute, time.Hour)
+			if err != nil {
+				t.Fatalf("newEventTimePolicy: %v", err)
+			}
+
+			got, err := policy.apply(tt.eventAt, receivedAt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("apply error = %v, want %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && !got.Equal(*tt.want) {
+				t.Errorf("apply = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
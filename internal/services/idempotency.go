@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+var ErrDuplicateMessage = errors.New("duplicate message")
+
+const (
+	dropReasonDuplicate = "duplicate"
+
+	defaultIdempotencyWindow = 24 * time.Hour
+	idempotencyPurgeSpec     = "@every 1m"
+)
+
+// recentIDs remembers message ids accepted within the deduplication window,
+// so most retries are answered without a database round trip. The
+// message_ids table stays the source of truth across restarts.
+type recentIDs struct {
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[repo.MessageIDKey]time.Time
+}
+
+func newRecentIDs(window time.Duration) *recentIDs {
+	return &recentIDs{
+		window: window,
+		seen:   make(map[repo.MessageIDKey]time.Time),
+	}
+}
+
+// add records the key and reports false if it was already seen within the
+// window.
+func (r *recentIDs) add(key repo.MessageIDKey, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if seenAt, ok := r.seen[key]; ok && now.Sub(seenAt) < r.window {
+		return false
+	}
+	r.seen[key] = now
+
+	return true
+}
+
+func (r *recentIDs) remove(key repo.MessageIDKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.seen, key)
+}
+
+func (r *recentIDs) purge(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, seenAt := range r.seen {
+		if now.Sub(seenAt) >= r.window {
+			delete(r.seen, key)
+		}
+	}
+}
+
+// messageIDKey scopes a message id to its device. Unknown senders share
+// device id 0, so they are told apart by their address.
+func messageIDKey(msg models.Message) repo.MessageIDKey {
+	sender := strconv.Itoa(int(msg.DeviceId))
+	if msg.DeviceId == unknownDeviceID {
+		sender = "ip:" + msg.DeviceIP
+	}
+
+	return repo.MessageIDKey{
+		Sender:    sender,
+		MessageID: msg.MessageID,
+	}
+}
+
+func (ms *MessagesService) initIdempotency(cfg MessagesServiceConfig) {
+	if cfg.IdempotencyWindow <= 0 {
+		cfg.IdempotencyWindow = defaultIdempotencyWindow
+	}
+
+	ms.idempotencyWindow = cfg.IdempotencyWindow
+	ms.recentIDs = newRecentIDs(cfg.IdempotencyWindow)
+
+	ms.cron = cron.New()
+	if _, err := ms.cron.AddFunc(idempotencyPurgeSpec, ms.purgeMessageIDs); err != nil {
+		ms.log.Error("ms.cron.AddFunc", zap.Error(err))
+	}
+	ms.cron.Start()
+}
+
+func (ms *MessagesService) purgeMessageIDs() {
+	now := time.Now()
+	ms.recentIDs.purge(now)
+
+	tx, err := ms.messageRepo.BeginTx(context.Background())
+	if err != nil {
+		ms.log.Error("tx.BeginTx", zap.Error(err))
+		return
+	}
+	defer tx.Rollback()
+
+	purged, err := tx.PurgeMessageIDs(context.Background(), now.Add(-ms.idempotencyWindow))
+	if err != nil {
+		ms.log.Error("tx.PurgeMessageIDs", zap.Error(err))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		ms.log.Error("tx.Commit", zap.Error(err))
+		return
+	}
+
+	ms.log.Debug("purged message ids", zap.Int64("count", purged))
+}
+
+// claimMessageIDs reports which messages repeat an id that was already
+// stored within the window. Messages without an id are never duplicates.
+func (ms *MessagesService) claimMessageIDs(tx repo.Messages, messages []models.Message) ([]bool, error) {
+	duplicates := make([]bool, len(messages))
+
+	keys := make([]repo.MessageIDKey, 0, len(messages))
+	for _, msg := range messages {
+		if msg.MessageID != "" {
+			keys = append(keys, messageIDKey(msg))
+		}
+	}
+	if len(keys) == 0 {
+		return duplicates, nil
+	}
+
+	now := time.Now()
+	claimed, err := tx.ClaimMessageIDs(context.Background(), repo.ClaimMessageIDsOpts{
+		Keys:       keys,
+		ReceivedAt: now,
+		Since:      now.Add(-ms.idempotencyWindow),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("tx.ClaimMessageIDs: %w", err)
+	}
+
+	unused := make(map[repo.MessageIDKey]struct{}, len(claimed))
+	for _, key := range claimed {
+		unused[key] = struct{}{}
+	}
+
+	for i, msg := range messages {
+		if msg.MessageID == "" {
+			continue
+		}
+
+		key := messageIDKey(msg)
+		if _, ok := unused[key]; ok {
+			delete(unused, key)
+			continue
+		}
+		duplicates[i] = true
+	}
+
+	return duplicates, nil
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"monolith/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrIngestQueueFull   = errors.New("ingest queue is full")
+	ErrIngestQueueClosed = errors.New("ingest queue is closed")
+	// ErrIngestBatchTooLarge is returned for a batch that does not fit the
+	// ingest queue even when it is empty.
+	ErrIngestBatchTooLarge = errors.New("batch is larger than the ingest queue")
+)
+
+const (
+	dropReasonQueueFull   = "queue_full"
+	dropReasonWriteFailed = "write_failed"
+
+	// flushAttempts bounds retries of a failed batch write. The id claim and
+	// the COPY share one transaction, so retrying never stores a batch twice.
+	flushAttempts = 3
+	flushBackoff  = 200 * time.Millisecond
+
+	defaultIngestQueueSize     = 10000
+	defaultIngestWriters       = 4
+	defaultIngestFlushSize     = 500
+	defaultIngestFlushInterval = time.Second
+)
+
+// resolveDevice looks up the sender of a message that was queued for the
+// unknown device again, as it may have been promoted meanwhile. The message
+// id was claimed for the unknown device, so this runs after the claim.
+func (ms *MessagesService) resolveDevice(msg models.Message) models.Message {
+	if msg.DeviceId != unknownDeviceID || msg.DeviceIP == "" || ms.deviceHandler == nil {
+		return msg
+	}
+
+	if deviceID, ok := ms.deviceHandler.GetDeviceIDByIp(msg.DeviceIP); ok {
+		msg.DeviceId = deviceID
+	}
+
+	return msg
+}
+
+// handledMessage is the outcome of handleMessage for one message of a batch.
+type handledMessage struct {
+	resp   handleMessageResponse
+	failed bool
+	done   bool
+}
+
+type ingestMetrics struct {
+	flushDuration prometheus.Histogram
+	flushedTotal  prometheus.Counter
+	droppedTotal  *prometheus.CounterVec
+}
+
+func (ms *MessagesService) initIngest(cfg MessagesServiceConfig) {
+	if cfg.IngestQueueSize <= 0 {
+		cfg.IngestQueueSize = defaultIngestQueueSize
+	}
+	if cfg.IngestWriters <= 0 {
+		cfg.IngestWriters = defaultIngestWriters
+	}
+	if cfg.IngestFlushSize <= 0 {
+		cfg.IngestFlushSize = defaultIngestFlushSize
+	}
+	if cfg.IngestFlushInterval <= 0 {
+		cfg.IngestFlushInterval = defaultIngestFlushInterval
+	}
+
+	ms.queue = make(chan models.Message, cfg.IngestQueueSize)
+	ms.flushSize = cfg.IngestFlushSize
+	ms.flushInterval = cfg.IngestFlushInterval
+
+	queueDepth := prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "ingest_queue_depth",
+			Help: "Messages waiting in the ingest queue",
+		},
+		func() float64 { return float64(len(ms.queue)) },
+	)
+
+	ms.ingestMetrics = ingestMetrics{
+		flushDuration: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "ingest_flush_duration_seconds",
+				Help:    "Time spent writing one batch of messages to the database",
+				Buckets: prometheus.DefBuckets,
+			},
+		),
+		flushedTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "ingest_flushed_messages_total",
+				Help: "Messages written to the database by the ingest writers",
+			},
+		),
+		droppedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ingest_dropped_messages_total",
+				Help: "Messages dropped by the ingest pipeline",
+			},
+			[]string{"reason"},
+		),
+	}
+
+	prometheus.MustRegister(
+		queueDepth,
+		ms.ingestMetrics.flushDuration,
+		ms.ingestMetrics.flushedTotal,
+		ms.ingestMetrics.droppedTotal,
+	)
+
+	for range cfg.IngestWriters {
+		ms.writers.Add(1)
+		go ms.runWriter()
+	}
+}
+
+// Enqueue hands a message over to the ingest writers. It never blocks: when
+// the queue is full the message is rejected with ErrIngestQueueFull so the
+// caller can apply backpressure. A repeated message id is rejected with
+// ErrDuplicateMessage and a bad device timestamp with ErrTimestampSkew.
+//
+// Only the ids this process accepted within the window are known here. An
+// id stored before a restart or by another replica is found in message_ids
+// when the batch is written, after the message was acknowledged, and the
+// message is dropped then. So ErrDuplicateMessage is best-effort, while a
+// message id is still stored at most once.
+func (ms *MessagesService) Enqueue(opts models.Message) error {
+	ms.queueMutex.RLock()
+	defer ms.queueMutex.RUnlock()
+
+	if ms.queueClosed {
+		return ErrIngestQueueClosed
+	}
+
+	if err := ms.prepare(&opts); err != nil {
+		return err
+	}
+
+	select {
+	case ms.queue <- opts:
+		return nil
+	default:
+		ms.release(opts)
+		ms.ingestMetrics.droppedTotal.WithLabelValues(dropReasonQueueFull).Inc()
+		return ErrIngestQueueFull
+	}
+}
+
+// EnqueueBatch hands the messages over to the ingest writers all at once.
+// errs has an entry per message: ErrDuplicateMessage or ErrTimestampSkew
+// for a message that is not queued, nil for one that is. When the queue
+// cannot take every other message, none is queued and ErrIngestQueueFull
+// is returned, so a client can retry the whole batch without storing a
+// message twice.
+func (ms *MessagesService) EnqueueBatch(opts []models.Message) ([]error, error) {
+	// The write lock keeps Enqueue from taking the room counted here.
+	ms.queueMutex.Lock()
+	defer ms.queueMutex.Unlock()
+
+	if ms.queueClosed {
+		return nil, ErrIngestQueueClosed
+	}
+
+	errs := make([]error, len(opts))
+	prepared := make([]models.Message, 0, len(opts))
+	for i := range opts {
+		msg := opts[i]
+		if errs[i] = ms.prepare(&msg); errs[i] == nil {
+			prepared = append(prepared, msg)
+		}
+	}
+
+	if len(prepared) > cap(ms.queue)-len(ms.queue) {
+		for _, msg := range prepared {
+			ms.release(msg)
+		}
+		if len(prepared) > cap(ms.queue) {
+			return nil, ErrIngestBatchTooLarge
+		}
+		ms.ingestMetrics.droppedTotal.WithLabelValues(dropReasonQueueFull).Add(float64(len(prepared)))
+		return nil, ErrIngestQueueFull
+	}
+
+	// The writers only take messages out, so there is room for all of them.
+	for _, msg := range prepared {
+		ms.queue <- msg
+	}
+
+	return errs, nil
+}
+
+// prepare stamps the receive time, applies the timestamp skew policy and
+// remembers the message id.
+func (ms *MessagesService) prepare(opts *models.Message) error {
+	if opts.GotAt.IsZero() {
+		opts.GotAt = time.Now()
+	}
+
+	eventAt, err := ms.eventTime.apply(opts.EventAt, opts.GotAt)
+	if err != nil {
+		ms.ingestMetrics.droppedTotal.WithLabelValues(dropReasonTimestampSkew).Inc()
+		return err
+	}
+	opts.EventAt = eventAt
+
+	if opts.MessageID != "" && !ms.recentIDs.add(messageIDKey(*opts), opts.GotAt) {
+		ms.ingestMetrics.droppedTotal.WithLabelValues(dropReasonDuplicate).Inc()
+		return ErrDuplicateMessage
+	}
+
+	return nil
+}
+
+// release forgets the id of a message that was not queued after all.
+func (ms *MessagesService) release(opts models.Message) {
+	if opts.MessageID != "" {
+		ms.recentIDs.remove(messageIDKey(opts))
+	}
+}
+
+// Close stops accepting messages and waits until the writers have flushed
+// everything that is already queued and its alerts are notified.
+func (ms *MessagesService) Close(ctx context.Context) error {
+	ms.queueMutex.Lock()
+	if !ms.queueClosed {
+		ms.queueClosed = true
+		close(ms.queue)
+		ms.cron.Stop()
+	}
+	ms.queueMutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		ms.writers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		ms.watchers.close()
+		return ms.closeAlerts(ctx)
+	case <-ctx.Done():
+		ms.watchers.close()
+		return fmt.Errorf("ingest queue drain: %w (%d messages left)", ctx.Err(), len(ms.queue))
+	}
+}
+
+func (ms *MessagesService) runWriter() {
+	defer ms.writers.Done()
+
+	batch := make([]models.Message, 0, ms.flushSize)
+	ticker := time.NewTicker(ms.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-ms.queue:
+			if !ok {
+				ms.flush(batch)
+				return
+			}
+
+			batch = append(batch, msg)
+			if len(batch) >= ms.flushSize {
+				ms.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) != 0 {
+				ms.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func (ms *MessagesService) flush(batch []models.Message) {
+	if len(batch) == 0 {
+		return
+	}
+
+	handled := make([]handledMessage, len(batch))
+	var (
+		messages  []models.Message
+		responses []handleMessageResponse
+		err       error
+	)
+	for attempt := 1; attempt <= flushAttempts; attempt++ {
+		start := time.Now()
+		messages, responses, err = ms.writeBatch(batch, handled)
+		ms.ingestMetrics.flushDuration.Observe(time.Since(start).Seconds())
+		if err == nil {
+			break
+		}
+
+		ms.log.Warn("ms.writeBatch", zap.Error(err), zap.Int("attempt", attempt))
+		if attempt < flushAttempts {
+			time.Sleep(time.Duration(attempt) * flushBackoff)
+		}
+	}
+	if err != nil {
+		ms.log.Error("ms.writeBatch", zap.Error(err), zap.Int("messages", len(batch)))
+		// Nothing of the batch is stored, so its ids are free to be sent
+		// again.
+		for i, msg := range batch {
+			if !handled[i].failed {
+				ms.ingestMetrics.droppedTotal.WithLabelValues(dropReasonWriteFailed).Inc()
+			}
+			if msg.MessageID != "" {
+				ms.recentIDs.remove(messageIDKey(msg))
+			}
+		}
+		return
+	}
+
+	ms.ingestMetrics.flushedTotal.Add(float64(len(messages)))
+	ms.watchers.publish(messages)
+	ms.alert(responses...)
+}
+
+// writeBatch claims the message ids of the batch and stores the messages
+// that are not duplicates in one transaction, so a failed write leaves no id
+// claimed. The claim also catches ids the in-memory check misses after a
+// restart or across replicas. handled keeps the handled messages across
+// attempts, so tags are not touched again on a retry.
+func (ms *MessagesService) writeBatch(batch []models.Message, handled []handledMessage) ([]models.Message, []handleMessageResponse, error) {
+	tx, err := ms.messageRepo.BeginTx(context.Background())
+	if err != nil {
+		return nil, nil, fmt.Errorf("ms.messageRepo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	duplicates, err := ms.claimMessageIDs(tx, batch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ms.claimMessageIDs: %w", err)
+	}
+
+	messages := make([]models.Message, 0, len(batch))
+	responses := make([]handleMessageResponse, 0, len(batch))
+	var skipped int
+	for i, msg := range batch {
+		if duplicates[i] {
+			skipped++
+			continue
+		}
+
+		if !handled[i].done {
+			resp, err := ms.handleMessage(ms.resolveDevice(msg))
+			if err != nil {
+				ms.log.Error("ms.handleMessage", zap.Error(err))
+				ms.ingestMetrics.droppedTotal.WithLabelValues(dropReasonWriteFailed).Inc()
+			}
+			handled[i] = handledMessage{resp: resp, failed: err != nil, done: true}
+		}
+		if handled[i].failed {
+			continue
+		}
+
+		messages = append(messages, handled[i].resp.Message)
+		responses = append(responses, handled[i].resp)
+	}
+
+	if len(messages) != 0 {
+		if _, err := tx.CopyFrom(context.Background(), messages); err != nil {
+			return nil, nil, fmt.Errorf("tx.CopyFrom: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	ms.ingestMetrics.droppedTotal.WithLabelValues(dropReasonDuplicate).Add(float64(skipped))
+
+	return messages, responses, nil
+}
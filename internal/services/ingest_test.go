@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"monolith/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newQueueOnlyService returns a service with an ingest queue and no
+// writers, so the test sees exactly what was queued.
+func newQueueOnlyService(queueSize int) *MessagesService {
+	return &MessagesService{
+		queue:     make(chan models.Message, queueSize),
+		recentIDs: newRecentIDs(time.Hour),
+		eventTime: eventTimePolicy{
+			policy:    SkewPolicyReject,
+			maxFuture: time.Minute,
+			maxPast:   time.Hour,
+		},
+		ingestMetrics: ingestMetrics{
+			droppedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "dropped"}, []string{"reason"}),
+		},
+	}
+}
+
+func TestEnqueueBatch(t *testing.T) {
+	ms := newQueueOnlyService(3)
+	future := time.Now().Add(time.Hour)
+
+	errs, err := ms.EnqueueBatch([]models.Message{
+		{Message: "a", MessageID: "1"},
+		{Message: "b", MessageID: "1"},
+		{Message: "c", EventAt: &future},
+		{Message: "d"},
+	})
+	if err != nil {
+		t.Fatalf("EnqueueBatch: %v", err)
+	}
+
+	wantErrs := []error{nil, ErrDuplicateMessage, ErrTimestampSkew, nil}
+	for i, want := range wantErrs {
+		if !errors.Is(errs[i], want) {
+			t.Errorf("errs[%d] = %v, want %v", i, errs[i], want)
+		}
+	}
+	if len(ms.queue) != 2 {
+		t.Errorf("queued %d messages, want 2", len(ms.queue))
+	}
+}
+
+func TestEnqueueBatchQueueFull(t *testing.T) {
+	ms := newQueueOnlyService(3)
+	if err := ms.Enqueue(models.Message{Message: "queued"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	_, err := ms.EnqueueBatch([]models.Message{
+		{Message: "a", MessageID: "1"},
+		{Message: "b", MessageID: "2"},
+		{Message: "c", MessageID: "3"},
+	})
+	if !errors.Is(err, ErrIngestQueueFull) {
+		t.Fatalf("EnqueueBatch error = %v, want %v", err, ErrIngestQueueFull)
+	}
+	if len(ms.queue) != 1 {
+		t.Errorf("queued %d messages, want only the first one", len(ms.queue))
+	}
+
+	// Nothing of the batch was queued, so its ids are not duplicates.
+	<-ms.queue
+	errs, err := ms.EnqueueBatch([]models.Message{{Message: "a", MessageID: "1"}})
+	if err != nil || errs[0] != nil {
+		t.Errorf("EnqueueBatch retry = %v, %v, want it queued", errs, err)
+	}
+}
+
+func TestEnqueueBatchTooLarge(t *testing.T) {
+	ms := newQueueOnlyService(1)
+
+	_, err := ms.EnqueueBatch(make([]models.Message, 2))
+	if !errors.Is(err, ErrIngestBatchTooLarge) {
+		t.Errorf("EnqueueBatch error = %v, want %v", err, ErrIngestBatchTooLarge)
+	}
+}
@@ -2,11 +2,14 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"monolith/internal/models"
 	"monolith/internal/repo"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,18 +22,61 @@ type Messages interface {
 	UpdateDevices()
 	UpdateTags()
 	Create(opts models.Message) (CreateMessageResponse, bool, error)
-	GetAllByPeriod(opts MessagesGetAllByPeriodOpts) ([]ReportGetAllByPeriod, error)
-	GetAllByDeviceId(deviceID int32) ([]ReportGetAllByDeviceId, error)
-	GetCountByMessageType(messageType string) ([]ReportGetCountByMessageType, error)
-	MonthReport() ([]models.MonthReportRow, error)
+	// GetAllByPeriod and GetAllByDeviceId return a page of messages, newest
+	// first. ErrInvalidReportCursor is returned for a malformed cursor.
+	GetAllByPeriod(opts MessagesGetAllByPeriodOpts) ([]ReportGetAllByPeriod, ReportPageInfo, error)
+	GetAllByDeviceId(opts MessagesGetAllByDeviceIdOpts) ([]ReportGetAllByDeviceId, ReportPageInfo, error)
+	// GetCountByMessageType and MonthReport are limited to the devices of
+	// the group and its subgroups unless groupID is zero.
+	GetCountByMessageType(messageType string, groupID int32) ([]ReportGetCountByMessageType, error)
+	MonthReport(timeBasis string, groupID int32) ([]models.MonthReportRow, error)
+	PeriodReport(params PeriodReportParams) ([]models.PeriodReportRow, error)
+	// Search returns a page of the messages matching a full-text search.
+	// ErrInvalidSearch is returned for a search without words or with an
+	// unknown order, ErrInvalidReportCursor for a malformed cursor.
+	Search(opts SearchMessagesOpts) ([]ReportSearchHit, ReportPageInfo, error)
+	// Device returns the device the reports name, or a placeholder for an
+	// unknown one.
+	Device(deviceID int32) models.Device
+
+	Enqueue(opts models.Message) error
+	EnqueueBatch(opts []models.Message) ([]error, error)
+	Watch() (<-chan models.Message, func())
+	Close(ctx context.Context) error
 }
 type MessagesService struct {
 	messageRepo        repo.Messages
 	tagRepo            repo.Tags
 	devicesRepo        repo.Devices
+	deviceHandler      DevicesHandler
 	cron               *cron.Cron
 	notificationPeriod time.Duration
 
+	queue         chan models.Message
+	queueMutex    sync.RWMutex
+	queueClosed   bool
+	writers       sync.WaitGroup
+	flushSize     int
+	flushInterval time.Duration
+	ingestMetrics ingestMetrics
+
+	idempotencyWindow time.Duration
+	recentIDs         *recentIDs
+
+	rollupLateWindow time.Duration
+
+	eventTime eventTimePolicy
+
+	watchers *watchers
+
+	notifier     Notifier
+	upstream     UpstreamChecker
+	alerts       chan alert
+	alertsDone   chan struct{}
+	alertsMutex  sync.RWMutex
+	alertsClosed bool
+	sent         map[alertKey]models.SendedNotification
+
 	log *zap.Logger
 }
 
@@ -38,20 +84,67 @@ type MessagesServiceConfig struct {
 	MessageRepo repo.Messages
 	TagRepo     repo.Tags
 	DevicesRepo repo.Devices
+	// DeviceHandler resolves again the senders of queued messages of the
+	// unknown device when they are written.
+	DeviceHandler DevicesHandler
 
+	// NotificationPeriod is how long a tag alert is not repeated for the
+	// same device and subject.
 	NotificationPeriod time.Duration
-	Log                *zap.Logger
+	// Notifier delivers tag alerts; without it they are not delivered.
+	Notifier Notifier
+	// Upstream suppresses tag alerts of devices behind a down device.
+	Upstream UpstreamChecker
+
+	IngestQueueSize     int
+	IngestWriters       int
+	IngestFlushSize     int
+	IngestFlushInterval time.Duration
+
+	// IdempotencyWindow is how long a client supplied message id is
+	// remembered.
+	IdempotencyWindow time.Duration
+
+	// RollupInterval is how often the daily message rollups are brought up
+	// to date. Days are rolled up once RollupLateWindow has passed since
+	// their end; until then reports read their messages.
+	RollupInterval   time.Duration
+	RollupLateWindow time.Duration
+
+	// TimestampSkewPolicy is one of SkewPolicyAccept, SkewPolicyClamp and
+	// SkewPolicyReject. It applies to device timestamps further than
+	// TimestampMaxFutureSkew ahead of or TimestampMaxPastSkew behind the
+	// receive time.
+	TimestampSkewPolicy    string
+	TimestampMaxFutureSkew time.Duration
+	TimestampMaxPastSkew   time.Duration
+
+	Log *zap.Logger
 }
 
-func NewMessagesService(cfg MessagesServiceConfig) Messages {
+// NewMessagesService fails with ErrInvalidSkewPolicy for an unknown
+// TimestampSkewPolicy.
+func NewMessagesService(cfg MessagesServiceConfig) (Messages, error) {
+	eventTime, err := newEventTimePolicy(cfg.TimestampSkewPolicy, cfg.TimestampMaxFutureSkew, cfg.TimestampMaxPastSkew)
+	if err != nil {
+		return nil, err
+	}
+
 	messagesService := &MessagesService{
-		messageRepo: cfg.MessageRepo,
-		tagRepo:     cfg.TagRepo,
-		devicesRepo: cfg.DevicesRepo,
-		log:         cfg.Log,
+		messageRepo:   cfg.MessageRepo,
+		tagRepo:       cfg.TagRepo,
+		devicesRepo:   cfg.DevicesRepo,
+		deviceHandler: cfg.DeviceHandler,
+		eventTime:     eventTime,
+		watchers:      newWatchers(),
+		log:           cfg.Log,
 	}
 	messagesService.UpdateTags()
-	return messagesService
+	messagesService.initIdempotency(cfg)
+	messagesService.initRollups(cfg)
+	messagesService.initAlerts(cfg)
+	messagesService.initIngest(cfg)
+	return messagesService, nil
 }
 
 var (
@@ -78,6 +171,16 @@ func (s *MessagesService) SetDevices(newDevices []models.Device) {
 	s.log.Debug("set devices", zap.Any("devices", deviceByDeviceID))
 }
 
+func (s *MessagesService) Device(deviceID int32) models.Device {
+	deviceByDeviceIDMutex.Lock()
+	defer deviceByDeviceIDMutex.Unlock()
+
+	if device, ok := deviceByDeviceID[deviceID]; ok {
+		return device
+	}
+	return defaultDevice
+}
+
 func (s *MessagesService) UpdateDevices() {
 	tx, err := s.devicesRepo.BeginTx(context.Background())
 	if err != nil {
@@ -196,6 +299,9 @@ func (ms *MessagesService) Create(opts models.Message) (CreateMessageResponse, b
 		return CreateMessageResponse{}, false, fmt.Errorf("tx.Commit: %w", err)
 	}
 
+	ms.watchers.publish([]models.Message{resp.Message})
+	ms.alert(resp)
+
 	return CreateMessageResponse{
 		Subject: resp.Subject,
 		Text:    resp.Text,
@@ -206,7 +312,11 @@ type handleMessageResponse struct {
 	Text       string
 	Subject    string
 	NeedNotify bool
-	Message    models.Message
+	// Suppressed is set instead of NeedNotify when a device upstream is
+	// down; UpstreamDeviceID is that device.
+	Suppressed       bool
+	UpstreamDeviceID int32
+	Message          models.Message
 }
 
 func (ms *MessagesService) handleMessage(message models.Message) (handleMessageResponse, error) {
@@ -221,11 +331,14 @@ func (ms *MessagesService) handleMessage(message models.Message) (handleMessageR
 			}
 			message.SeverityLevel = tag.SeverityLevel
 		}
+		upstream, suppressed := ms.suppress(message.DeviceId)
 		return handleMessageResponse{
-			Text:       message.Message,
-			Subject:    tag.Subject,
-			NeedNotify: true,
-			Message:    message,
+			Text:             message.Message,
+			Subject:          tag.Subject,
+			NeedNotify:       !suppressed,
+			Suppressed:       suppressed,
+			UpstreamDeviceID: upstream,
+			Message:          message,
 		}, nil
 	}
 
@@ -255,125 +368,299 @@ func (ms *MessagesService) handleReversedTag(tag models.Tag) {
 	}
 }
 
+var (
+	ErrInvalidReportCursor = errors.New("invalid report cursor")
+	ErrInvalidPeriodReport = errors.New("invalid period report")
+)
+
+const (
+	monthReportPeriod      = 30 * 24 * time.Hour
+	monthReportMinMessages = 101
+
+	defaultCriticalSeverity = "critical"
+)
+
+type (
+	// ReportPage selects a page of a message report.
+	ReportPage struct {
+		// Cursor is the NextCursor of the previous page. An empty one starts
+		// from the newest message.
+		Cursor string
+		// Limit of zero returns every message.
+		Limit int
+		// WithTotal counts every message of the report, which takes a
+		// second query.
+		WithTotal bool
+	}
+
+	ReportPageInfo struct {
+		// NextCursor is empty on the last page.
+		NextCursor string
+		Total      *int64
+	}
+)
+
 type (
 	MessagesGetAllByPeriodOpts struct {
 		StartTime time.Time
 		EndTime   time.Time
+		// TimeBasis is models.TimeBasisReceived or models.TimeBasisEvent.
+		TimeBasis string
+		Filter    repo.MessagesFilter
+		Page      ReportPage
 	}
 	ReportGetAllByPeriod struct {
-		DeviceID    int32
-		Name        string
-		DeviceType  string
-		Address     string
-		Responsible []int32
-		GotAt       time.Time
-		Message     string
-		MessageType string
+		ID            int32
+		DeviceID      int32
+		Name          string
+		DeviceType    string
+		Address       string
+		Responsible   []int32
+		GotAt         time.Time
+		EventAt       *time.Time
+		Message       string
+		MessageType   string
+		SeverityLevel string
+		Component     string
 	}
 )
 
-func (ms *MessagesService) GetAllByPeriod(opts MessagesGetAllByPeriodOpts) ([]ReportGetAllByPeriod, error) {
+func (ms *MessagesService) GetAllByPeriod(opts MessagesGetAllByPeriodOpts) ([]ReportGetAllByPeriod, ReportPageInfo, error) {
+	after, err := decodeReportCursor(opts.Page.Cursor)
+	if err != nil {
+		return nil, ReportPageInfo{}, err
+	}
+
 	tx, err := ms.messageRepo.BeginTx(context.Background())
 	if err != nil {
 		ms.log.Error("tx.BeginTx", zap.Error(err))
-		return nil, fmt.Errorf("ms.messageRepo.BeginTx: %w", err)
+		return nil, ReportPageInfo{}, fmt.Errorf("ms.messageRepo.BeginTx: %w", err)
 	}
 	defer tx.Rollback()
 
-	result, err := tx.GetAllByPeriod(
-		repo.MessagesGetAllByPeriodOpts{
-			StartTime: opts.StartTime,
-			EndTime:   opts.EndTime,
-		},
-	)
+	repoOpts := repo.MessagesGetAllByPeriodOpts{
+		StartTime: opts.StartTime,
+		EndTime:   opts.EndTime,
+		TimeBasis: opts.TimeBasis,
+		Filter:    opts.Filter,
+		After:     after,
+		Limit:     reportFetchLimit(opts.Page.Limit),
+	}
+
+	result, err := tx.GetAllByPeriod(repoOpts)
 	if err != nil {
-		return nil, fmt.Errorf("tx.GetAllByPeriod: %w", err)
+		return nil, ReportPageInfo{}, fmt.Errorf("tx.GetAllByPeriod: %w", err)
+	}
+
+	var info ReportPageInfo
+	result, info.NextCursor = reportPage(result, opts.Page.Limit, opts.TimeBasis)
+
+	if opts.Page.WithTotal {
+		total, err := tx.CountByPeriod(repoOpts)
+		if err != nil {
+			return nil, ReportPageInfo{}, fmt.Errorf("tx.CountByPeriod: %w", err)
+		}
+		info.Total = &total
 	}
 
 	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("tx.Commit: %w", err)
+		return nil, ReportPageInfo{}, fmt.Errorf("tx.Commit: %w", err)
 	}
 
 	return lo.Map(result, func(r models.Message, indx int) ReportGetAllByPeriod {
 		if dev, ok := deviceByDeviceID[r.DeviceId]; ok {
 			return ReportGetAllByPeriod{
-				DeviceID:    r.DeviceId,
-				Name:        dev.Name,
-				DeviceType:  dev.DeviceType,
-				Address:     dev.Address,
-				Responsible: dev.Responsible,
-				Message:     r.Message,
-				MessageType: r.MessageType,
-				GotAt:       r.GotAt,
+				ID:            r.Id,
+				DeviceID:      r.DeviceId,
+				Name:          dev.Name,
+				DeviceType:    dev.DeviceType,
+				Address:       dev.Address,
+				Responsible:   dev.Responsible,
+				Message:       r.Message,
+				MessageType:   r.MessageType,
+				SeverityLevel: r.SeverityLevel,
+				Component:     r.Component,
+				GotAt:         r.GotAt,
+				EventAt:       r.EventAt,
 			}
 		}
 		return ReportGetAllByPeriod{
-			DeviceID:    r.DeviceId,
-			Name:        defaultDevice.Name,
-			DeviceType:  defaultDevice.DeviceType,
-			Address:     defaultDevice.Address,
-			Responsible: defaultDevice.Responsible,
-			Message:     r.Message,
-			MessageType: r.MessageType,
-			GotAt:       r.GotAt,
+			ID:            r.Id,
+			DeviceID:      r.DeviceId,
+			Name:          defaultDevice.Name,
+			DeviceType:    defaultDevice.DeviceType,
+			Address:       defaultDevice.Address,
+			Responsible:   defaultDevice.Responsible,
+			Message:       r.Message,
+			MessageType:   r.MessageType,
+			SeverityLevel: r.SeverityLevel,
+			Component:     r.Component,
+			GotAt:         r.GotAt,
+			EventAt:       r.EventAt,
 		}
-	}), nil
+	}), info, nil
 }
 
 type (
+	MessagesGetAllByDeviceIdOpts struct {
+		DeviceID int32
+		Filter   repo.MessagesFilter
+		Page     ReportPage
+	}
 	ReportGetAllByDeviceId struct {
-		DeviceID    int32
-		Name        string
-		DeviceType  string
-		Address     string
-		Responsible []int32
-		GotAt       time.Time
-		Message     string
-		MessageType string
+		ID            int32
+		DeviceID      int32
+		Name          string
+		DeviceType    string
+		Address       string
+		Responsible   []int32
+		GotAt         time.Time
+		EventAt       *time.Time
+		Message       string
+		MessageType   string
+		SeverityLevel string
+		Component     string
 	}
 )
 
-func (ms *MessagesService) GetAllByDeviceId(deviceID int32) ([]ReportGetAllByDeviceId, error) {
+func (ms *MessagesService) GetAllByDeviceId(opts MessagesGetAllByDeviceIdOpts) ([]ReportGetAllByDeviceId, ReportPageInfo, error) {
+	after, err := decodeReportCursor(opts.Page.Cursor)
+	if err != nil {
+		return nil, ReportPageInfo{}, err
+	}
+
 	tx, err := ms.messageRepo.BeginTx(context.Background())
 	if err != nil {
 		ms.log.Error("tx.BeginTx", zap.Error(err))
-		return nil, fmt.Errorf("ms.messageRepo.BeginTx: %w", err)
+		return nil, ReportPageInfo{}, fmt.Errorf("ms.messageRepo.BeginTx: %w", err)
 	}
 	defer tx.Rollback()
 
-	result, err := tx.GetAllByDeviceId(deviceID)
+	repoOpts := repo.MessagesGetAllByDeviceIdOpts{
+		DeviceID: opts.DeviceID,
+		Filter:   opts.Filter,
+		After:    after,
+		Limit:    reportFetchLimit(opts.Page.Limit),
+	}
+
+	result, err := tx.GetAllByDeviceId(repoOpts)
 	if err != nil {
-		return nil, fmt.Errorf("tx.GetAllByDeviceId: %w", err)
+		return nil, ReportPageInfo{}, fmt.Errorf("tx.GetAllByDeviceId: %w", err)
+	}
+
+	var info ReportPageInfo
+	result, info.NextCursor = reportPage(result, opts.Page.Limit, models.TimeBasisReceived)
+
+	if opts.Page.WithTotal {
+		total, err := tx.CountByDeviceId(repoOpts)
+		if err != nil {
+			return nil, ReportPageInfo{}, fmt.Errorf("tx.CountByDeviceId: %w", err)
+		}
+		info.Total = &total
 	}
 
 	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("tx.Commit: %w", err)
+		return nil, ReportPageInfo{}, fmt.Errorf("tx.Commit: %w", err)
 	}
 
 	return lo.Map(result, func(r models.Message, indx int) ReportGetAllByDeviceId {
 		if dev, ok := deviceByDeviceID[r.DeviceId]; ok {
 			return ReportGetAllByDeviceId{
-				DeviceID:    r.DeviceId,
-				Name:        dev.Name,
-				DeviceType:  dev.DeviceType,
-				Address:     dev.Address,
-				Responsible: dev.Responsible,
-				Message:     r.Message,
-				MessageType: r.MessageType,
-				GotAt:       r.GotAt,
+				ID:            r.Id,
+				DeviceID:      r.DeviceId,
+				Name:          dev.Name,
+				DeviceType:    dev.DeviceType,
+				Address:       dev.Address,
+				Responsible:   dev.Responsible,
+				Message:       r.Message,
+				MessageType:   r.MessageType,
+				SeverityLevel: r.SeverityLevel,
+				Component:     r.Component,
+				GotAt:         r.GotAt,
+				EventAt:       r.EventAt,
 			}
 		}
 		return ReportGetAllByDeviceId{
-			DeviceID:    r.DeviceId,
-			Name:        defaultDevice.Name,
-			DeviceType:  defaultDevice.DeviceType,
-			Address:     defaultDevice.Address,
-			Responsible: defaultDevice.Responsible,
-			Message:     r.Message,
-			MessageType: r.MessageType,
-			GotAt:       r.GotAt,
+			ID:            r.Id,
+			DeviceID:      r.DeviceId,
+			Name:          defaultDevice.Name,
+			DeviceType:    defaultDevice.DeviceType,
+			Address:       defaultDevice.Address,
+			Responsible:   defaultDevice.Responsible,
+			Message:       r.Message,
+			MessageType:   r.MessageType,
+			SeverityLevel: r.SeverityLevel,
+			Component:     r.Component,
+			GotAt:         r.GotAt,
+			EventAt:       r.EventAt,
 		}
-	}), nil
+	}), info, nil
+}
+
+// reportFetchLimit asks for one message more than the page holds to learn
+// whether another page follows.
+func reportFetchLimit(limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return limit + 1
+}
+
+// reportPage cuts the extra message off and returns the cursor of the last
+// message kept, or an empty one when nothing follows.
+func reportPage(messages []models.Message, limit int, timeBasis string) ([]models.Message, string) {
+	if limit <= 0 || len(messages) <= limit {
+		return messages, ""
+	}
+
+	messages = messages[:limit]
+	last := messages[limit-1]
+
+	at := last.GotAt
+	if timeBasis == models.TimeBasisEvent && last.EventAt != nil {
+		at = *last.EventAt
+	}
+
+	return messages, encodeReportCursor(repo.MessagesCursor{
+		Time: at,
+		ID:   last.Id,
+	})
+}
+
+// A report cursor is the report time in unix nanoseconds and the message id,
+// opaque to clients.
+func encodeReportCursor(cursor repo.MessagesCursor) string {
+	return base64.RawURLEncoding.EncodeToString(
+		fmt.Appendf(nil, "%d.%d", cursor.Time.UnixNano(), cursor.ID),
+	)
+}
+
+func decodeReportCursor(cursor string) (*repo.MessagesCursor, error) {
+	if cursor == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidReportCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, ErrInvalidReportCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidReportCursor
+	}
+	messageID, err := strconv.ParseInt(id, 10, 32)
+	if err != nil || messageID <= 0 {
+		return nil, ErrInvalidReportCursor
+	}
+
+	return &repo.MessagesCursor{
+		Time: time.Unix(0, unixNano).UTC(),
+		ID:   int32(messageID),
+	}, nil
 }
 
 type ReportGetCountByMessageType struct {
@@ -385,7 +672,7 @@ type ReportGetCountByMessageType struct {
 	Count       int32
 }
 
-func (ms *MessagesService) GetCountByMessageType(messageType string) ([]ReportGetCountByMessageType, error) {
+func (ms *MessagesService) GetCountByMessageType(messageType string, groupID int32) ([]ReportGetCountByMessageType, error) {
 	tx, err := ms.messageRepo.BeginTx(context.Background())
 	if err != nil {
 		ms.log.Error("tx.BeginTx", zap.Error(err))
@@ -393,7 +680,7 @@ func (ms *MessagesService) GetCountByMessageType(messageType string) ([]ReportGe
 	}
 	defer tx.Rollback()
 
-	result, err := tx.GetCountByMessageType(messageType)
+	result, err := tx.GetCountByMessageType(messageType, groupID)
 	if err != nil {
 		return nil, fmt.Errorf("tx.GetCountByMessageType: %w", err)
 	}
@@ -424,7 +711,99 @@ func (ms *MessagesService) GetCountByMessageType(messageType string) ([]ReportGe
 	}), nil
 }
 
-func (ms *MessagesService) MonthReport() ([]models.MonthReportRow, error) {
+// MonthReport is the PeriodReport preset of MonthReportParams, in the shape
+// the month report has always had.
+func (ms *MessagesService) MonthReport(timeBasis string, groupID int32) ([]models.MonthReportRow, error) {
+	rows, err := ms.PeriodReport(MonthReportParams(timeBasis, groupID, time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("ms.PeriodReport: %w", err)
+	}
+
+	return lo.Map(rows, func(r models.PeriodReportRow, _ int) models.MonthReportRow {
+		return models.MonthReportRow{
+			DeviceID:               r.DeviceID.Int32,
+			MessageType:            r.MessageType.String,
+			ActiveDays:             r.ActiveDays,
+			TotalMessages:          r.TotalMessages,
+			AvgDailyMessages:       r.AvgDailyMessages,
+			MaxDailyMessages:       r.MaxDailyMessages,
+			MedianDailyMessages:    r.MedianDailyMessages,
+			TotalCritical:          r.TotalCritical,
+			MaxDailyCritical:       r.MaxDailyCritical,
+			MaxDailyComponents:     r.MaxDailyComponents,
+			MostActiveComponent:    r.MostActiveComponent,
+			FirstCriticalTime:      r.FirstCriticalTime,
+			LastCriticalTime:       r.LastCriticalTime,
+			AvgCriticalIntervalSec: r.AvgCriticalIntervalSec,
+			CriticalPercentage:     r.CriticalPercentage,
+			OverallVolumeRank:      r.OverallVolumeRank,
+		}
+	}), nil
+}
+
+// PeriodReportParams describe a period report. Zero values leave a filter
+// out; see the fields for the other defaults.
+type PeriodReportParams struct {
+	StartTime time.Time
+	EndTime   time.Time
+	// TimeBasis is models.TimeBasisReceived or models.TimeBasisEvent.
+	TimeBasis string
+	DeviceIDs []int32
+	// GroupID limits the report to the devices of the group and its
+	// subgroups.
+	GroupID int32
+	// MinMessages leaves out groups with fewer messages.
+	MinMessages int64
+	// CriticalSeverities are the severities counted as critical, only
+	// "critical" when empty.
+	CriticalSeverities []string
+	// GroupBy holds models.ReportDimension* values, device and message type
+	// when empty.
+	GroupBy []string
+}
+
+// MonthReportParams is the month report preset: the 30 days before now by
+// device and message type, groups of more than 100 messages.
+func MonthReportParams(timeBasis string, groupID int32, now time.Time) PeriodReportParams {
+	return PeriodReportParams{
+		StartTime:          now.Add(-monthReportPeriod),
+		EndTime:            now,
+		TimeBasis:          timeBasis,
+		DeviceIDs:          nil,
+		GroupID:            groupID,
+		MinMessages:        monthReportMinMessages,
+		CriticalSeverities: nil,
+		GroupBy:            nil,
+	}
+}
+
+// PeriodReport fails with ErrInvalidPeriodReport for an empty period or an
+// unknown dimension.
+func (ms *MessagesService) PeriodReport(params PeriodReportParams) ([]models.PeriodReportRow, error) {
+	if !params.EndTime.After(params.StartTime) {
+		return nil, fmt.Errorf("%w: end_time must be after start_time", ErrInvalidPeriodReport)
+	}
+	if params.MinMessages < 0 {
+		return nil, fmt.Errorf("%w: min_messages must not be negative", ErrInvalidPeriodReport)
+	}
+
+	groupBy := params.GroupBy
+	if len(groupBy) == 0 {
+		groupBy = []string{models.ReportDimensionDevice, models.ReportDimensionMessageType}
+	}
+	for _, dimension := range groupBy {
+		switch dimension {
+		case models.ReportDimensionDevice, models.ReportDimensionMessageType, models.ReportDimensionComponent:
+		default:
+			return nil, fmt.Errorf("%w: unknown dimension %q", ErrInvalidPeriodReport, dimension)
+		}
+	}
+
+	criticalSeverities := params.CriticalSeverities
+	if len(criticalSeverities) == 0 {
+		criticalSeverities = []string{defaultCriticalSeverity}
+	}
+
 	tx, err := ms.messageRepo.BeginTx(context.Background())
 	if err != nil {
 		ms.log.Error("tx.BeginTx", zap.Error(err))
@@ -432,9 +811,18 @@ func (ms *MessagesService) MonthReport() ([]models.MonthReportRow, error) {
 	}
 	defer tx.Rollback()
 
-	result, err := tx.MonthReport()
+	result, err := tx.PeriodReport(repo.PeriodReportOpts{
+		StartTime:          params.StartTime,
+		EndTime:            params.EndTime,
+		TimeBasis:          params.TimeBasis,
+		DeviceIDs:          params.DeviceIDs,
+		GroupID:            params.GroupID,
+		MinMessages:        params.MinMessages,
+		CriticalSeverities: criticalSeverities,
+		GroupBy:            groupBy,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("tx.MonthReport: %w", err)
+		return nil, fmt.Errorf("tx.PeriodReport: %w", err)
 	}
 
 	if err = tx.Commit(); err != nil {
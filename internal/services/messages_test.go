@@ -0,0 +1,154 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+)
+
+func TestReportCursor(t *testing.T) {
+	cursor := repo.MessagesCursor{
+		Time: time.Date(2024, 3, 1, 10, 30, 0, 123456789, time.UTC),
+		ID:   42,
+	}
+
+	got, err := decodeReportCursor(encodeReportCursor(cursor))
+	if err != nil {
+		t.Fatalf("decodeReportCursor: %v", err)
+	}
+	if !got.Time.Equal(cursor.Time) || got.ID != cursor.ID {
+		t.Errorf("decodeReportCursor(encodeReportCursor(%+v)) = %+v", cursor, *got)
+	}
+}
+
+func TestDecodeReportCursor(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name    string
+		cursor  string
+		want    *repo.MessagesCursor
+		wantErr error
+	}{
+		{
+			name: "empty starts from the newest",
+		},
+		{
+			name:   "valid",
+			cursor: encode("1700000000000000000.7"),
+			want: &repo.MessagesCursor{
+				Time: time.Unix(0, 1700000000000000000).UTC(),
+				ID:   7,
+			},
+		},
+		{
+			name:    "not base64",
+			cursor:  "***",
+			wantErr: ErrInvalidReportCursor,
+		},
+		{
+			name:    "no separator",
+			cursor:  encode("1700000000000000000"),
+			wantErr: ErrInvalidReportCursor,
+		},
+		{
+			name:    "bad time",
+			cursor:  encode("yesterday.7"),
+			wantErr: ErrInvalidReportCursor,
+		},
+		{
+			name:    "zero id",
+			cursor:  encode("1700000000000000000.0"),
+			wantErr: ErrInvalidReportCursor,
+		},
+		{
+			name:    "id out of range",
+			cursor:  encode("1700000000000000000.2147483648"),
+			wantErr: ErrInvalidReportCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeReportCursor(tt.cursor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("decodeReportCursor(%q) error = %v, want %v", tt.cursor, err, tt.wantErr)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("decodeReportCursor(%q) = %+v, want nil", tt.cursor, *got)
+				}
+				return
+			}
+			if got == nil || !got.Time.Equal(tt.want.Time) || got.ID != tt.want.ID {
+				t.Errorf("decodeReportCursor(%q) = %+v, want %+v", tt.cursor, got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestReportPage(t *testing.T) {
+	gotAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	eventAt := gotAt.Add(-time.Minute)
+	messages := []models.Message{
+		{Id: 3, GotAt: gotAt.Add(2 * time.Second)},
+		{Id: 2, GotAt: gotAt, EventAt: &eventAt},
+		{Id: 1, GotAt: gotAt.Add(-time.Second)},
+	}
+
+	tests := []struct {
+		name       string
+		limit      int
+		timeBasis  string
+		wantLen    int
+		wantCursor *repo.MessagesCursor
+	}{
+		{
+			name:    "no limit",
+			wantLen: 3,
+		},
+		{
+			name:    "last page",
+			limit:   3,
+			wantLen: 3,
+		},
+		{
+			name:       "received",
+			limit:      2,
+			timeBasis:  models.TimeBasisReceived,
+			wantLen:    2,
+			wantCursor: &repo.MessagesCursor{Time: gotAt, ID: 2},
+		},
+		{
+			name:       "event",
+			limit:      2,
+			timeBasis:  models.TimeBasisEvent,
+			wantLen:    2,
+			wantCursor: &repo.MessagesCursor{Time: eventAt, ID: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, cursor := reportPage(messages, tt.limit, tt.timeBasis)
+			if len(page) != tt.wantLen {
+				t.Errorf("len(page) = %d, want %d", len(page), tt.wantLen)
+			}
+			if tt.wantCursor == nil {
+				if cursor != "" {
+					t.Errorf("cursor = %q, want none", cursor)
+				}
+				return
+			}
+			if cursor != encodeReportCursor(*tt.wantCursor) {
+				t.Errorf("cursor = %q, want %q", cursor, encodeReportCursor(*tt.wantCursor))
+			}
+		})
+	}
+}
@@ -1,10 +1,47 @@
 package services
 
+import (
+	"context"
+	"fmt"
+
+	"monolith/internal/repo"
+
+	"go.uber.org/zap"
+)
+
+type Notifier interface {
+	// Notify mails the users responsible for the device.
+	Notify(ctx context.Context, deviceID int32, subject, text string) error
+}
+
+// Mailer sends a plain text email.
+type Mailer interface {
+	Mail(ctx context.Context, to []string, subject, body string) error
+}
+
 type NotificationService struct {
+	devicesRepo repo.Devices
+	authRepo    repo.Auth
+	mailer      Mailer
+
+	log *zap.Logger
 }
 
-func NewNotificationService() *NotificationService {
-	return &NotificationService{}
+type NotificationServiceConfig struct {
+	DevicesRepo repo.Devices
+	AuthRepo    repo.Auth
+	// Mailer is optional; without it notifications are only logged.
+	Mailer Mailer
+	Log    *zap.Logger
+}
+
+func NewNotificationService(cfg NotificationServiceConfig) *NotificationService {
+	return &NotificationService{
+		devicesRepo: cfg.DevicesRepo,
+		authRepo:    cfg.AuthRepo,
+		mailer:      cfg.Mailer,
+		log:         cfg.Log,
+	}
 }
 
 var ResponsiblesByDeviceId = map[int32][]string{}
@@ -16,3 +53,63 @@ func (ns *NotificationService) SetResponsiblesByDeviceId(resposiblesByDeviceID m
 func (ns *NotificationService) GetResposibles(deviceID int32) []string {
 	return ResponsiblesByDeviceId[deviceID]
 }
+
+func (ns *NotificationService) Notify(ctx context.Context, deviceID int32, subject, text string) error {
+	emails, err := ns.responsibleEmails(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("ns.responsibleEmails: %w", err)
+	}
+
+	if ns.mailer == nil || len(emails) == 0 {
+		ns.log.Info("notification",
+			zap.Int32("device_id", deviceID),
+			zap.Strings("to", emails),
+			zap.String("subject", subject),
+			zap.String("text", text),
+		)
+		return nil
+	}
+
+	err = ns.mailer.Mail(ctx, emails, subject, text)
+	if err != nil {
+		return fmt.Errorf("ns.mailer.Mail: %w", err)
+	}
+
+	return nil
+}
+
+func (ns *NotificationService) responsibleEmails(ctx context.Context, deviceID int32) ([]string, error) {
+	devicesTx, err := ns.devicesRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ns.devicesRepo.BeginTx: %w", err)
+	}
+	defer devicesTx.Rollback()
+
+	responsible, err := devicesTx.GetResponsible(ctx, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("devicesTx.GetResponsible: %w", err)
+	}
+	if err := devicesTx.Commit(); err != nil {
+		return nil, fmt.Errorf("devicesTx.Commit: %w", err)
+	}
+
+	if len(responsible) == 0 {
+		return nil, nil
+	}
+
+	authTx, err := ns.authRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ns.authRepo.BeginTx: %w", err)
+	}
+	defer authTx.Rollback()
+
+	emails, err := authTx.GetEmailsByIDs(ctx, responsible)
+	if err != nil {
+		return nil, fmt.Errorf("authTx.GetEmailsByIDs: %w", err)
+	}
+	if err := authTx.Commit(); err != nil {
+		return nil, fmt.Errorf("authTx.Commit: %w", err)
+	}
+
+	return emails, nil
+}
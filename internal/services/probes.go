@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultProbeExpectedStatus = 200
+	defaultProbeIntervalSec    = 60
+	defaultProbeTimeoutMs      = 5000
+)
+
+var ErrInvalidProbe = errors.New("invalid probe")
+
+type Probes interface {
+	Create(ctx context.Context, params models.Probe) (models.Probe, error)
+	Read(ctx context.Context) (ReadProbesResult, error)
+	Update(ctx context.Context, params UpdateProbeParams) error
+	Delete(ctx context.Context, id int32) error
+	// Enabled returns the cached probes the device checker runs.
+	Enabled() []models.Probe
+	UpdateProbes()
+}
+
+type ProbesService struct {
+	repo repo.Probes
+
+	probesMutex sync.RWMutex
+	probes      []models.Probe
+
+	log *zap.Logger
+}
+
+func NewProbesService(r repo.Probes, log *zap.Logger) *ProbesService {
+	service := &ProbesService{
+		repo: r,
+		log:  log,
+	}
+
+	service.UpdateProbes()
+	return service
+}
+
+// Create fills unset numeric fields with the defaults and fails with
+// ErrInvalidProbe for an unknown type, a body regexp that does not compile
+// or a tcp or dns probe without a target.
+func (s *ProbesService) Create(ctx context.Context, params models.Probe) (models.Probe, error) {
+	if params.ExpectedStatus == 0 {
+		params.ExpectedStatus = defaultProbeExpectedStatus
+	}
+	if params.IntervalSec == 0 {
+		params.IntervalSec = defaultProbeIntervalSec
+	}
+	if params.TimeoutMs == 0 {
+		params.TimeoutMs = defaultProbeTimeoutMs
+	}
+
+	if err := validateProbe(params); err != nil {
+		return models.Probe{}, err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return models.Probe{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Create(ctx, params)
+	if err != nil {
+		return models.Probe{}, fmt.Errorf("tx.Create: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Probe{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.UpdateProbes()
+
+	return ret, nil
+}
+
+type ReadProbesResult struct {
+	Probes []models.Probe
+}
+
+func (s *ProbesService) Read(ctx context.Context) (ReadProbesResult, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return ReadProbesResult{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Read(ctx)
+	if err != nil {
+		return ReadProbesResult{}, fmt.Errorf("tx.Read: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ReadProbesResult{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ReadProbesResult{
+		Probes: ret.Probes,
+	}, nil
+}
+
+type UpdateProbeParams struct {
+	ID             int32
+	ProbeType      *string
+	Target         *string
+	ExpectedStatus *int32
+	BodyRegexp     *string
+	IntervalSec    *int32
+	TimeoutMs      *int32
+	Enabled        *bool
+}
+
+// Update fails with ErrInvalidProbe when the stored probe with the changes
+// applied would not pass the checks of Create.
+func (s *ProbesService) Update(ctx context.Context, params UpdateProbeParams) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	probe, err := tx.Get(ctx, params.ID)
+	if err != nil {
+		return fmt.Errorf("tx.Get: %w", err)
+	}
+
+	if params.ProbeType != nil {
+		probe.ProbeType = *params.ProbeType
+	}
+	if params.Target != nil {
+		probe.Target = *params.Target
+	}
+	if params.BodyRegexp != nil {
+		probe.BodyRegexp = *params.BodyRegexp
+	}
+	if err := validateProbe(probe); err != nil {
+		return err
+	}
+
+	err = tx.Update(ctx, repo.UpdateProbeOpts{
+		ID:             params.ID,
+		ProbeType:      params.ProbeType,
+		Target:         params.Target,
+		ExpectedStatus: params.ExpectedStatus,
+		BodyRegexp:     params.BodyRegexp,
+		IntervalSec:    params.IntervalSec,
+		TimeoutMs:      params.TimeoutMs,
+		Enabled:        params.Enabled,
+	})
+	if err != nil {
+		return fmt.Errorf("tx.Update: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.UpdateProbes()
+
+	return nil
+}
+
+func (s *ProbesService) Delete(ctx context.Context, id int32) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("tx.Delete: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.UpdateProbes()
+
+	return nil
+}
+
+func (s *ProbesService) Enabled() []models.Probe {
+	s.probesMutex.RLock()
+	defer s.probesMutex.RUnlock()
+
+	return s.probes
+}
+
+// UpdateProbes reloads the enabled probes Enabled returns and compiles
+// their body regexps.
+func (s *ProbesService) UpdateProbes() {
+	tx, err := s.repo.BeginTx(context.Background())
+	if err != nil {
+		s.log.Error("tx.BeginTx", zap.Error(err))
+		return
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Read(context.Background())
+	if err != nil {
+		s.log.Error("tx.Read", zap.Error(err))
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		s.log.Error("tx.Commit", zap.Error(err))
+		return
+	}
+
+	probes := make([]models.Probe, 0, len(ret.Probes))
+	for _, p := range ret.Probes {
+		if !p.Enabled {
+			continue
+		}
+		if p.BodyRegexp != "" {
+			// Checked on create and update, so only a row edited by hand
+			// ends up here.
+			p.CompiledRegexp, err = regexp.Compile(p.BodyRegexp)
+			if err != nil {
+				s.log.Warn("regexp.Compile", zap.Error(err), zap.Int32("probe_id", p.ID))
+				continue
+			}
+		}
+		probes = append(probes, p)
+	}
+
+	s.probesMutex.Lock()
+	s.probes = probes
+	s.probesMutex.Unlock()
+}
+
+func validateProbe(probe models.Probe) error {
+	switch probe.ProbeType {
+	case models.ProbeTypeHTTP, models.ProbeTypeTCP, models.ProbeTypeDNS:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidProbe, probe.ProbeType)
+	}
+
+	if probe.BodyRegexp != "" {
+		if _, err := regexp.Compile(probe.BodyRegexp); err != nil {
+			return fmt.Errorf("%w: body_regexp: %w", ErrInvalidProbe, err)
+		}
+	}
+
+	if probe.ProbeType != models.ProbeTypeHTTP && probe.Target == "" {
+		return fmt.Errorf("%w: %s probe needs a target", ErrInvalidProbe, probe.ProbeType)
+	}
+
+	return nil
+}
@@ -0,0 +1,731 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/mail"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+
+	"github.com/robfig/cron/v3"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidReportSubscription = errors.New("invalid report subscription")
+	ErrReportSubscriptionRunning = errors.New("report subscription is already running")
+	ErrReportSubscriptionFailed  = errors.New("report subscription run failed")
+
+	errReportMailerMissing = errors.New("smtp is not configured")
+)
+
+const (
+	defaultReportSubscriptionPeriod = 24 * time.Hour
+	maxReportSubscriptionPeriod     = 366 * 24 * time.Hour
+
+	// reportSubscriptionTimeout bounds a run from the first query to the
+	// mail being accepted.
+	reportSubscriptionTimeout = 5 * time.Minute
+	// reportSubscriptionPageSize is how many messages a run reads at a time,
+	// and reportSubscriptionMaxMessages how many it attaches at most.
+	reportSubscriptionPageSize    = 1000
+	reportSubscriptionMaxMessages = 100000
+	// reportSubscriptionPreviewRows is how many rows the body shows; the
+	// attachment has all of them.
+	reportSubscriptionPreviewRows = 50
+
+	mimeCSV  = "text/csv; charset=utf-8"
+	mimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// ReportMailer sends a report with the file attached.
+type ReportMailer interface {
+	MailReport(
+		ctx context.Context,
+		to []string,
+		subject, text, html string,
+		filename, contentType string,
+		data []byte,
+	) error
+}
+
+type ReportSubscriptions interface {
+	// Create and Update fail with ErrInvalidReportSubscription for an
+	// unknown report or format, a malformed schedule, recipient or
+	// parameter.
+	Create(ctx context.Context, params models.ReportSubscription) (models.ReportSubscription, error)
+	Read(ctx context.Context) ([]models.ReportSubscription, error)
+	Update(ctx context.Context, params UpdateReportSubscriptionParams) (models.ReportSubscription, error)
+	Delete(ctx context.Context, id int32) error
+	// RunNow mails the report at once, disabled or not, and returns the
+	// subscription with the result of the run. A failed run is recorded
+	// and returned wrapped in ErrReportSubscriptionFailed.
+	RunNow(ctx context.Context, id int32) (models.ReportSubscription, error)
+	Close(ctx context.Context) error
+}
+
+// ReportSubscriptionsService runs every enabled subscription on its
+// schedule. The schedules are loaded at start and kept in step with the
+// changes made through the service.
+type ReportSubscriptionsService struct {
+	repo         repo.ReportSubscriptions
+	messages     Messages
+	deviceStatus DeviceStatus
+	mailer       ReportMailer
+
+	cron *cron.Cron
+
+	mu      sync.Mutex
+	entries map[int32]cron.EntryID
+	running map[int32]struct{}
+
+	log *zap.Logger
+}
+
+type ReportSubscriptionsServiceConfig struct {
+	Repo         repo.ReportSubscriptions
+	Messages     Messages
+	DeviceStatus DeviceStatus
+	// Mailer is optional; without it every run fails.
+	Mailer ReportMailer
+	Log    *zap.Logger
+}
+
+func NewReportSubscriptionsService(cfg ReportSubscriptionsServiceConfig) (*ReportSubscriptionsService, error) {
+	service := &ReportSubscriptionsService{
+		repo:         cfg.Repo,
+		messages:     cfg.Messages,
+		deviceStatus: cfg.DeviceStatus,
+		mailer:       cfg.Mailer,
+		cron:         cron.New(),
+		entries:      make(map[int32]cron.EntryID),
+		running:      make(map[int32]struct{}),
+		log:          cfg.Log,
+	}
+
+	subscriptions, err := service.Read(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("service.Read: %w", err)
+	}
+	for _, subscription := range subscriptions {
+		service.schedule(subscription)
+	}
+
+	service.cron.Start()
+
+	return service, nil
+}
+
+func (s *ReportSubscriptionsService) Create(
+	ctx context.Context,
+	params models.ReportSubscription,
+) (models.ReportSubscription, error) {
+	if err := normalizeReportSubscription(&params); err != nil {
+		return models.ReportSubscription{}, err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return models.ReportSubscription{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Create(ctx, params)
+	if err != nil {
+		return models.ReportSubscription{}, fmt.Errorf("tx.Create: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.ReportSubscription{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.schedule(ret)
+
+	return ret, nil
+}
+
+func (s *ReportSubscriptionsService) Read(ctx context.Context) ([]models.ReportSubscription, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tx.Read: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+// UpdateReportSubscriptionParams leaves nil fields as they are.
+type UpdateReportSubscriptionParams struct {
+	ID         int32
+	Name       *string
+	Report     *string
+	Params     *models.ReportSubscriptionParams
+	Schedule   *string
+	Recipients []string
+	Format     *string
+	Enabled    *bool
+}
+
+func (s *ReportSubscriptionsService) Update(
+	ctx context.Context,
+	params UpdateReportSubscriptionParams,
+) (models.ReportSubscription, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return models.ReportSubscription{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	subscription, err := tx.Get(ctx, params.ID)
+	if err != nil {
+		return models.ReportSubscription{}, fmt.Errorf("tx.Get: %w", err)
+	}
+
+	if params.Name != nil {
+		subscription.Name = *params.Name
+	}
+	if params.Report != nil {
+		subscription.Report = *params.Report
+	}
+	if params.Params != nil {
+		subscription.Params = *params.Params
+	}
+	if params.Schedule != nil {
+		subscription.Schedule = *params.Schedule
+	}
+	if params.Recipients != nil {
+		subscription.Recipients = params.Recipients
+	}
+	if params.Format != nil {
+		subscription.Format = *params.Format
+	}
+	if params.Enabled != nil {
+		subscription.Enabled = *params.Enabled
+	}
+
+	if err := normalizeReportSubscription(&subscription); err != nil {
+		return models.ReportSubscription{}, err
+	}
+
+	ret, err := tx.Update(ctx, subscription)
+	if err != nil {
+		return models.ReportSubscription{}, fmt.Errorf("tx.Update: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.ReportSubscription{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.schedule(ret)
+
+	return ret, nil
+}
+
+func (s *ReportSubscriptionsService) Delete(ctx context.Context, id int32) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("tx.Delete: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.unschedule(id)
+
+	return nil
+}
+
+func (s *ReportSubscriptionsService) RunNow(ctx context.Context, id int32) (models.ReportSubscription, error) {
+	subscription, err := s.get(ctx, id)
+	if err != nil {
+		return models.ReportSubscription{}, err
+	}
+
+	runErr := s.run(ctx, subscription)
+
+	subscription, err = s.get(ctx, id)
+	if err != nil {
+		return models.ReportSubscription{}, err
+	}
+
+	return subscription, runErr
+}
+
+// Close stops scheduling runs and waits for the running ones.
+func (s *ReportSubscriptionsService) Close(ctx context.Context) error {
+	select {
+	case <-s.cron.Stop().Done():
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("report subscriptions stop: %w", ctx.Err())
+	}
+}
+
+func (s *ReportSubscriptionsService) get(ctx context.Context, id int32) (models.ReportSubscription, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return models.ReportSubscription{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Get(ctx, id)
+	if err != nil {
+		return models.ReportSubscription{}, fmt.Errorf("tx.Get: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.ReportSubscription{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+// schedule replaces the cron entry of the subscription. The entry reads the
+// subscription again when it fires, so a run always uses the stored
+// definition.
+func (s *ReportSubscriptionsService) schedule(subscription models.ReportSubscription) {
+	s.unschedule(subscription.ID)
+	if !subscription.Enabled {
+		return
+	}
+
+	id := subscription.ID
+	entryID, err := s.cron.AddFunc(subscription.Schedule, func() { s.runScheduled(id) })
+	if err != nil {
+		s.log.Error("s.cron.AddFunc",
+			zap.Int32("subscription_id", id),
+			zap.String("schedule", subscription.Schedule),
+			zap.Error(err),
+		)
+		return
+	}
+
+	s.mu.Lock()
+	s.entries[id] = entryID
+	s.mu.Unlock()
+}
+
+func (s *ReportSubscriptionsService) unschedule(id int32) {
+	s.mu.Lock()
+	entryID, ok := s.entries[id]
+	delete(s.entries, id)
+	s.mu.Unlock()
+
+	if ok {
+		s.cron.Remove(entryID)
+	}
+}
+
+func (s *ReportSubscriptionsService) runScheduled(id int32) {
+	ctx := context.Background()
+
+	subscription, err := s.get(ctx, id)
+	if err != nil {
+		s.log.Error("s.get", zap.Int32("subscription_id", id), zap.Error(err))
+		return
+	}
+	if !subscription.Enabled {
+		return
+	}
+
+	if err := s.run(ctx, subscription); err != nil {
+		s.log.Error("report subscription run failed", zap.Int32("subscription_id", id), zap.Error(err))
+	}
+}
+
+// run mails the report and records the result. A subscription runs once at
+// a time; a run that overlaps is rejected rather than queued.
+func (s *ReportSubscriptionsService) run(ctx context.Context, subscription models.ReportSubscription) error {
+	s.mu.Lock()
+	if _, ok := s.running[subscription.ID]; ok {
+		s.mu.Unlock()
+		return ErrReportSubscriptionRunning
+	}
+	s.running[subscription.ID] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, subscription.ID)
+		s.mu.Unlock()
+	}()
+
+	runAt := time.Now()
+
+	runCtx, cancel := context.WithTimeout(ctx, reportSubscriptionTimeout)
+	runErr := s.deliver(runCtx, subscription, runAt)
+	cancel()
+
+	status := models.ReportRunStatusOK
+	var lastError *string
+	if runErr != nil {
+		status = models.ReportRunStatusFailed
+		lastError = lo.ToPtr(runErr.Error())
+	}
+
+	// The result is stored even when the request that started the run is
+	// gone.
+	if runErr != nil {
+		runErr = fmt.Errorf("%w: %w", ErrReportSubscriptionFailed, runErr)
+	}
+
+	if err := s.setLastRun(context.Background(), repo.SetReportSubscriptionRunOpts{
+		ID:     subscription.ID,
+		RunAt:  runAt,
+		Status: status,
+		Error:  lastError,
+	}); err != nil {
+		return errors.Join(runErr, err)
+	}
+
+	return runErr
+}
+
+func (s *ReportSubscriptionsService) setLastRun(ctx context.Context, opts repo.SetReportSubscriptionRunOpts) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.SetLastRun(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("tx.SetLastRun: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// deliver renders the report once: the attachment is written from the rows
+// as they come, and the first of them are kept for the body.
+func (s *ReportSubscriptionsService) deliver(
+	ctx context.Context,
+	subscription models.ReportSubscription,
+	runAt time.Time,
+) error {
+	if s.mailer == nil {
+		return errReportMailerMissing
+	}
+
+	var truncated bool
+	table, err := s.table(ctx, subscription, runAt, &truncated)
+	if err != nil {
+		return err
+	}
+
+	preview := make([][]string, 0, reportSubscriptionPreviewRows)
+	total := 0
+	rows := table.Rows
+	table.Rows = func(yield func([]any) error) error {
+		return rows(func(row []any) error {
+			total++
+			if len(preview) < reportSubscriptionPreviewRows {
+				cells := make([]string, len(row))
+				for i, cell := range row {
+					cells[i] = csvCell(cell)
+				}
+				preview = append(preview, cells)
+			}
+			return yield(row)
+		})
+	}
+
+	var file bytes.Buffer
+	contentType := mimeCSV
+	switch subscription.Format {
+	case models.ReportFormatXLSX:
+		contentType = mimeXLSX
+		err = table.WriteXLSX(&file)
+	default:
+		err = table.WriteCSV(&file)
+	}
+	if err != nil {
+		return fmt.Errorf("render %s: %w", subscription.Format, err)
+	}
+
+	body := reportMailBody{
+		Name:        subscription.Name,
+		Report:      subscription.Report,
+		GeneratedAt: runAt.Format(reportTimeLayout),
+		Headers:     table.Headers,
+		Rows:        preview,
+		Total:       total,
+		Truncated:   truncated,
+		MaxRows:     reportSubscriptionMaxMessages,
+	}
+
+	var html bytes.Buffer
+	if err := reportMailTemplate.Execute(&html, body); err != nil {
+		return fmt.Errorf("reportMailTemplate.Execute: %w", err)
+	}
+
+	err = s.mailer.MailReport(ctx,
+		subscription.Recipients,
+		fmt.Sprintf("%s: %s", subscription.Name, body.GeneratedAt),
+		body.text(),
+		html.String(),
+		fmt.Sprintf("%s_%s.%s", table.Name, runAt.Format("20060102_1504"), subscription.Format),
+		contentType,
+		file.Bytes(),
+	)
+	if err != nil {
+		return fmt.Errorf("s.mailer.MailReport: %w", err)
+	}
+
+	return nil
+}
+
+// table reads the report. Periods end at runAt. Message reports stop at
+// reportSubscriptionMaxMessages rows and set truncated.
+func (s *ReportSubscriptionsService) table(
+	ctx context.Context,
+	subscription models.ReportSubscription,
+	runAt time.Time,
+	truncated *bool,
+) (ReportTable, error) {
+	params := subscription.Params
+	period := reportSubscriptionPeriod(params)
+
+	switch subscription.Report {
+	case models.ReportMessagesByPeriod:
+		opts := MessagesGetAllByPeriodOpts{
+			StartTime: runAt.Add(-period),
+			EndTime:   runAt,
+			TimeBasis: params.TimeBasis,
+			Filter: repo.MessagesFilter{
+				Severities: params.Severities,
+			},
+			Page: ReportPage{
+				Limit: reportSubscriptionPageSize,
+			},
+		}
+		if params.DeviceID != 0 {
+			opts.Filter.DeviceIDs = []int32{params.DeviceID}
+		}
+		if params.MessageType != "" {
+			opts.Filter.MessageTypes = []string{params.MessageType}
+		}
+
+		first, page, err := s.messages.GetAllByPeriod(opts)
+		if err != nil {
+			return ReportTable{}, fmt.Errorf("s.messages.GetAllByPeriod: %w", err)
+		}
+
+		fetched := len(first)
+		next := func(cursor string) ([]ReportGetAllByPeriod, string, error) {
+			if fetched >= reportSubscriptionMaxMessages {
+				*truncated = true
+				return nil, "", nil
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, "", fmt.Errorf("ctx.Err: %w", err)
+			}
+
+			opts.Page.Cursor = cursor
+			res, page, err := s.messages.GetAllByPeriod(opts)
+			if err != nil {
+				return nil, "", fmt.Errorf("s.messages.GetAllByPeriod: %w", err)
+			}
+			fetched += len(res)
+			return res, page.NextCursor, nil
+		}
+
+		return MessagesTable(models.ReportMessagesByPeriod, first, page.NextCursor, next), nil
+
+	case models.ReportCountByMessageType:
+		res, err := s.messages.GetCountByMessageType(params.MessageType, params.GroupID)
+		if err != nil {
+			return ReportTable{}, fmt.Errorf("s.messages.GetCountByMessageType: %w", err)
+		}
+		return CountByMessageTypeTable(res), nil
+
+	case models.ReportMonth:
+		timeBasis := params.TimeBasis
+		if timeBasis == "" {
+			timeBasis = models.TimeBasisReceived
+		}
+		res, err := s.messages.MonthReport(timeBasis, params.GroupID)
+		if err != nil {
+			return ReportTable{}, fmt.Errorf("s.messages.MonthReport: %w", err)
+		}
+		return MonthReportTable(res, s.messages.Device), nil
+
+	case models.ReportAvailability:
+		res, err := s.deviceStatus.Availability(ctx, AvailabilityParams{
+			StartTime: runAt.Add(-period),
+			EndTime:   runAt,
+			DeviceID:  params.DeviceID,
+		})
+		if err != nil {
+			return ReportTable{}, fmt.Errorf("s.deviceStatus.Availability: %w", err)
+		}
+		return AvailabilityTable(res, s.messages.Device), nil
+
+	default:
+		return ReportTable{}, fmt.Errorf("%w: unknown report %q", ErrInvalidReportSubscription, subscription.Report)
+	}
+}
+
+func reportSubscriptionPeriod(params models.ReportSubscriptionParams) time.Duration {
+	period, err := time.ParseDuration(params.Period)
+	if err != nil || period <= 0 {
+		return defaultReportSubscriptionPeriod
+	}
+	return period
+}
+
+// normalizeReportSubscription validates the subscription and brings the
+// recipients to bare, unique addresses.
+func normalizeReportSubscription(subscription *models.ReportSubscription) error {
+	subscription.Name = strings.TrimSpace(subscription.Name)
+	if subscription.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidReportSubscription)
+	}
+
+	switch subscription.Report {
+	case models.ReportMessagesByPeriod, models.ReportCountByMessageType, models.ReportMonth, models.ReportAvailability:
+	default:
+		return fmt.Errorf("%w: unknown report %q", ErrInvalidReportSubscription, subscription.Report)
+	}
+
+	if subscription.Format == "" {
+		subscription.Format = models.ReportFormatCSV
+	}
+	if subscription.Format != models.ReportFormatCSV && subscription.Format != models.ReportFormatXLSX {
+		return fmt.Errorf("%w: unknown format %q", ErrInvalidReportSubscription, subscription.Format)
+	}
+
+	subscription.Schedule = strings.TrimSpace(subscription.Schedule)
+	if _, err := cron.ParseStandard(subscription.Schedule); err != nil {
+		return fmt.Errorf("%w: schedule: %w", ErrInvalidReportSubscription, err)
+	}
+
+	recipients := make([]string, 0, len(subscription.Recipients))
+	for _, recipient := range subscription.Recipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("%w: recipient %q: %w", ErrInvalidReportSubscription, recipient, err)
+		}
+		if !slices.Contains(recipients, address.Address) {
+			recipients = append(recipients, address.Address)
+		}
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("%w: no recipients", ErrInvalidReportSubscription)
+	}
+	subscription.Recipients = recipients
+
+	return validateReportSubscriptionParams(subscription.Report, subscription.Params)
+}
+
+func validateReportSubscriptionParams(report string, params models.ReportSubscriptionParams) error {
+	if params.Period != "" {
+		period, err := time.ParseDuration(params.Period)
+		if err != nil {
+			return fmt.Errorf("%w: period: %w", ErrInvalidReportSubscription, err)
+		}
+		if period <= 0 || period > maxReportSubscriptionPeriod {
+			return fmt.Errorf("%w: period must be positive and at most %s",
+				ErrInvalidReportSubscription, maxReportSubscriptionPeriod)
+		}
+	}
+
+	switch params.TimeBasis {
+	case "", models.TimeBasisReceived, models.TimeBasisEvent:
+	default:
+		return fmt.Errorf("%w: unknown time basis %q", ErrInvalidReportSubscription, params.TimeBasis)
+	}
+
+	if params.GroupID < 0 || params.DeviceID < 0 {
+		return fmt.Errorf("%w: ids must not be negative", ErrInvalidReportSubscription)
+	}
+
+	if report == models.ReportCountByMessageType && params.MessageType == "" {
+		return fmt.Errorf("%w: %s needs a message type", ErrInvalidReportSubscription, report)
+	}
+
+	return nil
+}
+
+type reportMailBody struct {
+	Name        string
+	Report      string
+	GeneratedAt string
+	Headers     []string
+	Rows        [][]string
+	Total       int
+	Truncated   bool
+	MaxRows     int
+}
+
+func (b reportMailBody) text() string {
+	var text strings.Builder
+
+	fmt.Fprintf(&text, "%s\n\nReport: %s\nGenerated at: %s\nRows: %d\n", b.Name, b.Report, b.GeneratedAt, b.Total)
+	if b.Truncated {
+		fmt.Fprintf(&text, "The report is cut at %d rows.\n", b.MaxRows)
+	}
+	text.WriteString("\nThe report is attached.\n")
+
+	return text.String()
+}
+
+var reportMailTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; font-size: 14px;">
+<h2>{{.Name}}</h2>
+<p>Report: {{.Report}}<br>Generated at: {{.GeneratedAt}}<br>Rows: {{.Total}}</p>
+{{- if .Truncated}}
+<p>The report is cut at {{.MaxRows}} rows.</p>
+{{- end}}
+{{- if .Rows}}
+<table border="1" cellpadding="4" cellspacing="0" style="border-collapse: collapse;">
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- if gt .Total (len .Rows)}}
+<p>Showing the first {{len .Rows}} rows; the full report is attached.</p>
+{{- end}}
+{{- else}}
+<p>The report is empty.</p>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"monolith/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// reportFlushRows is how many CSV rows are buffered before they are
+	// flushed to a writer that can flush.
+	reportFlushRows = 500
+
+	reportTimeLayout = "2006-01-02 15:04:05"
+
+	// csvFormulaPrefixes are the first characters that make spreadsheets
+	// read a cell as a formula.
+	csvFormulaPrefixes = "=+-@\t\r"
+)
+
+// ReportTable is a report rendered as a spreadsheet. A cell holds a string,
+// a number, a time.Time or nil for no value.
+type ReportTable struct {
+	// Name is the file name without extension and the sheet name.
+	Name    string
+	Headers []string
+	// Rows calls yield for every row in order. An error of yield stops the
+	// iteration and is returned as is.
+	Rows func(yield func([]any) error) error
+}
+
+// WriteCSV writes the rows as they are produced. A writer with a Flush
+// method, such as a bufio.Writer, is flushed every reportFlushRows rows.
+func (t ReportTable) WriteCSV(w io.Writer) error {
+	flusher, _ := w.(interface{ Flush() error })
+	cw := csv.NewWriter(w)
+
+	flush := func() error {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("cw.Flush: %w", err)
+		}
+		if flusher != nil {
+			if err := flusher.Flush(); err != nil {
+				return fmt.Errorf("flusher.Flush: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if err := cw.Write(t.Headers); err != nil {
+		return fmt.Errorf("cw.Write: %w", err)
+	}
+
+	record := make([]string, len(t.Headers))
+	written := 0
+	err := t.Rows(func(row []any) error {
+		for i, cell := range row {
+			record[i] = csvCell(cell)
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("cw.Write: %w", err)
+		}
+
+		written++
+		if written%reportFlushRows == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		_ = flush()
+		return err
+	}
+
+	return flush()
+}
+
+// WriteXLSX fills the sheet through the excelize stream writer, which keeps
+// large sheets on disk rather than in memory, and writes the workbook once
+// the last row is in.
+func (t ReportTable) WriteXLSX(w io.Writer) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName(f.GetSheetName(0), t.Name); err != nil {
+		return fmt.Errorf("f.SetSheetName: %w", err)
+	}
+
+	sw, err := f.NewStreamWriter(t.Name)
+	if err != nil {
+		return fmt.Errorf("f.NewStreamWriter: %w", err)
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("f.NewStyle: %w", err)
+	}
+
+	headers := make([]any, 0, len(t.Headers))
+	for _, header := range t.Headers {
+		headers = append(headers, excelize.Cell{StyleID: bold, Value: header})
+	}
+	if err := sw.SetRow("A1", headers); err != nil {
+		return fmt.Errorf("sw.SetRow: %w", err)
+	}
+
+	rowNum := 1
+	err = t.Rows(func(row []any) error {
+		rowNum++
+		cell, err := excelize.CoordinatesToCellName(1, rowNum)
+		if err != nil {
+			return fmt.Errorf("excelize.CoordinatesToCellName: %w", err)
+		}
+		if err := sw.SetRow(cell, row); err != nil {
+			return fmt.Errorf("sw.SetRow: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := sw.Flush(); err != nil {
+		return fmt.Errorf("sw.Flush: %w", err)
+	}
+	if err := f.Write(w); err != nil {
+		return fmt.Errorf("f.Write: %w", err)
+	}
+
+	if flusher, ok := w.(interface{ Flush() error }); ok {
+		return flusher.Flush() //nolint:wrapcheck
+	}
+	return nil
+}
+
+// csvCell renders a cell for CSV and the mail preview. A text starting like
+// a formula gets a leading quote, so a spreadsheet opening the file shows it
+// as text instead of evaluating it.
+func csvCell(cell any) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		if v != "" && strings.ContainsRune(csvFormulaPrefixes, rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case time.Time:
+		return v.Format(reportTimeLayout)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// SliceRows yields the rows of an already loaded report.
+func SliceRows[T any](items []T, row func(T) []any) func(yield func([]any) error) error {
+	return func(yield func([]any) error) error {
+		for _, item := range items {
+			if err := yield(row(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// PagedRows yields the rows of the first page and then reads the following
+// pages with next until a page comes without a cursor.
+func PagedRows[T any](
+	first []T,
+	cursor string,
+	next func(cursor string) ([]T, string, error),
+	row func(T) []any,
+) func(yield func([]any) error) error {
+	return func(yield func([]any) error) error {
+		page := first
+		for {
+			for _, item := range page {
+				if err := yield(row(item)); err != nil {
+					return err
+				}
+			}
+			if cursor == "" {
+				return nil
+			}
+
+			var err error
+			page, cursor, err = next(cursor)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// MessagesTable renders a message report read page by page.
+func MessagesTable[T ReportGetAllByPeriod | ReportGetAllByDeviceId](
+	name string,
+	first []T,
+	cursor string,
+	next func(cursor string) ([]T, string, error),
+) ReportTable {
+	return ReportTable{
+		Name: name,
+		Headers: []string{
+			"Received at", "Event time", "Device ID", "Device name", "Device type", "Address",
+			"Message type", "Severity", "Component", "Message",
+		},
+		Rows: PagedRows(first, cursor, next, func(m T) []any {
+			r := ReportGetAllByPeriod(m)
+			return []any{
+				r.GotAt, timeCell(r.EventAt), r.DeviceID, r.Name, r.DeviceType, r.Address,
+				r.MessageType, r.SeverityLevel, r.Component, r.Message,
+			}
+		}),
+	}
+}
+
+func CountByMessageTypeTable(rows []ReportGetCountByMessageType) ReportTable {
+	return ReportTable{
+		Name: "count_by_message_type",
+		Headers: []string{
+			"Device ID", "Device name", "Device type", "Address", "Messages",
+		},
+		Rows: SliceRows(rows, func(r ReportGetCountByMessageType) []any {
+			return []any{r.DeviceID, r.Name, r.DeviceType, r.Address, r.Count}
+		}),
+	}
+}
+
+// MonthReportTable names the devices with device, since the rows only hold
+// their ids.
+func MonthReportTable(rows []models.MonthReportRow, device func(deviceID int32) models.Device) ReportTable {
+	return ReportTable{
+		Name: "month_report",
+		Headers: []string{
+			"Device ID", "Device name", "Device type", "Message type", "Active days",
+			"Total messages", "Avg daily messages", "Max daily messages", "Median daily messages",
+			"Total critical", "Max daily critical", "Max daily components", "Most active component",
+			"First critical at", "Last critical at", "Avg interval between criticals, s",
+			"Critical, %", "Volume rank",
+		},
+		Rows: SliceRows(rows, func(r models.MonthReportRow) []any {
+			d := device(r.DeviceID)
+			return []any{
+				r.DeviceID, d.Name, d.DeviceType, r.MessageType, r.ActiveDays,
+				r.TotalMessages, r.AvgDailyMessages, r.MaxDailyMessages, r.MedianDailyMessages,
+				r.TotalCritical, r.MaxDailyCritical, r.MaxDailyComponents, nullStringCell(r.MostActiveComponent),
+				nullTimeCell(r.FirstCriticalTime), nullTimeCell(r.LastCriticalTime), nullFloatCell(r.AvgCriticalIntervalSec),
+				r.CriticalPercentage, r.OverallVolumeRank,
+			}
+		}),
+	}
+}
+
+// PeriodReportTable leaves the columns of dimensions the report is not
+// grouped by empty.
+func PeriodReportTable(rows []models.PeriodReportRow, device func(deviceID int32) models.Device) ReportTable {
+	return ReportTable{
+		Name: "period_report",
+		Headers: []string{
+			"Device ID", "Device name", "Device type", "Message type", "Component", "Active days",
+			"Total messages", "Avg daily messages", "Max daily messages", "Median daily messages",
+			"Total critical", "Max daily critical", "Max daily components", "Most active component",
+			"First critical at", "Last critical at", "Avg interval between criticals, s",
+			"Critical, %", "Volume rank",
+		},
+		Rows: SliceRows(rows, func(r models.PeriodReportRow) []any {
+			var deviceID, name, deviceType any
+			if r.DeviceID.Valid {
+				d := device(r.DeviceID.Int32)
+				deviceID, name, deviceType = r.DeviceID.Int32, d.Name, d.DeviceType
+			}
+			return []any{
+				deviceID, name, deviceType, nullStringCell(r.MessageType), nullStringCell(r.Component), r.ActiveDays,
+				r.TotalMessages, r.AvgDailyMessages, r.MaxDailyMessages, r.MedianDailyMessages,
+				r.TotalCritical, r.MaxDailyCritical, r.MaxDailyComponents, nullStringCell(r.MostActiveComponent),
+				nullTimeCell(r.FirstCriticalTime), nullTimeCell(r.LastCriticalTime), nullFloatCell(r.AvgCriticalIntervalSec),
+				r.CriticalPercentage, r.OverallVolumeRank,
+			}
+		}),
+	}
+}
+
+func AvailabilityTable(rows []models.AvailabilityReportRow, device func(deviceID int32) models.Device) ReportTable {
+	return ReportTable{
+		Name: "availability",
+		Headers: []string{
+			"Device ID", "Device name", "Device type", "Uptime, %", "Monitored, s", "Up, s",
+			"Degraded, s", "Down, s", "Outages", "Longest outage, s", "MTTR, s",
+		},
+		Rows: SliceRows(rows, func(r models.AvailabilityReportRow) []any {
+			d := device(r.DeviceID)
+			return []any{
+				r.DeviceID, d.Name, d.DeviceType, floatCell(r.UptimePercent), r.MonitoredSec, r.UpSec,
+				r.DegradedSec, r.DownSec, r.Outages, r.LongestOutageSec, floatCell(r.MTTRSec),
+			}
+		}),
+	}
+}
+
+// Cells of nullable values are left empty.
+
+func timeCell(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+func nullStringCell(s sql.NullString) any {
+	if !s.Valid {
+		return nil
+	}
+	return s.String
+}
+
+func nullTimeCell(t sql.NullTime) any {
+	if !t.Valid {
+		return nil
+	}
+	return t.Time
+}
+
+func nullFloatCell(f sql.NullFloat64) any {
+	if !f.Valid {
+		return nil
+	}
+	return f.Float64
+}
+
+func floatCell(f *float64) any {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		name string
+		cell any
+		want string
+	}{
+		{name: "nil", cell: nil, want: ""},
+		{name: "text", cell: "link down", want: "link down"},
+		{name: "empty text", cell: "", want: ""},
+		{name: "formula", cell: "=HYPERLINK(\"http://x\")", want: "'=HYPERLINK(\"http://x\")"},
+		{name: "plus", cell: "+1", want: "'+1"},
+		{name: "minus", cell: "-1+2", want: "'-1+2"},
+		{name: "at", cell: "@SUM(A1)", want: "'@SUM(A1)"},
+		{name: "tab", cell: "\t=1", want: "'\t=1"},
+		{name: "negative number", cell: float64(-1.5), want: "-1.5"},
+		{name: "integer", cell: int32(-7), want: "-7"},
+		{name: "time", cell: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC), want: "2024-03-01 10:30:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvCell(tt.cell); got != tt.want {
+				t.Errorf("csvCell(%#v) = %q, want %q", tt.cell, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	table := ReportTable{
+		Name:    "report",
+		Headers: []string{"Device", "Message"},
+		Rows: SliceRows([][]any{
+			{"router", "=cmd|' /C calc'!A0"},
+			{"switch", nil},
+		}, func(row []any) []any { return row }),
+	}
+
+	var out strings.Builder
+	if err := table.WriteCSV(&out); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	want := "Device,Message\nrouter,'=cmd|' /C calc'!A0\nswitch,\n"
+	if out.String() != want {
+		t.Errorf("WriteCSV wrote %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRollupInterval   = 5 * time.Minute
+	defaultRollupLateWindow = time.Hour
+
+	// rollupBackfillDays is how many days one transaction rolls up while the
+	// rollups catch up with older messages.
+	rollupBackfillDays = 7
+)
+
+// initRollups schedules the daily message rollups on the cron of
+// initIdempotency. A run that takes longer than the interval, e.g. the
+// first one over a long history, is not overlapped by the next.
+func (ms *MessagesService) initRollups(cfg MessagesServiceConfig) {
+	if cfg.RollupInterval <= 0 {
+		cfg.RollupInterval = defaultRollupInterval
+	}
+	if cfg.RollupLateWindow <= 0 {
+		cfg.RollupLateWindow = defaultRollupLateWindow
+	}
+
+	ms.rollupLateWindow = cfg.RollupLateWindow
+
+	job := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(cron.FuncJob(ms.refreshRollups))
+	if _, err := ms.cron.AddJob("@every "+cfg.RollupInterval.String(), job); err != nil {
+		ms.log.Error("ms.cron.AddJob", zap.Error(err))
+	}
+}
+
+// refreshRollups recomputes the days marked dirty and rolls up the days that
+// ended more than the late window ago, a few days per transaction.
+func (ms *MessagesService) refreshRollups() {
+	cutoff := rollupDay(time.Now().Add(-ms.rollupLateWindow))
+
+	for {
+		done, err := ms.rollupDays(cutoff)
+		if err != nil {
+			ms.log.Error("ms.rollupDays", zap.Error(err))
+			return
+		}
+		if done {
+			return
+		}
+	}
+}
+
+// rollupDays reports whether the rollups reach cutoff.
+func (ms *MessagesService) rollupDays(cutoff time.Time) (bool, error) {
+	ctx := context.Background()
+
+	tx, err := ms.messageRepo.BeginTx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("ms.messageRepo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	state, err := tx.RollupState(ctx)
+	if err != nil {
+		return false, fmt.Errorf("tx.RollupState: %w", err)
+	}
+	completeUntil := rollupDay(state.CompleteUntil)
+
+	dirty, err := tx.TakeDirtyRollupDays(ctx)
+	if err != nil {
+		return false, fmt.Errorf("tx.TakeDirtyRollupDays: %w", err)
+	}
+	for _, day := range dirty {
+		day = rollupDay(day)
+		if !day.Before(completeUntil) {
+			continue
+		}
+		if _, err := tx.RollupDays(ctx, day, day.AddDate(0, 0, 1)); err != nil {
+			return false, fmt.Errorf("tx.RollupDays: %w", err)
+		}
+	}
+
+	until := completeUntil.AddDate(0, 0, rollupBackfillDays)
+	if cutoff.Before(until) {
+		until = cutoff
+	}
+	if until.After(completeUntil) {
+		written, err := tx.RollupDays(ctx, completeUntil, until)
+		if err != nil {
+			return false, fmt.Errorf("tx.RollupDays: %w", err)
+		}
+		if err := tx.SetRollupCompleteUntil(ctx, until); err != nil {
+			return false, fmt.Errorf("tx.SetRollupCompleteUntil: %w", err)
+		}
+
+		ms.log.Debug("rolled up messages",
+			zap.Time("from", completeUntil),
+			zap.Time("until", until),
+			zap.Int64("rows", written),
+		)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return !until.Before(cutoff), nil
+}
+
+// rollupDay is the local midnight of the calendar date of t. got_at holds
+// the local receive time, so rollup days start at local midnight; dates read
+// from the database come as UTC midnight and keep their date.
+func rollupDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidSearch = errors.New("invalid search")
+
+type (
+	// SearchMessagesOpts searches the message text. Query takes words, which
+	// must all match, "quoted phrases", prefixes ending with *, terms
+	// excluded with a leading - and OR between alternatives, e.g.
+	// `"fan failure" OR overheat* -test`. A zero StartTime or EndTime
+	// leaves that side of the period open.
+	SearchMessagesOpts struct {
+		Query     string
+		StartTime time.Time
+		EndTime   time.Time
+		// TimeBasis is models.TimeBasisReceived or models.TimeBasisEvent.
+		TimeBasis string
+		Filter    repo.MessagesFilter
+		// OrderBy is models.SearchOrderRank, the default, or
+		// models.SearchOrderTime.
+		OrderBy string
+		Page    ReportPage
+	}
+
+	// ReportSearchHit is a message of a search. Snippet is HTML with the
+	// matches in <mark>; Rank is higher for better matches.
+	ReportSearchHit struct {
+		ID            int32
+		DeviceID      int32
+		Name          string
+		DeviceType    string
+		Address       string
+		Responsible   []int32
+		GotAt         time.Time
+		EventAt       *time.Time
+		Message       string
+		MessageType   string
+		SeverityLevel string
+		Component     string
+		Rank          float32
+		Snippet       string
+	}
+)
+
+func (ms *MessagesService) Search(opts SearchMessagesOpts) ([]ReportSearchHit, ReportPageInfo, error) {
+	if strings.Trim(opts.Query, " \t\r\n\"*-") == "" {
+		return nil, ReportPageInfo{}, fmt.Errorf("%w: query has no words", ErrInvalidSearch)
+	}
+	if opts.OrderBy == "" {
+		opts.OrderBy = models.SearchOrderRank
+	}
+	if opts.OrderBy != models.SearchOrderRank && opts.OrderBy != models.SearchOrderTime {
+		return nil, ReportPageInfo{}, fmt.Errorf("%w: unknown order %q", ErrInvalidSearch, opts.OrderBy)
+	}
+	if !opts.StartTime.IsZero() && !opts.EndTime.IsZero() && opts.EndTime.Before(opts.StartTime) {
+		return nil, ReportPageInfo{}, fmt.Errorf("%w: end_time is before start_time", ErrInvalidSearch)
+	}
+
+	after, err := decodeSearchCursor(opts.Page.Cursor)
+	if err != nil {
+		return nil, ReportPageInfo{}, err
+	}
+
+	tx, err := ms.messageRepo.BeginTx(context.Background())
+	if err != nil {
+		ms.log.Error("tx.BeginTx", zap.Error(err))
+		return nil, ReportPageInfo{}, fmt.Errorf("ms.messageRepo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	repoOpts := repo.SearchMessagesOpts{
+		Query:     opts.Query,
+		StartTime: opts.StartTime,
+		EndTime:   opts.EndTime,
+		TimeBasis: opts.TimeBasis,
+		Filter:    opts.Filter,
+		OrderBy:   opts.OrderBy,
+		After:     after,
+		Limit:     reportFetchLimit(opts.Page.Limit),
+	}
+
+	hits, err := tx.SearchMessages(repoOpts)
+	if err != nil {
+		return nil, ReportPageInfo{}, fmt.Errorf("tx.SearchMessages: %w", err)
+	}
+
+	var info ReportPageInfo
+	hits, info.NextCursor = searchPage(hits, opts.Page.Limit, opts.TimeBasis)
+
+	if opts.Page.WithTotal {
+		total, err := tx.CountSearchMessages(repoOpts)
+		if err != nil {
+			return nil, ReportPageInfo{}, fmt.Errorf("tx.CountSearchMessages: %w", err)
+		}
+		info.Total = &total
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, ReportPageInfo{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return lo.Map(hits, func(h models.MessageSearchHit, _ int) ReportSearchHit {
+		dev := ms.Device(h.DeviceId)
+		return ReportSearchHit{
+			ID:            h.Id,
+			DeviceID:      h.DeviceId,
+			Name:          dev.Name,
+			DeviceType:    dev.DeviceType,
+			Address:       dev.Address,
+			Responsible:   dev.Responsible,
+			GotAt:         h.GotAt,
+			EventAt:       h.EventAt,
+			Message:       h.Message.Message,
+			MessageType:   h.MessageType,
+			SeverityLevel: h.SeverityLevel,
+			Component:     h.Component,
+			Rank:          h.Rank,
+			Snippet:       h.Snippet,
+		}
+	}), info, nil
+}
+
+// searchPage is reportPage for search hits.
+func searchPage(hits []models.MessageSearchHit, limit int, timeBasis string) ([]models.MessageSearchHit, string) {
+	if limit <= 0 || len(hits) <= limit {
+		return hits, ""
+	}
+
+	hits = hits[:limit]
+	last := hits[limit-1]
+
+	at := last.GotAt
+	if timeBasis == models.TimeBasisEvent && last.EventAt != nil {
+		at = *last.EventAt
+	}
+
+	return hits, encodeSearchCursor(repo.SearchMessagesCursor{
+		Rank: last.Rank,
+		Time: at,
+		ID:   last.Id,
+	})
+}
+
+// A search cursor is the bits of the rank, the report time in unix
+// nanoseconds and the message id, opaque to clients. The rank is kept as
+// bits so that it compares equal to the one postgres computed.
+func encodeSearchCursor(cursor repo.SearchMessagesCursor) string {
+	return base64.RawURLEncoding.EncodeToString(
+		fmt.Appendf(nil, "%d.%d.%d", math.Float32bits(cursor.Rank), cursor.Time.UnixNano(), cursor.ID),
+	)
+}
+
+func decodeSearchCursor(cursor string) (*repo.SearchMessagesCursor, error) {
+	if cursor == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidReportCursor
+	}
+	parts := strings.Split(string(raw), ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidReportCursor
+	}
+	rankBits, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return nil, ErrInvalidReportCursor
+	}
+	unixNano, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidReportCursor
+	}
+	messageID, err := strconv.ParseInt(parts[2], 10, 32)
+	if err != nil || messageID <= 0 {
+		return nil, ErrInvalidReportCursor
+	}
+
+	return &repo.SearchMessagesCursor{
+		Rank: math.Float32frombits(uint32(rankBits)),
+		Time: time.Unix(0, unixNano).UTC(),
+		ID:   int32(messageID),
+	}, nil
+}
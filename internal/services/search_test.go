@@ -0,0 +1,52 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"monolith/internal/repo"
+)
+
+func TestSearchCursor(t *testing.T) {
+	cursor := repo.SearchMessagesCursor{
+		Rank: 0.0607927,
+		Time: time.Date(2024, 3, 1, 10, 30, 0, 123456789, time.UTC),
+		ID:   42,
+	}
+
+	got, err := decodeSearchCursor(encodeSearchCursor(cursor))
+	if err != nil {
+		t.Fatalf("decodeSearchCursor: %v", err)
+	}
+	if got.Rank != cursor.Rank || !got.Time.Equal(cursor.Time) || got.ID != cursor.ID {
+		t.Errorf("decodeSearchCursor(encodeSearchCursor(%+v)) = %+v", cursor, *got)
+	}
+}
+
+func TestDecodeSearchCursorInvalid(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "***"},
+		{name: "report cursor", cursor: encode("1700000000000000000.7")},
+		{name: "bad rank", cursor: encode("high.1700000000000000000.7")},
+		{name: "rank out of range", cursor: encode("4294967296.1700000000000000000.7")},
+		{name: "bad time", cursor: encode("1031127695.yesterday.7")},
+		{name: "zero id", cursor: encode("1031127695.1700000000000000000.0")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeSearchCursor(tt.cursor); !errors.Is(err, ErrInvalidReportCursor) {
+				t.Errorf("decodeSearchCursor(%q) error = %v, want %v", tt.cursor, err, ErrInvalidReportCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"monolith/internal/models"
+)
+
+var ErrInvalidSelector = errors.New("invalid selector")
+
+var selectorKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_./-]*[A-Za-z0-9])?$`)
+
+// ParseSelector parses a comma separated list of requirements in the
+// Kubernetes label selector syntax: "key=value", "key==value",
+// "key!=value", "key in (a,b)", "key notin (a,b)", "key" and "!key". An
+// empty selector has no requirements.
+func ParseSelector(selector string) ([]models.SelectorRequirement, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, nil
+	}
+
+	terms, err := splitSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	requirements := make([]models.SelectorRequirement, 0, len(terms))
+	for _, term := range terms {
+		req, err := parseSelectorTerm(strings.TrimSpace(term))
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, req)
+	}
+
+	return requirements, nil
+}
+
+// splitSelector splits on the commas outside of parentheses.
+func splitSelector(selector string) ([]string, error) {
+	var (
+		terms []string
+		depth int
+		start int
+	)
+	for i, r := range selector {
+		switch r {
+		case '(':
+			depth++
+			if depth > 1 {
+				return nil, fmt.Errorf("%w: nested parentheses", ErrInvalidSelector)
+			}
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("%w: unbalanced parentheses", ErrInvalidSelector)
+			}
+		case ',':
+			if depth == 0 {
+				terms = append(terms, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("%w: unbalanced parentheses", ErrInvalidSelector)
+	}
+
+	return append(terms, selector[start:]), nil
+}
+
+func parseSelectorTerm(term string) (models.SelectorRequirement, error) {
+	var req models.SelectorRequirement
+
+	switch {
+	case term == "":
+		return req, fmt.Errorf("%w: empty requirement", ErrInvalidSelector)
+	case strings.HasSuffix(term, ")"):
+		open := strings.Index(term, "(")
+		if open < 0 {
+			return req, fmt.Errorf("%w: %q", ErrInvalidSelector, term)
+		}
+		head := strings.Fields(term[:open])
+		if len(head) != 2 { //nolint:mnd
+			return req, fmt.Errorf("%w: %q", ErrInvalidSelector, term)
+		}
+
+		req.Key = head[0]
+		switch strings.ToLower(head[1]) {
+		case models.SelectorOpIn:
+			req.Operator = models.SelectorOpIn
+		case models.SelectorOpNotIn:
+			req.Operator = models.SelectorOpNotIn
+		default:
+			return req, fmt.Errorf("%w: unknown operator %q", ErrInvalidSelector, head[1])
+		}
+
+		for _, v := range strings.Split(term[open+1:len(term)-1], ",") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				return req, fmt.Errorf("%w: empty value in %q", ErrInvalidSelector, term)
+			}
+			req.Values = append(req.Values, v)
+		}
+	case strings.HasPrefix(term, "!") && !strings.Contains(term, "="):
+		req.Key = strings.TrimSpace(term[1:])
+		req.Operator = models.SelectorOpNotExists
+	case strings.Contains(term, "!="):
+		key, value, _ := strings.Cut(term, "!=")
+		req.Key = strings.TrimSpace(key)
+		req.Operator = models.SelectorOpNotEquals
+		req.Values = []string{strings.TrimSpace(value)}
+	case strings.Contains(term, "="):
+		key, value, _ := strings.Cut(term, "=")
+		req.Key = strings.TrimSpace(key)
+		req.Operator = models.SelectorOpEquals
+		req.Values = []string{strings.TrimSpace(strings.TrimPrefix(value, "="))}
+	default:
+		req.Key = term
+		req.Operator = models.SelectorOpExists
+	}
+
+	if !selectorKeyRegexp.MatchString(req.Key) {
+		return req, fmt.Errorf("%w: invalid key %q", ErrInvalidSelector, req.Key)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"monolith/internal/models"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		want     []models.SelectorRequirement
+		wantErr  error
+	}{
+		{
+			name:     "empty",
+			selector: "  ",
+		},
+		{
+			name:     "equals",
+			selector: "site=msk",
+			want: []models.SelectorRequirement{
+				{Key: "site", Operator: models.SelectorOpEquals, Values: []string{"msk"}},
+			},
+		},
+		{
+			name:     "double equals",
+			selector: "site == msk",
+			want: []models.SelectorRequirement{
+				{Key: "site", Operator: models.SelectorOpEquals, Values: []string{"msk"}},
+			},
+		},
+		{
+			name:     "not equals",
+			selector: "env!=prod",
+			want: []models.SelectorRequirement{
+				{Key: "env", Operator: models.SelectorOpNotEquals, Values: []string{"prod"}},
+			},
+		},
+		{
+			name:     "in and notin",
+			selector: "rack in (a1, a2),role NOTIN (db)",
+			want: []models.SelectorRequirement{
+				{Key: "rack", Operator: models.SelectorOpIn, Values: []string{"a1", "a2"}},
+				{Key: "role", Operator: models.SelectorOpNotIn, Values: []string{"db"}},
+			},
+		},
+		{
+			name:     "exists and not exists",
+			selector: "example.com/owner, !deprecated",
+			want: []models.SelectorRequirement{
+				{Key: "example.com/owner", Operator: models.SelectorOpExists},
+				{Key: "deprecated", Operator: models.SelectorOpNotExists},
+			},
+		},
+		{
+			name:     "empty value",
+			selector: "site=",
+			want: []models.SelectorRequirement{
+				{Key: "site", Operator: models.SelectorOpEquals, Values: []string{""}},
+			},
+		},
+		{
+			name:     "empty requirement",
+			selector: "site=msk,",
+			wantErr:  ErrInvalidSelector,
+		},
+		{
+			name:     "nested parentheses",
+			selector: "rack in ((a1))",
+			wantErr:  ErrInvalidSelector,
+		},
+		{
+			name:     "unbalanced parentheses",
+			selector: "rack in (a1",
+			wantErr:  ErrInvalidSelector,
+		},
+		{
+			name:     "unknown operator",
+			selector: "rack within (a1)",
+			wantErr:  ErrInvalidSelector,
+		},
+		{
+			name:     "empty value in set",
+			selector: "rack in (a1,)",
+			wantErr:  ErrInvalidSelector,
+		},
+		{
+			name:     "invalid key",
+			selector: "-site=msk",
+			wantErr:  ErrInvalidSelector,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSelector(tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseSelector(%q) error = %v, want %v", tt.selector, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSelector(%q) = %+v, want %+v", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+
+	"go.uber.org/zap"
+)
+
+var ErrSourceBlocked = errors.New("source is blocked")
+
+const (
+	// unknownDeviceID is stored in messages.device_id for senders that are
+	// not registered as devices.
+	unknownDeviceID int32 = 0
+
+	unknownSourcesFlushInterval = 10 * time.Second
+	autoRegisterNamePrefix      = "auto-"
+)
+
+type UnknownSources interface {
+	// Resolve returns the device of a sender address. Unknown senders are
+	// tracked and get unknownDeviceID, or a new device when auto
+	// registration is enabled. Blocked senders yield ErrSourceBlocked.
+	Resolve(address string) (int32, error)
+	Read(ctx context.Context, status string) ([]models.UnknownSource, error)
+	Promote(ctx context.Context, params PromoteUnknownSourceParams) (PromoteUnknownSourceResult, error)
+	Block(ctx context.Context, id int32) (models.UnknownSource, error)
+	Close(ctx context.Context) error
+}
+
+type UnknownSourcesService struct {
+	repo          repo.UnknownSources
+	deviceHandler DevicesHandler
+	messages      Messages
+
+	autoRegister     bool
+	autoRegisterType string
+	registerMutex    sync.Mutex
+
+	hitsMutex sync.Mutex
+	hits      map[string]*repo.UnknownSourceHit
+	blocked   map[string]struct{}
+
+	stop chan struct{}
+	done chan struct{}
+
+	log *zap.Logger
+}
+
+type UnknownSourcesServiceConfig struct {
+	Repo          repo.UnknownSources
+	DeviceHandler DevicesHandler
+	Messages      Messages
+
+	AutoRegister     bool
+	AutoRegisterType string
+
+	Log *zap.Logger
+}
+
+func NewUnknownSourcesService(cfg UnknownSourcesServiceConfig) (*UnknownSourcesService, error) {
+	service := &UnknownSourcesService{
+		repo:             cfg.Repo,
+		deviceHandler:    cfg.DeviceHandler,
+		messages:         cfg.Messages,
+		autoRegister:     cfg.AutoRegister,
+		autoRegisterType: cfg.AutoRegisterType,
+		hits:             make(map[string]*repo.UnknownSourceHit),
+		blocked:          make(map[string]struct{}),
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+		log:              cfg.Log,
+	}
+
+	blocked, err := service.Read(context.Background(), models.UnknownSourceStatusBlocked)
+	if err != nil {
+		return nil, fmt.Errorf("service.Read: %w", err)
+	}
+	for _, source := range blocked {
+		service.blocked[source.Address] = struct{}{}
+	}
+
+	go service.run()
+
+	return service, nil
+}
+
+func (s *UnknownSourcesService) Resolve(address string) (int32, error) {
+	if deviceID, ok := s.deviceHandler.GetDeviceIDByIp(address); ok {
+		return deviceID, nil
+	}
+
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return unknownDeviceID, nil
+	}
+	address = addr.String()
+
+	s.hitsMutex.Lock()
+	_, blocked := s.blocked[address]
+	s.hitsMutex.Unlock()
+	if blocked {
+		return unknownDeviceID, ErrSourceBlocked
+	}
+
+	if s.autoRegister {
+		deviceID, err := s.register(address)
+		if err == nil {
+			return deviceID, nil
+		}
+		s.log.Warn("s.register", zap.Error(err), zap.String("address", address))
+	}
+
+	s.track(address)
+
+	return unknownDeviceID, nil
+}
+
+// register promotes an unknown sender right away. The mutex keeps a burst
+// of messages from one sender from racing to create the same device.
+func (s *UnknownSourcesService) register(address string) (int32, error) {
+	s.registerMutex.Lock()
+	defer s.registerMutex.Unlock()
+
+	if deviceID, ok := s.deviceHandler.GetDeviceIDByIp(address); ok {
+		return deviceID, nil
+	}
+
+	ctx := context.Background()
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	err = tx.Track(ctx, []repo.UnknownSourceHit{{
+		Address:      address,
+		FirstSeen:    now,
+		LastSeen:     now,
+		MessageCount: 1,
+	}})
+	if err != nil {
+		return 0, fmt.Errorf("tx.Track: %w", err)
+	}
+
+	res, err := tx.Promote(ctx, repo.PromoteUnknownSourceOpts{
+		Address:    address,
+		Name:       autoRegisterNamePrefix + address,
+		DeviceType: s.autoRegisterType,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("tx.Promote: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.updateDevices()
+
+	s.log.Info("unknown source auto registered",
+		zap.String("address", address),
+		zap.Int32("device_id", res.Device.ID),
+	)
+
+	return res.Device.ID, nil
+}
+
+func (s *UnknownSourcesService) track(address string) {
+	now := time.Now()
+
+	s.hitsMutex.Lock()
+	defer s.hitsMutex.Unlock()
+
+	hit, ok := s.hits[address]
+	if !ok {
+		hit = &repo.UnknownSourceHit{
+			Address:   address,
+			FirstSeen: now,
+		}
+		s.hits[address] = hit
+	}
+	hit.LastSeen = now
+	hit.MessageCount++
+}
+
+func (s *UnknownSourcesService) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(unknownSourcesFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.flush(context.Background()); err != nil {
+				s.log.Error("s.flush", zap.Error(err))
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// flush writes the buffered hits. Hits that fail to be written are merged
+// back so the counts survive until the next attempt.
+func (s *UnknownSourcesService) flush(ctx context.Context) error {
+	s.hitsMutex.Lock()
+	pending := s.hits
+	s.hits = make(map[string]*repo.UnknownSourceHit)
+	s.hitsMutex.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	hits := make([]repo.UnknownSourceHit, 0, len(pending))
+	for _, hit := range pending {
+		hits = append(hits, *hit)
+	}
+
+	err := s.writeHits(ctx, hits)
+	if err != nil {
+		s.hitsMutex.Lock()
+		for address, hit := range pending {
+			if current, ok := s.hits[address]; ok {
+				current.FirstSeen = hit.FirstSeen
+				current.MessageCount += hit.MessageCount
+				continue
+			}
+			s.hits[address] = hit
+		}
+		s.hitsMutex.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *UnknownSourcesService) writeHits(ctx context.Context, hits []repo.UnknownSourceHit) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.Track(ctx, hits)
+	if err != nil {
+		return fmt.Errorf("tx.Track: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *UnknownSourcesService) Read(ctx context.Context, status string) ([]models.UnknownSource, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Read(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("tx.Read: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return ret, nil
+}
+
+type (
+	PromoteUnknownSourceParams struct {
+		ID          int32
+		Name        string
+		DeviceType  string
+		Responsible []int32
+	}
+
+	PromoteUnknownSourceResult struct {
+		Device           models.Device
+		MessagesAssigned int64
+	}
+)
+
+// Promote turns a tracked source into a device. Messages the source has
+// sent so far are re-attributed to the new device.
+func (s *UnknownSourcesService) Promote(
+	ctx context.Context,
+	params PromoteUnknownSourceParams,
+) (PromoteUnknownSourceResult, error) {
+	// Pending hits must reach the table first, otherwise a source seen only
+	// since the last flush could not be promoted.
+	if err := s.flush(ctx); err != nil {
+		return PromoteUnknownSourceResult{}, fmt.Errorf("s.flush: %w", err)
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return PromoteUnknownSourceResult{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Promote(ctx, repo.PromoteUnknownSourceOpts{
+		ID:          params.ID,
+		Name:        params.Name,
+		DeviceType:  params.DeviceType,
+		Responsible: params.Responsible,
+	})
+	if err != nil {
+		return PromoteUnknownSourceResult{}, fmt.Errorf("tx.Promote: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return PromoteUnknownSourceResult{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.hitsMutex.Lock()
+	delete(s.blocked, ret.Device.Address)
+	s.hitsMutex.Unlock()
+
+	s.updateDevices()
+
+	// Messages resolved to the unknown device before the refresh may have
+	// been stored after the promotion; move them as well.
+	assigned, err := s.assignMessages(ctx, ret.Device)
+	if err != nil {
+		s.log.Error("s.assignMessages", zap.Error(err), zap.Int32("device_id", ret.Device.ID))
+	}
+
+	return PromoteUnknownSourceResult{
+		Device:           ret.Device,
+		MessagesAssigned: ret.MessagesAssigned + assigned,
+	}, nil
+}
+
+func (s *UnknownSourcesService) assignMessages(ctx context.Context, device models.Device) (int64, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	assigned, err := tx.AssignMessages(ctx, device.ID, device.Address)
+	if err != nil {
+		return 0, fmt.Errorf("tx.AssignMessages: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return assigned, nil
+}
+
+func (s *UnknownSourcesService) Block(ctx context.Context, id int32) (models.UnknownSource, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return models.UnknownSource{}, fmt.Errorf("s.repo.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	ret, err := tx.Block(ctx, id)
+	if err != nil {
+		return models.UnknownSource{}, fmt.Errorf("tx.Block: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.UnknownSource{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	s.hitsMutex.Lock()
+	s.blocked[ret.Address] = struct{}{}
+	delete(s.hits, ret.Address)
+	s.hitsMutex.Unlock()
+
+	return ret, nil
+}
+
+// Close stops the periodic flush and writes the hits buffered so far.
+func (s *UnknownSourcesService) Close(ctx context.Context) error {
+	close(s.stop)
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return fmt.Errorf("unknown sources stop: %w", ctx.Err())
+	}
+
+	if err := s.flush(ctx); err != nil {
+		return fmt.Errorf("s.flush: %w", err)
+	}
+
+	return nil
+}
+
+func (s *UnknownSourcesService) updateDevices() {
+	s.deviceHandler.UpdateDevices()
+	s.messages.UpdateDevices()
+}
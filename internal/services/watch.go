@@ -0,0 +1,98 @@
+package services
+
+import (
+	"sync"
+
+	"monolith/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// watchBufferSize is how many stored messages a watcher may fall behind
+// before messages are dropped for it.
+const watchBufferSize = 256
+
+// watchers fans stored messages out to subscribers. Publishing never blocks,
+// so a slow subscriber misses messages instead of holding up the writers.
+type watchers struct {
+	mu          sync.RWMutex
+	closed      bool
+	subscribers map[chan models.Message]struct{}
+
+	droppedTotal prometheus.Counter
+}
+
+func newWatchers() *watchers {
+	w := &watchers{
+		subscribers: make(map[chan models.Message]struct{}),
+		droppedTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "watch_dropped_messages_total",
+				Help: "Stored messages not delivered to a slow watcher",
+			},
+		),
+	}
+
+	prometheus.MustRegister(w.droppedTotal)
+
+	return w
+}
+
+func (w *watchers) subscribe() (<-chan models.Message, func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ch := make(chan models.Message, watchBufferSize)
+	if w.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	w.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+
+			if _, ok := w.subscribers[ch]; ok {
+				delete(w.subscribers, ch)
+				close(ch)
+			}
+		})
+	}
+}
+
+func (w *watchers) publish(messages []models.Message) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	for ch := range w.subscribers {
+		for _, msg := range messages {
+			select {
+			case ch <- msg:
+			default:
+				w.droppedTotal.Inc()
+			}
+		}
+	}
+}
+
+// close ends every subscription, which lets streaming clients finish before
+// the service shuts down.
+func (w *watchers) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	for ch := range w.subscribers {
+		delete(w.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Watch subscribes to messages as they are stored. The channel is closed
+// when cancel is called or the service is closed.
+func (ms *MessagesService) Watch() (<-chan models.Message, func()) {
+	return ms.watchers.subscribe()
+}
@@ -0,0 +1,269 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/services"
+	userspb "monolith/proto/api-gateway/users"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	localID = "localID"
+
+	metadataAuthorization = "authorization"
+	// metadataDeviceToken carries the ingest token of a device, as the
+	// authorization metadata already carries the JWT of the user.
+	metadataDeviceToken = "x-device-token"
+)
+
+// Ingest authentication modes, the same as those of the HTTP ingest. Any
+// other value is handled as required so a typo fails closed.
+const (
+	ingestAuthOff      = "off"
+	ingestAuthOptional = "optional"
+)
+
+var errAddressRequired = errors.New("address is required without a device credential")
+
+type localIDKey struct{}
+
+// publicMethods do not need a JWT, just like /auth of the HTTP API.
+var publicMethods = map[string]struct{}{
+	userspb.UsersService_Register_FullMethodName: {},
+	userspb.UsersService_SignIn_FullMethodName:   {},
+}
+
+func (s *Server) authUnary(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	ctx, err := s.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *Server) authStream(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := s.authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authorize checks the JWT in the "authorization" metadata the same way
+// deserializeMW of the HTTP handlers checks the Authorization header.
+func (s *Server) authorize(ctx context.Context, method string) (context.Context, error) {
+	if _, ok := publicMethods[method]; ok {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(metadataAuthorization)
+	if len(values) == 0 || values[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, errors.New("tokenString is empty").Error())
+	}
+
+	tokenString := strings.ReplaceAll(values[0], "Bearer ", "")
+	token, err := jwt.Parse(tokenString, func(_ *jwt.Token) (interface{}, error) {
+		return []byte(s.jwtKey), nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, fmt.Errorf("jwt.Parse: %w", err).Error())
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims) //nolint:varnamelen
+	if !ok {
+		return nil, status.Error(
+			codes.Unauthenticated,
+			errors.New("token.Claims.(jwt.MapClaims): invalid token").Error(),
+		)
+	}
+
+	userID, ok := claims[localID].(float64) //nolint:varnamelen
+	if !ok {
+		return nil, status.Error(
+			codes.Unauthenticated,
+			errors.New("claims["+localID+"].(float64): invalid token").Error(),
+		)
+	}
+
+	return context.WithValue(ctx, localIDKey{}, int(userID)), nil
+}
+
+// ingestSender returns the device id and address a message is stored with,
+// applying the ingest authentication of the HTTP ingest: with a valid device
+// token in the metadata the address of the request is ignored, so a device
+// can only send messages on its own behalf.
+func (s *Server) ingestSender(ctx context.Context, address string) (int32, string, error) {
+	if s.ingestAuth != ingestAuthOff {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(metadataDeviceToken)
+
+		token := ""
+		if len(values) != 0 {
+			token = strings.TrimSpace(values[0])
+		}
+
+		switch {
+		case token != "":
+			credential, ok := s.credentials.Authenticate(token)
+			if !ok {
+				return 0, "", status.Error(
+					codes.Unauthenticated,
+					errors.New("s.credentials.Authenticate: invalid device token").Error(),
+				)
+			}
+
+			if s.ingestStrictIP {
+				peerAddress := peerIP(ctx)
+				if !s.ownAddress(peerAddress, credential) {
+					return 0, "", status.Error(
+						codes.PermissionDenied,
+						fmt.Errorf("request address %s does not match device address %s",
+							peerAddress, credential.DeviceAddress).Error(),
+					)
+				}
+			}
+
+			return credential.DeviceID, credential.DeviceAddress, nil
+		case s.ingestAuth != ingestAuthOptional:
+			return 0, "", status.Error(codes.Unauthenticated, errors.New("device token is empty").Error())
+		}
+	}
+
+	if address == "" {
+		return 0, "", status.Error(codes.InvalidArgument, errAddressRequired.Error())
+	}
+
+	id, err := s.unknownSources.Resolve(address)
+	if errors.Is(err, services.ErrSourceBlocked) {
+		return 0, "", status.Error(codes.PermissionDenied, fmt.Errorf("s.unknownSources.Resolve: %w", err).Error())
+	}
+	if err != nil {
+		return 0, "", status.Error(codes.Internal, fmt.Errorf("s.unknownSources.Resolve: %w", err).Error())
+	}
+
+	return id, address, nil
+}
+
+// ownAddress reports whether the call comes from the primary address of the
+// device or from one of its extra addresses and ranges.
+func (s *Server) ownAddress(address string, credential models.DeviceCredential) bool {
+	if sameAddress(address, credential.DeviceAddress) {
+		return true
+	}
+	if s.deviceChecker == nil {
+		return false
+	}
+
+	deviceID, ok := s.deviceChecker.GetDeviceIDByIp(address)
+	return ok && deviceID == credential.DeviceID
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	addrPort, err := netip.ParseAddrPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return addrPort.Addr().String()
+}
+
+func sameAddress(a, b string) bool {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+
+	return addrA.Unmap() == addrB.Unmap()
+}
+
+func (s *Server) signToken(userID int) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		localID: userID,
+		"exp":   time.Now().Add(s.tokenLifeTime).Unix(),
+	})
+
+	signed, err := token.SignedString([]byte(s.jwtKey))
+	if err != nil {
+		return "", fmt.Errorf("token.SignedString: %w", err)
+	}
+
+	return signed, nil
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context //nolint:containedctx
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Server) logUnary(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		s.logError(info.FullMethod, err)
+	}
+
+	return resp, err
+}
+
+func (s *Server) logStream(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	err := handler(srv, stream)
+	if err != nil {
+		s.logError(info.FullMethod, err)
+	}
+
+	return err
+}
+
+func (s *Server) logError(method string, err error) {
+	s.log.Error(
+		err.Error(),
+		zap.String("method", method),
+		zap.String("code", status.Code(err).String()),
+	)
+}
@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// field is a request field checked with the validator tags the HTTP
+// handlers use for it.
+type field struct {
+	name  string
+	value any
+	tag   string
+}
+
+func (s *Server) validateFields(fields ...field) error {
+	for _, f := range fields {
+		if err := s.validate.Var(f.value, f.tag); err != nil {
+			return status.Error(
+				codes.InvalidArgument,
+				fmt.Errorf("%s: %w", f.name, err).Error(),
+			)
+		}
+	}
+
+	return nil
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
+
+func nullTimestamp(t sql.NullTime) *timestamppb.Timestamp {
+	if !t.Valid {
+		return nil
+	}
+
+	return timestamppb.New(t.Time)
+}
+
+func fromTimestamp(t *timestamppb.Timestamp) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	ts := t.AsTime()
+	return &ts
+}
@@ -0,0 +1,198 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/internal/services"
+	devicespb "monolith/proto/api-gateway/devices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type devicesHandler struct {
+	devicespb.UnimplementedDevicesServiceServer
+	*Server
+}
+
+func (h *devicesHandler) Create(ctx context.Context, req *devicespb.CreateDeviceRequest) (*devicespb.Device, error) {
+	err := h.validateFields(
+		field{name: "name", value: req.GetName(), tag: "required"},
+		field{name: "device_type", value: req.GetDeviceType(), tag: "required"},
+		field{name: "address", value: req.GetAddress(), tag: "required,ip"},
+		field{name: "responsible", value: req.GetResponsible(), tag: "required"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := h.devicesHandlers.Create(ctx, models.Device{
+		Name:        req.GetName(),
+		DeviceType:  req.GetDeviceType(),
+		Address:     req.GetAddress(),
+		Responsible: req.GetResponsible(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.devicesHandlers.Create: %w", err).Error())
+	}
+
+	h.reportsHandler.UpdateDevices()
+	h.deviceChecker.UpdateDevices()
+
+	return device(res), nil
+}
+
+func (h *devicesHandler) Read(ctx context.Context, _ *devicespb.ReadDevicesRequest) (*devicespb.ReadDevicesResponse, error) {
+	res, err := h.devicesHandlers.Read(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.devicesHandlers.Read: %w", err).Error())
+	}
+
+	devices := make([]*devicespb.Device, 0, len(res.Devices))
+	for _, d := range res.Devices {
+		devices = append(devices, device(d))
+	}
+
+	return &devicespb.ReadDevicesResponse{
+		Devices: devices,
+	}, nil
+}
+
+func (h *devicesHandler) Update(ctx context.Context, req *devicespb.UpdateDeviceRequest) (*emptypb.Empty, error) {
+	err := h.validateFields(
+		field{name: "id", value: req.GetId(), tag: "required"},
+		field{name: "address", value: req.GetAddress(), tag: "omitempty,ip"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name == nil && req.DeviceType == nil && req.Address == nil && len(req.GetResponsible()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, errors.New("nothing to update").Error())
+	}
+
+	err = h.devicesHandlers.Update(ctx, services.UpdateDeviceParams{
+		ID:          req.GetId(),
+		Name:        req.Name,
+		DeviceType:  req.DeviceType,
+		Address:     req.Address,
+		Responsible: req.GetResponsible(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.devicesHandlers.Update: %w", err).Error())
+	}
+
+	// The cached credentials and probes hold the device address too.
+	h.reportsHandler.UpdateDevices()
+	h.deviceChecker.UpdateDevices()
+	h.credentials.UpdateCredentials()
+	h.probes.UpdateProbes()
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *devicesHandler) Delete(ctx context.Context, req *devicespb.DeleteDeviceRequest) (*emptypb.Empty, error) {
+	err := h.validateFields(
+		field{name: "id", value: req.GetId(), tag: "required"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.devicesHandlers.Delete(ctx, req.GetId()); err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.devicesHandlers.Delete: %w", err).Error())
+	}
+
+	h.reportsHandler.UpdateDevices()
+	h.deviceChecker.UpdateDevices()
+	h.credentials.UpdateCredentials()
+	h.probes.UpdateProbes()
+	h.dependencies.UpdateDependencies()
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *devicesHandler) IssueCredential(
+	ctx context.Context,
+	req *devicespb.CredentialRequest,
+) (*devicespb.Credential, error) {
+	return h.sendCredential(ctx, req, h.credentials.Issue)
+}
+
+func (h *devicesHandler) RotateCredential(
+	ctx context.Context,
+	req *devicespb.CredentialRequest,
+) (*devicespb.Credential, error) {
+	return h.sendCredential(ctx, req, h.credentials.Rotate)
+}
+
+func (h *devicesHandler) sendCredential(
+	ctx context.Context,
+	req *devicespb.CredentialRequest,
+	issue func(ctx context.Context, deviceID int32) (services.IssueDeviceCredentialResult, error),
+) (*devicespb.Credential, error) {
+	err := h.validateFields(
+		field{name: "device_id", value: req.GetDeviceId(), tag: "required"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := issue(ctx, req.GetDeviceId())
+	if err != nil {
+		return nil, status.Error(credentialErrorCode(err), fmt.Errorf("h.credentials.Issue: %w", err).Error())
+	}
+
+	return &devicespb.Credential{
+		DeviceId:  res.DeviceID,
+		Token:     res.Token,
+		CreatedAt: timestamppb.New(res.CreatedAt),
+	}, nil
+}
+
+func (h *devicesHandler) RevokeCredential(
+	ctx context.Context,
+	req *devicespb.CredentialRequest,
+) (*emptypb.Empty, error) {
+	err := h.validateFields(
+		field{name: "device_id", value: req.GetDeviceId(), tag: "required"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.credentials.Revoke(ctx, req.GetDeviceId()); err != nil {
+		return nil, status.Error(credentialErrorCode(err), fmt.Errorf("h.credentials.Revoke: %w", err).Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func credentialErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, repo.ErrDeviceNotFound), errors.Is(err, repo.ErrDeviceCredentialNotFound):
+		return codes.NotFound
+	case errors.Is(err, repo.ErrDeviceCredentialExists):
+		return codes.AlreadyExists
+	default:
+		return codes.Internal
+	}
+}
+
+func device(d models.Device) *devicespb.Device {
+	return &devicespb.Device{
+		Id:          d.ID,
+		Name:        d.Name,
+		DeviceType:  d.DeviceType,
+		Address:     d.Address,
+		Responsible: d.Responsible,
+		CreatedAt:   timestamp(d.CreatedAt),
+		UpdatedAt:   timestamp(d.UpdatedAt),
+	}
+}
@@ -0,0 +1,356 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"monolith/internal/models"
+	"monolith/internal/services"
+	messagespb "monolith/proto/api-gateway/messages"
+
+	"github.com/samber/lo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultReportLimit is the page size of GetAllByPeriod and
+// GetAllByDeviceId without a limit, as in the HTTP reports.
+const defaultReportLimit = 100
+
+type messagesHandler struct {
+	messagespb.UnimplementedMessagesServiceServer
+	*Server
+}
+
+func (h *messagesHandler) SendMessage(
+	ctx context.Context,
+	req *messagespb.SendMessageRequest,
+) (*messagespb.SendMessageResponse, error) {
+	duplicate, err := h.enqueue(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &messagespb.SendMessageResponse{
+		Duplicate: duplicate,
+	}, nil
+}
+
+// SendMessages enqueues every message of the stream as it arrives. A message
+// that is not accepted does not end the stream; it is listed in the response
+// so the collector can resend just that message.
+func (h *messagesHandler) SendMessages(stream grpc.ClientStreamingServer[messagespb.SendMessageRequest, messagespb.SendMessagesResponse]) error {
+	resp := &messagespb.SendMessagesResponse{}
+
+	for index := int32(0); ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("stream.Recv: %w", err)
+		}
+
+		duplicate, err := h.enqueue(stream.Context(), req)
+		if err != nil {
+			resp.Rejected++
+			resp.RejectedMessages = append(resp.RejectedMessages, &messagespb.RejectedMessage{
+				Index: index,
+				Code:  int32(status.Code(err)), //nolint:gosec
+				Error: status.Convert(err).Message(),
+			})
+			continue
+		}
+
+		resp.Accepted++
+		if duplicate {
+			resp.Duplicates++
+		}
+	}
+
+	if err := stream.SendAndClose(resp); err != nil {
+		return fmt.Errorf("stream.SendAndClose: %w", err)
+	}
+
+	return nil
+}
+
+// enqueue reports a message whose id was accepted before as a duplicate
+// instead of failing, just like /messages/send_msg.
+func (h *messagesHandler) enqueue(ctx context.Context, req *messagespb.SendMessageRequest) (bool, error) {
+	err := h.validateFields(
+		field{name: "message", value: req.GetMessage(), tag: "required"},
+		field{name: "message_type", value: req.GetMessageType(), tag: "required"},
+		field{name: "component", value: req.GetComponent(), tag: "required"},
+		field{name: "address", value: req.GetAddress(), tag: "omitempty,ip"},
+		field{name: "message_id", value: req.GetMessageId(), tag: "max=128"},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	id, address, err := h.ingestSender(ctx, req.GetAddress())
+	if err != nil {
+		return false, err
+	}
+
+	err = h.reportsHandler.Enqueue(models.Message{
+		Message:     req.GetMessage(),
+		MessageType: req.GetMessageType(),
+		Component:   req.GetComponent(),
+		DeviceId:    id,
+		DeviceIP:    address,
+		MessageID:   req.GetMessageId(),
+		EventAt:     fromTimestamp(req.GetTimestamp()),
+	})
+	switch {
+	case err == nil:
+		return false, nil
+	case errors.Is(err, services.ErrDuplicateMessage):
+		return true, nil
+	case errors.Is(err, services.ErrTimestampSkew):
+		return false, status.Error(codes.InvalidArgument, fmt.Errorf("h.reportsHandler.Enqueue: %w", err).Error())
+	case errors.Is(err, services.ErrIngestQueueFull), errors.Is(err, services.ErrIngestQueueClosed):
+		return false, status.Error(codes.Unavailable, fmt.Errorf("h.reportsHandler.Enqueue: %w", err).Error())
+	default:
+		return false, status.Error(codes.Internal, fmt.Errorf("h.reportsHandler.Enqueue: %w", err).Error())
+	}
+}
+
+// WatchMessages runs until the client goes away or the server stops.
+func (h *messagesHandler) WatchMessages(
+	req *messagespb.WatchMessagesRequest,
+	stream grpc.ServerStreamingServer[messagespb.StoredMessage],
+) error {
+	messages, cancel := h.reportsHandler.Watch()
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-h.shutdown:
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+
+			if !watchMatches(req, msg) {
+				continue
+			}
+
+			if err := stream.Send(storedMessage(msg)); err != nil {
+				return fmt.Errorf("stream.Send: %w", err)
+			}
+		}
+	}
+}
+
+func watchMatches(req *messagespb.WatchMessagesRequest, msg models.Message) bool {
+	if len(req.GetDeviceIds()) != 0 && !lo.Contains(req.GetDeviceIds(), msg.DeviceId) {
+		return false
+	}
+	if len(req.GetMessageTypes()) != 0 && !lo.Contains(req.GetMessageTypes(), msg.MessageType) {
+		return false
+	}
+	if len(req.GetSeverityLevels()) != 0 && !lo.Contains(req.GetSeverityLevels(), msg.SeverityLevel) {
+		return false
+	}
+
+	return true
+}
+
+func storedMessage(msg models.Message) *messagespb.StoredMessage {
+	return &messagespb.StoredMessage{
+		DeviceId:      msg.DeviceId,
+		DeviceIp:      msg.DeviceIP,
+		Message:       msg.Message,
+		MessageType:   msg.MessageType,
+		SeverityLevel: msg.SeverityLevel,
+		Component:     msg.Component,
+		GotAt:         timestamppb.New(msg.GotAt),
+		EventAt:       timestamp(msg.EventAt),
+	}
+}
+
+func (h *messagesHandler) GetAllByPeriod(
+	_ context.Context,
+	req *messagespb.GetAllByPeriodRequest,
+) (*messagespb.ReportMessagesResponse, error) {
+	err := h.validateFields(
+		field{name: "start_time", value: req.GetStartTime().GetSeconds(), tag: "required"},
+		field{name: "end_time", value: req.GetEndTime().GetSeconds(), tag: "required"},
+		field{name: "time_basis", value: req.GetTimeBasis(), tag: "omitempty,oneof=received event"},
+		field{name: "limit", value: req.GetLimit(), tag: "omitempty,min=1,max=1000"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, page, err := h.reportsHandler.GetAllByPeriod(services.MessagesGetAllByPeriodOpts{
+		StartTime: req.GetStartTime().AsTime().Local(),
+		EndTime:   req.GetEndTime().AsTime().Local(),
+		TimeBasis: req.GetTimeBasis(),
+		Page:      reportPage(req.GetCursor(), req.GetLimit(), req.GetWithTotal()),
+	})
+	if err != nil {
+		return nil, status.Error(reportErrorCode(err), fmt.Errorf("h.reportsHandler.GetAllByPeriod: %w", err).Error())
+	}
+
+	return &messagespb.ReportMessagesResponse{
+		Messages: lo.Map(res, func(r services.ReportGetAllByPeriod, _ int) *messagespb.ReportMessage {
+			return &messagespb.ReportMessage{
+				DeviceId:    r.DeviceID,
+				Name:        r.Name,
+				DeviceType:  r.DeviceType,
+				Address:     r.Address,
+				Responsible: r.Responsible,
+				GotAt:       timestamppb.New(r.GotAt),
+				EventAt:     timestamp(r.EventAt),
+				Message:     r.Message,
+				MessageType: r.MessageType,
+			}
+		}),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}, nil
+}
+
+func (h *messagesHandler) GetAllByDeviceId(
+	_ context.Context,
+	req *messagespb.GetAllByDeviceIdRequest,
+) (*messagespb.ReportMessagesResponse, error) {
+	err := h.validateFields(
+		field{name: "device_id", value: req.GetDeviceId(), tag: "required"},
+		field{name: "limit", value: req.GetLimit(), tag: "omitempty,min=1,max=1000"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, page, err := h.reportsHandler.GetAllByDeviceId(services.MessagesGetAllByDeviceIdOpts{
+		DeviceID: req.GetDeviceId(),
+		Page:     reportPage(req.GetCursor(), req.GetLimit(), req.GetWithTotal()),
+	})
+	if err != nil {
+		return nil, status.Error(reportErrorCode(err), fmt.Errorf("h.reportsHandler.GetAllByDeviceId: %w", err).Error())
+	}
+
+	return &messagespb.ReportMessagesResponse{
+		Messages: lo.Map(res, func(r services.ReportGetAllByDeviceId, _ int) *messagespb.ReportMessage {
+			return &messagespb.ReportMessage{
+				DeviceId:    r.DeviceID,
+				Name:        r.Name,
+				DeviceType:  r.DeviceType,
+				Address:     r.Address,
+				Responsible: r.Responsible,
+				GotAt:       timestamppb.New(r.GotAt),
+				EventAt:     timestamp(r.EventAt),
+				Message:     r.Message,
+				MessageType: r.MessageType,
+			}
+		}),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}, nil
+}
+
+func reportPage(cursor string, limit int32, withTotal bool) services.ReportPage {
+	if limit == 0 {
+		limit = defaultReportLimit
+	}
+
+	return services.ReportPage{
+		Cursor:    cursor,
+		Limit:     int(limit),
+		WithTotal: withTotal,
+	}
+}
+
+func reportErrorCode(err error) codes.Code {
+	if errors.Is(err, services.ErrInvalidReportCursor) {
+		return codes.InvalidArgument
+	}
+
+	return codes.Internal
+}
+
+func (h *messagesHandler) GetCountByMessageType(
+	_ context.Context,
+	req *messagespb.GetCountByMessageTypeRequest,
+) (*messagespb.GetCountByMessageTypeResponse, error) {
+	err := h.validateFields(
+		field{name: "message_type", value: req.GetMessageType(), tag: "required"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := h.reportsHandler.GetCountByMessageType(req.GetMessageType(), 0)
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.reportsHandler.GetCountByMessageType: %w", err).Error())
+	}
+
+	return &messagespb.GetCountByMessageTypeResponse{
+		Counts: lo.Map(res, func(r services.ReportGetCountByMessageType, _ int) *messagespb.CountByMessageType {
+			return &messagespb.CountByMessageType{
+				DeviceId:    r.DeviceID,
+				Name:        r.Name,
+				DeviceType:  r.DeviceType,
+				Address:     r.Address,
+				Responsible: r.Responsible,
+				Count:       r.Count,
+			}
+		}),
+	}, nil
+}
+
+func (h *messagesHandler) MonthReport(
+	_ context.Context,
+	req *messagespb.MonthReportRequest,
+) (*messagespb.MonthReportResponse, error) {
+	err := h.validateFields(
+		field{name: "time_basis", value: req.GetTimeBasis(), tag: "omitempty,oneof=received event"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := h.reportsHandler.MonthReport(req.GetTimeBasis(), 0)
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.reportsHandler.MonthReport: %w", err).Error())
+	}
+
+	return &messagespb.MonthReportResponse{
+		Rows: lo.Map(res, func(r models.MonthReportRow, _ int) *messagespb.MonthReportRow {
+			row := &messagespb.MonthReportRow{
+				DeviceId:            r.DeviceID,
+				MessageType:         r.MessageType,
+				ActiveDays:          r.ActiveDays,
+				TotalMessages:       r.TotalMessages,
+				AvgDailyMessages:    r.AvgDailyMessages,
+				MaxDailyMessages:    r.MaxDailyMessages,
+				MedianDailyMessages: r.MedianDailyMessages,
+				TotalCritical:       r.TotalCritical,
+				MaxDailyCritical:    r.MaxDailyCritical,
+				MaxDailyComponents:  r.MaxDailyComponents,
+				FirstCriticalTime:   nullTimestamp(r.FirstCriticalTime),
+				LastCriticalTime:    nullTimestamp(r.LastCriticalTime),
+				CriticalPercentage:  r.CriticalPercentage,
+				OverallVolumeRank:   r.OverallVolumeRank,
+			}
+			if r.MostActiveComponent.Valid {
+				row.MostActiveComponent = &r.MostActiveComponent.String
+			}
+			if r.AvgCriticalIntervalSec.Valid {
+				row.AvgCriticalIntervalSec = &r.AvgCriticalIntervalSec.Float64
+			}
+			return row
+		}),
+	}, nil
+}
@@ -0,0 +1,136 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"monolith/internal/services"
+	devicespb "monolith/proto/api-gateway/devices"
+	messagespb "monolith/proto/api-gateway/messages"
+	tagspb "monolith/proto/api-gateway/tags"
+	userspb "monolith/proto/api-gateway/users"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// Server exposes the services of the v1 HTTP API over gRPC.
+type Server struct {
+	server   *grpc.Server
+	addr     string
+	log      *zap.Logger
+	validate *validator.Validate
+
+	// shutdown is closed on Stop so that WatchMessages streams end and a
+	// graceful stop does not wait for them.
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+
+	jwtKey          string
+	tokenLifeTime   time.Duration
+	authHandlers    services.Auth
+	devicesHandlers services.Devices
+	tagsHandler     services.Tags
+	reportsHandler  services.Messages
+	deviceChecker   services.DevicesHandler
+	unknownSources  services.UnknownSources
+	credentials     services.DeviceCredentials
+	probes          services.Probes
+	dependencies    services.DeviceDependencies
+
+	ingestAuth     string
+	ingestStrictIP bool
+}
+
+type Config struct {
+	Log             *zap.Logger
+	JwtKey          string
+	TokenLifeTime   time.Duration
+	Addr            string
+	AuthHandlers    services.Auth
+	DevicesHandlers services.Devices
+	TagsHandler     services.Tags
+	ReportsHandler  services.Messages
+	DeviceChecker   services.DevicesHandler
+	UnknownSources  services.UnknownSources
+	Credentials     services.DeviceCredentials
+	Probes          services.Probes
+	Dependencies    services.DeviceDependencies
+
+	// IngestAuth and IngestStrictIP apply to SendMessage and SendMessages
+	// as they do to the HTTP ingest. The device token is passed in the
+	// x-device-token metadata.
+	IngestAuth     string
+	IngestStrictIP bool
+}
+
+func NewServer(cfg Config) *Server {
+	server := &Server{
+		addr:            cfg.Addr,
+		log:             cfg.Log,
+		validate:        validator.New(),
+		shutdown:        make(chan struct{}),
+		jwtKey:          cfg.JwtKey,
+		tokenLifeTime:   cfg.TokenLifeTime,
+		authHandlers:    cfg.AuthHandlers,
+		devicesHandlers: cfg.DevicesHandlers,
+		tagsHandler:     cfg.TagsHandler,
+		reportsHandler:  cfg.ReportsHandler,
+		deviceChecker:   cfg.DeviceChecker,
+		unknownSources:  cfg.UnknownSources,
+		credentials:     cfg.Credentials,
+		probes:          cfg.Probes,
+		dependencies:    cfg.Dependencies,
+		ingestAuth:      cfg.IngestAuth,
+		ingestStrictIP:  cfg.IngestStrictIP,
+	}
+
+	server.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.logUnary, server.authUnary),
+		grpc.ChainStreamInterceptor(server.logStream, server.authStream),
+	)
+
+	userspb.RegisterUsersServiceServer(server.server, &usersHandler{Server: server})
+	devicespb.RegisterDevicesServiceServer(server.server, &devicesHandler{Server: server})
+	tagspb.RegisterTagsServiceServer(server.server, &tagsHandler{Server: server})
+	messagespb.RegisterMessagesServiceServer(server.server, &messagesHandler{Server: server})
+
+	return server
+}
+
+func (s *Server) Run() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("net.Listen: %w", err)
+	}
+
+	if err := s.server.Serve(listener); err != nil {
+		return fmt.Errorf("s.server.Serve: %w", err)
+	}
+
+	return nil
+}
+
+// Stop waits for running calls to finish. Calls still running when ctx is
+// done are cancelled.
+func (s *Server) Stop(ctx context.Context) error {
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return fmt.Errorf("s.server.GracefulStop: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,137 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"monolith/internal/models"
+	"monolith/internal/services"
+	tagspb "monolith/proto/api-gateway/tags"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type tagsHandler struct {
+	tagspb.UnimplementedTagsServiceServer
+	*Server
+}
+
+func (h *tagsHandler) Create(ctx context.Context, req *tagspb.CreateTagRequest) (*tagspb.Tag, error) {
+	err := h.validateFields(
+		field{name: "name", value: req.GetName(), tag: "required"},
+		field{name: "device_id", value: req.GetDeviceId(), tag: "required"},
+		field{name: "regexp", value: req.GetRegexp(), tag: "required"},
+		field{name: "compare_type", value: req.GetCompareType(), tag: "required,oneof='<' '>' '='"},
+		field{name: "value", value: req.GetValue(), tag: "required"},
+		field{name: "array_index", value: req.GetArrayIndex(), tag: "required"},
+		field{name: "subject", value: req.GetSubject(), tag: "required"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := h.tagsHandler.Create(ctx, models.Tag{
+		Name:          req.GetName(),
+		DeviceId:      req.GetDeviceId(),
+		Regexp:        req.GetRegexp(),
+		CompareType:   req.GetCompareType(),
+		Value:         req.GetValue(),
+		ArrayIndex:    req.GetArrayIndex(),
+		Subject:       req.GetSubject(),
+		SeverityLevel: req.GetSeverityLevel(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.tagsHandler.Create: %w", err).Error())
+	}
+
+	h.reportsHandler.UpdateTags()
+
+	return tag(res), nil
+}
+
+func (h *tagsHandler) Read(ctx context.Context, _ *tagspb.ReadTagsRequest) (*tagspb.ReadTagsResponse, error) {
+	res, err := h.tagsHandler.Read(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.tagsHandler.Read: %w", err).Error())
+	}
+
+	tags := make([]*tagspb.Tag, 0, len(res.Tags))
+	for _, t := range res.Tags {
+		tags = append(tags, tag(t))
+	}
+
+	return &tagspb.ReadTagsResponse{
+		Tags: tags,
+	}, nil
+}
+
+func (h *tagsHandler) Update(ctx context.Context, req *tagspb.UpdateTagRequest) (*emptypb.Empty, error) {
+	err := h.validateFields(
+		field{name: "id", value: req.GetId(), tag: "required"},
+		field{name: "compare_type", value: req.GetCompareType(), tag: "omitempty,oneof='<' '>' '='"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name == nil && req.DeviceId == nil && req.Regexp == nil && req.CompareType == nil &&
+		req.Value == nil && req.ArrayIndex == nil && req.Subject == nil && req.SeverityLevel == nil {
+		return nil, status.Error(codes.InvalidArgument, errors.New("nothing to update").Error())
+	}
+
+	err = h.tagsHandler.Update(ctx, services.UpdateParams{
+		ID:            req.GetId(),
+		Name:          req.Name,
+		DeviceId:      req.DeviceId,
+		Regexp:        req.Regexp,
+		CompareType:   req.CompareType,
+		Value:         req.Value,
+		ArrayIndex:    req.ArrayIndex,
+		Subject:       req.Subject,
+		SeverityLevel: req.SeverityLevel,
+	})
+
+	h.reportsHandler.UpdateTags()
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.tagsHandler.Update: %w", err).Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *tagsHandler) Delete(ctx context.Context, req *tagspb.DeleteTagRequest) (*emptypb.Empty, error) {
+	err := h.validateFields(
+		field{name: "id", value: req.GetId(), tag: "required"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.tagsHandler.Delete(ctx, req.GetId())
+
+	h.reportsHandler.UpdateTags()
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.tagsHandler.Delete: %w", err).Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func tag(t models.Tag) *tagspb.Tag {
+	return &tagspb.Tag{
+		Id:            t.ID,
+		Name:          t.Name,
+		DeviceId:      t.DeviceId,
+		Regexp:        t.Regexp,
+		CompareType:   t.CompareType,
+		Value:         t.Value,
+		ArrayIndex:    t.ArrayIndex,
+		Subject:       t.Subject,
+		SeverityLevel: t.SeverityLevel,
+		CreatedAt:     timestamp(t.CreatedAt),
+		UpdatedAt:     timestamp(t.UpdatedAt),
+	}
+}
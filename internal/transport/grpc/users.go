@@ -0,0 +1,135 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"monolith/internal/services"
+	userspb "monolith/proto/api-gateway/users"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type usersHandler struct {
+	userspb.UnimplementedUsersServiceServer
+	*Server
+}
+
+func (h *usersHandler) Register(ctx context.Context, req *userspb.RegisterRequest) (*userspb.User, error) {
+	err := h.validateFields(
+		field{name: "name", value: req.GetName(), tag: "required"},
+		field{name: "email", value: req.GetEmail(), tag: "required,email"},
+		field{name: "password", value: req.GetPassword(), tag: "required"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := h.authHandlers.Create(ctx, services.CreateUserParams{
+		Name:     req.GetName(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.authHandlers.Create: %w", err).Error())
+	}
+
+	return &userspb.User{
+		Id:    res.ID,
+		Name:  res.Name,
+		Email: res.Email,
+	}, nil
+}
+
+func (h *usersHandler) SignIn(ctx context.Context, req *userspb.SignInRequest) (*userspb.SignInResponse, error) {
+	err := h.validateFields(
+		field{name: "email", value: req.GetEmail(), tag: "required,email"},
+		field{name: "password", value: req.GetPassword(), tag: "required"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := h.authHandlers.Authorize(ctx, services.AuthorizeParams{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, fmt.Errorf("h.authHandlers.Authorize: %w", err).Error())
+	}
+
+	token, err := h.signToken(userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.signToken: %w", err).Error())
+	}
+
+	return &userspb.SignInResponse{
+		Jwt: token,
+	}, nil
+}
+
+func (h *usersHandler) Read(ctx context.Context, _ *userspb.ReadUsersRequest) (*userspb.ReadUsersResponse, error) {
+	res, err := h.authHandlers.Read(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.authHandlers.Read: %w", err).Error())
+	}
+
+	users := make([]*userspb.User, 0, len(res.Users))
+	for _, user := range res.Users {
+		users = append(users, &userspb.User{
+			Id:        user.ID,
+			Name:      user.Name,
+			Email:     user.Email,
+			CreatedAt: timestamp(user.CreatedAt),
+			UpdatedAt: timestamp(user.UpdatedAt),
+		})
+	}
+
+	return &userspb.ReadUsersResponse{
+		Users: users,
+	}, nil
+}
+
+func (h *usersHandler) Update(ctx context.Context, req *userspb.UpdateUserRequest) (*emptypb.Empty, error) {
+	err := h.validateFields(
+		field{name: "id", value: req.GetId(), tag: "required"},
+		field{name: "email", value: req.GetEmail(), tag: "omitempty,email"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name == nil && req.Email == nil && req.Password == nil {
+		return nil, status.Error(codes.InvalidArgument, errors.New("nothing to update").Error())
+	}
+
+	err = h.authHandlers.Update(ctx, services.UpdateUsersParams{
+		ID:       req.GetId(),
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.authHandlers.Update: %w", err).Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *usersHandler) Delete(ctx context.Context, req *userspb.DeleteUserRequest) (*emptypb.Empty, error) {
+	err := h.validateFields(
+		field{name: "id", value: req.GetId(), tag: "required"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.authHandlers.Delete(ctx, req.GetId()); err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("h.authHandlers.Delete: %w", err).Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
@@ -26,6 +26,18 @@ type Server struct {
 	tagsHandler     services.Tags
 	reportsHandler  services.Messages
 	deviceChecker   services.DevicesHandler
+	unknownSources  services.UnknownSources
+	credentials     services.DeviceCredentials
+	probes          services.Probes
+	deviceStatus    services.DeviceStatus
+	dependencies    services.DeviceDependencies
+	deviceGroups    services.DeviceGroups
+	discovery       services.Discovery
+	deviceAddresses services.DeviceAddresses
+	subscriptions   services.ReportSubscriptions
+	ingestAuth      string
+	ingestStrictIP  bool
+	otlpAddressAttr string
 }
 
 type Config struct {
@@ -39,6 +51,18 @@ type Config struct {
 	TagsHandler     services.Tags
 	ReportsHandler  services.Messages
 	DeviceChecker   services.DevicesHandler
+	UnknownSources  services.UnknownSources
+	Credentials     services.DeviceCredentials
+	Probes          services.Probes
+	DeviceStatus    services.DeviceStatus
+	Dependencies    services.DeviceDependencies
+	DeviceGroups    services.DeviceGroups
+	Discovery       services.Discovery
+	DeviceAddresses services.DeviceAddresses
+	Subscriptions   services.ReportSubscriptions
+	IngestAuth      string
+	IngestStrictIP  bool
+	OTLPAddressAttr string
 }
 
 func NewServer(cfg Config) *Server {
@@ -52,6 +76,18 @@ func NewServer(cfg Config) *Server {
 		reportsHandler:  cfg.ReportsHandler,
 		tokenLifeTime:   cfg.TokenLifeTime,
 		deviceChecker:   cfg.DeviceChecker,
+		unknownSources:  cfg.UnknownSources,
+		credentials:     cfg.Credentials,
+		probes:          cfg.Probes,
+		deviceStatus:    cfg.DeviceStatus,
+		dependencies:    cfg.Dependencies,
+		deviceGroups:    cfg.DeviceGroups,
+		discovery:       cfg.Discovery,
+		deviceAddresses: cfg.DeviceAddresses,
+		subscriptions:   cfg.Subscriptions,
+		ingestAuth:      cfg.IngestAuth,
+		ingestStrictIP:  cfg.IngestStrictIP,
+		otlpAddressAttr: cfg.OTLPAddressAttr,
 		app:             nil,
 	}
 
@@ -173,6 +209,19 @@ func (s *Server) setHandlers() {
 		ReportsHandlers: s.reportsHandler,
 		TokenLifeTime:   s.tokenLifeTime,
 		DeviceChecker:   s.deviceChecker,
+		UnknownSources:  s.unknownSources,
+		Credentials:     s.credentials,
+		Probes:          s.probes,
+		DeviceStatus:    s.deviceStatus,
+		Dependencies:    s.dependencies,
+		DeviceGroups:    s.deviceGroups,
+		Discovery:       s.discovery,
+		DeviceAddresses: s.deviceAddresses,
+		Subscriptions:   s.subscriptions,
+		IngestAuth:      s.ingestAuth,
+		IngestStrictIP:  s.ingestStrictIP,
+		OTLPAddressAttr: s.otlpAddressAttr,
+		Log:             s.log,
 	})
 	{
 		apiV1 := rootRoute.Group("/v1")
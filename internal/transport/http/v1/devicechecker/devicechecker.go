@@ -1,78 +1,191 @@
 package devicechecker
 
 import (
+	"context"
 	"fmt"
 	"monolith/internal/models"
 	"monolith/internal/services"
-	"net/http"
+	"sync"
+	"time"
 
-	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 )
 
-type devicechecekrHandler struct {
-	deviceService  services.DeviceHandler
-	messageService services.Messages
+const (
+	// schedulerTick is the resolution of probe intervals.
+	schedulerTick = time.Second
 
-	deviceCheckPeriod int
-	cron              *cron.Cron
+	defaultWorkers = 8
+)
+
+// deviceCheckerHandler runs the probes of every device at their own
+// interval. A probe is dispatched to a bounded pool of workers and is not
+// dispatched again while its previous run is still in flight, so a slow
+// device never occupies more than one worker per probe. Results go to the
+// device status service, which reports transitions.
+type deviceCheckerHandler struct {
+	probes services.Probes
+	status services.DeviceStatus
+
+	workers int
+	jobs    chan models.Probe
+	probers map[string]prober
+
+	inFlightMutex sync.Mutex
+	inFlight      map[int32]struct{}
+	lastRun       map[int32]time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
 
 	log *zap.Logger
 }
 
 type Config struct {
-	DeviceService  services.DeviceHandler
-	MessageService services.Messages
+	Probes services.Probes
+	Status services.DeviceStatus
 
-	DeviceCheckPeriod int
-	Logger            *zap.Logger
+	// Workers bounds the number of probes running at the same time.
+	Workers int
+	Logger  *zap.Logger
 }
 
-func NewDeviceCheckerHandler(cfg *Config) *devicechecekrHandler {
-	cron := cron.New(cron.WithSeconds())
-	return &devicechecekrHandler{
-		deviceService:     cfg.DeviceService,
-		deviceCheckPeriod: cfg.DeviceCheckPeriod,
-		cron:              cron,
-		log:               cfg.Logger,
+func NewDeviceCheckerHandler(cfg *Config) *deviceCheckerHandler {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
 	}
-}
 
-func (dch *devicechecekrHandler) Start() {
-	_, err := dch.cron.AddFunc(fmt.Sprintf("*/%d * * * * *", dch.deviceCheckPeriod),
-		func() {
-			ips := dch.deviceService.GetDevicesIPs()
-			for _, ip := range ips {
-				id, ok := dch.deviceService.GetDeviceIDByIp(ip)
-				if !ok {
-					dch.log.Warn("device not found", zap.String("ip", ip))
-					continue
-				}
-				resp, err := http.Get(fmt.Sprintf("http://%s/healthcheck", ip))
-				if err != nil {
-					dch.messageService.Create(models.Message{
-						DeviceId:    id,
-						Message:     fmt.Sprintf("unable to connect to device %s", ip),
-						MessageType: "error",
-						Component:   "General",
-					})
-					return
-				}
-
-				if resp.StatusCode != http.StatusOK {
-					dch.messageService.Create(models.Message{
-						DeviceId:    id,
-						Message:     fmt.Sprintf("device %s status is not OK", ip),
-						MessageType: "error",
-						Component:   "General",
-					})
-				}
-			}
+	return &deviceCheckerHandler{
+		probes:  cfg.Probes,
+		status:  cfg.Status,
+		workers: workers,
+		jobs:    make(chan models.Probe, workers),
+		probers: map[string]prober{
+			models.ProbeTypeHTTP: newHTTPProber(),
+			models.ProbeTypeTCP:  tcpProber{},
+			models.ProbeTypeDNS:  dnsProber{},
 		},
-	)
+		inFlight: map[int32]struct{}{},
+		lastRun:  map[int32]time.Time{},
+		stop:     make(chan struct{}),
+		log:      cfg.Logger,
+	}
+}
+
+func (dch *deviceCheckerHandler) Start() {
+	for range dch.workers {
+		dch.wg.Add(1)
+		go dch.runWorker()
+	}
+
+	dch.wg.Add(1)
+	go dch.runScheduler()
+}
+
+// Stop waits for running probes; they are bounded by their own timeouts.
+func (dch *deviceCheckerHandler) Stop(ctx context.Context) error {
+	close(dch.stop)
+
+	done := make(chan struct{})
+	go func() {
+		dch.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("device checker: %w", ctx.Err())
+	}
+}
+
+func (dch *deviceCheckerHandler) runScheduler() {
+	defer dch.wg.Done()
+	defer close(dch.jobs)
+
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-dch.stop:
+			return
+		case now := <-ticker.C:
+			dch.dispatch(now)
+		}
+	}
+}
+
+// dispatch hands due probes to the workers. A probe that does not fit into
+// the queue stays due and is retried on the next tick.
+func (dch *deviceCheckerHandler) dispatch(now time.Time) {
+	probes := dch.probes.Enabled()
+
+	dch.inFlightMutex.Lock()
+	defer dch.inFlightMutex.Unlock()
+
+	known := make(map[int32]struct{}, len(probes))
+	for _, probe := range probes {
+		known[probe.ID] = struct{}{}
+
+		if _, ok := dch.inFlight[probe.ID]; ok {
+			continue
+		}
+		interval := time.Duration(probe.IntervalSec) * time.Second
+		if last, ok := dch.lastRun[probe.ID]; ok && now.Sub(last) < interval {
+			continue
+		}
+
+		select {
+		case dch.jobs <- probe:
+			dch.inFlight[probe.ID] = struct{}{}
+			dch.lastRun[probe.ID] = now
+		default:
+			return
+		}
+	}
+
+	for id := range dch.lastRun {
+		if _, ok := known[id]; !ok {
+			delete(dch.lastRun, id)
+		}
+	}
+}
+
+func (dch *deviceCheckerHandler) runWorker() {
+	defer dch.wg.Done()
+
+	for probe := range dch.jobs {
+		dch.check(probe)
+
+		dch.inFlightMutex.Lock()
+		delete(dch.inFlight, probe.ID)
+		dch.inFlightMutex.Unlock()
+	}
+}
+
+func (dch *deviceCheckerHandler) check(probe models.Probe) {
+	p, ok := dch.probers[probe.ProbeType]
+	if !ok {
+		dch.log.Warn("unknown probe type", zap.Int32("probe_id", probe.ID), zap.String("type", probe.ProbeType))
+		return
+	}
+
+	host := deviceHost(probe.DeviceAddress)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(probe.TimeoutMs)*time.Millisecond)
+	defer cancel()
+
+	err := p.probe(ctx, probe, host)
 	if err != nil {
-		dch.log.Error("error adding device checker to cron", zap.Error(err))
+		dch.log.Debug("probe failed",
+			zap.Int32("probe_id", probe.ID),
+			zap.Int32("device_id", probe.DeviceID),
+			zap.Error(err),
+		)
 	}
 
-	dch.cron.Start()
+	dch.status.Observe(probe, host, err)
 }
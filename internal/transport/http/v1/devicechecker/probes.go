@@ -0,0 +1,143 @@
+package devicechecker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"monolith/internal/models"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const (
+	// maxProbeBodySize limits how much of an HTTP response is matched
+	// against the body regexp.
+	maxProbeBodySize = 1 << 20
+
+	dnsPort = "53"
+)
+
+type prober interface {
+	// probe returns nil when the device is available. ctx carries the
+	// timeout of the probe.
+	probe(ctx context.Context, probe models.Probe, host string) error
+}
+
+// httpProber requests the target, or http://<device>/healthcheck without a
+// target, and checks the status and optionally the body.
+type httpProber struct {
+	client *http.Client
+}
+
+func newHTTPProber() httpProber {
+	return httpProber{
+		client: &http.Client{
+			// A redirect is reported as the status the device returned.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (p httpProber) probe(ctx context.Context, probe models.Probe, host string) error {
+	target := probe.Target
+	if target == "" {
+		target = "http://" + urlHost(host) + "/healthcheck"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to connect: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if int32(resp.StatusCode) != probe.ExpectedStatus {
+		return fmt.Errorf("status %d, expected %d", resp.StatusCode, probe.ExpectedStatus)
+	}
+
+	if probe.CompiledRegexp == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBodySize))
+	if err != nil {
+		return fmt.Errorf("io.ReadAll: %w", err)
+	}
+	if !probe.CompiledRegexp.Match(body) {
+		return fmt.Errorf("body does not match %q", probe.BodyRegexp)
+	}
+
+	return nil
+}
+
+// tcpProber connects to the target, a port of the device or host:port.
+type tcpProber struct{}
+
+func (tcpProber) probe(ctx context.Context, probe models.Probe, host string) error {
+	address := probe.Target
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(host, probe.Target)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", address, err)
+	}
+
+	return conn.Close()
+}
+
+// dnsProber resolves the target name with the device as the DNS server.
+type dnsProber struct{}
+
+func (dnsProber) probe(ctx context.Context, probe models.Probe, host string) error {
+	if probe.Target == "" {
+		return errors.New("no name to resolve")
+	}
+
+	server := net.JoinHostPort(host, dnsPort)
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+
+	addrs, err := resolver.LookupHost(ctx, probe.Target)
+	if err != nil {
+		return fmt.Errorf("unable to resolve %s: %w", probe.Target, err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("no addresses for %s", probe.Target)
+	}
+
+	return nil
+}
+
+// deviceHost strips the prefix length an inet address may come with.
+func deviceHost(address string) string {
+	if prefix, err := netip.ParsePrefix(address); err == nil {
+		return prefix.Addr().String()
+	}
+
+	return address
+}
+
+func urlHost(host string) string {
+	if strings.Contains(host, ":") {
+		return "[" + host + "]"
+	}
+
+	return host
+}
@@ -0,0 +1,67 @@
+package devicechecker
+
+import (
+	"context"
+	"monolith/internal/models"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultScanTimeout = time.Second
+
+// hostScanner probes hosts for discovery with the probers of the checker:
+// a TCP connect per port and the default /healthcheck request.
+type hostScanner struct {
+	http    httpProber
+	tcp     tcpProber
+	timeout time.Duration
+}
+
+// NewHostScanner bounds every single probe of a host by timeout.
+func NewHostScanner(timeout time.Duration) hostScanner {
+	if timeout <= 0 {
+		timeout = defaultScanTimeout
+	}
+
+	return hostScanner{
+		http:    newHTTPProber(),
+		tcp:     tcpProber{},
+		timeout: timeout,
+	}
+}
+
+func (s hostScanner) Scan(ctx context.Context, host string, ports []int32, healthcheck bool) ([]int32, bool) {
+	openPorts := make([]int32, 0, len(ports))
+	for _, port := range ports {
+		probe := models.Probe{
+			ProbeType: models.ProbeTypeTCP,
+			Target:    strconv.Itoa(int(port)),
+		}
+		if s.run(ctx, s.tcp, probe, host) {
+			openPorts = append(openPorts, port)
+		}
+	}
+
+	healthy := false
+	if healthcheck {
+		probe := models.Probe{
+			ProbeType:      models.ProbeTypeHTTP,
+			ExpectedStatus: http.StatusOK,
+		}
+		healthy = s.run(ctx, s.http, probe, host)
+	}
+
+	return openPorts, healthy
+}
+
+func (s hostScanner) run(ctx context.Context, p prober, probe models.Probe, host string) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return p.probe(probeCtx, probe, host) == nil
+}
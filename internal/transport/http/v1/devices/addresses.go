@@ -0,0 +1,257 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/internal/services"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type (
+	addAddressReq struct {
+		DeviceID    int32  `form:"device_id"     json:"device_id"     validate:"required"                xml:"device_id"`
+		AddressType string `form:"address_type"  json:"address_type"  validate:"oneof=ip cidr hostname"  xml:"address_type"`
+		Value       string `form:"value"         json:"value"         validate:"required"                xml:"value"`
+	}
+
+	addressResp struct {
+		Data models.DeviceAddress `json:"data"`
+	}
+)
+
+// addAddress attaches an IP, a CIDR range or a hostname to the device. An
+// address taken by another device is rejected with 409.
+func (h *devicesHandler) addAddress(ctx fiber.Ctx) error {
+	body := addAddressReq{
+		DeviceID:    0,
+		AddressType: "",
+		Value:       "",
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	res, err := h.addresses.Add(context.Background(), models.DeviceAddress{
+		DeviceID:    body.DeviceID,
+		AddressType: body.AddressType,
+		Value:       body.Value,
+	})
+	if err != nil {
+		return fiber.NewError(
+			addressErrorStatus(err),
+			fmt.Errorf("h.addresses.Add: %w", err).Error(),
+		)
+	}
+
+	h.messages.UpdateDevices()
+	h.deviceChecker.UpdateDevices()
+
+	jsonResponse, err := jsoniter.Marshal(
+		&addressResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	// readAddressesReq lists the addresses of every device when DeviceID
+	// is not given.
+	readAddressesReq struct {
+		DeviceID int32 `query:"device_id" validate:"omitempty,min=1"`
+	}
+
+	readAddressesResp struct {
+		Data []models.DeviceAddress `json:"data"`
+	}
+)
+
+func (h *devicesHandler) readAddresses(ctx fiber.Ctx) error {
+	query := readAddressesReq{
+		DeviceID: 0,
+	}
+
+	if err := ctx.Bind().Query(&query); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Query: %w", err).Error(),
+		)
+	}
+
+	res, err := h.addresses.Read(context.Background(), query.DeviceID)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("h.addresses.Read: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&readAddressesResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	deleteAddressReq struct {
+		ID int32 `form:"id"  json:"id"  validate:"required"  xml:"id"`
+	}
+)
+
+func (h *devicesHandler) deleteAddress(ctx fiber.Ctx) error {
+	body := deleteAddressReq{
+		ID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	err := h.addresses.Delete(context.Background(), body.ID)
+	if err != nil {
+		return fiber.NewError(
+			addressErrorStatus(err),
+			fmt.Errorf("h.addresses.Delete: %w", err).Error(),
+		)
+	}
+
+	h.messages.UpdateDevices()
+	h.deviceChecker.UpdateDevices()
+
+	jsonResponse, err := jsoniter.Marshal(
+		&deleteResp{
+			Data: fiber.StatusOK,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	lookupAddressReq struct {
+		Address string `query:"address" validate:"required"`
+	}
+
+	lookupAddressResult struct {
+		DeviceID int32 `json:"device_id"`
+	}
+
+	lookupAddressResp struct {
+		Data lookupAddressResult `json:"data"`
+	}
+)
+
+// lookupAddress shows which device messages from the address are attributed
+// to, the same way ingestion resolves them.
+func (h *devicesHandler) lookupAddress(ctx fiber.Ctx) error {
+	query := lookupAddressReq{
+		Address: "",
+	}
+
+	if err := ctx.Bind().Query(&query); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Query: %w", err).Error(),
+		)
+	}
+
+	deviceID, ok := h.deviceChecker.GetDeviceIDByIp(query.Address)
+	if !ok {
+		return fiber.NewError(
+			fiber.StatusNotFound,
+			fmt.Errorf("h.deviceChecker.GetDeviceIDByIp: no device has address %s", query.Address).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&lookupAddressResp{
+			Data: lookupAddressResult{
+				DeviceID: deviceID,
+			},
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+func addressErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidDeviceAddress):
+		return fiber.StatusUnprocessableEntity
+	case errors.Is(err, repo.ErrDeviceNotFound), errors.Is(err, repo.ErrDeviceAddressNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, repo.ErrDeviceAddressExists):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
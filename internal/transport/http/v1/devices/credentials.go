@@ -0,0 +1,131 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monolith/internal/repo"
+	"monolith/internal/services"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type (
+	credentialReq struct {
+		DeviceID int32 `form:"device_id"  json:"device_id"  validate:"required"  xml:"device_id"`
+	}
+
+	credentialResp struct {
+		Data services.IssueDeviceCredentialResult `json:"data"`
+	}
+)
+
+// issueCredential returns the token in plain text. It is the only time the
+// token is available, the database keeps just its hash.
+func (h *devicesHandler) issueCredential(ctx fiber.Ctx) error {
+	return h.sendCredential(ctx, h.credentials.Issue)
+}
+
+func (h *devicesHandler) rotateCredential(ctx fiber.Ctx) error {
+	return h.sendCredential(ctx, h.credentials.Rotate)
+}
+
+func (h *devicesHandler) sendCredential(
+	ctx fiber.Ctx,
+	issue func(ctx context.Context, deviceID int32) (services.IssueDeviceCredentialResult, error),
+) error {
+	body := credentialReq{
+		DeviceID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	res, err := issue(context.Background(), body.DeviceID)
+	if err != nil {
+		return fiber.NewError(
+			credentialErrorStatus(err),
+			fmt.Errorf("h.credentials.Issue: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&credentialResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+func (h *devicesHandler) revokeCredential(ctx fiber.Ctx) error {
+	body := credentialReq{
+		DeviceID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	err := h.credentials.Revoke(context.Background(), body.DeviceID)
+	if err != nil {
+		return fiber.NewError(
+			credentialErrorStatus(err),
+			fmt.Errorf("h.credentials.Revoke: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&deleteResp{
+			Data: fiber.StatusOK,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+func credentialErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repo.ErrDeviceNotFound), errors.Is(err, repo.ErrDeviceCredentialNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, repo.ErrDeviceCredentialExists):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,227 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/internal/services"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type (
+	dependencyReq struct {
+		DeviceID int32 `form:"device_id"  json:"device_id"  validate:"required"  xml:"device_id"`
+		ParentID int32 `form:"parent_id"  json:"parent_id"  validate:"required"  xml:"parent_id"`
+	}
+
+	dependencyResp struct {
+		Data models.DeviceDependency `json:"data"`
+	}
+)
+
+// addDependency makes parent_id upstream of device_id. A dependency that
+// would close a cycle is rejected with 409.
+func (h *devicesHandler) addDependency(ctx fiber.Ctx) error {
+	body := dependencyReq{
+		DeviceID: 0,
+		ParentID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	res, err := h.dependencies.Add(context.Background(), body.DeviceID, body.ParentID)
+	if err != nil {
+		return fiber.NewError(
+			dependencyErrorStatus(err),
+			fmt.Errorf("h.dependencies.Add: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&dependencyResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	readDependenciesResp struct {
+		Data []models.DeviceDependency `json:"data"`
+	}
+)
+
+func (h *devicesHandler) readDependencies(ctx fiber.Ctx) error {
+	res, err := h.dependencies.Read(context.Background())
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("h.dependencies.Read: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&readDependenciesResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+func (h *devicesHandler) removeDependency(ctx fiber.Ctx) error {
+	body := dependencyReq{
+		DeviceID: 0,
+		ParentID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	err := h.dependencies.Remove(context.Background(), body.DeviceID, body.ParentID)
+	if err != nil {
+		return fiber.NewError(
+			dependencyErrorStatus(err),
+			fmt.Errorf("h.dependencies.Remove: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&deleteResp{
+			Data: fiber.StatusOK,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	impactReq struct {
+		DeviceID int32 `query:"device_id" validate:"required"`
+	}
+
+	impactResult struct {
+		Tree services.ImpactNode `json:"tree"`
+		// Affected counts the distinct dependent devices.
+		Affected int `json:"affected"`
+	}
+
+	impactResp struct {
+		Data impactResult `json:"data"`
+	}
+)
+
+// impact returns the tree of devices that depend on the device, with their
+// current statuses.
+func (h *devicesHandler) impact(ctx fiber.Ctx) error {
+	body := impactReq{
+		DeviceID: 0,
+	}
+
+	if err := ctx.Bind().Query(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Query: %w", err).Error(),
+		)
+	}
+
+	tree := h.dependencies.Impact(body.DeviceID)
+
+	jsonResponse, err := jsoniter.Marshal(
+		&impactResp{
+			Data: impactResult{
+				Tree:     tree,
+				Affected: len(impactDevices(tree, map[int32]struct{}{})) - 1,
+			},
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+func impactDevices(node services.ImpactNode, devices map[int32]struct{}) map[int32]struct{} {
+	devices[node.DeviceID] = struct{}{}
+	for _, child := range node.Children {
+		impactDevices(child, devices)
+	}
+
+	return devices
+}
+
+func dependencyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repo.ErrDeviceNotFound), errors.Is(err, repo.ErrDependencyNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, repo.ErrDependencyExists), errors.Is(err, repo.ErrDependencyCycle):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/samber/lo"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/golang-jwt/jwt/v5"
@@ -23,6 +24,11 @@ type devicesHandler struct {
 	devicesService services.Devices
 	messages       services.Messages
 	deviceChecker  services.DevicesHandler
+	credentials    services.DeviceCredentials
+	probes         services.Probes
+	status         services.DeviceStatus
+	dependencies   services.DeviceDependencies
+	addresses      services.DeviceAddresses
 	jwtKey         string
 }
 
@@ -31,6 +37,11 @@ type Config struct {
 	DevicesService services.Devices
 	Messages       services.Messages
 	DeviceChecker  services.DevicesHandler
+	Credentials    services.DeviceCredentials
+	Probes         services.Probes
+	Status         services.DeviceStatus
+	Dependencies   services.DeviceDependencies
+	Addresses      services.DeviceAddresses
 }
 
 func NewDevicesHandler(cfg *Config) *devicesHandler {
@@ -39,6 +50,11 @@ func NewDevicesHandler(cfg *Config) *devicesHandler {
 		devicesService: cfg.DevicesService,
 		messages:       cfg.Messages,
 		deviceChecker:  cfg.DeviceChecker,
+		credentials:    cfg.Credentials,
+		probes:         cfg.Probes,
+		status:         cfg.Status,
+		dependencies:   cfg.Dependencies,
+		addresses:      cfg.Addresses,
 	}
 }
 
@@ -48,15 +64,37 @@ func (h *devicesHandler) InitDevicesRoutes(api fiber.Router) {
 	servicesRoute.Get("/read", h.read)
 	servicesRoute.Put("/update", h.update)
 	servicesRoute.Delete("/delete", h.delete)
+	servicesRoute.Post("/credentials/issue", h.issueCredential)
+	servicesRoute.Post("/credentials/rotate", h.rotateCredential)
+	servicesRoute.Delete("/credentials/revoke", h.revokeCredential)
+	servicesRoute.Post("/probes/create", h.createProbe)
+	servicesRoute.Get("/probes/read", h.readProbes)
+	servicesRoute.Put("/probes/update", h.updateProbe)
+	servicesRoute.Delete("/probes/delete", h.deleteProbe)
+	servicesRoute.Get("/status", h.readStatus)
+	servicesRoute.Post("/dependencies/add", h.addDependency)
+	servicesRoute.Get("/dependencies/read", h.readDependencies)
+	servicesRoute.Delete("/dependencies/remove", h.removeDependency)
+	servicesRoute.Get("/impact", h.impact)
+	servicesRoute.Post("/import", h.importDevices)
+	servicesRoute.Get("/export", h.exportDevices)
+	servicesRoute.Post("/addresses/add", h.addAddress)
+	servicesRoute.Get("/addresses/read", h.readAddresses)
+	servicesRoute.Delete("/addresses/delete", h.deleteAddress)
+	servicesRoute.Get("/addresses/lookup", h.lookupAddress)
 
 }
 
 type (
+	// Attributes hold vendor, model, serial, firmware, location, owner team
+	// and alike; Labels are what selectors match.
 	registerReq struct {
-		Name        string  `form:"name"         json:"name"         validate:"required"       xml:"name"`
-		DeviceType  string  `form:"device_type"  json:"device_type"  validate:"required"       xml:"device_type"`
-		Address     string  `form:"address"      json:"address"      validate:"required,ip"    xml:"address"`
-		Responsible []int32 `form:"responsible"  json:"responsible"  validate:"required"       xml:"responsible"`
+		Name        string            `form:"name"         json:"name"         validate:"required"       xml:"name"         yaml:"name"`
+		DeviceType  string            `form:"device_type"  json:"device_type"  validate:"required"       xml:"device_type"  yaml:"device_type"`
+		Address     string            `form:"address"      json:"address"      validate:"required,ip"    xml:"address"      yaml:"address"`
+		Responsible []int32           `form:"responsible"  json:"responsible"  validate:"required"       xml:"responsible"  yaml:"responsible"`
+		Attributes  map[string]string `form:"attributes"   json:"attributes"   validate:"omitempty"      xml:"attributes"   yaml:"attributes,omitempty"`
+		Labels      map[string]string `form:"labels"       json:"labels"       validate:"omitempty"      xml:"labels"       yaml:"labels,omitempty"`
 	}
 
 	registerResp struct {
@@ -86,6 +124,8 @@ func (h *devicesHandler) create(ctx fiber.Ctx) error {
 			DeviceType:  body.DeviceType,
 			Address:     body.Address,
 			Responsible: body.Responsible,
+			Attributes:  body.Attributes,
+			Labels:      body.Labels,
 		},
 	)
 	if err != nil {
@@ -121,6 +161,16 @@ func (h *devicesHandler) create(ctx fiber.Ctx) error {
 }
 
 type (
+	// readReq limits the listing to the devices of the group and its
+	// subgroups.
+	// Labels and Attributes are selectors such as
+	// "env=prod,vendor in (cisco,juniper)".
+	readReq struct {
+		GroupID    int32  `query:"group_id"   validate:"omitempty,min=1"`
+		Labels     string `query:"labels"     validate:"omitempty"`
+		Attributes string `query:"attributes" validate:"omitempty"`
+	}
+
 	read struct {
 		Data services.ReadDevicesResult `json:"data"`
 	}
@@ -136,11 +186,35 @@ func (h *devicesHandler) read(ctx fiber.Ctx) error {
 		)
 	}
 
-	res, err := h.devicesService.Read(context.Background())
+	query := readReq{
+		GroupID:    0,
+		Labels:     "",
+		Attributes: "",
+	}
+
+	if err := ctx.Bind().Query(&query); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Query: %w", err).Error(),
+		)
+	}
+
+	res, err := h.devicesService.ReadFiltered(
+		context.Background(),
+		services.ReadDevicesParams{
+			GroupID:    query.GroupID,
+			Labels:     query.Labels,
+			Attributes: query.Attributes,
+		},
+	)
 	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidSelector) {
+			status = fiber.StatusBadRequest
+		}
 		return fiber.NewError(
-			fiber.StatusInternalServerError,
-			fmt.Errorf("h.devicesService.PublishRead: %w", err).Error(),
+			status,
+			fmt.Errorf("h.devicesService.ReadFiltered: %w", err).Error(),
 		)
 	}
 
@@ -166,12 +240,15 @@ func (h *devicesHandler) read(ctx fiber.Ctx) error {
 }
 
 type (
+	// Attributes and Labels replace the stored maps when given.
 	updateReq struct {
-		ID          int32   `form:"id"           json:"id"           validate:"required"       xml:"id"`
-		Name        string  `form:"name"         json:"name"         validate:"omitempty"       xml:"name"`
-		DeviceType  string  `form:"device_type"  json:"device_type"  validate:"omitempty"       xml:"device_type"`
-		Address     string  `form:"address"      json:"address"      validate:"omitempty,ip"    xml:"address"`
-		Responsible []int32 `form:"responsible"  json:"responsible"  validate:"omitempty"       xml:"responsible"`
+		ID          int32             `form:"id"           json:"id"           validate:"required"       xml:"id"`
+		Name        string            `form:"name"         json:"name"         validate:"omitempty"       xml:"name"`
+		DeviceType  string            `form:"device_type"  json:"device_type"  validate:"omitempty"       xml:"device_type"`
+		Address     string            `form:"address"      json:"address"      validate:"omitempty,ip"    xml:"address"`
+		Responsible []int32           `form:"responsible"  json:"responsible"  validate:"omitempty"       xml:"responsible"`
+		Attributes  map[string]string `form:"attributes"   json:"attributes"   validate:"omitempty"      xml:"attributes"`
+		Labels      map[string]string `form:"labels"       json:"labels"       validate:"omitempty"      xml:"labels"`
 	}
 
 	updateResp struct {
@@ -204,21 +281,25 @@ func (h *devicesHandler) update(ctx fiber.Ctx) error {
 	}
 
 	if body.DeviceType == "" && body.Address == "" &&
-		len(body.Responsible) == 0 && body.Name == "" {
+		len(body.Responsible) == 0 && body.Name == "" &&
+		body.Attributes == nil && body.Labels == nil {
 		return fiber.NewError(
 			fiber.StatusBadRequest,
-			errors.New(`body.DeviceType == "" && body.Address == "" && len(body.Responsible) == 0 && body.Name == ""`).Error(),
+			errors.New("nothing to update").Error(),
 		)
 	}
 
+	// Empty fields are left as they are.
 	err := h.devicesService.Update(
 		context.Background(),
 		services.UpdateDeviceParams{
 			ID:          body.ID,
-			Name:        &body.Name,
-			DeviceType:  &body.DeviceType,
-			Address:     &body.Address,
+			Name:        lo.EmptyableToPtr(body.Name),
+			DeviceType:  lo.EmptyableToPtr(body.DeviceType),
+			Address:     lo.EmptyableToPtr(body.Address),
 			Responsible: body.Responsible,
+			Attributes:  body.Attributes,
+			Labels:      body.Labels,
 		},
 	)
 	if err != nil {
@@ -227,8 +308,12 @@ func (h *devicesHandler) update(ctx fiber.Ctx) error {
 			fmt.Errorf("h.devicesService.Update: %w", err).Error(),
 		)
 	}
+	// Credentials and probes are cached with the address of their device,
+	// so they are reloaded along with the devices.
 	h.messages.UpdateDevices()
 	h.deviceChecker.UpdateDevices()
+	h.credentials.UpdateCredentials()
+	h.probes.UpdateProbes()
 
 	jsonResponse, err := jsoniter.Marshal(
 		&updateResp{
@@ -295,6 +380,9 @@ func (h *devicesHandler) delete(ctx fiber.Ctx) error {
 
 	h.messages.UpdateDevices()
 	h.deviceChecker.UpdateDevices()
+	h.credentials.UpdateCredentials()
+	h.probes.UpdateProbes()
+	h.dependencies.UpdateDependencies()
 
 	jsonResponse, err := jsoniter.Marshal(
 		&deleteResp{
@@ -0,0 +1,295 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/internal/services"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type (
+	createProbeReq struct {
+		DeviceID       int32  `form:"device_id"        json:"device_id"        validate:"required"                 xml:"device_id"`
+		ProbeType      string `form:"probe_type"       json:"probe_type"       validate:"required,oneof=http tcp dns" xml:"probe_type"`
+		Target         string `form:"target"           json:"target"           validate:"omitempty"                xml:"target"`
+		ExpectedStatus int32  `form:"expected_status"  json:"expected_status"  validate:"omitempty,min=100,max=599" xml:"expected_status"`
+		BodyRegexp     string `form:"body_regexp"      json:"body_regexp"      validate:"omitempty"                xml:"body_regexp"`
+		IntervalSec    int32  `form:"interval_sec"     json:"interval_sec"     validate:"omitempty,min=1"          xml:"interval_sec"`
+		TimeoutMs      int32  `form:"timeout_ms"       json:"timeout_ms"       validate:"omitempty,min=1"          xml:"timeout_ms"`
+		Enabled        *bool  `form:"enabled"          json:"enabled"          validate:"omitempty"                xml:"enabled"`
+	}
+
+	probeResp struct {
+		Data models.Probe `json:"data"`
+	}
+)
+
+func (h *devicesHandler) createProbe(ctx fiber.Ctx) error {
+	body := createProbeReq{
+		DeviceID:  0,
+		ProbeType: "",
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	enabled := true
+	if body.Enabled != nil {
+		enabled = *body.Enabled
+	}
+
+	res, err := h.probes.Create(
+		context.Background(),
+		models.Probe{
+			DeviceID:       body.DeviceID,
+			ProbeType:      body.ProbeType,
+			Target:         body.Target,
+			ExpectedStatus: body.ExpectedStatus,
+			BodyRegexp:     body.BodyRegexp,
+			IntervalSec:    body.IntervalSec,
+			TimeoutMs:      body.TimeoutMs,
+			Enabled:        enabled,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			probeErrorStatus(err),
+			fmt.Errorf("h.probes.Create: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&probeResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	readProbesResp struct {
+		Data services.ReadProbesResult `json:"data"`
+	}
+)
+
+func (h *devicesHandler) readProbes(ctx fiber.Ctx) error {
+	res, err := h.probes.Read(context.Background())
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("h.probes.Read: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&readProbesResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	updateProbeReq struct {
+		ID             int32   `form:"id"               json:"id"               validate:"required"                    xml:"id"`
+		ProbeType      *string `form:"probe_type"       json:"probe_type"       validate:"omitempty,oneof=http tcp dns" xml:"probe_type"`
+		Target         *string `form:"target"           json:"target"           validate:"omitempty"                   xml:"target"`
+		ExpectedStatus *int32  `form:"expected_status"  json:"expected_status"  validate:"omitempty,min=100,max=599"    xml:"expected_status"`
+		BodyRegexp     *string `form:"body_regexp"      json:"body_regexp"      validate:"omitempty"                   xml:"body_regexp"`
+		IntervalSec    *int32  `form:"interval_sec"     json:"interval_sec"     validate:"omitempty,min=1"             xml:"interval_sec"`
+		TimeoutMs      *int32  `form:"timeout_ms"       json:"timeout_ms"       validate:"omitempty,min=1"             xml:"timeout_ms"`
+		Enabled        *bool   `form:"enabled"          json:"enabled"          validate:"omitempty"                   xml:"enabled"`
+	}
+)
+
+func (h *devicesHandler) updateProbe(ctx fiber.Ctx) error {
+	body := updateProbeReq{
+		ID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	if body.ProbeType == nil && body.Target == nil && body.ExpectedStatus == nil && body.BodyRegexp == nil &&
+		body.IntervalSec == nil && body.TimeoutMs == nil && body.Enabled == nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			errors.New("nothing to update").Error(),
+		)
+	}
+
+	err := h.probes.Update(
+		context.Background(),
+		services.UpdateProbeParams{
+			ID:             body.ID,
+			ProbeType:      body.ProbeType,
+			Target:         body.Target,
+			ExpectedStatus: body.ExpectedStatus,
+			BodyRegexp:     body.BodyRegexp,
+			IntervalSec:    body.IntervalSec,
+			TimeoutMs:      body.TimeoutMs,
+			Enabled:        body.Enabled,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			probeErrorStatus(err),
+			fmt.Errorf("h.probes.Update: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&updateResp{
+			Data: fiber.StatusOK,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+func (h *devicesHandler) deleteProbe(ctx fiber.Ctx) error {
+	body := deleteReq{
+		ID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	err := h.probes.Delete(context.Background(), int32(body.ID))
+	if err != nil {
+		return fiber.NewError(
+			probeErrorStatus(err),
+			fmt.Errorf("h.probes.Delete: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&deleteResp{
+			Data: fiber.StatusOK,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	readStatusResp struct {
+		Data []models.DeviceStatusChange `json:"data"`
+	}
+)
+
+// readStatus returns the current status of every device that has been
+// probed, together with the change that led to it.
+func (h *devicesHandler) readStatus(ctx fiber.Ctx) error {
+	res, err := h.status.Current(context.Background())
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("h.status.Current: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&readStatusResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+func probeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repo.ErrDeviceNotFound), errors.Is(err, repo.ErrProbeNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrInvalidProbe):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,522 @@
+package devices
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"monolith/internal/models"
+	"monolith/internal/services"
+	"net/netip"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	formatCSV  = "csv"
+	formatYAML = "yaml"
+
+	maxImportSize = 5000
+)
+
+// transferColumns is the CSV header of an import or export. Responsible
+// holds user ids and the maps hold key=value pairs, both separated by
+// semicolons.
+var transferColumns = []string{"name", "device_type", "address", "responsible", "attributes", "labels"}
+
+type (
+	importReq struct {
+		Format string `query:"format"  validate:"omitempty,oneof=csv yaml"`
+		DryRun bool   `query:"dry_run"`
+	}
+
+	importRowResult struct {
+		Index  int      `json:"index"`
+		Name   string   `json:"name,omitempty"`
+		Status int      `json:"status"`
+		Errors []string `json:"errors,omitempty"`
+		ID     int32    `json:"id,omitempty"`
+	}
+
+	importResult struct {
+		DryRun    bool              `json:"dry_run"`
+		Applied   bool              `json:"applied"`
+		Total     int               `json:"total"`
+		Invalid   int               `json:"invalid"`
+		Conflicts int               `json:"conflicts"`
+		Rows      []importRowResult `json:"rows"`
+	}
+
+	importResp struct {
+		Data importResult `json:"data"`
+	}
+)
+
+// importDevices creates devices from a CSV or YAML list of registerReq
+// items. Each item is validated like a create request and checked against
+// the stored names and addresses. The import is applied only when every
+// item passes, otherwise the response reports the failing items with 422
+// for invalid ones and 409 for conflicting ones. A dry run reports the same
+// without creating anything.
+func (h *devicesHandler) importDevices(ctx fiber.Ctx) error {
+	query := importReq{
+		Format: "",
+		DryRun: false,
+	}
+
+	if err := ctx.Bind().Query(&query); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Query: %w", err).Error(),
+		)
+	}
+
+	format := query.Format
+	if format == "" {
+		format = formatFromContentType(ctx.Get(fiber.HeaderContentType))
+	}
+
+	var (
+		items     []registerReq
+		rowErrors []error
+		err       error
+	)
+	switch format {
+	case formatCSV:
+		items, rowErrors, err = decodeDevicesCSV(ctx.Body())
+	case formatYAML:
+		items, rowErrors, err = decodeDevicesYAML(ctx.Body())
+	default:
+		return fiber.NewError(
+			fiber.StatusUnsupportedMediaType,
+			errors.New("format must be csv or yaml").Error(),
+		)
+	}
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("decode %s: %w", format, err).Error(),
+		)
+	}
+
+	if len(items) == 0 {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			errors.New("import is empty").Error(),
+		)
+	}
+
+	if len(items) > maxImportSize {
+		return fiber.NewError(
+			fiber.StatusRequestEntityTooLarge,
+			fmt.Errorf("import size %d exceeds limit %d", len(items), maxImportSize).Error(),
+		)
+	}
+
+	validator := ctx.App().Config().StructValidator
+	result := importResult{
+		DryRun: query.DryRun,
+		Total:  len(items),
+		Rows:   make([]importRowResult, len(items)),
+	}
+	devices := make([]models.Device, 0, len(items))
+	valid := make([]int, 0, len(items))
+	for i, item := range items {
+		result.Rows[i].Index = i
+		result.Rows[i].Name = item.Name
+
+		if rowErrors[i] != nil {
+			result.Rows[i].Status = fiber.StatusUnprocessableEntity
+			result.Rows[i].Errors = []string{rowErrors[i].Error()}
+			result.Invalid++
+			continue
+		}
+
+		if err := validator.Validate(&item); err != nil {
+			result.Rows[i].Status = fiber.StatusUnprocessableEntity
+			result.Rows[i].Errors = []string{fmt.Errorf("validator.Validate: %w", err).Error()}
+			result.Invalid++
+			continue
+		}
+
+		devices = append(devices, models.Device{
+			Name:        item.Name,
+			DeviceType:  item.DeviceType,
+			Address:     item.Address,
+			Responsible: item.Responsible,
+			Attributes:  item.Attributes,
+			Labels:      item.Labels,
+		})
+		valid = append(valid, i)
+	}
+
+	// Invalid items still get their conflicts reported, but nothing is
+	// applied.
+	imported, err := h.devicesService.Import(
+		context.Background(),
+		devices,
+		query.DryRun || result.Invalid != 0,
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("h.devicesService.Import: %w", err).Error(),
+		)
+	}
+
+	for j, i := range valid {
+		if conflicts, ok := imported.Conflicts[j]; ok {
+			result.Rows[i].Status = fiber.StatusConflict
+			result.Rows[i].Errors = conflicts
+			result.Conflicts++
+			continue
+		}
+		result.Rows[i].Status = fiber.StatusOK
+		if len(imported.Created) != 0 {
+			result.Rows[i].Status = fiber.StatusCreated
+			result.Rows[i].ID = imported.Created[j].ID
+		}
+	}
+	result.Applied = len(imported.Created) != 0
+
+	if result.Applied {
+		h.messages.UpdateDevices()
+		h.deviceChecker.UpdateDevices()
+	}
+
+	status := fiber.StatusOK
+	switch {
+	case result.Invalid != 0:
+		status = fiber.StatusUnprocessableEntity
+	case result.Conflicts != 0:
+		status = fiber.StatusConflict
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&importResp{
+			Data: result,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(status).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	// exportReq takes the filters of readReq.
+	exportReq struct {
+		Format     string `query:"format"     validate:"omitempty,oneof=csv yaml"`
+		GroupID    int32  `query:"group_id"   validate:"omitempty,min=1"`
+		Labels     string `query:"labels"     validate:"omitempty"`
+		Attributes string `query:"attributes" validate:"omitempty"`
+	}
+)
+
+// exportDevices returns the inventory as an attachment in the format the
+// import takes, CSV unless asked otherwise.
+func (h *devicesHandler) exportDevices(ctx fiber.Ctx) error {
+	query := exportReq{
+		Format:     "",
+		GroupID:    0,
+		Labels:     "",
+		Attributes: "",
+	}
+
+	if err := ctx.Bind().Query(&query); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Query: %w", err).Error(),
+		)
+	}
+
+	res, err := h.devicesService.ReadFiltered(
+		context.Background(),
+		services.ReadDevicesParams{
+			GroupID:    query.GroupID,
+			Labels:     query.Labels,
+			Attributes: query.Attributes,
+		},
+	)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidSelector) {
+			status = fiber.StatusBadRequest
+		}
+		return fiber.NewError(
+			status,
+			fmt.Errorf("h.devicesService.ReadFiltered: %w", err).Error(),
+		)
+	}
+
+	items := make([]registerReq, 0, len(res.Devices))
+	for _, d := range res.Devices {
+		items = append(items, registerReq{
+			Name:        d.Name,
+			DeviceType:  d.DeviceType,
+			Address:     hostAddress(d.Address),
+			Responsible: d.Responsible,
+			Attributes:  d.Attributes,
+			Labels:      d.Labels,
+		})
+	}
+
+	var (
+		export      []byte
+		contentType string
+	)
+	switch query.Format {
+	case formatYAML:
+		export, err = yaml.Marshal(items)
+		contentType = "application/yaml"
+	default:
+		query.Format = formatCSV
+		export, err = encodeDevicesCSV(items)
+		contentType = "text/csv"
+	}
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("encode %s: %w", query.Format, err).Error(),
+		)
+	}
+
+	ctx.Attachment("devices." + query.Format)
+	ctx.Set(fiber.HeaderContentType, contentType)
+
+	if err = ctx.Status(fiber.StatusOK).Send(export); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+// hostAddress drops the mask postgres may give a host address, so the
+// export passes the ip validation of the import.
+func hostAddress(address string) string {
+	if prefix, err := netip.ParsePrefix(address); err == nil && prefix.IsSingleIP() {
+		return prefix.Addr().String()
+	}
+
+	return address
+}
+
+func formatFromContentType(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "csv"):
+		return formatCSV
+	case strings.Contains(contentType, "yaml"):
+		return formatYAML
+	default:
+		return ""
+	}
+}
+
+// decodeDevicesCSV reads a CSV with a header naming the transferColumns in
+// any order. A row that does not parse is reported in the returned slice
+// at its index rather than failing the whole file.
+func decodeDevicesCSV(data []byte) ([]registerReq, []error, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("r.Read: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(transferColumns, name) {
+			return nil, nil, fmt.Errorf("unknown column %q", name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, nil, fmt.Errorf("duplicate column %q", name)
+		}
+		columns[name] = i
+	}
+	r.FieldsPerRecord = len(header)
+
+	var (
+		items     []registerReq
+		rowErrors []error
+	)
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if !errors.Is(err, csv.ErrFieldCount) {
+				return nil, nil, fmt.Errorf("r.Read: %w", err)
+			}
+			items = append(items, registerReq{})
+			rowErrors = append(rowErrors, err)
+			continue
+		}
+
+		item, err := decodeDeviceRecord(record, columns)
+		items = append(items, item)
+		rowErrors = append(rowErrors, err)
+	}
+
+	return items, rowErrors, nil
+}
+
+func decodeDeviceRecord(record []string, columns map[string]int) (registerReq, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	item := registerReq{
+		Name:       field("name"),
+		DeviceType: field("device_type"),
+		Address:    field("address"),
+	}
+
+	for _, id := range splitTransferList(field("responsible")) {
+		userID, err := strconv.ParseInt(id, 10, 32)
+		if err != nil {
+			return item, fmt.Errorf("responsible: %q is not a user id", id)
+		}
+		item.Responsible = append(item.Responsible, int32(userID))
+	}
+
+	var err error
+	if item.Attributes, err = decodeTransferMap(field("attributes")); err != nil {
+		return item, fmt.Errorf("attributes: %w", err)
+	}
+	if item.Labels, err = decodeTransferMap(field("labels")); err != nil {
+		return item, fmt.Errorf("labels: %w", err)
+	}
+
+	return item, nil
+}
+
+func splitTransferList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+func decodeTransferMap(value string) (map[string]string, error) {
+	pairs := splitTransferList(value)
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	m := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("%q is not a key=value pair", pair)
+		}
+		m[k] = strings.TrimSpace(v)
+	}
+
+	return m, nil
+}
+
+func encodeTransferMap(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+m[k])
+	}
+
+	return strings.Join(pairs, ";")
+}
+
+func encodeDevicesCSV(items []registerReq) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(transferColumns); err != nil {
+		return nil, fmt.Errorf("w.Write: %w", err)
+	}
+	for _, item := range items {
+		responsible := make([]string, 0, len(item.Responsible))
+		for _, id := range item.Responsible {
+			responsible = append(responsible, strconv.Itoa(int(id)))
+		}
+
+		err := w.Write([]string{
+			item.Name,
+			item.DeviceType,
+			item.Address,
+			strings.Join(responsible, ";"),
+			encodeTransferMap(item.Attributes),
+			encodeTransferMap(item.Labels),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("w.Write: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("w.Flush: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeDevicesYAML reads a YAML sequence of registerReq items. An item
+// that does not decode is reported at its index.
+func decodeDevicesYAML(data []byte) ([]registerReq, []error, error) {
+	var nodes []yaml.Node
+	if err := yaml.Unmarshal(data, &nodes); err != nil {
+		return nil, nil, fmt.Errorf("yaml.Unmarshal: %w", err)
+	}
+
+	items := make([]registerReq, len(nodes))
+	rowErrors := make([]error, len(nodes))
+	for i := range nodes {
+		if err := nodes[i].Decode(&items[i]); err != nil {
+			rowErrors[i] = fmt.Errorf("node.Decode: %w", err)
+		}
+	}
+
+	return items, rowErrors, nil
+}
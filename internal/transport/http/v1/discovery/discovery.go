@@ -0,0 +1,376 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/internal/services"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	localID = "localID"
+)
+
+type discoveryHandler struct {
+	discovery services.Discovery
+	jwtKey    string
+}
+
+type Config struct {
+	JWTKey    string
+	Discovery services.Discovery
+}
+
+func NewDiscoveryHandler(cfg *Config) *discoveryHandler {
+	return &discoveryHandler{
+		jwtKey:    cfg.JWTKey,
+		discovery: cfg.Discovery,
+	}
+}
+
+func (h *discoveryHandler) InitDiscoveryRoutes(api fiber.Router) {
+	servicesRoute := api.Group("/discovery", h.deserializeMW)
+	servicesRoute.Post("/jobs/create", h.createJob)
+	servicesRoute.Get("/jobs/read", h.readJobs)
+	servicesRoute.Get("/candidates/read", h.readCandidates)
+	servicesRoute.Post("/candidates/promote", h.promote)
+	servicesRoute.Post("/candidates/dismiss", h.dismiss)
+}
+
+type (
+	// createJobReq probes /healthcheck too unless Healthcheck is false.
+	createJobReq struct {
+		CIDR        string  `form:"cidr"         json:"cidr"         validate:"required,cidr"                    xml:"cidr"`
+		Ports       []int32 `form:"ports"        json:"ports"        validate:"omitempty,dive,min=1,max=65535"   xml:"ports"`
+		Healthcheck *bool   `form:"healthcheck"  json:"healthcheck"  validate:"omitempty"                        xml:"healthcheck"`
+	}
+
+	createJobResp struct {
+		Data models.DiscoveryJob `json:"data"`
+	}
+)
+
+// createJob queues a scan of the range and returns at once; the progress
+// is in /discovery/jobs/read.
+func (h *discoveryHandler) createJob(ctx fiber.Ctx) error {
+	body := createJobReq{
+		CIDR:        "",
+		Ports:       []int32{},
+		Healthcheck: nil,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	res, err := h.discovery.CreateJob(
+		context.Background(),
+		services.CreateDiscoveryJobParams{
+			CIDR:        body.CIDR,
+			Ports:       body.Ports,
+			Healthcheck: body.Healthcheck == nil || *body.Healthcheck,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			errorStatus(err),
+			fmt.Errorf("h.discovery.CreateJob: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&createJobResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	readJobsResp struct {
+		Data []models.DiscoveryJob `json:"data"`
+	}
+)
+
+func (h *discoveryHandler) readJobs(ctx fiber.Ctx) error {
+	res, err := h.discovery.ReadJobs(context.Background())
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("h.discovery.ReadJobs: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&readJobsResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	readCandidatesReq struct {
+		JobID  int32  `query:"job_id" validate:"omitempty,min=1"`
+		Status string `query:"status" validate:"omitempty,oneof=pending promoted dismissed"`
+	}
+
+	readCandidatesResp struct {
+		Data []models.DiscoveryCandidate `json:"data"`
+	}
+)
+
+func (h *discoveryHandler) readCandidates(ctx fiber.Ctx) error {
+	body := readCandidatesReq{
+		JobID:  0,
+		Status: "",
+	}
+
+	if err := ctx.Bind().Query(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Query: %w", err).Error(),
+		)
+	}
+
+	res, err := h.discovery.ReadCandidates(context.Background(), body.JobID, body.Status)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("h.discovery.ReadCandidates: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&readCandidatesResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	promoteReq struct {
+		ID          int32   `form:"id"           json:"id"           validate:"required"  xml:"id"`
+		Name        string  `form:"name"         json:"name"         validate:"required"  xml:"name"`
+		DeviceType  string  `form:"device_type"  json:"device_type"  validate:"required"  xml:"device_type"`
+		Responsible []int32 `form:"responsible"  json:"responsible"  validate:"omitempty" xml:"responsible"`
+	}
+
+	promoteResp struct {
+		Data models.Device `json:"data"`
+	}
+)
+
+func (h *discoveryHandler) promote(ctx fiber.Ctx) error {
+	body := promoteReq{
+		ID:          0,
+		Name:        "",
+		DeviceType:  "",
+		Responsible: []int32{},
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	res, err := h.discovery.Promote(
+		context.Background(),
+		services.PromoteDiscoveryCandidateParams{
+			ID:          body.ID,
+			Name:        body.Name,
+			DeviceType:  body.DeviceType,
+			Responsible: body.Responsible,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			errorStatus(err),
+			fmt.Errorf("h.discovery.Promote: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&promoteResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	dismissReq struct {
+		ID int32 `form:"id"  json:"id"  validate:"required"  xml:"id"`
+	}
+
+	dismissResp struct {
+		Data models.DiscoveryCandidate `json:"data"`
+	}
+)
+
+func (h *discoveryHandler) dismiss(ctx fiber.Ctx) error {
+	body := dismissReq{
+		ID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	res, err := h.discovery.Dismiss(context.Background(), body.ID)
+	if err != nil {
+		return fiber.NewError(
+			errorStatus(err),
+			fmt.Errorf("h.discovery.Dismiss: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&dismissResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidDiscoveryJob):
+		return fiber.StatusBadRequest
+	case errors.Is(err, repo.ErrDiscoveryCandidateNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, repo.ErrDiscoveryCandidateProcessed), errors.Is(err, repo.ErrDeviceExists):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func (h *discoveryHandler) deserializeMW(ctx fiber.Ctx) error {
+	tokenString := ctx.Get("Authorization")
+
+	if tokenString == "" {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			errors.New("tokenString is empty").Error(),
+		)
+	}
+
+	tokenString = strings.ReplaceAll(tokenString, "Bearer ", "")
+	token, err := jwt.Parse(tokenString, func(_ *jwt.Token) (interface{}, error) {
+		return []byte(h.jwtKey), nil
+	})
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			fmt.Errorf("jwt.Parse: %w", err).Error(),
+		)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims) //nolint:varnamelen
+	if !ok {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			errors.New("token.Claims.(jwt.MapClaims): invalid token").Error(),
+		)
+	}
+
+	userID, ok := claims[localID].(float64) //nolint:varnamelen
+	if !ok {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			errors.New("claims["+localID+"].(float64): invalid token").Error(),
+		)
+	}
+
+	ctx.Locals(localID, int(userID))
+
+	return ctx.Next() //nolint:wrapcheck
+}
@@ -0,0 +1,459 @@
+package groups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/internal/services"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	localID = "localID"
+)
+
+type groupsHandler struct {
+	groups services.DeviceGroups
+	jwtKey string
+}
+
+type Config struct {
+	JWTKey string
+	Groups services.DeviceGroups
+}
+
+func NewGroupsHandler(cfg *Config) *groupsHandler {
+	return &groupsHandler{
+		jwtKey: cfg.JWTKey,
+		groups: cfg.Groups,
+	}
+}
+
+func (h *groupsHandler) InitGroupsRoutes(api fiber.Router) {
+	servicesRoute := api.Group("/groups", h.deserializeMW)
+	servicesRoute.Post("/create", h.create)
+	servicesRoute.Get("/read", h.read)
+	servicesRoute.Get("/tree", h.tree)
+	servicesRoute.Put("/update", h.update)
+	servicesRoute.Delete("/delete", h.delete)
+	servicesRoute.Post("/devices/add", h.addDevice)
+	servicesRoute.Delete("/devices/remove", h.removeDevice)
+}
+
+type (
+	createReq struct {
+		Name      string `form:"name"        json:"name"        validate:"required"                             xml:"name"`
+		GroupType string `form:"group_type"  json:"group_type"  validate:"omitempty,oneof=site room rack group" xml:"group_type"`
+		ParentID  int32  `form:"parent_id"   json:"parent_id"   validate:"omitempty,min=1"                      xml:"parent_id"`
+	}
+
+	groupResp struct {
+		Data models.DeviceGroup `json:"data"`
+	}
+)
+
+func (h *groupsHandler) create(ctx fiber.Ctx) error {
+	body := createReq{
+		Name:      "",
+		GroupType: "",
+		ParentID:  0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	res, err := h.groups.Create(
+		context.Background(),
+		models.DeviceGroup{
+			Name:      body.Name,
+			GroupType: body.GroupType,
+			ParentID:  &body.ParentID,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			errorStatus(err),
+			fmt.Errorf("h.groups.Create: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&groupResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	readResp struct {
+		Data []models.DeviceGroup `json:"data"`
+	}
+)
+
+func (h *groupsHandler) read(ctx fiber.Ctx) error {
+	res, err := h.groups.Read(context.Background())
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("h.groups.Read: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&readResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	treeResp struct {
+		Data []services.DeviceGroupNode `json:"data"`
+	}
+)
+
+func (h *groupsHandler) tree(ctx fiber.Ctx) error {
+	res, err := h.groups.Tree(context.Background())
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("h.groups.Tree: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&treeResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	// updateReq moves the group to the root when parent_id is 0.
+	updateReq struct {
+		ID        int32   `form:"id"          json:"id"          validate:"required"                             xml:"id"`
+		Name      *string `form:"name"        json:"name"        validate:"omitempty"                            xml:"name"`
+		GroupType *string `form:"group_type"  json:"group_type"  validate:"omitempty,oneof=site room rack group" xml:"group_type"`
+		ParentID  *int32  `form:"parent_id"   json:"parent_id"   validate:"omitempty,min=0"                      xml:"parent_id"`
+	}
+
+	updateResp struct {
+		Data int `json:"data"`
+	}
+)
+
+func (h *groupsHandler) update(ctx fiber.Ctx) error {
+	body := updateReq{
+		ID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	if body.Name == nil && body.GroupType == nil && body.ParentID == nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			errors.New("nothing to update").Error(),
+		)
+	}
+
+	err := h.groups.Update(
+		context.Background(),
+		services.UpdateDeviceGroupParams{
+			ID:        body.ID,
+			Name:      body.Name,
+			GroupType: body.GroupType,
+			ParentID:  body.ParentID,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			errorStatus(err),
+			fmt.Errorf("h.groups.Update: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&updateResp{
+			Data: fiber.StatusOK,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	deleteReq struct {
+		ID int32 `form:"id"  json:"id"  validate:"required"  xml:"id"`
+	}
+
+	deleteResp struct {
+		Data int `json:"data"`
+	}
+)
+
+func (h *groupsHandler) delete(ctx fiber.Ctx) error {
+	body := deleteReq{
+		ID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	err := h.groups.Delete(context.Background(), body.ID)
+	if err != nil {
+		return fiber.NewError(
+			errorStatus(err),
+			fmt.Errorf("h.groups.Delete: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&deleteResp{
+			Data: fiber.StatusOK,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	memberReq struct {
+		GroupID  int32 `form:"group_id"   json:"group_id"   validate:"required"  xml:"group_id"`
+		DeviceID int32 `form:"device_id"  json:"device_id"  validate:"required"  xml:"device_id"`
+	}
+)
+
+// addDevice is a no-op for a device that is already a member.
+func (h *groupsHandler) addDevice(ctx fiber.Ctx) error {
+	body := memberReq{
+		GroupID:  0,
+		DeviceID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	err := h.groups.AddDevice(context.Background(), body.GroupID, body.DeviceID)
+	if err != nil {
+		return fiber.NewError(
+			errorStatus(err),
+			fmt.Errorf("h.groups.AddDevice: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&updateResp{
+			Data: fiber.StatusOK,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+func (h *groupsHandler) removeDevice(ctx fiber.Ctx) error {
+	body := memberReq{
+		GroupID:  0,
+		DeviceID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	err := h.groups.RemoveDevice(context.Background(), body.GroupID, body.DeviceID)
+	if err != nil {
+		return fiber.NewError(
+			errorStatus(err),
+			fmt.Errorf("h.groups.RemoveDevice: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&deleteResp{
+			Data: fiber.StatusOK,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, repo.ErrDeviceGroupNotFound), errors.Is(err, repo.ErrDeviceNotFound),
+		errors.Is(err, repo.ErrDeviceGroupMemberNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, repo.ErrDeviceGroupExists), errors.Is(err, repo.ErrDeviceGroupCycle),
+		errors.Is(err, repo.ErrDeviceGroupNotEmpty):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrInvalidDeviceGroup):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func (h *groupsHandler) deserializeMW(ctx fiber.Ctx) error {
+	tokenString := ctx.Get("Authorization")
+
+	if tokenString == "" {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			errors.New("tokenString is empty").Error(),
+		)
+	}
+
+	tokenString = strings.ReplaceAll(tokenString, "Bearer ", "")
+	token, err := jwt.Parse(tokenString, func(_ *jwt.Token) (interface{}, error) {
+		return []byte(h.jwtKey), nil
+	})
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			fmt.Errorf("jwt.Parse: %w", err).Error(),
+		)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims) //nolint:varnamelen
+	if !ok {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			errors.New("token.Claims.(jwt.MapClaims): invalid token").Error(),
+		)
+	}
+
+	userID, ok := claims[localID].(float64) //nolint:varnamelen
+	if !ok {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			errors.New("claims["+localID+"].(float64): invalid token").Error(),
+		)
+	}
+
+	ctx.Locals(localID, int(userID))
+
+	return ctx.Next() //nolint:wrapcheck
+}
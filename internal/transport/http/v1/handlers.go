@@ -4,12 +4,16 @@ import (
 	"monolith/internal/services"
 	authHandlers "monolith/internal/transport/http/v1/auth"
 	devicesHandlers "monolith/internal/transport/http/v1/devices"
+	discoveryHandlers "monolith/internal/transport/http/v1/discovery"
+	groupsHandlers "monolith/internal/transport/http/v1/groups"
 	"monolith/internal/transport/http/v1/messages"
 	reportsHandlers "monolith/internal/transport/http/v1/reports"
 	tagsHandlers "monolith/internal/transport/http/v1/tags"
+	unknownSourcesHandlers "monolith/internal/transport/http/v1/unknownsources"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
 )
 
 type Handler struct {
@@ -20,6 +24,19 @@ type Handler struct {
 	tagsHandlers    services.Tags
 	reportsHandlers services.Messages
 	deviceChecker   services.DevicesHandler
+	unknownSources  services.UnknownSources
+	credentials     services.DeviceCredentials
+	probes          services.Probes
+	deviceStatus    services.DeviceStatus
+	dependencies    services.DeviceDependencies
+	deviceGroups    services.DeviceGroups
+	discovery       services.Discovery
+	deviceAddresses services.DeviceAddresses
+	subscriptions   services.ReportSubscriptions
+	ingestAuth      string
+	ingestStrictIP  bool
+	otlpAddressAttr string
+	log             *zap.Logger
 }
 
 type Config struct {
@@ -30,6 +47,19 @@ type Config struct {
 	TagsHandlers    services.Tags
 	ReportsHandlers services.Messages
 	DeviceChecker   services.DevicesHandler
+	UnknownSources  services.UnknownSources
+	Credentials     services.DeviceCredentials
+	Probes          services.Probes
+	DeviceStatus    services.DeviceStatus
+	Dependencies    services.DeviceDependencies
+	DeviceGroups    services.DeviceGroups
+	Discovery       services.Discovery
+	DeviceAddresses services.DeviceAddresses
+	Subscriptions   services.ReportSubscriptions
+	IngestAuth      string
+	IngestStrictIP  bool
+	OTLPAddressAttr string
+	Log             *zap.Logger
 }
 
 func NewHandler(cfg Config) *Handler {
@@ -41,6 +71,19 @@ func NewHandler(cfg Config) *Handler {
 		tagsHandlers:    cfg.TagsHandlers,
 		reportsHandlers: cfg.ReportsHandlers,
 		deviceChecker:   cfg.DeviceChecker,
+		unknownSources:  cfg.UnknownSources,
+		credentials:     cfg.Credentials,
+		probes:          cfg.Probes,
+		deviceStatus:    cfg.DeviceStatus,
+		dependencies:    cfg.Dependencies,
+		deviceGroups:    cfg.DeviceGroups,
+		discovery:       cfg.Discovery,
+		deviceAddresses: cfg.DeviceAddresses,
+		subscriptions:   cfg.Subscriptions,
+		ingestAuth:      cfg.IngestAuth,
+		ingestStrictIP:  cfg.IngestStrictIP,
+		otlpAddressAttr: cfg.OTLPAddressAttr,
+		log:             cfg.Log,
 	}
 }
 
@@ -55,6 +98,11 @@ func (h *Handler) InitRouter(routeV1 fiber.Router) {
 		DevicesService: h.devicesService,
 		Messages:       h.reportsHandlers,
 		DeviceChecker:  h.deviceChecker,
+		Credentials:    h.credentials,
+		Probes:         h.probes,
+		Status:         h.deviceStatus,
+		Dependencies:   h.dependencies,
+		Addresses:      h.deviceAddresses,
 		JWTKey:         h.jwtKey,
 	}).InitDevicesRoutes(routeV1)
 
@@ -65,20 +113,48 @@ func (h *Handler) InitRouter(routeV1 fiber.Router) {
 	}).InitTagsRoutes(routeV1)
 
 	reportsHandlers.NewReportsHandler(&reportsHandlers.Config{
-		NatsHandlers: h.reportsHandlers,
-		JWTKey:       h.jwtKey,
+		NatsHandlers:  h.reportsHandlers,
+		Availability:  h.deviceStatus,
+		Subscriptions: h.subscriptions,
+		JWTKey:        h.jwtKey,
+		Log:           h.log,
 	}).InitReportsRoutes(routeV1)
 
 	devicesHandlers.NewDevicesHandler(&devicesHandlers.Config{
 		DevicesService: h.devicesService,
 		Messages:       h.reportsHandlers,
+		Credentials:    h.credentials,
+		Probes:         h.probes,
+		Status:         h.deviceStatus,
+		Dependencies:   h.dependencies,
+		Addresses:      h.deviceAddresses,
 		JWTKey:         h.jwtKey,
 	}).InitDevicesRoutes(routeV1)
 
 	messages.NewMessagesHandler(
 		&messages.Config{
-			NatsHandlers:  h.reportsHandlers,
-			DeviceHandler: h.deviceChecker,
+			NatsHandlers:   h.reportsHandlers,
+			UnknownSources: h.unknownSources,
+			Credentials:    h.credentials,
+			DeviceChecker:  h.deviceChecker,
+			IngestAuth:     h.ingestAuth,
+			StrictIP:       h.ingestStrictIP,
+			AddressAttr:    h.otlpAddressAttr,
 		},
 	).InitMessagesRoutes(routeV1)
+
+	unknownSourcesHandlers.NewUnknownSourcesHandler(&unknownSourcesHandlers.Config{
+		UnknownSources: h.unknownSources,
+		JWTKey:         h.jwtKey,
+	}).InitUnknownSourcesRoutes(routeV1)
+
+	groupsHandlers.NewGroupsHandler(&groupsHandlers.Config{
+		Groups: h.deviceGroups,
+		JWTKey: h.jwtKey,
+	}).InitGroupsRoutes(routeV1)
+
+	discoveryHandlers.NewDiscoveryHandler(&discoveryHandlers.Config{
+		Discovery: h.discovery,
+		JWTKey:    h.jwtKey,
+	}).InitDiscoveryRoutes(routeV1)
 }
@@ -0,0 +1,120 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"monolith/internal/models"
+	"monolith/internal/services"
+	"net/netip"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Ingest authentication modes. Any other value is handled as
+// IngestAuthRequired so a typo in the configuration fails closed.
+const (
+	IngestAuthOff      = "off"
+	IngestAuthOptional = "optional"
+	IngestAuthRequired = "required"
+)
+
+const localCredential = "localCredential"
+
+var errAddressRequired = errors.New("address is required without a device credential")
+
+// ingestAuthMW resolves the sending device from the ingest token in the
+// Authorization header. With a valid token the address in the body is
+// ignored, so a device can only send messages on its own behalf.
+func (h *messagesHandler) ingestAuthMW(ctx fiber.Ctx) error {
+	if h.ingestAuth == IngestAuthOff {
+		return ctx.Next() //nolint:wrapcheck
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer "))
+	if token == "" {
+		if h.ingestAuth == IngestAuthOptional {
+			return ctx.Next() //nolint:wrapcheck
+		}
+
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			errors.New("device token is empty").Error(),
+		)
+	}
+
+	credential, ok := h.credentials.Authenticate(token)
+	if !ok {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			errors.New("h.credentials.Authenticate: invalid device token").Error(),
+		)
+	}
+
+	if h.strictIP && !h.ownAddress(ctx.IP(), credential) {
+		return fiber.NewError(
+			fiber.StatusForbidden,
+			fmt.Errorf("request address %s does not match device address %s",
+				ctx.IP(), credential.DeviceAddress).Error(),
+		)
+	}
+
+	ctx.Locals(localCredential, credential)
+
+	return ctx.Next() //nolint:wrapcheck
+}
+
+// sender returns the device id and address a message is stored with: the
+// device of the ingest credential if one was presented, otherwise the device
+// resolved from the address in the body.
+func (h *messagesHandler) sender(ctx fiber.Ctx, address string) (int32, string, error) {
+	if credential, ok := ctx.Locals(localCredential).(models.DeviceCredential); ok {
+		return credential.DeviceID, credential.DeviceAddress, nil
+	}
+
+	if address == "" {
+		return 0, "", errAddressRequired
+	}
+
+	id, err := h.unknownSources.Resolve(address)
+	if err != nil {
+		return 0, "", fmt.Errorf("h.unknownSources.Resolve: %w", err)
+	}
+
+	return id, address, nil
+}
+
+func senderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errAddressRequired):
+		return fiber.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrSourceBlocked):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// ownAddress reports whether the request comes from the primary address of
+// the device or from one of its extra addresses and ranges.
+func (h *messagesHandler) ownAddress(address string, credential models.DeviceCredential) bool {
+	if sameAddress(address, credential.DeviceAddress) {
+		return true
+	}
+	if h.deviceChecker == nil {
+		return false
+	}
+
+	deviceID, ok := h.deviceChecker.GetDeviceIDByIp(address)
+	return ok && deviceID == credential.DeviceID
+}
+
+func sameAddress(a, b string) bool {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+
+	return addrA.Unmap() == addrB.Unmap()
+}
@@ -1,9 +1,14 @@
 package messages
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"monolith/internal/models"
 	"monolith/internal/services"
+	"strings"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/prometheus/client_golang/prometheus"
@@ -13,18 +18,46 @@ import (
 
 const (
 	localID = "localID"
+
+	mimeApplicationNDJSON = "application/x-ndjson"
+	maxBatchSize          = 10000
+
+	// retryAfterSeconds is sent with 503 when the ingest queue is full.
+	retryAfterSeconds = "1"
+
+	headerIdempotencyKey     = "Idempotency-Key"
+	headerIdempotentReplayed = "Idempotent-Replayed"
+	maxMessageIDLength       = 128
 )
 
 type messagesHandler struct {
-	natsHandlers  services.Messages
-	deviceHandler services.DevicesHandler
-	egrMetrics    prometheus.Counter
-	ingrMetrics   prometheus.Counter
+	natsHandlers   services.Messages
+	unknownSources services.UnknownSources
+	credentials    services.DeviceCredentials
+	deviceChecker  services.DevicesHandler
+	ingestAuth     string
+	strictIP       bool
+	addressAttr    string
+	egrMetrics     prometheus.Counter
+	ingrMetrics    prometheus.Counter
 }
 
 type Config struct {
-	NatsHandlers  services.Messages
-	DeviceHandler services.DevicesHandler
+	NatsHandlers   services.Messages
+	UnknownSources services.UnknownSources
+	Credentials    services.DeviceCredentials
+	// DeviceChecker lets StrictIP accept any address of the device, not
+	// only its primary one.
+	DeviceChecker services.DevicesHandler
+	// IngestAuth is one of IngestAuthOff, IngestAuthOptional and
+	// IngestAuthRequired.
+	IngestAuth string
+	// StrictIP rejects credentials used from an address other than the
+	// address of their device.
+	StrictIP bool
+	// AddressAttr is the OTLP resource attribute holding the address of
+	// the sending device. It defaults to DefaultOTLPAddressAttribute.
+	AddressAttr string
 }
 
 func NewMessagesHandler(cfg *Config) *messagesHandler {
@@ -45,28 +78,50 @@ func NewMessagesHandler(cfg *Config) *messagesHandler {
 	prometheus.MustRegister(egressResponses)
 
 	prometheus.MustRegister(ingressRequests)
+
+	if cfg.AddressAttr == "" {
+		cfg.AddressAttr = DefaultOTLPAddressAttribute
+	}
+
 	return &messagesHandler{
-		natsHandlers:  cfg.NatsHandlers,
-		deviceHandler: cfg.DeviceHandler,
-		egrMetrics:    egressResponses,
-		ingrMetrics:   ingressRequests,
+		natsHandlers:   cfg.NatsHandlers,
+		unknownSources: cfg.UnknownSources,
+		credentials:    cfg.Credentials,
+		deviceChecker:  cfg.DeviceChecker,
+		ingestAuth:     cfg.IngestAuth,
+		strictIP:       cfg.StrictIP,
+		addressAttr:    cfg.AddressAttr,
+		egrMetrics:     egressResponses,
+		ingrMetrics:    ingressRequests,
 	}
 }
 
 func (h *messagesHandler) InitMessagesRoutes(api fiber.Router) {
-	servicesRoute := api.Group("/messages")
+	servicesRoute := api.Group("/messages", h.ingestAuthMW)
 	servicesRoute.Post("/send_msg", h.sendMsg)
+	servicesRoute.Post("/send_batch", h.sendBatch)
+
+	api.Post("/logs", h.ingestAuthMW, h.exportLogs)
 }
 
 type (
+	// sendMsgReq.Timestamp is the time the event happened on the device,
+	// in RFC 3339.
 	sendMsgReq struct {
-		Message     string `form:"message" 		json:"message" 		validate:"required" 	xml:"message"`
-		MessageType string `form:"message_type" json:"message_type" validate:"required" 	xml:"message_type"`
-		Component   string `form:"component" 	json:"component" 	validate:"required" 	xml:"component"`
-		Address     string `form:"address" 		json:"address" 		validate:"required,ip" 	xml:"address"`
+		Message     string     `form:"message"      json:"message"      validate:"required"     xml:"message"`
+		MessageType string     `form:"message_type" json:"message_type" validate:"required"     xml:"message_type"`
+		Component   string     `form:"component"    json:"component"    validate:"required"     xml:"component"`
+		Address     string     `form:"address"      json:"address"      validate:"omitempty,ip" xml:"address"`
+		MessageID   string     `form:"message_id"   json:"message_id"   validate:"max=128"      xml:"message_id"`
+		Timestamp   *time.Time `form:"timestamp"    json:"timestamp"    validate:"omitempty"    xml:"timestamp"`
 	}
 )
 
+// sendMsg queues a message and answers 202. A message_id, or the
+// Idempotency-Key header, that was accepted before is answered 202 with
+// Idempotent-Replayed. That header is best-effort: an id only known from
+// before a restart or from another replica is answered as new, and the
+// message is dropped as a duplicate when it is written.
 func (h *messagesHandler) sendMsg(ctx fiber.Ctx) error {
 	h.ingrMetrics.Inc()
 	body := sendMsgReq{
@@ -74,6 +129,8 @@ func (h *messagesHandler) sendMsg(ctx fiber.Ctx) error {
 		MessageType: "",
 		Component:   "",
 		Address:     "",
+		MessageID:   "",
+		Timestamp:   nil,
 	}
 
 	if err := ctx.Bind().Body(&body); err != nil {
@@ -83,17 +140,55 @@ func (h *messagesHandler) sendMsg(ctx fiber.Ctx) error {
 		)
 	}
 
-	id, _ := h.deviceHandler.GetDeviceIDByIp(body.Address)
-	_, _, err := h.natsHandlers.Create(models.Message{
+	if body.MessageID == "" {
+		body.MessageID = ctx.Get(headerIdempotencyKey)
+		if len(body.MessageID) > maxMessageIDLength {
+			return fiber.NewError(
+				fiber.StatusUnprocessableEntity,
+				fmt.Errorf("%s is longer than %d characters", headerIdempotencyKey, maxMessageIDLength).Error(),
+			)
+		}
+	}
+
+	id, address, err := h.sender(ctx, body.Address)
+	if err != nil {
+		return fiber.NewError(
+			senderErrorStatus(err),
+			fmt.Errorf("h.sender: %w", err).Error(),
+		)
+	}
+
+	err = h.natsHandlers.Enqueue(models.Message{
 		Message:     body.Message,
 		MessageType: body.MessageType,
 		Component:   body.Component,
 		DeviceId:    id,
+		DeviceIP:    address,
+		MessageID:   body.MessageID,
+		EventAt:     body.Timestamp,
 	})
+	if errors.Is(err, services.ErrTimestampSkew) {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("h.natsHandlers.Enqueue: %w", err).Error(),
+		)
+	}
+	if errors.Is(err, services.ErrDuplicateMessage) {
+		// The message was accepted before: answer exactly as the first time.
+		ctx.Set(headerIdempotentReplayed, "true")
+		err = nil
+	}
+	if errors.Is(err, services.ErrIngestQueueFull) || errors.Is(err, services.ErrIngestQueueClosed) {
+		ctx.Set(fiber.HeaderRetryAfter, retryAfterSeconds)
+		return fiber.NewError(
+			fiber.StatusServiceUnavailable,
+			fmt.Errorf("h.natsHandlers.Enqueue: %w", err).Error(),
+		)
+	}
 	if err != nil {
 		return fiber.NewError(
 			fiber.StatusInternalServerError,
-			fmt.Errorf("h.nats.PublishGetAllByDeviceId: %w", err).Error(),
+			fmt.Errorf("h.natsHandlers.Enqueue: %w", err).Error(),
 		)
 	}
 
@@ -107,6 +202,158 @@ func (h *messagesHandler) sendMsg(ctx fiber.Ctx) error {
 		)
 	}
 
+	if err = ctx.Status(fiber.StatusAccepted).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+	h.egrMetrics.Inc()
+
+	return nil
+}
+
+type (
+	sendBatchItemResult struct {
+		Index     int    `json:"index"`
+		Status    int    `json:"status"`
+		Duplicate bool   `json:"duplicate,omitempty"`
+		Error     string `json:"error,omitempty"`
+	}
+
+	sendBatchResult struct {
+		Accepted int                   `json:"accepted"`
+		Rejected int                   `json:"rejected"`
+		Results  []sendBatchItemResult `json:"results"`
+	}
+
+	sendBatchResp struct {
+		Data sendBatchResult `json:"data"`
+	}
+)
+
+// sendBatch accepts a JSON array or an NDJSON stream of sendMsgReq items.
+// Invalid items are reported individually and do not fail the batch, so a
+// client only needs to resend the items whose status is not 202. Items that
+// repeat a message_id are acknowledged with 202 and marked as duplicate.
+// The valid items are queued together like /messages/send_msg: when the
+// ingest queue cannot take them all, none is queued and the batch is
+// answered with 503 and Retry-After.
+func (h *messagesHandler) sendBatch(ctx fiber.Ctx) error {
+	h.ingrMetrics.Inc()
+
+	items, err := splitBatch(ctx)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("splitBatch: %w", err).Error(),
+		)
+	}
+
+	if len(items) == 0 {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			errors.New("batch is empty").Error(),
+		)
+	}
+
+	if len(items) > maxBatchSize {
+		return fiber.NewError(
+			fiber.StatusRequestEntityTooLarge,
+			fmt.Errorf("batch size %d exceeds limit %d", len(items), maxBatchSize).Error(),
+		)
+	}
+
+	validator := ctx.App().Config().StructValidator
+	result := sendBatchResult{
+		Results: make([]sendBatchItemResult, len(items)),
+	}
+	messages := make([]models.Message, 0, len(items))
+	accepted := make([]int, 0, len(items))
+	for i, item := range items {
+		result.Results[i].Index = i
+
+		var body sendMsgReq
+		if err := jsoniter.Unmarshal(item, &body); err != nil {
+			result.Results[i].Status = fiber.StatusUnprocessableEntity
+			result.Results[i].Error = fmt.Errorf("jsoniter.Unmarshal: %w", err).Error()
+			continue
+		}
+
+		if err := validator.Validate(&body); err != nil {
+			result.Results[i].Status = fiber.StatusUnprocessableEntity
+			result.Results[i].Error = fmt.Errorf("validator.Validate: %w", err).Error()
+			continue
+		}
+
+		id, address, err := h.sender(ctx, body.Address)
+		if err != nil {
+			result.Results[i].Status = senderErrorStatus(err)
+			result.Results[i].Error = fmt.Errorf("h.sender: %w", err).Error()
+			continue
+		}
+
+		messages = append(messages, models.Message{
+			Message:     body.Message,
+			MessageType: body.MessageType,
+			Component:   body.Component,
+			DeviceId:    id,
+			DeviceIP:    address,
+			MessageID:   body.MessageID,
+			EventAt:     body.Timestamp,
+		})
+		accepted = append(accepted, i)
+	}
+
+	if len(messages) != 0 {
+		errs, err := h.natsHandlers.EnqueueBatch(messages)
+		if errors.Is(err, services.ErrIngestBatchTooLarge) {
+			return fiber.NewError(
+				fiber.StatusRequestEntityTooLarge,
+				fmt.Errorf("h.natsHandlers.EnqueueBatch: %w", err).Error(),
+			)
+		}
+		if errors.Is(err, services.ErrIngestQueueFull) || errors.Is(err, services.ErrIngestQueueClosed) {
+			ctx.Set(fiber.HeaderRetryAfter, retryAfterSeconds)
+			return fiber.NewError(
+				fiber.StatusServiceUnavailable,
+				fmt.Errorf("h.natsHandlers.EnqueueBatch: %w", err).Error(),
+			)
+		}
+		if err != nil {
+			return fiber.NewError(
+				fiber.StatusInternalServerError,
+				fmt.Errorf("h.natsHandlers.EnqueueBatch: %w", err).Error(),
+			)
+		}
+
+		for j, i := range accepted {
+			switch {
+			case errors.Is(errs[j], services.ErrDuplicateMessage):
+				result.Results[i].Duplicate = true
+			case errs[j] != nil:
+				result.Results[i].Status = fiber.StatusUnprocessableEntity
+				result.Results[i].Error = errs[j].Error()
+				continue
+			}
+			result.Results[i].Status = fiber.StatusAccepted
+			result.Accepted++
+		}
+	}
+	result.Rejected = len(items) - result.Accepted
+
+	jsonResponse, err := jsoniter.Marshal(
+		&sendBatchResp{
+			Data: result,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
 	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
 		return fiber.NewError(
 			fiber.StatusInternalServerError,
@@ -117,3 +364,35 @@ func (h *messagesHandler) sendMsg(ctx fiber.Ctx) error {
 
 	return nil
 }
+
+// splitBatch returns the raw items of the request body. A body is treated as
+// a JSON array when it starts with '[' and as NDJSON otherwise, unless the
+// content type says so explicitly.
+func splitBatch(ctx fiber.Ctx) ([]jsoniter.RawMessage, error) {
+	body := bytes.TrimSpace(ctx.Body())
+	contentType := ctx.Get(fiber.HeaderContentType)
+
+	if !strings.HasPrefix(contentType, mimeApplicationNDJSON) && bytes.HasPrefix(body, []byte("[")) {
+		var items []jsoniter.RawMessage
+		if err := jsoniter.Unmarshal(body, &items); err != nil {
+			return nil, fmt.Errorf("jsoniter.Unmarshal: %w", err)
+		}
+		return items, nil
+	}
+
+	items := make([]jsoniter.RawMessage, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), len(body)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, jsoniter.RawMessage(bytes.Clone(line)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner.Scan: %w", err)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,355 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"monolith/internal/models"
+	"monolith/internal/services"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v3"
+	jsoniter "github.com/json-iterator/go"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	mimeApplicationProtobuf = "application/x-protobuf"
+
+	otlpMessageType      = "otlp"
+	otlpDefaultComponent = "otlp"
+	// otlpMaxComponentLen matches messages.component varchar(30).
+	otlpMaxComponentLen = 30
+
+	// DefaultOTLPAddressAttribute is the resource attribute that holds the
+	// address of the sending device unless configured otherwise.
+	DefaultOTLPAddressAttribute = "host.ip"
+	otlpServiceNameAttribute    = "service.name"
+	otlpHostNameAttribute       = "host.name"
+)
+
+// exportLogs is an OTLP/HTTP logs receiver. Exporters reach it with
+// /monolith as their endpoint, so the signal path is /monolith/v1/logs.
+// Every log record is queued as a message of the device that sent the
+// resource, found by the address attribute, else by a host.name that is a
+// hostname address of a device. Only without either is the record
+// attributed to the peer address, which behind an OpenTelemetry Collector
+// or a proxy is theirs and not the address of the host. Records the
+// pipeline refuses are reported as a partial success, which OTLP clients
+// do not retry. A failure that would make the exporter
+// retry the whole request is only returned while nothing is queued yet;
+// afterwards the records left are reported as rejected instead, so the
+// queued ones are not sent twice.
+func (h *messagesHandler) exportLogs(ctx fiber.Ctx) error {
+	h.ingrMetrics.Inc()
+
+	contentType := ctx.Get(fiber.HeaderContentType)
+	isJSON := strings.HasPrefix(contentType, fiber.MIMEApplicationJSON)
+	if !isJSON && !strings.HasPrefix(contentType, mimeApplicationProtobuf) {
+		return fiber.NewError(
+			fiber.StatusUnsupportedMediaType,
+			fmt.Errorf("unsupported content type %q", contentType).Error(),
+		)
+	}
+
+	var req collogspb.ExportLogsServiceRequest
+	var err error
+	if isJSON {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(ctx.Body(), &req)
+	} else {
+		err = proto.Unmarshal(ctx.Body(), &req)
+	}
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("unmarshal ExportLogsServiceRequest: %w", err).Error(),
+		)
+	}
+
+	var total int64
+	for _, resourceLogs := range req.GetResourceLogs() {
+		total += countLogRecords(resourceLogs)
+	}
+
+	var queued, rejected int64
+	var rejectErr error
+resources:
+	for _, resourceLogs := range req.GetResourceLogs() {
+		attributes := resourceLogs.GetResource().GetAttributes()
+
+		address := h.otlpAddress(attributes)
+		if address == "" {
+			address = h.otlpHostName(attributes)
+		}
+		if address == "" {
+			address = ctx.IP()
+		}
+
+		id, address, err := h.sender(ctx, address)
+		if errors.Is(err, services.ErrSourceBlocked) {
+			rejected += countLogRecords(resourceLogs)
+			rejectErr = err
+			continue
+		}
+		if err != nil {
+			if queued == 0 {
+				return fiber.NewError(
+					senderErrorStatus(err),
+					fmt.Errorf("h.sender: %w", err).Error(),
+				)
+			}
+			rejected = total - queued
+			rejectErr = err
+			break resources
+		}
+
+		serviceName := anyValueString(attributeValue(attributes, otlpServiceNameAttribute))
+		for _, scopeLogs := range resourceLogs.GetScopeLogs() {
+			component := otlpComponent(scopeLogs.GetScope().GetName(), serviceName)
+
+			for _, record := range scopeLogs.GetLogRecords() {
+				err := h.natsHandlers.Enqueue(models.Message{
+					Message:       anyValueString(record.GetBody()),
+					MessageType:   otlpMessageType,
+					SeverityLevel: otlpSeverityLevel(record.GetSeverityNumber(), record.GetSeverityText()),
+					Component:     component,
+					DeviceId:      id,
+					DeviceIP:      address,
+					EventAt:       otlpEventTime(record),
+				})
+				if errors.Is(err, services.ErrIngestQueueFull) || errors.Is(err, services.ErrIngestQueueClosed) {
+					if queued == 0 {
+						ctx.Set(fiber.HeaderRetryAfter, retryAfterSeconds)
+						return fiber.NewError(
+							fiber.StatusServiceUnavailable,
+							fmt.Errorf("h.natsHandlers.Enqueue: %w", err).Error(),
+						)
+					}
+					rejected = total - queued
+					rejectErr = err
+					break resources
+				}
+				if err != nil {
+					rejected++
+					rejectErr = err
+					continue
+				}
+				queued++
+			}
+		}
+	}
+
+	resp := &collogspb.ExportLogsServiceResponse{}
+	if rejected != 0 {
+		resp.PartialSuccess = &collogspb.ExportLogsPartialSuccess{
+			RejectedLogRecords: rejected,
+			ErrorMessage:       rejectErr.Error(),
+		}
+	}
+
+	var body []byte
+	if isJSON {
+		body, err = protojson.Marshal(resp)
+	} else {
+		body, err = proto.Marshal(resp)
+		ctx.Set(fiber.HeaderContentType, mimeApplicationProtobuf)
+	}
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("marshal ExportLogsServiceResponse: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(body); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+	h.egrMetrics.Inc()
+
+	return nil
+}
+
+// otlpAddress returns the first IP address in the configured resource
+// attribute. host.ip is a list, so both lists and plain strings are
+// accepted.
+func (h *messagesHandler) otlpAddress(attributes []*commonpb.KeyValue) string {
+	value := attributeValue(attributes, h.addressAttr)
+	if value == nil {
+		return ""
+	}
+
+	values := []*commonpb.AnyValue{value}
+	if array := value.GetArrayValue(); array != nil {
+		values = array.GetValues()
+	}
+
+	for _, v := range values {
+		if addr, err := netip.ParseAddr(v.GetStringValue()); err == nil {
+			return addr.Unmap().String()
+		}
+	}
+
+	return ""
+}
+
+// otlpHostName returns the host.name attribute when it is the hostname
+// address of a device. Such messages are stored without an address.
+func (h *messagesHandler) otlpHostName(attributes []*commonpb.KeyValue) string {
+	hostName := anyValueString(attributeValue(attributes, otlpHostNameAttribute))
+	if hostName == "" || h.deviceChecker == nil {
+		return ""
+	}
+
+	if _, ok := h.deviceChecker.GetDeviceIDByIp(hostName); !ok {
+		return ""
+	}
+
+	return hostName
+}
+
+func attributeValue(attributes []*commonpb.KeyValue, key string) *commonpb.AnyValue {
+	for _, attribute := range attributes {
+		if attribute.GetKey() == key {
+			return attribute.GetValue()
+		}
+	}
+
+	return nil
+}
+
+// anyValueString renders scalar values as text and structured values as
+// JSON.
+func anyValueString(value *commonpb.AnyValue) string {
+	switch v := value.GetValue().(type) {
+	case nil:
+		return ""
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+	default:
+		text, err := jsoniter.MarshalToString(anyValueInterface(value))
+		if err != nil {
+			return ""
+		}
+		return text
+	}
+}
+
+func anyValueInterface(value *commonpb.AnyValue) any {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return v.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return v.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return v.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return v.BytesValue
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]any, 0, len(v.ArrayValue.GetValues()))
+		for _, item := range v.ArrayValue.GetValues() {
+			values = append(values, anyValueInterface(item))
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		values := make(map[string]any, len(v.KvlistValue.GetValues()))
+		for _, kv := range v.KvlistValue.GetValues() {
+			values[kv.GetKey()] = anyValueInterface(kv.GetValue())
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// otlpSeverityLevel maps the OTLP severity onto the severity_level values
+// used by tags and reports. The severity number wins over the text; a
+// record without either is left for the tags to classify.
+func otlpSeverityLevel(number logspb.SeverityNumber, text string) string {
+	switch {
+	case number >= logspb.SeverityNumber_SEVERITY_NUMBER_FATAL:
+		return "critical"
+	case number >= logspb.SeverityNumber_SEVERITY_NUMBER_ERROR:
+		return "error"
+	case number >= logspb.SeverityNumber_SEVERITY_NUMBER_WARN:
+		return "warning"
+	case number >= logspb.SeverityNumber_SEVERITY_NUMBER_INFO:
+		return "info"
+	case number >= logspb.SeverityNumber_SEVERITY_NUMBER_TRACE:
+		return "debug"
+	}
+
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "fatal", "critical", "crit", "alert", "emerg", "emergency", "panic":
+		return "critical"
+	case "error", "err":
+		return "error"
+	case "warn", "warning":
+		return "warning"
+	case "info", "notice", "information":
+		return "info"
+	case "debug", "trace":
+		return "debug"
+	default:
+		return ""
+	}
+}
+
+// otlpComponent prefers the instrumentation scope name and falls back to
+// the service name of the resource.
+func otlpComponent(scopeName, serviceName string) string {
+	name := scopeName
+	if name == "" {
+		name = serviceName
+	}
+	if name == "" {
+		return otlpDefaultComponent
+	}
+
+	if utf8.RuneCountInString(name) > otlpMaxComponentLen {
+		return string([]rune(name)[:otlpMaxComponentLen])
+	}
+
+	return name
+}
+
+// otlpEventTime falls back to the time the record was observed by the
+// collector when the event time is unknown.
+func otlpEventTime(record *logspb.LogRecord) *time.Time {
+	nanos := record.GetTimeUnixNano()
+	if nanos == 0 {
+		nanos = record.GetObservedTimeUnixNano()
+	}
+	if nanos == 0 {
+		return nil
+	}
+
+	ts := time.Unix(0, int64(nanos)) //nolint:gosec
+	return &ts
+}
+
+func countLogRecords(resourceLogs *logspb.ResourceLogs) int64 {
+	var count int64
+	for _, scopeLogs := range resourceLogs.GetScopeLogs() {
+		count += int64(len(scopeLogs.GetLogRecords()))
+	}
+
+	return count
+}
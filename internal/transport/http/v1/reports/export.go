@@ -0,0 +1,75 @@
+package reports
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"monolith/internal/services"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+	formatXLSX = "xlsx"
+
+	mimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	// exportPageSize is how many messages an export reads at a time.
+	exportPageSize = 1000
+)
+
+// reportFormat picks the format from the format query parameter, or from
+// the Accept header when the parameter is not given. JSON is the default.
+func reportFormat(ctx fiber.Ctx) (string, error) {
+	switch format := strings.ToLower(ctx.Query("format")); format {
+	case formatJSON, formatCSV, formatXLSX:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unknown format %q, want one of json, csv, xlsx", format)
+	}
+
+	switch ctx.Accepts(fiber.MIMEApplicationJSON, "text/csv", mimeXLSX) {
+	case "text/csv":
+		return formatCSV, nil
+	case mimeXLSX:
+		return formatXLSX, nil
+	default:
+		return formatJSON, nil
+	}
+}
+
+// sendTable streams the table as a file download. The rows are produced
+// while the response is written, so an error past the headers can no
+// longer change the status. The connection is closed instead, before the
+// chunked body is terminated, so the client sees a failed download rather
+// than a file cut short.
+func (h *reportsHandler) sendTable(ctx fiber.Ctx, format string, table services.ReportTable) error {
+	ctx.Attachment(table.Name + "." + format)
+
+	write := table.WriteCSV
+	ctx.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	if format == formatXLSX {
+		write = table.WriteXLSX
+		ctx.Set(fiber.HeaderContentType, mimeXLSX)
+	}
+
+	// The stream is written after the handler returns, when ctx is no
+	// longer valid.
+	conn := ctx.RequestCtx().Conn()
+
+	return ctx.Status(fiber.StatusOK).SendStreamWriter(func(w *bufio.Writer) { //nolint:wrapcheck
+		if err := write(w); err != nil {
+			h.log.Error("report export failed",
+				zap.Error(err),
+				zap.String("report", table.Name),
+				zap.String("format", format),
+			)
+			_ = conn.Close()
+		}
+	})
+}
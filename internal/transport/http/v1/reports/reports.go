@@ -1,9 +1,11 @@
 package reports
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"monolith/internal/models"
+	"monolith/internal/repo"
 	"monolith/internal/services"
 	"strings"
 	"time"
@@ -12,26 +14,40 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
 )
 
 const (
 	localID = "localID"
+
+	// defaultReportLimit is the page size of a message report when the
+	// request does not give one.
+	defaultReportLimit = 100
 )
 
 type reportsHandler struct {
-	natsHandlers services.Messages
-	jwtKey       string
+	natsHandlers  services.Messages
+	availability  services.DeviceStatus
+	subscriptions services.ReportSubscriptions
+	jwtKey        string
+	log           *zap.Logger
 }
 
 type Config struct {
-	JWTKey       string
-	NatsHandlers services.Messages
+	JWTKey        string
+	NatsHandlers  services.Messages
+	Availability  services.DeviceStatus
+	Subscriptions services.ReportSubscriptions
+	Log           *zap.Logger
 }
 
 func NewReportsHandler(cfg *Config) *reportsHandler {
 	return &reportsHandler{
-		jwtKey:       cfg.JWTKey,
-		natsHandlers: cfg.NatsHandlers,
+		jwtKey:        cfg.JWTKey,
+		natsHandlers:  cfg.NatsHandlers,
+		availability:  cfg.Availability,
+		subscriptions: cfg.Subscriptions,
+		log:           cfg.Log,
 	}
 }
 
@@ -41,22 +57,50 @@ func (h *reportsHandler) InitReportsRoutes(api fiber.Router) {
 	servicesRoute.Get("/get_all_by_period", h.getAllByPeriod)
 	servicesRoute.Get("/get_count_by_message_type", h.getCountByMessageType)
 	servicesRoute.Get("/month_report", h.getMonthReport)
+	servicesRoute.Get("/period_report", h.getPeriodReport)
+	servicesRoute.Get("/availability", h.getAvailability)
+	servicesRoute.Get("/search", h.search)
+	servicesRoute.Post("/subscriptions/create", h.createSubscription)
+	servicesRoute.Get("/subscriptions/read", h.readSubscriptions)
+	servicesRoute.Post("/subscriptions/update", h.updateSubscription)
+	servicesRoute.Post("/subscriptions/delete", h.deleteSubscription)
+	servicesRoute.Post("/subscriptions/run", h.runSubscription)
 
 }
 
 type (
+	// getAllByDeviceIdReq pages the messages with Cursor and Limit, which
+	// defaults to defaultReportLimit. WithTotal also counts every matching
+	// message.
 	getAllByDeviceIdReq struct {
-		DeviceID int `form:"device_id" json:"device_id" validate:"required" xml:"device_id"`
+		DeviceID     int      `form:"device_id"     json:"device_id"     validate:"required"                 xml:"device_id"`
+		MessageTypes []string `form:"message_types" json:"message_types" validate:"omitempty"                xml:"message_types"`
+		Severities   []string `form:"severities"    json:"severities"    validate:"omitempty"                xml:"severities"`
+		Component    string   `form:"component"     json:"component"     validate:"omitempty"                xml:"component"`
+		Text         string   `form:"text"          json:"text"          validate:"omitempty"                xml:"text"`
+		Cursor       string   `form:"cursor"        json:"cursor"        validate:"omitempty"                xml:"cursor"`
+		Limit        int      `form:"limit"         json:"limit"         validate:"omitempty,min=1,max=1000" xml:"limit"`
+		WithTotal    bool     `form:"with_total"    json:"with_total"    validate:"omitempty"                xml:"with_total"`
 	}
 
 	getAllByDeviceIdResp struct {
 		Data []services.ReportGetAllByDeviceId `json:"data"`
+		// NextCursor is left out on the last page.
+		NextCursor string `json:"next_cursor,omitempty"`
+		Total      *int64 `json:"total,omitempty"`
 	}
 )
 
 func (h *reportsHandler) getAllByDeviceId(ctx fiber.Ctx) error {
 	body := getAllByDeviceIdReq{
-		DeviceID: 0,
+		DeviceID:     0,
+		MessageTypes: nil,
+		Severities:   nil,
+		Component:    "",
+		Text:         "",
+		Cursor:       "",
+		Limit:        0,
+		WithTotal:    false,
 	}
 
 	if err := ctx.Bind().Body(&body); err != nil {
@@ -65,20 +109,60 @@ func (h *reportsHandler) getAllByDeviceId(ctx fiber.Ctx) error {
 			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
 		)
 	}
+	if body.Limit == 0 {
+		body.Limit = defaultReportLimit
+	}
+	format, err := reportFormat(ctx)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("reportFormat: %w", err).Error(),
+		)
+	}
+	if format != formatJSON {
+		body.Limit = exportPageSize
+		body.WithTotal = false
+	}
 
-	res, err := h.natsHandlers.GetAllByDeviceId(
-		int32(body.DeviceID),
-	)
+	opts := services.MessagesGetAllByDeviceIdOpts{
+		DeviceID: int32(body.DeviceID),
+		Filter: repo.MessagesFilter{
+			DeviceIDs:    nil,
+			MessageTypes: body.MessageTypes,
+			Severities:   body.Severities,
+			Component:    body.Component,
+			Text:         body.Text,
+		},
+		Page: services.ReportPage{
+			Cursor:    body.Cursor,
+			Limit:     body.Limit,
+			WithTotal: body.WithTotal,
+		},
+	}
+
+	res, page, err := h.natsHandlers.GetAllByDeviceId(opts)
 	if err != nil {
 		return fiber.NewError(
-			fiber.StatusInternalServerError,
+			reportErrorStatus(err),
 			fmt.Errorf("h.nats.PublishGetAllByDeviceId: %w", err).Error(),
 		)
 	}
 
+	if format != formatJSON {
+		next := func(cursor string) ([]services.ReportGetAllByDeviceId, string, error) {
+			opts.Page.Cursor = cursor
+			res, page, err := h.natsHandlers.GetAllByDeviceId(opts)
+			return res, page.NextCursor, err
+		}
+
+		return h.sendTable(ctx, format, services.MessagesTable("messages_by_device", res, page.NextCursor, next))
+	}
+
 	jsonResponse, err := jsoniter.Marshal(
 		&getAllByDeviceIdResp{
-			Data: res,
+			Data:       res,
+			NextCursor: page.NextCursor,
+			Total:      page.Total,
 		},
 	)
 	if err != nil {
@@ -99,20 +183,41 @@ func (h *reportsHandler) getAllByDeviceId(ctx fiber.Ctx) error {
 }
 
 type (
+	// getAllByPeriodReq is paged and filtered like getAllByDeviceIdReq.
 	getAllByPeriodReq struct {
-		StartTime time.Time `form:"start_time" json:"start_time" validate:"required" xml:"start_time"`
-		EndTime   time.Time `form:"end_time"   json:"end_time"   validate:"required" xml:"end_time"`
+		StartTime    time.Time `form:"start_time"    json:"start_time"    validate:"required"                       xml:"start_time"`
+		EndTime      time.Time `form:"end_time"      json:"end_time"      validate:"required"                       xml:"end_time"`
+		TimeBasis    string    `form:"time_basis"    json:"time_basis"    validate:"omitempty,oneof=received event" xml:"time_basis"`
+		DeviceIDs    []int32   `form:"device_ids"    json:"device_ids"    validate:"omitempty"                      xml:"device_ids"`
+		MessageTypes []string  `form:"message_types" json:"message_types" validate:"omitempty"                      xml:"message_types"`
+		Severities   []string  `form:"severities"    json:"severities"    validate:"omitempty"                      xml:"severities"`
+		Component    string    `form:"component"     json:"component"     validate:"omitempty"                      xml:"component"`
+		Text         string    `form:"text"          json:"text"          validate:"omitempty"                      xml:"text"`
+		Cursor       string    `form:"cursor"        json:"cursor"        validate:"omitempty"                      xml:"cursor"`
+		Limit        int       `form:"limit"         json:"limit"         validate:"omitempty,min=1,max=1000"       xml:"limit"`
+		WithTotal    bool      `form:"with_total"    json:"with_total"    validate:"omitempty"                      xml:"with_total"`
 	}
 
 	getAllByPeriodResp struct {
-		Data []services.ReportGetAllByPeriod `json:"data"`
+		Data       []services.ReportGetAllByPeriod `json:"data"`
+		NextCursor string                          `json:"next_cursor,omitempty"`
+		Total      *int64                          `json:"total,omitempty"`
 	}
 )
 
 func (h *reportsHandler) getAllByPeriod(ctx fiber.Ctx) error {
 	body := getAllByPeriodReq{
-		StartTime: time.Time{},
-		EndTime:   time.Time{},
+		StartTime:    time.Time{},
+		EndTime:      time.Time{},
+		TimeBasis:    "",
+		DeviceIDs:    nil,
+		MessageTypes: nil,
+		Severities:   nil,
+		Component:    "",
+		Text:         "",
+		Cursor:       "",
+		Limit:        0,
+		WithTotal:    false,
 	}
 
 	if err := ctx.Bind().Body(&body); err != nil {
@@ -121,23 +226,62 @@ func (h *reportsHandler) getAllByPeriod(ctx fiber.Ctx) error {
 			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
 		)
 	}
+	if body.Limit == 0 {
+		body.Limit = defaultReportLimit
+	}
+	format, err := reportFormat(ctx)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("reportFormat: %w", err).Error(),
+		)
+	}
+	if format != formatJSON {
+		body.Limit = exportPageSize
+		body.WithTotal = false
+	}
 
-	res, err := h.natsHandlers.GetAllByPeriod(
-		services.MessagesGetAllByPeriodOpts{
-			StartTime: body.StartTime,
-			EndTime:   body.EndTime,
+	opts := services.MessagesGetAllByPeriodOpts{
+		StartTime: body.StartTime,
+		EndTime:   body.EndTime,
+		TimeBasis: body.TimeBasis,
+		Filter: repo.MessagesFilter{
+			DeviceIDs:    body.DeviceIDs,
+			MessageTypes: body.MessageTypes,
+			Severities:   body.Severities,
+			Component:    body.Component,
+			Text:         body.Text,
 		},
-	)
+		Page: services.ReportPage{
+			Cursor:    body.Cursor,
+			Limit:     body.Limit,
+			WithTotal: body.WithTotal,
+		},
+	}
+
+	res, page, err := h.natsHandlers.GetAllByPeriod(opts)
 	if err != nil {
 		return fiber.NewError(
-			fiber.StatusInternalServerError,
+			reportErrorStatus(err),
 			fmt.Errorf("h.nats.PublishGetAllByPeriod: %w", err).Error(),
 		)
 	}
 
+	if format != formatJSON {
+		next := func(cursor string) ([]services.ReportGetAllByPeriod, string, error) {
+			opts.Page.Cursor = cursor
+			res, page, err := h.natsHandlers.GetAllByPeriod(opts)
+			return res, page.NextCursor, err
+		}
+
+		return h.sendTable(ctx, format, services.MessagesTable("messages_by_period", res, page.NextCursor, next))
+	}
+
 	jsonResponse, err := jsoniter.Marshal(
 		&getAllByPeriodResp{
-			Data: res,
+			Data:       res,
+			NextCursor: page.NextCursor,
+			Total:      page.Total,
 		},
 	)
 	if err != nil {
@@ -159,7 +303,8 @@ func (h *reportsHandler) getAllByPeriod(ctx fiber.Ctx) error {
 
 type (
 	getCountByMessageTypeReq struct {
-		MessageType string `form:"message_type" json:"message_type" validate:"required" xml:"message_type"`
+		MessageType string `form:"message_type" json:"message_type" validate:"required"        xml:"message_type"`
+		GroupID     int32  `form:"group_id"     json:"group_id"     validate:"omitempty,min=1" xml:"group_id"`
 	}
 
 	getCountByMessageTypeResp struct {
@@ -170,6 +315,7 @@ type (
 func (h *reportsHandler) getCountByMessageType(ctx fiber.Ctx) error {
 	body := getCountByMessageTypeReq{
 		MessageType: "",
+		GroupID:     0,
 	}
 
 	if err := ctx.Bind().Body(&body); err != nil {
@@ -179,8 +325,17 @@ func (h *reportsHandler) getCountByMessageType(ctx fiber.Ctx) error {
 		)
 	}
 
+	format, err := reportFormat(ctx)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("reportFormat: %w", err).Error(),
+		)
+	}
+
 	res, err := h.natsHandlers.GetCountByMessageType(
 		body.MessageType,
+		body.GroupID,
 	)
 
 	if err != nil {
@@ -190,6 +345,10 @@ func (h *reportsHandler) getCountByMessageType(ctx fiber.Ctx) error {
 		)
 	}
 
+	if format != formatJSON {
+		return h.sendTable(ctx, format, services.CountByMessageTypeTable(res))
+	}
+
 	jsonResponse, err := jsoniter.Marshal(
 		&getCountByMessageTypeResp{
 			Data: res,
@@ -213,13 +372,38 @@ func (h *reportsHandler) getCountByMessageType(ctx fiber.Ctx) error {
 }
 
 type (
+	getMonthReportReq struct {
+		TimeBasis string `query:"time_basis" validate:"omitempty,oneof=received event"`
+		GroupID   int32  `query:"group_id"   validate:"omitempty,min=1"`
+	}
+
 	getMonthReportResp struct {
 		Data []models.MonthReportRow `json:"data"`
 	}
 )
 
 func (h *reportsHandler) getMonthReport(ctx fiber.Ctx) error {
-	res, err := h.natsHandlers.MonthReport()
+	body := getMonthReportReq{
+		TimeBasis: "",
+		GroupID:   0,
+	}
+
+	if err := ctx.Bind().Query(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Query: %w", err).Error(),
+		)
+	}
+
+	format, err := reportFormat(ctx)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("reportFormat: %w", err).Error(),
+		)
+	}
+
+	res, err := h.natsHandlers.MonthReport(body.TimeBasis, body.GroupID)
 	if err != nil {
 		return fiber.NewError(
 			fiber.StatusInternalServerError,
@@ -227,6 +411,10 @@ func (h *reportsHandler) getMonthReport(ctx fiber.Ctx) error {
 		)
 	}
 
+	if format != formatJSON {
+		return h.sendTable(ctx, format, services.MonthReportTable(res, h.natsHandlers.Device))
+	}
+
 	jsonResponse, err := jsoniter.Marshal(
 		&getMonthReportResp{
 			Data: res,
@@ -249,6 +437,206 @@ func (h *reportsHandler) getMonthReport(ctx fiber.Ctx) error {
 	return nil
 }
 
+type (
+	// getPeriodReportReq groups by device and message type and counts the
+	// critical severity as critical unless told otherwise.
+	getPeriodReportReq struct {
+		StartTime          time.Time `form:"start_time"           json:"start_time"           validate:"required"                                           xml:"start_time"`
+		EndTime            time.Time `form:"end_time"             json:"end_time"             validate:"required"                                           xml:"end_time"`
+		TimeBasis          string    `form:"time_basis"           json:"time_basis"           validate:"omitempty,oneof=received event"                     xml:"time_basis"`
+		DeviceIDs          []int32   `form:"device_ids"           json:"device_ids"           validate:"omitempty"                                          xml:"device_ids"`
+		GroupID            int32     `form:"group_id"             json:"group_id"             validate:"omitempty,min=1"                                    xml:"group_id"`
+		MinMessages        int64     `form:"min_messages"         json:"min_messages"         validate:"omitempty,min=0"                                    xml:"min_messages"`
+		CriticalSeverities []string  `form:"critical_severities"  json:"critical_severities"  validate:"omitempty"                                          xml:"critical_severities"`
+		GroupBy            []string  `form:"group_by"             json:"group_by"             validate:"omitempty,dive,oneof=device message_type component" xml:"group_by"`
+	}
+
+	getPeriodReportResp struct {
+		Data []models.PeriodReportRow `json:"data"`
+	}
+)
+
+// getPeriodReport is the month report over any period, threshold, critical
+// severities and grouping.
+func (h *reportsHandler) getPeriodReport(ctx fiber.Ctx) error {
+	body := getPeriodReportReq{
+		StartTime:          time.Time{},
+		EndTime:            time.Time{},
+		TimeBasis:          "",
+		DeviceIDs:          nil,
+		GroupID:            0,
+		MinMessages:        0,
+		CriticalSeverities: nil,
+		GroupBy:            nil,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	format, err := reportFormat(ctx)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("reportFormat: %w", err).Error(),
+		)
+	}
+
+	res, err := h.natsHandlers.PeriodReport(services.PeriodReportParams{
+		StartTime:          body.StartTime,
+		EndTime:            body.EndTime,
+		TimeBasis:          body.TimeBasis,
+		DeviceIDs:          body.DeviceIDs,
+		GroupID:            body.GroupID,
+		MinMessages:        body.MinMessages,
+		CriticalSeverities: body.CriticalSeverities,
+		GroupBy:            body.GroupBy,
+	})
+	if err != nil {
+		return fiber.NewError(
+			reportErrorStatus(err),
+			fmt.Errorf("h.natsHandlers.PeriodReport: %w", err).Error(),
+		)
+	}
+
+	if format != formatJSON {
+		return h.sendTable(ctx, format, services.PeriodReportTable(res, h.natsHandlers.Device))
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&getPeriodReportResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	getAvailabilityReq struct {
+		StartTime string `query:"start_time" validate:"required"`
+		EndTime   string `query:"end_time"   validate:"required"`
+		DeviceID  int32  `query:"device_id"  validate:"omitempty,min=1"`
+	}
+
+	getAvailabilityResp struct {
+		Data []models.AvailabilityReportRow `json:"data"`
+	}
+)
+
+// getAvailability reports uptime per device for the period. Times are
+// RFC 3339.
+func (h *reportsHandler) getAvailability(ctx fiber.Ctx) error {
+	body := getAvailabilityReq{
+		StartTime: "",
+		EndTime:   "",
+		DeviceID:  0,
+	}
+
+	if err := ctx.Bind().Query(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Query: %w", err).Error(),
+		)
+	}
+
+	format, err := reportFormat(ctx)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("reportFormat: %w", err).Error(),
+		)
+	}
+
+	startTime, err := time.Parse(time.RFC3339, body.StartTime)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("start_time: %w", err).Error(),
+		)
+	}
+	endTime, err := time.Parse(time.RFC3339, body.EndTime)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Errorf("end_time: %w", err).Error(),
+		)
+	}
+	if !endTime.After(startTime) {
+		return fiber.NewError(
+			fiber.StatusBadRequest,
+			errors.New("end_time must be after start_time").Error(),
+		)
+	}
+
+	res, err := h.availability.Availability(
+		context.Background(),
+		services.AvailabilityParams{
+			StartTime: startTime,
+			EndTime:   endTime,
+			DeviceID:  body.DeviceID,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("h.availability.Availability: %w", err).Error(),
+		)
+	}
+
+	if format != formatJSON {
+		return h.sendTable(ctx, format, services.AvailabilityTable(res, h.natsHandlers.Device))
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&getAvailabilityResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+func reportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidReportCursor):
+		return fiber.StatusBadRequest
+	case errors.Is(err, services.ErrInvalidPeriodReport), errors.Is(err, services.ErrInvalidSearch):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
 func (h *reportsHandler) deserializeMW(ctx fiber.Ctx) error {
 	tokenString := ctx.Get("Authorization")
 
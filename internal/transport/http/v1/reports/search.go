@@ -0,0 +1,115 @@
+package reports
+
+import (
+	"fmt"
+	"monolith/internal/repo"
+	"monolith/internal/services"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type (
+	// searchReq searches the message text, see services.SearchMessagesOpts
+	// for the query syntax, and is paged like getAllByPeriodReq. Without
+	// start_time or end_time the period is open on that side; order_by is
+	// rank unless it is time.
+	searchReq struct {
+		Query        string    `form:"query"         json:"query"         validate:"required"                       xml:"query"`
+		StartTime    time.Time `form:"start_time"    json:"start_time"    validate:"omitempty"                      xml:"start_time"`
+		EndTime      time.Time `form:"end_time"      json:"end_time"      validate:"omitempty"                      xml:"end_time"`
+		TimeBasis    string    `form:"time_basis"    json:"time_basis"    validate:"omitempty,oneof=received event" xml:"time_basis"`
+		OrderBy      string    `form:"order_by"      json:"order_by"      validate:"omitempty,oneof=rank time"      xml:"order_by"`
+		DeviceIDs    []int32   `form:"device_ids"    json:"device_ids"    validate:"omitempty"                      xml:"device_ids"`
+		MessageTypes []string  `form:"message_types" json:"message_types" validate:"omitempty"                      xml:"message_types"`
+		Severities   []string  `form:"severities"    json:"severities"    validate:"omitempty"                      xml:"severities"`
+		Component    string    `form:"component"     json:"component"     validate:"omitempty"                      xml:"component"`
+		Cursor       string    `form:"cursor"        json:"cursor"        validate:"omitempty"                      xml:"cursor"`
+		Limit        int       `form:"limit"         json:"limit"         validate:"omitempty,min=1,max=1000"       xml:"limit"`
+		WithTotal    bool      `form:"with_total"    json:"with_total"    validate:"omitempty"                      xml:"with_total"`
+	}
+
+	searchResp struct {
+		Data       []services.ReportSearchHit `json:"data"`
+		NextCursor string                     `json:"next_cursor,omitempty"`
+		Total      *int64                     `json:"total,omitempty"`
+	}
+)
+
+func (h *reportsHandler) search(ctx fiber.Ctx) error {
+	body := searchReq{
+		Query:        "",
+		StartTime:    time.Time{},
+		EndTime:      time.Time{},
+		TimeBasis:    "",
+		OrderBy:      "",
+		DeviceIDs:    nil,
+		MessageTypes: nil,
+		Severities:   nil,
+		Component:    "",
+		Cursor:       "",
+		Limit:        0,
+		WithTotal:    false,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+	if body.Limit == 0 {
+		body.Limit = defaultReportLimit
+	}
+
+	res, page, err := h.natsHandlers.Search(services.SearchMessagesOpts{
+		Query:     body.Query,
+		StartTime: body.StartTime,
+		EndTime:   body.EndTime,
+		TimeBasis: body.TimeBasis,
+		Filter: repo.MessagesFilter{
+			DeviceIDs:    body.DeviceIDs,
+			MessageTypes: body.MessageTypes,
+			Severities:   body.Severities,
+			Component:    body.Component,
+			Text:         "",
+		},
+		OrderBy: body.OrderBy,
+		Page: services.ReportPage{
+			Cursor:    body.Cursor,
+			Limit:     body.Limit,
+			WithTotal: body.WithTotal,
+		},
+	})
+	if err != nil {
+		return fiber.NewError(
+			reportErrorStatus(err),
+			fmt.Errorf("h.natsHandlers.Search: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&searchResp{
+			Data:       res,
+			NextCursor: page.NextCursor,
+			Total:      page.Total,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
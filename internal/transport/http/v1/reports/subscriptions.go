@@ -0,0 +1,303 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/internal/services"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type (
+	subscriptionParams struct {
+		Period      string   `form:"period"        json:"period"        validate:"omitempty"                      xml:"period"`
+		TimeBasis   string   `form:"time_basis"    json:"time_basis"    validate:"omitempty,oneof=received event" xml:"time_basis"`
+		GroupID     int32    `form:"group_id"      json:"group_id"      validate:"omitempty,min=1"                xml:"group_id"`
+		DeviceID    int32    `form:"device_id"     json:"device_id"     validate:"omitempty,min=1"                xml:"device_id"`
+		MessageType string   `form:"message_type"  json:"message_type"  validate:"omitempty"                      xml:"message_type"`
+		Severities  []string `form:"severities"    json:"severities"    validate:"omitempty"                      xml:"severities"`
+	}
+
+	// createSubscriptionReq is enabled unless Enabled is false. Schedule is
+	// a five-field cron expression, e.g. "0 8 * * 1" for Mondays at 8:00.
+	createSubscriptionReq struct {
+		Name       string             `form:"name"        json:"name"        validate:"required"                                                                 xml:"name"`
+		Report     string             `form:"report"      json:"report"      validate:"oneof=messages_by_period count_by_message_type month_report availability" xml:"report"`
+		Params     subscriptionParams `form:"params"      json:"params"      validate:"omitempty"                                                                xml:"params"`
+		Schedule   string             `form:"schedule"    json:"schedule"    validate:"required"                                                                 xml:"schedule"`
+		Recipients []string           `form:"recipients"  json:"recipients"  validate:"required,min=1,dive,email"                                                xml:"recipients"`
+		Format     string             `form:"format"      json:"format"      validate:"omitempty,oneof=csv xlsx"                                                 xml:"format"`
+		Enabled    *bool              `form:"enabled"     json:"enabled"     validate:"omitempty"                                                                xml:"enabled"`
+	}
+
+	subscriptionResp struct {
+		Data models.ReportSubscription `json:"data"`
+	}
+)
+
+func (p subscriptionParams) model() models.ReportSubscriptionParams {
+	return models.ReportSubscriptionParams{
+		Period:      p.Period,
+		TimeBasis:   p.TimeBasis,
+		GroupID:     p.GroupID,
+		DeviceID:    p.DeviceID,
+		MessageType: p.MessageType,
+		Severities:  p.Severities,
+	}
+}
+
+func (h *reportsHandler) createSubscription(ctx fiber.Ctx) error {
+	body := createSubscriptionReq{
+		Name:       "",
+		Report:     "",
+		Params:     subscriptionParams{},
+		Schedule:   "",
+		Recipients: []string{},
+		Format:     "",
+		Enabled:    nil,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	res, err := h.subscriptions.Create(context.Background(), models.ReportSubscription{
+		Name:       body.Name,
+		Report:     body.Report,
+		Params:     body.Params.model(),
+		Schedule:   body.Schedule,
+		Recipients: body.Recipients,
+		Format:     body.Format,
+		Enabled:    body.Enabled == nil || *body.Enabled,
+	})
+	if err != nil {
+		return fiber.NewError(
+			subscriptionErrorStatus(err),
+			fmt.Errorf("h.subscriptions.Create: %w", err).Error(),
+		)
+	}
+
+	return sendSubscription(ctx, res)
+}
+
+type (
+	readSubscriptionsResp struct {
+		Data []models.ReportSubscription `json:"data"`
+	}
+)
+
+func (h *reportsHandler) readSubscriptions(ctx fiber.Ctx) error {
+	res, err := h.subscriptions.Read(context.Background())
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("h.subscriptions.Read: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&readSubscriptionsResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	// updateSubscriptionReq leaves the fields that are not given as they
+	// are. Params are replaced as a whole.
+	updateSubscriptionReq struct {
+		ID         int32               `form:"id"          json:"id"          validate:"required"                                                                           xml:"id"`
+		Name       *string             `form:"name"        json:"name"        validate:"omitempty,min=1"                                                                    xml:"name"`
+		Report     *string             `form:"report"      json:"report"      validate:"omitempty,oneof=messages_by_period count_by_message_type month_report availability" xml:"report"`
+		Params     *subscriptionParams `form:"params"      json:"params"      validate:"omitempty"                                                                          xml:"params"`
+		Schedule   *string             `form:"schedule"    json:"schedule"    validate:"omitempty,min=1"                                                                    xml:"schedule"`
+		Recipients []string            `form:"recipients"  json:"recipients"  validate:"omitempty,min=1,dive,email"                                                         xml:"recipients"`
+		Format     *string             `form:"format"      json:"format"      validate:"omitempty,oneof=csv xlsx"                                                           xml:"format"`
+		Enabled    *bool               `form:"enabled"     json:"enabled"     validate:"omitempty"                                                                          xml:"enabled"`
+	}
+)
+
+func (h *reportsHandler) updateSubscription(ctx fiber.Ctx) error {
+	body := updateSubscriptionReq{
+		ID:         0,
+		Name:       nil,
+		Report:     nil,
+		Params:     nil,
+		Schedule:   nil,
+		Recipients: nil,
+		Format:     nil,
+		Enabled:    nil,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	params := services.UpdateReportSubscriptionParams{
+		ID:         body.ID,
+		Name:       body.Name,
+		Report:     body.Report,
+		Params:     nil,
+		Schedule:   body.Schedule,
+		Recipients: body.Recipients,
+		Format:     body.Format,
+		Enabled:    body.Enabled,
+	}
+	if body.Params != nil {
+		model := body.Params.model()
+		params.Params = &model
+	}
+
+	res, err := h.subscriptions.Update(context.Background(), params)
+	if err != nil {
+		return fiber.NewError(
+			subscriptionErrorStatus(err),
+			fmt.Errorf("h.subscriptions.Update: %w", err).Error(),
+		)
+	}
+
+	return sendSubscription(ctx, res)
+}
+
+type (
+	subscriptionIDReq struct {
+		ID int32 `form:"id"  json:"id"  validate:"required"  xml:"id"`
+	}
+
+	deleteSubscriptionResp struct {
+		Data int `json:"data"`
+	}
+)
+
+func (h *reportsHandler) deleteSubscription(ctx fiber.Ctx) error {
+	body := subscriptionIDReq{
+		ID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	err := h.subscriptions.Delete(context.Background(), body.ID)
+	if err != nil {
+		return fiber.NewError(
+			subscriptionErrorStatus(err),
+			fmt.Errorf("h.subscriptions.Delete: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&deleteSubscriptionResp{
+			Data: fiber.StatusOK,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+// runSubscription mails the report now and waits for the result. A failed
+// run is recorded on the subscription and answered with 502.
+func (h *reportsHandler) runSubscription(ctx fiber.Ctx) error {
+	body := subscriptionIDReq{
+		ID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	res, err := h.subscriptions.RunNow(context.Background(), body.ID)
+	if err != nil {
+		return fiber.NewError(
+			subscriptionErrorStatus(err),
+			fmt.Errorf("h.subscriptions.RunNow: %w", err).Error(),
+		)
+	}
+
+	return sendSubscription(ctx, res)
+}
+
+func sendSubscription(ctx fiber.Ctx, subscription models.ReportSubscription) error {
+	jsonResponse, err := jsoniter.Marshal(
+		&subscriptionResp{
+			Data: subscription,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+// subscriptionErrorStatus answers a run that failed to render or mail with
+// 502; its error is also stored as the last run of the subscription.
+func subscriptionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidReportSubscription):
+		return fiber.StatusUnprocessableEntity
+	case errors.Is(err, repo.ErrReportSubscriptionNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, repo.ErrReportSubscriptionExists), errors.Is(err, services.ErrReportSubscriptionRunning):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrReportSubscriptionFailed):
+		return fiber.StatusBadGateway
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
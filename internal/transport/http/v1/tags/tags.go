@@ -49,14 +49,14 @@ func (h *tagsHandler) InitTagsRoutes(api fiber.Router) {
 
 type (
 	createReq struct {
-		Name          string `form:"name"         		json:"name"         	validate:"required"       				xml:"name"`
-		DeviceID      int32  `form:"device_id"    		json:"device_id"    	validate:"required"       				xml:"device_id"`
-		Regexp        string `form:"regexp"       		json:"regexp"       	validate:"required"          			xml:"regexp"`
-		CompareType   string `form:"compare_type" 		json:"compare_type" 	validate:"required,oneof='<' '>' '='"   xml:"compare_type"`
-		Value         string `form:"value"        		json:"value"        	validate:"required"       				xml:"value"`
-		ArrayIndex    int32  `form:"array_index"  		json:"array_index"  	validate:"required"       				xml:"array_index"`
-		Subject       string `form:"subject"      		json:"subject"      	validate:"required"	                    xml:"subject"`
-		SeverityLevel string `form:"severity_level" 	json:"severity_level" 	validate:"omitempty"					xml:"severity_level"`
+		Name          string `form:"name"            json:"name"            validate:"required"                    xml:"name"`
+		DeviceID      int32  `form:"device_id"       json:"device_id"       validate:"required"                    xml:"device_id"`
+		Regexp        string `form:"regexp"          json:"regexp"          validate:"required"                    xml:"regexp"`
+		CompareType   string `form:"compare_type"    json:"compare_type"    validate:"required,oneof='<' '>' '='"  xml:"compare_type"`
+		Value         string `form:"value"           json:"value"           validate:"required"                    xml:"value"`
+		ArrayIndex    int32  `form:"array_index"     json:"array_index"     validate:"gte=0"                       xml:"array_index"`
+		Subject       string `form:"subject"         json:"subject"         validate:"required"                    xml:"subject"`
+		SeverityLevel string `form:"severity_level"  json:"severity_level"  validate:"omitempty"                   xml:"severity_level"`
 	}
 
 	createResp struct {
@@ -169,15 +169,15 @@ func (h *tagsHandler) read(ctx fiber.Ctx) error {
 
 type (
 	updateReq struct {
-		ID            int32  `form:"id"           		json:"id"           	validate:"required"        						xml:"id"`
-		Name          string `form:"name"         		json:"name"         	validate:"omitempty"       						xml:"name"`
-		DeviceID      int32  `form:"device_id"    		json:"device_id"    	validate:"omitempty"       						xml:"device_id"`
-		Regexp        string `form:"regexp"       		json:"regexp"       	validate:"omitempty"       						xml:"regexp"`
-		CompareType   string `form:"compare_type" 		json:"compare_type" 	validate:"omitempty,oneof='<' '>' '='"       	xml:"compare_type"`
-		Value         string `form:"value"        		json:"value"        	validate:"omitempty"       						xml:"value"`
-		ArrayIndex    int32  `form:"array_index"  		json:"array_index"  	validate:"omitempty"       						xml:"array_index"`
-		Subject       string `form:"subject"      		json:"subject"      	validate:"omitempty"							xml:"subject"`
-		SeverityLevel string `form:"severity_level" 	json:"severity_level" 	validate:"omitempty"							xml:"severity_level"`
+		ID            int32  `form:"id"              json:"id"              validate:"required"                     xml:"id"`
+		Name          string `form:"name"            json:"name"            validate:"omitempty"                    xml:"name"`
+		DeviceID      int32  `form:"device_id"       json:"device_id"       validate:"omitempty"                    xml:"device_id"`
+		Regexp        string `form:"regexp"          json:"regexp"          validate:"omitempty"                    xml:"regexp"`
+		CompareType   string `form:"compare_type"    json:"compare_type"    validate:"omitempty,oneof='<' '>' '='"  xml:"compare_type"`
+		Value         string `form:"value"           json:"value"           validate:"omitempty"                    xml:"value"`
+		ArrayIndex    int32  `form:"array_index"     json:"array_index"     validate:"gte=0"                        xml:"array_index"`
+		Subject       string `form:"subject"         json:"subject"         validate:"omitempty"                    xml:"subject"`
+		SeverityLevel string `form:"severity_level"  json:"severity_level"  validate:"omitempty"                    xml:"severity_level"`
 	}
 
 	updateResp struct {
@@ -266,7 +266,7 @@ func (h *tagsHandler) update(ctx fiber.Ctx) error {
 
 type (
 	deleteReq struct {
-		ID int `form:"id"     json:"id"     validate:"required"            xml:"id"`
+		ID int `form:"id"  json:"id"  validate:"required"  xml:"id"`
 	}
 
 	deleteResp struct {
@@ -0,0 +1,267 @@
+package unknownsources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monolith/internal/models"
+	"monolith/internal/repo"
+	"monolith/internal/services"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	localID = "localID"
+)
+
+type unknownSourcesHandler struct {
+	unknownSources services.UnknownSources
+	jwtKey         string
+}
+
+type Config struct {
+	JWTKey         string
+	UnknownSources services.UnknownSources
+}
+
+func NewUnknownSourcesHandler(cfg *Config) *unknownSourcesHandler {
+	return &unknownSourcesHandler{
+		jwtKey:         cfg.JWTKey,
+		unknownSources: cfg.UnknownSources,
+	}
+}
+
+func (h *unknownSourcesHandler) InitUnknownSourcesRoutes(api fiber.Router) {
+	servicesRoute := api.Group("/unknown_sources", h.deserializeMW)
+	servicesRoute.Get("/read", h.read)
+	servicesRoute.Post("/promote", h.promote)
+	servicesRoute.Post("/block", h.block)
+}
+
+type (
+	readReq struct {
+		Status string `query:"status" validate:"omitempty,oneof=pending blocked promoted"`
+	}
+
+	readResp struct {
+		Data []models.UnknownSource `json:"data"`
+	}
+)
+
+func (h *unknownSourcesHandler) read(ctx fiber.Ctx) error {
+	body := readReq{
+		Status: "",
+	}
+
+	if err := ctx.Bind().Query(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Query: %w", err).Error(),
+		)
+	}
+
+	res, err := h.unknownSources.Read(context.Background(), body.Status)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("h.unknownSources.Read: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&readResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	promoteReq struct {
+		ID          int32   `form:"id"           json:"id"           validate:"required"  xml:"id"`
+		Name        string  `form:"name"         json:"name"         validate:"required"  xml:"name"`
+		DeviceType  string  `form:"device_type"  json:"device_type"  validate:"required"  xml:"device_type"`
+		Responsible []int32 `form:"responsible"  json:"responsible"  validate:"omitempty" xml:"responsible"`
+	}
+
+	promoteResp struct {
+		Data services.PromoteUnknownSourceResult `json:"data"`
+	}
+)
+
+func (h *unknownSourcesHandler) promote(ctx fiber.Ctx) error {
+	body := promoteReq{
+		ID:          0,
+		Name:        "",
+		DeviceType:  "",
+		Responsible: []int32{},
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	res, err := h.unknownSources.Promote(
+		context.Background(),
+		services.PromoteUnknownSourceParams{
+			ID:          body.ID,
+			Name:        body.Name,
+			DeviceType:  body.DeviceType,
+			Responsible: body.Responsible,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			errorStatus(err),
+			fmt.Errorf("h.unknownSources.Promote: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&promoteResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+type (
+	blockReq struct {
+		ID int32 `form:"id"  json:"id"  validate:"required"  xml:"id"`
+	}
+
+	blockResp struct {
+		Data models.UnknownSource `json:"data"`
+	}
+)
+
+func (h *unknownSourcesHandler) block(ctx fiber.Ctx) error {
+	body := blockReq{
+		ID: 0,
+	}
+
+	if err := ctx.Bind().Body(&body); err != nil {
+		return fiber.NewError(
+			fiber.StatusUnprocessableEntity,
+			fmt.Errorf("ctx.Bind().Body: %w", err).Error(),
+		)
+	}
+
+	res, err := h.unknownSources.Block(context.Background(), body.ID)
+	if err != nil {
+		return fiber.NewError(
+			errorStatus(err),
+			fmt.Errorf("h.unknownSources.Block: %w", err).Error(),
+		)
+	}
+
+	jsonResponse, err := jsoniter.Marshal(
+		&blockResp{
+			Data: res,
+		},
+	)
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("json.Marshal: %w", err).Error(),
+		)
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(jsonResponse); err != nil {
+		return fiber.NewError(
+			fiber.StatusInternalServerError,
+			fmt.Errorf("ctx.Send: %w", err).Error(),
+		)
+	}
+
+	return nil
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, repo.ErrUnknownSourceNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, repo.ErrUnknownSourcePromoted), errors.Is(err, repo.ErrDeviceExists):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func (h *unknownSourcesHandler) deserializeMW(ctx fiber.Ctx) error {
+	tokenString := ctx.Get("Authorization")
+
+	if tokenString == "" {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			errors.New("tokenString is empty").Error(),
+		)
+	}
+
+	tokenString = strings.ReplaceAll(tokenString, "Bearer ", "")
+	token, err := jwt.Parse(tokenString, func(_ *jwt.Token) (interface{}, error) {
+		return []byte(h.jwtKey), nil
+	})
+	if err != nil {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			fmt.Errorf("jwt.Parse: %w", err).Error(),
+		)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims) //nolint:varnamelen
+	if !ok {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			errors.New("token.Claims.(jwt.MapClaims): invalid token").Error(),
+		)
+	}
+
+	userID, ok := claims[localID].(float64) //nolint:varnamelen
+	if !ok {
+		return fiber.NewError(
+			fiber.StatusUnauthorized,
+			errors.New("claims["+localID+"].(float64): invalid token").Error(),
+		)
+	}
+
+	ctx.Locals(localID, int(userID))
+
+	return ctx.Next() //nolint:wrapcheck
+}
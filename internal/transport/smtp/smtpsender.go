@@ -1,15 +1,32 @@
 package smtpsender
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
 	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
 )
 
+const defaultTimeout = 10 * time.Second
+
 type SMTPSender struct {
 	User     string
 	Password string
 	Host     string
 	Port     string
+	From     string
+	Timeout  time.Duration
 }
 
 type Config struct {
@@ -17,41 +34,273 @@ type Config struct {
 	Port     string
 	User     string
 	Password string
+	// From is used by Mail; Send takes the sender from the email.
+	From    string
+	Timeout time.Duration
 }
 
 func New(cfg Config) SMTPSender {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
 	return SMTPSender{
 		User:     cfg.User,
 		Password: cfg.Password,
 		Host:     cfg.Host,
 		Port:     cfg.Port,
+		From:     cfg.From,
+		Timeout:  timeout,
 	}
 }
 
 func (s *SMTPSender) Send(e *Email) error {
-	// auth := smtp.PlainAuth("", s.User, s.Password, s.Host)
+	return s.SendContext(context.Background(), e)
+}
+
+// Mail sends a plain text email from the configured sender.
+func (s *SMTPSender) Mail(ctx context.Context, to []string, subject, body string) error {
+	return s.SendContext(ctx, &Email{
+		Subject: subject,
+		Body:    body,
+		From:    s.From,
+		To:      to,
+	})
+}
+
+// MailReport sends a report from the configured sender: the text and HTML
+// versions of the body with the report file attached.
+func (s *SMTPSender) MailReport(
+	ctx context.Context,
+	to []string,
+	subject, text, html string,
+	filename, contentType string,
+	data []byte,
+) error {
+	return s.SendContext(ctx, &Email{
+		Subject: subject,
+		Body:    text,
+		HTML:    html,
+		From:    s.From,
+		To:      to,
+		Attachments: []Attachment{{
+			Filename:    filename,
+			ContentType: contentType,
+			Data:        data,
+		}},
+	})
+}
+
+// SendContext uses STARTTLS when the server offers it and authenticates
+// when a user is configured.
+func (s *SMTPSender) SendContext(ctx context.Context, e *Email) error {
+	if len(e.To) == 0 {
+		return errors.New("no recipients")
+	}
 
-	// Send email
-	err := smtp.SendMail(fmt.Sprintf("%s:%s", s.Host, s.Port), nil, e.From, e.To, e.build())
+	msg, err := e.build()
 	if err != nil {
-		return fmt.Errorf("smtp.SendMail: %w", err)
+		return fmt.Errorf("e.build: %w", err)
 	}
 
-	return nil
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.Host, s.Port))
+	if err != nil {
+		return fmt.Errorf("dialer.DialContext: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp.NewClient: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("client.StartTLS: %w", err)
+		}
+	}
+
+	if s.User != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.User, s.Password, s.Host)); err != nil {
+			return fmt.Errorf("client.Auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(e.From); err != nil {
+		return fmt.Errorf("client.Mail: %w", err)
+	}
+	for _, to := range e.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("client.Rcpt: %w", err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("client.Data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return fmt.Errorf("w.Write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("w.Close: %w", err)
+	}
+
+	return client.Quit() //nolint:wrapcheck
 }
 
 type Email struct {
 	Subject string
 	Body    string
-	From    string
-	To      []string
+	// HTML is optional; with it the email carries both versions of the body.
+	HTML        string
+	From        string
+	To          []string
+	Attachments []Attachment
+}
+
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
 }
 
-func (e *Email) build() []byte {
-	message := fmt.Sprintf("From: %s\r\n", e.From)
-	message += fmt.Sprintf("To: %s\r\n", e.To)
-	message += fmt.Sprintf("Subject: %s\r\n", e.Subject)
-	message += fmt.Sprintf("\r\n%s\r\n", e.Body)
+// base64LineLen is the longest line of a base64 encoded part allowed by
+// RFC 2045.
+const base64LineLen = 76
 
-	return []byte(message)
+func (e *Email) build() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("From: " + e.From + "\r\n")
+	buf.WriteString("To: " + strings.Join(e.To, ", ") + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", e.Subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if e.HTML == "" && len(e.Attachments) == 0 {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+		buf.WriteString("\r\n")
+
+		if err := writeQuotedPrintable(&buf, e.Body); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	buf.WriteString("Content-Type: multipart/mixed; boundary=" + mixed.Boundary() + "\r\n")
+	buf.WriteString("\r\n")
+
+	if err := e.writeBody(mixed); err != nil {
+		return nil, err
+	}
+
+	for _, a := range e.Attachments {
+		if err := writeAttachment(mixed, a); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, fmt.Errorf("mixed.Close: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeBody writes the body as a multipart/alternative part with the plain
+// text first, so clients that cannot show HTML fall back to it.
+func (e *Email) writeBody(mixed *multipart.Writer) error {
+	var body bytes.Buffer
+	alternative := multipart.NewWriter(&body)
+
+	parts := []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", e.Body},
+		{"text/html; charset=utf-8", e.HTML},
+	}
+	for _, part := range parts {
+		if part.content == "" {
+			continue
+		}
+
+		w, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return fmt.Errorf("alternative.CreatePart: %w", err)
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return err
+		}
+	}
+
+	if err := alternative.Close(); err != nil {
+		return fmt.Errorf("alternative.Close: %w", err)
+	}
+
+	w, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()},
+	})
+	if err != nil {
+		return fmt.Errorf("mixed.CreatePart: %w", err)
+	}
+	if _, err := w.Write(body.Bytes()); err != nil {
+		return fmt.Errorf("w.Write: %w", err)
+	}
+
+	return nil
+}
+
+func writeAttachment(mixed *multipart.Writer, a Attachment) error {
+	mediaType, params, err := mime.ParseMediaType(a.ContentType)
+	if err != nil {
+		mediaType, params = "application/octet-stream", map[string]string{}
+	}
+	params["name"] = a.Filename
+
+	w, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(mediaType, params)},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return fmt.Errorf("mixed.CreatePart: %w", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	for len(encoded) > 0 {
+		n := min(base64LineLen, len(encoded))
+		if _, err := io.WriteString(w, encoded[:n]+"\r\n"); err != nil {
+			return fmt.Errorf("io.WriteString: %w", err)
+		}
+		encoded = encoded[n:]
+	}
+
+	return nil
+}
+
+func writeQuotedPrintable(w io.Writer, text string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return fmt.Errorf("qp.Write: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("qp.Close: %w", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,226 @@
+package snmp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"monolith/internal/models"
+	"monolith/internal/services"
+
+	"github.com/gosnmp/gosnmp"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+)
+
+const (
+	messageType      = "snmp_trap"
+	defaultComponent = "snmp"
+
+	// maxComponentLen matches messages.component varchar(30).
+	maxComponentLen = 30
+
+	oidSysUpTime   = "1.3.6.1.2.1.1.3.0"
+	oidTrapOID     = "1.3.6.1.6.3.1.1.4.1.0"
+	oidTrapAddress = "1.3.6.1.6.3.18.1.3.0"
+)
+
+// Server receives SNMPv2c traps and turns each of them into a message of
+// the device the trap was sent for.
+type Server struct {
+	addr           string
+	community      string
+	oidNames       map[string]string
+	listener       *gosnmp.TrapListener
+	messages       services.Messages
+	unknownSources services.UnknownSources
+	log            *zap.Logger
+}
+
+type Config struct {
+	// Addr is a UDP address, e.g. ":162" or "127.0.0.1:9162".
+	Addr string
+	// Community is checked against incoming traps when not empty.
+	Community string
+	// OIDNames maps numeric OIDs (without the leading dot) to readable
+	// names. It may be nil.
+	OIDNames       map[string]string
+	Messages       services.Messages
+	UnknownSources services.UnknownSources
+	Log            *zap.Logger
+}
+
+func NewServer(cfg Config) *Server {
+	oidNames := make(map[string]string, len(cfg.OIDNames))
+	for oid, name := range cfg.OIDNames {
+		oidNames[strings.TrimPrefix(oid, ".")] = name
+	}
+
+	server := &Server{
+		addr:           cfg.Addr,
+		community:      cfg.Community,
+		oidNames:       oidNames,
+		listener:       gosnmp.NewTrapListener(),
+		messages:       cfg.Messages,
+		unknownSources: cfg.UnknownSources,
+		log:            cfg.Log,
+	}
+
+	server.listener.Params = &gosnmp.GoSNMP{
+		Port:      gosnmp.Default.Port,
+		Transport: gosnmp.Default.Transport,
+		Community: cfg.Community,
+		Version:   gosnmp.Version2c,
+		Timeout:   gosnmp.Default.Timeout,
+		Retries:   gosnmp.Default.Retries,
+		MaxOids:   gosnmp.MaxOids,
+	}
+	server.listener.OnNewTrap = server.handle
+
+	return server
+}
+
+// LoadOIDNames reads an OID-to-name table from a JSON object file such as
+// {"1.3.6.1.4.1.318.0.5": "upsOnBattery"}.
+func LoadOIDNames(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	oidNames := make(map[string]string)
+	if err := jsoniter.Unmarshal(data, &oidNames); err != nil {
+		return nil, fmt.Errorf("jsoniter.Unmarshal: %w", err)
+	}
+
+	return oidNames, nil
+}
+
+// Run blocks until Stop is called.
+func (s *Server) Run() error {
+	if err := s.listener.Listen(s.addr); err != nil {
+		return fmt.Errorf("s.listener.Listen: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.listener.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("snmp server stop: %w", ctx.Err())
+	}
+}
+
+func (s *Server) handle(packet *gosnmp.SnmpPacket, remote *net.UDPAddr) {
+	if s.community != "" && packet.Community != s.community {
+		s.log.Warn("snmp trap with unexpected community", zap.String("address", remote.IP.String()))
+		return
+	}
+
+	address := remote.IP.String()
+	if packet.AgentAddress != "" && packet.AgentAddress != "0.0.0.0" {
+		address = packet.AgentAddress
+	}
+
+	trapName := ""
+	if packet.Version == gosnmp.Version1 {
+		trapName = s.name(packet.Enterprise)
+	}
+
+	varbinds := make([]string, 0, len(packet.Variables))
+	for _, variable := range packet.Variables {
+		switch strings.TrimPrefix(variable.Name, ".") {
+		case oidSysUpTime:
+			continue
+		case oidTrapOID:
+			trapName = s.name(fmt.Sprint(variable.Value))
+			continue
+		case oidTrapAddress:
+			if ip, ok := variable.Value.(string); ok && ip != "" {
+				address = ip
+			}
+			continue
+		}
+
+		varbinds = append(varbinds, fmt.Sprintf("%s=%s", s.name(variable.Name), s.value(variable)))
+	}
+
+	if trapName == "" {
+		trapName = "trap"
+	}
+
+	deviceID, err := s.unknownSources.Resolve(address)
+	if err != nil {
+		s.log.Debug("s.unknownSources.Resolve", zap.Error(err), zap.String("address", address))
+		return
+	}
+
+	err = s.messages.Enqueue(models.Message{
+		DeviceId:    deviceID,
+		Message:     strings.TrimSpace(trapName + " " + strings.Join(varbinds, " ")),
+		MessageType: messageType,
+		Component:   component(trapName),
+		DeviceIP:    address,
+	})
+	if err != nil {
+		s.log.Warn("s.messages.Enqueue", zap.Error(err), zap.String("address", address))
+	}
+}
+
+// name resolves an OID by the longest known prefix, so a table entry for
+// ifDescr also renders "1.3.6.1.2.1.2.2.1.2.3" as "ifDescr.3".
+func (s *Server) name(oid string) string {
+	oid = strings.TrimPrefix(oid, ".")
+	if name, ok := s.oidNames[oid]; ok {
+		return name
+	}
+
+	for prefix := oid; ; {
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			return oid
+		}
+		prefix = prefix[:i]
+		if name, ok := s.oidNames[prefix]; ok {
+			return name + oid[len(prefix):]
+		}
+	}
+}
+
+func (s *Server) value(variable gosnmp.SnmpPDU) string {
+	switch variable.Type {
+	case gosnmp.OctetString:
+		if b, ok := variable.Value.([]byte); ok {
+			return string(b)
+		}
+	case gosnmp.ObjectIdentifier:
+		return s.name(fmt.Sprint(variable.Value))
+	case gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView:
+		return variable.Type.String()
+	}
+
+	return fmt.Sprint(variable.Value)
+}
+
+func component(name string) string {
+	if name == "" {
+		return defaultComponent
+	}
+
+	if utf8.RuneCountInString(name) > maxComponentLen {
+		return string([]rune(name)[:maxComponentLen])
+	}
+
+	return name
+}
@@ -0,0 +1,142 @@
+package snmp
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"monolith/internal/models"
+	"monolith/internal/services"
+
+	"github.com/gosnmp/gosnmp"
+	"go.uber.org/zap"
+)
+
+// fakeMessages records the enqueued messages; other methods are not used.
+type fakeMessages struct {
+	services.Messages
+	enqueued chan models.Message
+}
+
+func (m *fakeMessages) Enqueue(msg models.Message) error {
+	m.enqueued <- msg
+	return nil
+}
+
+type fakeUnknownSources struct {
+	services.UnknownSources
+	devices map[string]int32
+}
+
+func (u *fakeUnknownSources) Resolve(address string) (int32, error) {
+	return u.devices[address], nil
+}
+
+const testDeviceID = 7
+
+// startServer runs a server on a free loopback port and returns the port.
+func startServer(t *testing.T, community string) (*fakeMessages, int) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.ListenPacket: %v", err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	conn.Close()
+
+	messages := &fakeMessages{enqueued: make(chan models.Message, 1)}
+	server := NewServer(Config{
+		Addr:      net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		Community: community,
+		OIDNames: map[string]string{
+			".1.3.6.1.4.1.318.0.5": "upsOnBattery",
+			"1.3.6.1.2.1.2.2.1.2":  "ifDescr",
+		},
+		Messages:       messages,
+		UnknownSources: &fakeUnknownSources{devices: map[string]int32{"127.0.0.1": testDeviceID}},
+		Log:            zap.NewNop(),
+	})
+
+	go func() {
+		if err := server.Run(); err != nil {
+			t.Errorf("server.Run: %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		if err := server.Stop(context.Background()); err != nil {
+			t.Errorf("server.Stop: %v", err)
+		}
+	})
+
+	select {
+	case <-server.listener.Listening():
+	case <-time.After(time.Second):
+		t.Fatal("listener did not start")
+	}
+
+	return messages, port
+}
+
+func sendTrap(t *testing.T, port int, community string) {
+	t.Helper()
+
+	sender := &gosnmp.GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(port),
+		Transport: "udp",
+		Community: community,
+		Version:   gosnmp.Version2c,
+		Timeout:   time.Second,
+		MaxOids:   gosnmp.MaxOids,
+	}
+	if err := sender.Connect(); err != nil {
+		t.Fatalf("sender.Connect: %v", err)
+	}
+	defer sender.Conn.Close()
+
+	_, err := sender.SendTrap(gosnmp.SnmpTrap{
+		Variables: []gosnmp.SnmpPDU{
+			{Name: "." + oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+			{Name: "." + oidTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.318.0.5"},
+			{Name: ".1.3.6.1.2.1.2.2.1.2.3", Type: gosnmp.OctetString, Value: "eth0"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("sender.SendTrap: %v", err)
+	}
+}
+
+func TestServerEnqueuesTrap(t *testing.T) {
+	messages, port := startServer(t, "public")
+	sendTrap(t, port, "public")
+
+	select {
+	case msg := <-messages.enqueued:
+		want := models.Message{
+			DeviceId:    testDeviceID,
+			Message:     "upsOnBattery ifDescr.3=eth0",
+			MessageType: messageType,
+			Component:   "upsOnBattery",
+			DeviceIP:    "127.0.0.1",
+		}
+		if msg != want {
+			t.Errorf("enqueued %+v, want %+v", msg, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message was enqueued")
+	}
+}
+
+func TestServerDropsTrapWithOtherCommunity(t *testing.T) {
+	messages, port := startServer(t, "public")
+	sendTrap(t, port, "private")
+
+	select {
+	case msg := <-messages.enqueued:
+		t.Errorf("enqueued %+v, want nothing", msg)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,210 @@
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrEmptyMessage = errors.New("empty message")
+	ErrInvalidPRI   = errors.New("invalid PRI part")
+)
+
+const nilValue = "-"
+
+// Message is a syslog record parsed from either RFC 3164 or RFC 5424 format.
+// Fields that are absent in the original record are left empty.
+type Message struct {
+	Facility  int
+	Severity  int
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+	Text      string
+}
+
+// Parse detects the record format by the version field that follows PRI:
+// "<34>1 ..." is RFC 5424, everything else is handled as RFC 3164.
+func Parse(raw []byte) (Message, error) {
+	raw = bytes.TrimRight(raw, "\r\n\x00")
+	if len(raw) == 0 {
+		return Message{}, ErrEmptyMessage
+	}
+
+	pri, rest, err := parsePRI(raw)
+	if err != nil {
+		return Message{}, err
+	}
+
+	msg := Message{
+		Facility: pri / 8, //nolint:mnd
+		Severity: pri % 8, //nolint:mnd
+	}
+
+	if len(rest) > 1 && rest[0] == '1' && rest[1] == ' ' {
+		return parseRFC5424(msg, string(rest[2:]))
+	}
+
+	return parseRFC3164(msg, string(rest)), nil
+}
+
+func parsePRI(raw []byte) (int, []byte, error) {
+	if raw[0] != '<' {
+		return 0, nil, ErrInvalidPRI
+	}
+
+	end := bytes.IndexByte(raw, '>')
+	if end < 2 || end > 4 { //nolint:mnd
+		return 0, nil, ErrInvalidPRI
+	}
+
+	pri, err := strconv.Atoi(string(raw[1:end]))
+	if err != nil || pri > 191 { //nolint:mnd
+		return 0, nil, ErrInvalidPRI
+	}
+
+	return pri, raw[end+1:], nil
+}
+
+func parseRFC5424(msg Message, rest string) (Message, error) {
+	fields := make([]string, 0, 5) //nolint:mnd
+	for range 5 {
+		field, tail, ok := strings.Cut(rest, " ")
+		if !ok {
+			return Message{}, errors.New("rfc5424: header is truncated")
+		}
+		fields = append(fields, field)
+		rest = tail
+	}
+
+	if fields[0] != nilValue {
+		ts, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return Message{}, fmt.Errorf("rfc5424: time.Parse: %w", err)
+		}
+		msg.Timestamp = ts
+	}
+
+	msg.Hostname = nilToEmpty(fields[1])
+	msg.AppName = nilToEmpty(fields[2])
+	msg.ProcID = nilToEmpty(fields[3])
+	msg.MsgID = nilToEmpty(fields[4])
+
+	rest, err := skipStructuredData(rest)
+	if err != nil {
+		return Message{}, err
+	}
+
+	msg.Text = strings.TrimPrefix(strings.TrimPrefix(rest, " "), "\ufeff")
+
+	return msg, nil
+}
+
+// skipStructuredData drops the STRUCTURED-DATA part, honouring escaped
+// characters inside param values.
+func skipStructuredData(rest string) (string, error) {
+	if strings.HasPrefix(rest, nilValue) {
+		return rest[1:], nil
+	}
+
+	for strings.HasPrefix(rest, "[") {
+		inQuotes := false
+		end := -1
+		for i := 1; i < len(rest) && end < 0; i++ {
+			switch rest[i] {
+			case '\\':
+				i++
+			case '"':
+				inQuotes = !inQuotes
+			case ']':
+				if !inQuotes {
+					end = i
+				}
+			}
+		}
+		if end < 0 {
+			return "", errors.New("rfc5424: unterminated structured data")
+		}
+		rest = rest[end+1:]
+	}
+
+	return rest, nil
+}
+
+// rfc3164TimeLayout is the BSD timestamp, e.g. "Oct 11 22:14:15". Days below
+// ten are padded with a space, which time.Stamp handles via "_2".
+const rfc3164TimeLayout = time.Stamp
+
+// parseRFC3164 is deliberately lenient: real devices often omit the
+// timestamp or the hostname, so the record degrades to plain text rather
+// than being rejected.
+func parseRFC3164(msg Message, rest string) Message {
+	if len(rest) >= len(rfc3164TimeLayout) {
+		if ts, err := time.Parse(rfc3164TimeLayout, rest[:len(rfc3164TimeLayout)]); err == nil {
+			now := time.Now()
+			msg.Timestamp = time.Date(now.Year(), ts.Month(), ts.Day(),
+				ts.Hour(), ts.Minute(), ts.Second(), 0, time.Local)
+			// A December record received in January belongs to last year.
+			if msg.Timestamp.After(now.AddDate(0, 1, 0)) {
+				msg.Timestamp = msg.Timestamp.AddDate(-1, 0, 0)
+			}
+			rest = strings.TrimPrefix(rest[len(rfc3164TimeLayout):], " ")
+
+			if host, tail, ok := strings.Cut(rest, " "); ok && !isTag(host) {
+				msg.Hostname = host
+				rest = tail
+			}
+		}
+	}
+
+	if tag, tail, ok := cutTag(rest); ok {
+		msg.AppName = tag
+		rest = tail
+	}
+
+	msg.Text = strings.TrimSpace(rest)
+
+	return msg
+}
+
+// cutTag splits "app[123]: text" into the app name and the text. Process
+// IDs are dropped since devices are identified by their address.
+func cutTag(rest string) (string, string, bool) {
+	end := strings.IndexAny(rest, "[: ")
+	if end <= 0 || end > 48 { //nolint:mnd
+		return "", rest, false
+	}
+
+	tag := rest[:end]
+	tail := rest[end:]
+	if strings.HasPrefix(tail, "[") {
+		closing := strings.IndexByte(tail, ']')
+		if closing < 0 {
+			return "", rest, false
+		}
+		tail = tail[closing+1:]
+	}
+
+	if !strings.HasPrefix(tail, ":") {
+		return "", rest, false
+	}
+
+	return tag, tail[1:], true
+}
+
+func isTag(s string) bool {
+	return strings.HasSuffix(s, ":")
+}
+
+func nilToEmpty(s string) string {
+	if s == nilValue {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+package syslog
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    Message
+		wantErr error
+	}{
+		{
+			name: "rfc5424",
+			raw:  "<34>1 2024-10-11T22:14:15.003Z host su 77 ID47 - 'su root' failed\n",
+			want: Message{
+				Facility:  4,
+				Severity:  2,
+				Timestamp: time.Date(2024, 10, 11, 22, 14, 15, 3000000, time.UTC),
+				Hostname:  "host",
+				AppName:   "su",
+				ProcID:    "77",
+				MsgID:     "ID47",
+				Text:      "'su root' failed",
+			},
+		},
+		{
+			name: "rfc5424 with structured data and nil values",
+			raw:  `<165>1 - - - - - [ex@1 a="x\]y"][ex@2 b="z"] text`,
+			want: Message{
+				Facility: 20,
+				Severity: 5,
+				Text:     "text",
+			},
+		},
+		{
+			name: "rfc3164 tag without timestamp",
+			raw:  "<13>sshd[42]: Accepted password",
+			want: Message{
+				Facility: 1,
+				Severity: 5,
+				AppName:  "sshd",
+				Text:     "Accepted password",
+			},
+		},
+		{
+			name: "rfc3164 plain text",
+			raw:  "<0>fan failure on tray 2",
+			want: Message{
+				Text: "fan failure on tray 2",
+			},
+		},
+		{
+			name:    "empty",
+			raw:     "\r\n",
+			wantErr: ErrEmptyMessage,
+		},
+		{
+			name:    "missing PRI",
+			raw:     "no pri",
+			wantErr: ErrInvalidPRI,
+		},
+		{
+			name:    "PRI out of range",
+			raw:     "<192>text",
+			wantErr: ErrInvalidPRI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.raw))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !got.Timestamp.Equal(tt.want.Timestamp) {
+				t.Errorf("Parse() timestamp = %v, want %v", got.Timestamp, tt.want.Timestamp)
+			}
+			got.Timestamp, tt.want.Timestamp = time.Time{}, time.Time{}
+			if got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverityLevel(t *testing.T) {
+	want := []string{"critical", "critical", "critical", "error", "warning", "info", "info", "debug"}
+	for severity, level := range want {
+		if got := SeverityLevel(severity); got != level {
+			t.Errorf("SeverityLevel(%d) = %q, want %q", severity, got, level)
+		}
+	}
+}
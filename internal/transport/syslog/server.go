@@ -0,0 +1,338 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"monolith/internal/models"
+	"monolith/internal/services"
+
+	"go.uber.org/zap"
+)
+
+const (
+	messageType      = "syslog"
+	defaultComponent = "syslog"
+
+	// maxMessageSize bounds both UDP datagrams and TCP frames.
+	maxMessageSize = 64 * 1024
+	// maxFrameLengthDigits is the number of digits of maxMessageSize, the
+	// longest octet count a frame may start with.
+	maxFrameLengthDigits = 5
+	// maxComponentLen matches messages.component varchar(30).
+	maxComponentLen = 30
+)
+
+type Server struct {
+	udpAddr        string
+	tcpAddr        string
+	messages       services.Messages
+	unknownSources services.UnknownSources
+	log            *zap.Logger
+
+	mu          sync.Mutex
+	udpConn     net.PacketConn
+	tcpListener net.Listener
+	conns       map[net.Conn]struct{}
+	wg          sync.WaitGroup
+	closed      atomic.Bool
+}
+
+type Config struct {
+	UDPAddr        string
+	TCPAddr        string
+	Messages       services.Messages
+	UnknownSources services.UnknownSources
+	Log            *zap.Logger
+}
+
+func NewServer(cfg Config) *Server {
+	return &Server{
+		udpAddr:        cfg.UDPAddr,
+		tcpAddr:        cfg.TCPAddr,
+		messages:       cfg.Messages,
+		unknownSources: cfg.UnknownSources,
+		log:            cfg.Log,
+		conns:          make(map[net.Conn]struct{}),
+	}
+}
+
+// Run listens on the configured addresses and blocks until both listeners
+// are stopped. An empty address disables the corresponding listener.
+func (s *Server) Run() error {
+	errCh := make(chan error, 2) //nolint:mnd
+
+	if s.udpAddr != "" {
+		conn, err := net.ListenPacket("udp", s.udpAddr)
+		if err != nil {
+			return fmt.Errorf("net.ListenPacket: %w", err)
+		}
+		s.mu.Lock()
+		s.udpConn = conn
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			errCh <- s.serveUDP(conn)
+		}()
+	}
+
+	if s.tcpAddr != "" {
+		listener, err := net.Listen("tcp", s.tcpAddr)
+		if err != nil {
+			// Stop the UDP listener, so that nothing outlives Run.
+			s.closed.Store(true)
+			s.mu.Lock()
+			if s.udpConn != nil {
+				s.udpConn.Close()
+			}
+			s.mu.Unlock()
+			s.wg.Wait()
+
+			return fmt.Errorf("net.Listen: %w", err)
+		}
+		s.mu.Lock()
+		s.tcpListener = listener
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			errCh <- s.serveTCP(listener)
+		}()
+	}
+
+	s.wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	s.closed.Store(true)
+
+	s.mu.Lock()
+	if s.udpConn != nil {
+		s.udpConn.Close()
+	}
+	if s.tcpListener != nil {
+		s.tcpListener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("syslog server stop: %w", ctx.Err())
+	}
+}
+
+func (s *Server) serveUDP(conn net.PacketConn) error {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if s.closed.Load() {
+				return nil
+			}
+			return fmt.Errorf("conn.ReadFrom: %w", err)
+		}
+
+		s.handle(buf[:n], hostOf(addr))
+	}
+}
+
+func (s *Server) serveTCP(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if s.closed.Load() {
+				return nil
+			}
+			return fmt.Errorf("listener.Accept: %w", err)
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	remote := hostOf(conn.RemoteAddr())
+	reader := bufio.NewReaderSize(conn, maxMessageSize)
+	for {
+		frame, err := readFrame(reader)
+		if len(frame) != 0 {
+			s.handle(frame, remote)
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !s.closed.Load() {
+				s.log.Warn("syslog tcp connection closed", zap.Error(err), zap.String("address", remote))
+			}
+			return
+		}
+	}
+}
+
+// readFrame supports both TCP framings from RFC 6587: octet counting
+// ("12 <34>1 ...") and newline delimited records. The octet count is read
+// digit by digit, so a peer cannot make it grow without bound.
+func readFrame(reader *bufio.Reader) ([]byte, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	if first[0] >= '0' && first[0] <= '9' {
+		prefix := make([]byte, 0, maxFrameLengthDigits)
+		for {
+			b, err := reader.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("reader.ReadByte: %w", err)
+			}
+			if b == ' ' {
+				break
+			}
+			if b < '0' || b > '9' || len(prefix) == maxFrameLengthDigits {
+				return nil, fmt.Errorf("invalid frame length %q", append(prefix, b))
+			}
+			prefix = append(prefix, b)
+		}
+
+		size, err := strconv.Atoi(string(prefix))
+		if err != nil || size <= 0 || size > maxMessageSize {
+			return nil, fmt.Errorf("invalid frame length %q", prefix)
+		}
+
+		frame := make([]byte, size)
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			return nil, fmt.Errorf("io.ReadFull: %w", err)
+		}
+		return frame, nil
+	}
+
+	line, err := reader.ReadSlice('\n')
+	frame := make([]byte, len(line))
+	copy(frame, line)
+	if err != nil {
+		return frame, err //nolint:wrapcheck
+	}
+
+	return frame, nil
+}
+
+func (s *Server) handle(raw []byte, remote string) {
+	msg, err := Parse(raw)
+	if err != nil {
+		s.log.Warn("syslog.Parse", zap.Error(err), zap.String("address", remote))
+		return
+	}
+
+	deviceID, err := s.unknownSources.Resolve(remote)
+	if err != nil {
+		s.log.Debug("s.unknownSources.Resolve", zap.Error(err), zap.String("address", remote))
+		return
+	}
+
+	var eventAt *time.Time
+	if !msg.Timestamp.IsZero() {
+		eventAt = &msg.Timestamp
+	}
+
+	err = s.messages.Enqueue(models.Message{
+		EventAt:       eventAt,
+		DeviceId:      deviceID,
+		Message:       msg.Text,
+		MessageType:   messageType,
+		SeverityLevel: SeverityLevel(msg.Severity),
+		Component:     component(msg.AppName),
+		DeviceIP:      remote,
+	})
+	if err != nil {
+		s.log.Warn("s.messages.Enqueue", zap.Error(err), zap.String("address", remote))
+	}
+}
+
+// SeverityLevel maps a syslog severity (0..7) onto the severity_level
+// values used by tags and reports.
+func SeverityLevel(severity int) string {
+	switch {
+	case severity <= 2: //nolint:mnd
+		return "critical"
+	case severity == 3: //nolint:mnd
+		return "error"
+	case severity == 4: //nolint:mnd
+		return "warning"
+	case severity <= 6: //nolint:mnd
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+func component(appName string) string {
+	if appName == "" {
+		return defaultComponent
+	}
+
+	if utf8.RuneCountInString(appName) > maxComponentLen {
+		return string([]rune(appName)[:maxComponentLen])
+	}
+
+	return appName
+}
+
+func hostOf(addr net.Addr) string {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP.String()
+	case *net.TCPAddr:
+		return a.IP.String()
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -0,0 +1,91 @@
+package syslog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		frames  []string
+		wantErr bool
+	}{
+		{
+			name:   "octet counting",
+			input:  "5 <1>ab3 <2>",
+			frames: []string{"<1>ab", "<2>"},
+		},
+		{
+			name:   "newline delimited",
+			input:  "<1>first\n<2>second\n",
+			frames: []string{"<1>first\n", "<2>second\n"},
+		},
+		{
+			name:   "newline delimited without final newline",
+			input:  "<1>last",
+			frames: []string{"<1>last"},
+		},
+		{
+			name:    "length is not a number",
+			input:   "12a <1>",
+			wantErr: true,
+		},
+		{
+			name:    "length has too many digits",
+			input:   strings.Repeat("1", 1000),
+			wantErr: true,
+		},
+		{
+			name:    "length exceeds the maximum message size",
+			input:   "99999 <1>",
+			wantErr: true,
+		},
+		{
+			name:    "length of zero",
+			input:   "0 <1>",
+			wantErr: true,
+		},
+		{
+			name:    "frame shorter than its length",
+			input:   "10 <1>",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := bufio.NewReader(strings.NewReader(tt.input))
+
+			var frames []string
+			var err error
+			for {
+				var frame []byte
+				frame, err = readFrame(reader)
+				if len(frame) != 0 {
+					frames = append(frames, string(frame))
+				}
+				if err != nil {
+					break
+				}
+			}
+
+			if tt.wantErr {
+				if errors.Is(err, io.EOF) {
+					t.Fatalf("readFrame() error = %v, want a framing error", err)
+				}
+				return
+			}
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("readFrame() error = %v, want io.EOF", err)
+			}
+			if strings.Join(frames, "|") != strings.Join(tt.frames, "|") {
+				t.Errorf("readFrame() frames = %q, want %q", frames, tt.frames)
+			}
+		})
+	}
+}
@@ -0,0 +1,621 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: devices.proto
+
+package devices
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Device struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	DeviceType    string                 `protobuf:"bytes,3,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	Address       string                 `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	Responsible   []int32                `protobuf:"varint,5,rep,packed,name=responsible,proto3" json:"responsible,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	mi := &file_devices_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_devices_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Device) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Device) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Device) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *Device) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Device) GetResponsible() []int32 {
+	if x != nil {
+		return x.Responsible
+	}
+	return nil
+}
+
+func (x *Device) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Device) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type CreateDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DeviceType    string                 `protobuf:"bytes,2,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Responsible   []int32                `protobuf:"varint,4,rep,packed,name=responsible,proto3" json:"responsible,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDeviceRequest) Reset() {
+	*x = CreateDeviceRequest{}
+	mi := &file_devices_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDeviceRequest) ProtoMessage() {}
+
+func (x *CreateDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDeviceRequest.ProtoReflect.Descriptor instead.
+func (*CreateDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_devices_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateDeviceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateDeviceRequest) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *CreateDeviceRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *CreateDeviceRequest) GetResponsible() []int32 {
+	if x != nil {
+		return x.Responsible
+	}
+	return nil
+}
+
+type ReadDevicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadDevicesRequest) Reset() {
+	*x = ReadDevicesRequest{}
+	mi := &file_devices_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadDevicesRequest) ProtoMessage() {}
+
+func (x *ReadDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadDevicesRequest.ProtoReflect.Descriptor instead.
+func (*ReadDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_devices_proto_rawDescGZIP(), []int{2}
+}
+
+type ReadDevicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       []*Device              `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadDevicesResponse) Reset() {
+	*x = ReadDevicesResponse{}
+	mi := &file_devices_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadDevicesResponse) ProtoMessage() {}
+
+func (x *ReadDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ReadDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_devices_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ReadDevicesResponse) GetDevices() []*Device {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+// UpdateDeviceRequest changes only the fields that are set. Responsible is
+// replaced when it is not empty.
+type UpdateDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	DeviceType    *string                `protobuf:"bytes,3,opt,name=device_type,json=deviceType,proto3,oneof" json:"device_type,omitempty"`
+	Address       *string                `protobuf:"bytes,4,opt,name=address,proto3,oneof" json:"address,omitempty"`
+	Responsible   []int32                `protobuf:"varint,5,rep,packed,name=responsible,proto3" json:"responsible,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDeviceRequest) Reset() {
+	*x = UpdateDeviceRequest{}
+	mi := &file_devices_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDeviceRequest) ProtoMessage() {}
+
+func (x *UpdateDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateDeviceRequest.ProtoReflect.Descriptor instead.
+func (*UpdateDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_devices_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateDeviceRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateDeviceRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateDeviceRequest) GetDeviceType() string {
+	if x != nil && x.DeviceType != nil {
+		return *x.DeviceType
+	}
+	return ""
+}
+
+func (x *UpdateDeviceRequest) GetAddress() string {
+	if x != nil && x.Address != nil {
+		return *x.Address
+	}
+	return ""
+}
+
+func (x *UpdateDeviceRequest) GetResponsible() []int32 {
+	if x != nil {
+		return x.Responsible
+	}
+	return nil
+}
+
+type DeleteDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDeviceRequest) Reset() {
+	*x = DeleteDeviceRequest{}
+	mi := &file_devices_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDeviceRequest) ProtoMessage() {}
+
+func (x *DeleteDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDeviceRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_devices_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteDeviceRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CredentialRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      int32                  `protobuf:"varint,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CredentialRequest) Reset() {
+	*x = CredentialRequest{}
+	mi := &file_devices_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CredentialRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CredentialRequest) ProtoMessage() {}
+
+func (x *CredentialRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CredentialRequest.ProtoReflect.Descriptor instead.
+func (*CredentialRequest) Descriptor() ([]byte, []int) {
+	return file_devices_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CredentialRequest) GetDeviceId() int32 {
+	if x != nil {
+		return x.DeviceId
+	}
+	return 0
+}
+
+type Credential struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      int32                  `protobuf:"varint,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Credential) Reset() {
+	*x = Credential{}
+	mi := &file_devices_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Credential) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Credential) ProtoMessage() {}
+
+func (x *Credential) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Credential.ProtoReflect.Descriptor instead.
+func (*Credential) Descriptor() ([]byte, []int) {
+	return file_devices_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Credential) GetDeviceId() int32 {
+	if x != nil {
+		return x.DeviceId
+	}
+	return 0
+}
+
+func (x *Credential) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Credential) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+var File_devices_proto protoreflect.FileDescriptor
+
+const file_devices_proto_rawDesc = "" +
+	"\n" +
+	"\rdevices.proto\x12\adevices\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xff\x01\n" +
+	"\x06Device\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1f\n" +
+	"\vdevice_type\x18\x03 \x01(\tR\n" +
+	"deviceType\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\x12 \n" +
+	"\vresponsible\x18\x05 \x03(\x05R\vresponsible\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x86\x01\n" +
+	"\x13CreateDeviceRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1f\n" +
+	"\vdevice_type\x18\x02 \x01(\tR\n" +
+	"deviceType\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12 \n" +
+	"\vresponsible\x18\x04 \x03(\x05R\vresponsible\"\x14\n" +
+	"\x12ReadDevicesRequest\"@\n" +
+	"\x13ReadDevicesResponse\x12)\n" +
+	"\adevices\x18\x01 \x03(\v2\x0f.devices.DeviceR\adevices\"\xca\x01\n" +
+	"\x13UpdateDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12$\n" +
+	"\vdevice_type\x18\x03 \x01(\tH\x01R\n" +
+	"deviceType\x88\x01\x01\x12\x1d\n" +
+	"\aaddress\x18\x04 \x01(\tH\x02R\aaddress\x88\x01\x01\x12 \n" +
+	"\vresponsible\x18\x05 \x03(\x05R\vresponsibleB\a\n" +
+	"\x05_nameB\x0e\n" +
+	"\f_device_typeB\n" +
+	"\n" +
+	"\b_address\"%\n" +
+	"\x13DeleteDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"0\n" +
+	"\x11CredentialRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\x05R\bdeviceId\"z\n" +
+	"\n" +
+	"Credential\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\x05R\bdeviceId\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt2\xdd\x03\n" +
+	"\x0eDevicesService\x127\n" +
+	"\x06Create\x12\x1c.devices.CreateDeviceRequest\x1a\x0f.devices.Device\x12A\n" +
+	"\x04Read\x12\x1b.devices.ReadDevicesRequest\x1a\x1c.devices.ReadDevicesResponse\x12>\n" +
+	"\x06Update\x12\x1c.devices.UpdateDeviceRequest\x1a\x16.google.protobuf.Empty\x12>\n" +
+	"\x06Delete\x12\x1c.devices.DeleteDeviceRequest\x1a\x16.google.protobuf.Empty\x12B\n" +
+	"\x0fIssueCredential\x12\x1a.devices.CredentialRequest\x1a\x13.devices.Credential\x12C\n" +
+	"\x10RotateCredential\x12\x1a.devices.CredentialRequest\x1a\x13.devices.Credential\x12F\n" +
+	"\x10RevokeCredential\x12\x1a.devices.CredentialRequest\x1a\x16.google.protobuf.EmptyB$Z\"monolith/proto/api-gateway/devicesb\x06proto3"
+
+var (
+	file_devices_proto_rawDescOnce sync.Once
+	file_devices_proto_rawDescData []byte
+)
+
+func file_devices_proto_rawDescGZIP() []byte {
+	file_devices_proto_rawDescOnce.Do(func() {
+		file_devices_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_devices_proto_rawDesc), len(file_devices_proto_rawDesc)))
+	})
+	return file_devices_proto_rawDescData
+}
+
+var file_devices_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_devices_proto_goTypes = []any{
+	(*Device)(nil),                // 0: devices.Device
+	(*CreateDeviceRequest)(nil),   // 1: devices.CreateDeviceRequest
+	(*ReadDevicesRequest)(nil),    // 2: devices.ReadDevicesRequest
+	(*ReadDevicesResponse)(nil),   // 3: devices.ReadDevicesResponse
+	(*UpdateDeviceRequest)(nil),   // 4: devices.UpdateDeviceRequest
+	(*DeleteDeviceRequest)(nil),   // 5: devices.DeleteDeviceRequest
+	(*CredentialRequest)(nil),     // 6: devices.CredentialRequest
+	(*Credential)(nil),            // 7: devices.Credential
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 9: google.protobuf.Empty
+}
+var file_devices_proto_depIdxs = []int32{
+	8,  // 0: devices.Device.created_at:type_name -> google.protobuf.Timestamp
+	8,  // 1: devices.Device.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 2: devices.ReadDevicesResponse.devices:type_name -> devices.Device
+	8,  // 3: devices.Credential.created_at:type_name -> google.protobuf.Timestamp
+	1,  // 4: devices.DevicesService.Create:input_type -> devices.CreateDeviceRequest
+	2,  // 5: devices.DevicesService.Read:input_type -> devices.ReadDevicesRequest
+	4,  // 6: devices.DevicesService.Update:input_type -> devices.UpdateDeviceRequest
+	5,  // 7: devices.DevicesService.Delete:input_type -> devices.DeleteDeviceRequest
+	6,  // 8: devices.DevicesService.IssueCredential:input_type -> devices.CredentialRequest
+	6,  // 9: devices.DevicesService.RotateCredential:input_type -> devices.CredentialRequest
+	6,  // 10: devices.DevicesService.RevokeCredential:input_type -> devices.CredentialRequest
+	0,  // 11: devices.DevicesService.Create:output_type -> devices.Device
+	3,  // 12: devices.DevicesService.Read:output_type -> devices.ReadDevicesResponse
+	9,  // 13: devices.DevicesService.Update:output_type -> google.protobuf.Empty
+	9,  // 14: devices.DevicesService.Delete:output_type -> google.protobuf.Empty
+	7,  // 15: devices.DevicesService.IssueCredential:output_type -> devices.Credential
+	7,  // 16: devices.DevicesService.RotateCredential:output_type -> devices.Credential
+	9,  // 17: devices.DevicesService.RevokeCredential:output_type -> google.protobuf.Empty
+	11, // [11:18] is the sub-list for method output_type
+	4,  // [4:11] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_devices_proto_init() }
+func file_devices_proto_init() {
+	if File_devices_proto != nil {
+		return
+	}
+	file_devices_proto_msgTypes[4].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_devices_proto_rawDesc), len(file_devices_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_devices_proto_goTypes,
+		DependencyIndexes: file_devices_proto_depIdxs,
+		MessageInfos:      file_devices_proto_msgTypes,
+	}.Build()
+	File_devices_proto = out.File
+	file_devices_proto_goTypes = nil
+	file_devices_proto_depIdxs = nil
+}
@@ -0,0 +1,360 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: devices.proto
+
+package devices
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	DevicesService_Create_FullMethodName           = "/devices.DevicesService/Create"
+	DevicesService_Read_FullMethodName             = "/devices.DevicesService/Read"
+	DevicesService_Update_FullMethodName           = "/devices.DevicesService/Update"
+	DevicesService_Delete_FullMethodName           = "/devices.DevicesService/Delete"
+	DevicesService_IssueCredential_FullMethodName  = "/devices.DevicesService/IssueCredential"
+	DevicesService_RotateCredential_FullMethodName = "/devices.DevicesService/RotateCredential"
+	DevicesService_RevokeCredential_FullMethodName = "/devices.DevicesService/RevokeCredential"
+)
+
+// DevicesServiceClient is the client API for DevicesService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DevicesService mirrors /devices of the HTTP API, including the ingest
+// credentials of a device.
+type DevicesServiceClient interface {
+	Create(ctx context.Context, in *CreateDeviceRequest, opts ...grpc.CallOption) (*Device, error)
+	Read(ctx context.Context, in *ReadDevicesRequest, opts ...grpc.CallOption) (*ReadDevicesResponse, error)
+	Update(ctx context.Context, in *UpdateDeviceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Delete(ctx context.Context, in *DeleteDeviceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// IssueCredential and RotateCredential return the plain token. It is the
+	// only time the token is available.
+	IssueCredential(ctx context.Context, in *CredentialRequest, opts ...grpc.CallOption) (*Credential, error)
+	RotateCredential(ctx context.Context, in *CredentialRequest, opts ...grpc.CallOption) (*Credential, error)
+	RevokeCredential(ctx context.Context, in *CredentialRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type devicesServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDevicesServiceClient(cc grpc.ClientConnInterface) DevicesServiceClient {
+	return &devicesServiceClient{cc}
+}
+
+func (c *devicesServiceClient) Create(ctx context.Context, in *CreateDeviceRequest, opts ...grpc.CallOption) (*Device, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Device)
+	err := c.cc.Invoke(ctx, DevicesService_Create_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) Read(ctx context.Context, in *ReadDevicesRequest, opts ...grpc.CallOption) (*ReadDevicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadDevicesResponse)
+	err := c.cc.Invoke(ctx, DevicesService_Read_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) Update(ctx context.Context, in *UpdateDeviceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, DevicesService_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) Delete(ctx context.Context, in *DeleteDeviceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, DevicesService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) IssueCredential(ctx context.Context, in *CredentialRequest, opts ...grpc.CallOption) (*Credential, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Credential)
+	err := c.cc.Invoke(ctx, DevicesService_IssueCredential_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) RotateCredential(ctx context.Context, in *CredentialRequest, opts ...grpc.CallOption) (*Credential, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Credential)
+	err := c.cc.Invoke(ctx, DevicesService_RotateCredential_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicesServiceClient) RevokeCredential(ctx context.Context, in *CredentialRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, DevicesService_RevokeCredential_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DevicesServiceServer is the server API for DevicesService service.
+// All implementations must embed UnimplementedDevicesServiceServer
+// for forward compatibility.
+//
+// DevicesService mirrors /devices of the HTTP API, including the ingest
+// credentials of a device.
+type DevicesServiceServer interface {
+	Create(context.Context, *CreateDeviceRequest) (*Device, error)
+	Read(context.Context, *ReadDevicesRequest) (*ReadDevicesResponse, error)
+	Update(context.Context, *UpdateDeviceRequest) (*emptypb.Empty, error)
+	Delete(context.Context, *DeleteDeviceRequest) (*emptypb.Empty, error)
+	// IssueCredential and RotateCredential return the plain token. It is the
+	// only time the token is available.
+	IssueCredential(context.Context, *CredentialRequest) (*Credential, error)
+	RotateCredential(context.Context, *CredentialRequest) (*Credential, error)
+	RevokeCredential(context.Context, *CredentialRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedDevicesServiceServer()
+}
+
+// UnimplementedDevicesServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDevicesServiceServer struct{}
+
+func (UnimplementedDevicesServiceServer) Create(context.Context, *CreateDeviceRequest) (*Device, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedDevicesServiceServer) Read(context.Context, *ReadDevicesRequest) (*ReadDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Read not implemented")
+}
+func (UnimplementedDevicesServiceServer) Update(context.Context, *UpdateDeviceRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedDevicesServiceServer) Delete(context.Context, *DeleteDeviceRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedDevicesServiceServer) IssueCredential(context.Context, *CredentialRequest) (*Credential, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IssueCredential not implemented")
+}
+func (UnimplementedDevicesServiceServer) RotateCredential(context.Context, *CredentialRequest) (*Credential, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateCredential not implemented")
+}
+func (UnimplementedDevicesServiceServer) RevokeCredential(context.Context, *CredentialRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeCredential not implemented")
+}
+func (UnimplementedDevicesServiceServer) mustEmbedUnimplementedDevicesServiceServer() {}
+func (UnimplementedDevicesServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeDevicesServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DevicesServiceServer will
+// result in compilation errors.
+type UnsafeDevicesServiceServer interface {
+	mustEmbedUnimplementedDevicesServiceServer()
+}
+
+func RegisterDevicesServiceServer(s grpc.ServiceRegistrar, srv DevicesServiceServer) {
+	// If the following call pancis, it indicates UnimplementedDevicesServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DevicesService_ServiceDesc, srv)
+}
+
+func _DevicesService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).Create(ctx, req.(*CreateDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_Read_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).Read(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_Read_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).Read(ctx, req.(*ReadDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).Update(ctx, req.(*UpdateDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).Delete(ctx, req.(*DeleteDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_IssueCredential_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CredentialRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).IssueCredential(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_IssueCredential_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).IssueCredential(ctx, req.(*CredentialRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_RotateCredential_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CredentialRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).RotateCredential(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_RotateCredential_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).RotateCredential(ctx, req.(*CredentialRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicesService_RevokeCredential_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CredentialRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicesServiceServer).RevokeCredential(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DevicesService_RevokeCredential_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicesServiceServer).RevokeCredential(ctx, req.(*CredentialRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DevicesService_ServiceDesc is the grpc.ServiceDesc for DevicesService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DevicesService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "devices.DevicesService",
+	HandlerType: (*DevicesServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _DevicesService_Create_Handler,
+		},
+		{
+			MethodName: "Read",
+			Handler:    _DevicesService_Read_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _DevicesService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _DevicesService_Delete_Handler,
+		},
+		{
+			MethodName: "IssueCredential",
+			Handler:    _DevicesService_IssueCredential_Handler,
+		},
+		{
+			MethodName: "RotateCredential",
+			Handler:    _DevicesService_RotateCredential_Handler,
+		},
+		{
+			MethodName: "RevokeCredential",
+			Handler:    _DevicesService_RevokeCredential_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "devices.proto",
+}
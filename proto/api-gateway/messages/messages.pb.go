@@ -0,0 +1,1407 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: messages.proto
+
+package messages
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SendMessageRequest.timestamp is the time the event happened on the device.
+type SendMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	MessageType   string                 `protobuf:"bytes,2,opt,name=message_type,json=messageType,proto3" json:"message_type,omitempty"`
+	Component     string                 `protobuf:"bytes,3,opt,name=component,proto3" json:"component,omitempty"`
+	Address       string                 `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	MessageId     string                 `protobuf:"bytes,5,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendMessageRequest) Reset() {
+	*x = SendMessageRequest{}
+	mi := &file_messages_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessageRequest) ProtoMessage() {}
+
+func (x *SendMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessageRequest.ProtoReflect.Descriptor instead.
+func (*SendMessageRequest) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SendMessageRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetMessageType() string {
+	if x != nil {
+		return x.MessageType
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// SendMessageResponse.duplicate is set when message_id was accepted before.
+type SendMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Duplicate     bool                   `protobuf:"varint,1,opt,name=duplicate,proto3" json:"duplicate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendMessageResponse) Reset() {
+	*x = SendMessageResponse{}
+	mi := &file_messages_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessageResponse) ProtoMessage() {}
+
+func (x *SendMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessageResponse.ProtoReflect.Descriptor instead.
+func (*SendMessageResponse) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SendMessageResponse) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
+type SendMessagesResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Accepted         int32                  `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Rejected         int32                  `protobuf:"varint,2,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	Duplicates       int32                  `protobuf:"varint,3,opt,name=duplicates,proto3" json:"duplicates,omitempty"`
+	RejectedMessages []*RejectedMessage     `protobuf:"bytes,4,rep,name=rejected_messages,json=rejectedMessages,proto3" json:"rejected_messages,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SendMessagesResponse) Reset() {
+	*x = SendMessagesResponse{}
+	mi := &file_messages_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessagesResponse) ProtoMessage() {}
+
+func (x *SendMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessagesResponse.ProtoReflect.Descriptor instead.
+func (*SendMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SendMessagesResponse) GetAccepted() int32 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+func (x *SendMessagesResponse) GetRejected() int32 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *SendMessagesResponse) GetDuplicates() int32 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
+
+func (x *SendMessagesResponse) GetRejectedMessages() []*RejectedMessage {
+	if x != nil {
+		return x.RejectedMessages
+	}
+	return nil
+}
+
+// RejectedMessage.index is the position of the message in the stream and
+// code is a google.rpc.Code.
+type RejectedMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Code          int32                  `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectedMessage) Reset() {
+	*x = RejectedMessage{}
+	mi := &file_messages_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectedMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectedMessage) ProtoMessage() {}
+
+func (x *RejectedMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectedMessage.ProtoReflect.Descriptor instead.
+func (*RejectedMessage) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RejectedMessage) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *RejectedMessage) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *RejectedMessage) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// WatchMessagesRequest filters the stream. Empty lists match everything.
+type WatchMessagesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	DeviceIds      []int32                `protobuf:"varint,1,rep,packed,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`
+	MessageTypes   []string               `protobuf:"bytes,2,rep,name=message_types,json=messageTypes,proto3" json:"message_types,omitempty"`
+	SeverityLevels []string               `protobuf:"bytes,3,rep,name=severity_levels,json=severityLevels,proto3" json:"severity_levels,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *WatchMessagesRequest) Reset() {
+	*x = WatchMessagesRequest{}
+	mi := &file_messages_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMessagesRequest) ProtoMessage() {}
+
+func (x *WatchMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMessagesRequest.ProtoReflect.Descriptor instead.
+func (*WatchMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WatchMessagesRequest) GetDeviceIds() []int32 {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+func (x *WatchMessagesRequest) GetMessageTypes() []string {
+	if x != nil {
+		return x.MessageTypes
+	}
+	return nil
+}
+
+func (x *WatchMessagesRequest) GetSeverityLevels() []string {
+	if x != nil {
+		return x.SeverityLevels
+	}
+	return nil
+}
+
+type StoredMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      int32                  `protobuf:"varint,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	DeviceIp      string                 `protobuf:"bytes,2,opt,name=device_ip,json=deviceIp,proto3" json:"device_ip,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	MessageType   string                 `protobuf:"bytes,4,opt,name=message_type,json=messageType,proto3" json:"message_type,omitempty"`
+	SeverityLevel string                 `protobuf:"bytes,5,opt,name=severity_level,json=severityLevel,proto3" json:"severity_level,omitempty"`
+	Component     string                 `protobuf:"bytes,6,opt,name=component,proto3" json:"component,omitempty"`
+	GotAt         *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=got_at,json=gotAt,proto3" json:"got_at,omitempty"`
+	EventAt       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=event_at,json=eventAt,proto3" json:"event_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoredMessage) Reset() {
+	*x = StoredMessage{}
+	mi := &file_messages_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoredMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredMessage) ProtoMessage() {}
+
+func (x *StoredMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredMessage.ProtoReflect.Descriptor instead.
+func (*StoredMessage) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StoredMessage) GetDeviceId() int32 {
+	if x != nil {
+		return x.DeviceId
+	}
+	return 0
+}
+
+func (x *StoredMessage) GetDeviceIp() string {
+	if x != nil {
+		return x.DeviceIp
+	}
+	return ""
+}
+
+func (x *StoredMessage) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *StoredMessage) GetMessageType() string {
+	if x != nil {
+		return x.MessageType
+	}
+	return ""
+}
+
+func (x *StoredMessage) GetSeverityLevel() string {
+	if x != nil {
+		return x.SeverityLevel
+	}
+	return ""
+}
+
+func (x *StoredMessage) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *StoredMessage) GetGotAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GotAt
+	}
+	return nil
+}
+
+func (x *StoredMessage) GetEventAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EventAt
+	}
+	return nil
+}
+
+// time_basis is "received" (the default) or "event". The messages are paged
+// as in the HTTP reports: cursor is the next_cursor of the previous page,
+// limit defaults to 100 and with_total also counts every message.
+type GetAllByPeriodRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	TimeBasis     string                 `protobuf:"bytes,3,opt,name=time_basis,json=timeBasis,proto3" json:"time_basis,omitempty"`
+	Cursor        string                 `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	WithTotal     bool                   `protobuf:"varint,6,opt,name=with_total,json=withTotal,proto3" json:"with_total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllByPeriodRequest) Reset() {
+	*x = GetAllByPeriodRequest{}
+	mi := &file_messages_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllByPeriodRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllByPeriodRequest) ProtoMessage() {}
+
+func (x *GetAllByPeriodRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllByPeriodRequest.ProtoReflect.Descriptor instead.
+func (*GetAllByPeriodRequest) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetAllByPeriodRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *GetAllByPeriodRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *GetAllByPeriodRequest) GetTimeBasis() string {
+	if x != nil {
+		return x.TimeBasis
+	}
+	return ""
+}
+
+func (x *GetAllByPeriodRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetAllByPeriodRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetAllByPeriodRequest) GetWithTotal() bool {
+	if x != nil {
+		return x.WithTotal
+	}
+	return false
+}
+
+// GetAllByDeviceIdRequest is paged as GetAllByPeriodRequest.
+type GetAllByDeviceIdRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      int32                  `protobuf:"varint,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	WithTotal     bool                   `protobuf:"varint,4,opt,name=with_total,json=withTotal,proto3" json:"with_total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllByDeviceIdRequest) Reset() {
+	*x = GetAllByDeviceIdRequest{}
+	mi := &file_messages_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllByDeviceIdRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllByDeviceIdRequest) ProtoMessage() {}
+
+func (x *GetAllByDeviceIdRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllByDeviceIdRequest.ProtoReflect.Descriptor instead.
+func (*GetAllByDeviceIdRequest) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetAllByDeviceIdRequest) GetDeviceId() int32 {
+	if x != nil {
+		return x.DeviceId
+	}
+	return 0
+}
+
+func (x *GetAllByDeviceIdRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetAllByDeviceIdRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetAllByDeviceIdRequest) GetWithTotal() bool {
+	if x != nil {
+		return x.WithTotal
+	}
+	return false
+}
+
+type ReportMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      int32                  `protobuf:"varint,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	DeviceType    string                 `protobuf:"bytes,3,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	Address       string                 `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	Responsible   []int32                `protobuf:"varint,5,rep,packed,name=responsible,proto3" json:"responsible,omitempty"`
+	GotAt         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=got_at,json=gotAt,proto3" json:"got_at,omitempty"`
+	EventAt       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=event_at,json=eventAt,proto3" json:"event_at,omitempty"`
+	Message       string                 `protobuf:"bytes,8,opt,name=message,proto3" json:"message,omitempty"`
+	MessageType   string                 `protobuf:"bytes,9,opt,name=message_type,json=messageType,proto3" json:"message_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportMessage) Reset() {
+	*x = ReportMessage{}
+	mi := &file_messages_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportMessage) ProtoMessage() {}
+
+func (x *ReportMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportMessage.ProtoReflect.Descriptor instead.
+func (*ReportMessage) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReportMessage) GetDeviceId() int32 {
+	if x != nil {
+		return x.DeviceId
+	}
+	return 0
+}
+
+func (x *ReportMessage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ReportMessage) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *ReportMessage) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ReportMessage) GetResponsible() []int32 {
+	if x != nil {
+		return x.Responsible
+	}
+	return nil
+}
+
+func (x *ReportMessage) GetGotAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GotAt
+	}
+	return nil
+}
+
+func (x *ReportMessage) GetEventAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EventAt
+	}
+	return nil
+}
+
+func (x *ReportMessage) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ReportMessage) GetMessageType() string {
+	if x != nil {
+		return x.MessageType
+	}
+	return ""
+}
+
+// ReportMessagesResponse.next_cursor is empty on the last page and total is
+// only set when with_total was.
+type ReportMessagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*ReportMessage       `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	Total         *int64                 `protobuf:"varint,3,opt,name=total,proto3,oneof" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportMessagesResponse) Reset() {
+	*x = ReportMessagesResponse{}
+	mi := &file_messages_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportMessagesResponse) ProtoMessage() {}
+
+func (x *ReportMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportMessagesResponse.ProtoReflect.Descriptor instead.
+func (*ReportMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ReportMessagesResponse) GetMessages() []*ReportMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *ReportMessagesResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *ReportMessagesResponse) GetTotal() int64 {
+	if x != nil && x.Total != nil {
+		return *x.Total
+	}
+	return 0
+}
+
+type GetCountByMessageTypeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageType   string                 `protobuf:"bytes,1,opt,name=message_type,json=messageType,proto3" json:"message_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCountByMessageTypeRequest) Reset() {
+	*x = GetCountByMessageTypeRequest{}
+	mi := &file_messages_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCountByMessageTypeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCountByMessageTypeRequest) ProtoMessage() {}
+
+func (x *GetCountByMessageTypeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCountByMessageTypeRequest.ProtoReflect.Descriptor instead.
+func (*GetCountByMessageTypeRequest) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetCountByMessageTypeRequest) GetMessageType() string {
+	if x != nil {
+		return x.MessageType
+	}
+	return ""
+}
+
+type CountByMessageType struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      int32                  `protobuf:"varint,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	DeviceType    string                 `protobuf:"bytes,3,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	Address       string                 `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	Responsible   []int32                `protobuf:"varint,5,rep,packed,name=responsible,proto3" json:"responsible,omitempty"`
+	Count         int32                  `protobuf:"varint,6,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountByMessageType) Reset() {
+	*x = CountByMessageType{}
+	mi := &file_messages_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountByMessageType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountByMessageType) ProtoMessage() {}
+
+func (x *CountByMessageType) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountByMessageType.ProtoReflect.Descriptor instead.
+func (*CountByMessageType) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CountByMessageType) GetDeviceId() int32 {
+	if x != nil {
+		return x.DeviceId
+	}
+	return 0
+}
+
+func (x *CountByMessageType) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CountByMessageType) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *CountByMessageType) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *CountByMessageType) GetResponsible() []int32 {
+	if x != nil {
+		return x.Responsible
+	}
+	return nil
+}
+
+func (x *CountByMessageType) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type GetCountByMessageTypeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Counts        []*CountByMessageType  `protobuf:"bytes,1,rep,name=counts,proto3" json:"counts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCountByMessageTypeResponse) Reset() {
+	*x = GetCountByMessageTypeResponse{}
+	mi := &file_messages_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCountByMessageTypeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCountByMessageTypeResponse) ProtoMessage() {}
+
+func (x *GetCountByMessageTypeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCountByMessageTypeResponse.ProtoReflect.Descriptor instead.
+func (*GetCountByMessageTypeResponse) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetCountByMessageTypeResponse) GetCounts() []*CountByMessageType {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+type MonthReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TimeBasis     string                 `protobuf:"bytes,1,opt,name=time_basis,json=timeBasis,proto3" json:"time_basis,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MonthReportRequest) Reset() {
+	*x = MonthReportRequest{}
+	mi := &file_messages_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MonthReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MonthReportRequest) ProtoMessage() {}
+
+func (x *MonthReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MonthReportRequest.ProtoReflect.Descriptor instead.
+func (*MonthReportRequest) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MonthReportRequest) GetTimeBasis() string {
+	if x != nil {
+		return x.TimeBasis
+	}
+	return ""
+}
+
+type MonthReportRow struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId               int32                  `protobuf:"varint,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	MessageType            string                 `protobuf:"bytes,2,opt,name=message_type,json=messageType,proto3" json:"message_type,omitempty"`
+	ActiveDays             int32                  `protobuf:"varint,3,opt,name=active_days,json=activeDays,proto3" json:"active_days,omitempty"`
+	TotalMessages          int64                  `protobuf:"varint,4,opt,name=total_messages,json=totalMessages,proto3" json:"total_messages,omitempty"`
+	AvgDailyMessages       float64                `protobuf:"fixed64,5,opt,name=avg_daily_messages,json=avgDailyMessages,proto3" json:"avg_daily_messages,omitempty"`
+	MaxDailyMessages       int64                  `protobuf:"varint,6,opt,name=max_daily_messages,json=maxDailyMessages,proto3" json:"max_daily_messages,omitempty"`
+	MedianDailyMessages    float64                `protobuf:"fixed64,7,opt,name=median_daily_messages,json=medianDailyMessages,proto3" json:"median_daily_messages,omitempty"`
+	TotalCritical          int64                  `protobuf:"varint,8,opt,name=total_critical,json=totalCritical,proto3" json:"total_critical,omitempty"`
+	MaxDailyCritical       int64                  `protobuf:"varint,9,opt,name=max_daily_critical,json=maxDailyCritical,proto3" json:"max_daily_critical,omitempty"`
+	MaxDailyComponents     int32                  `protobuf:"varint,10,opt,name=max_daily_components,json=maxDailyComponents,proto3" json:"max_daily_components,omitempty"`
+	MostActiveComponent    *string                `protobuf:"bytes,11,opt,name=most_active_component,json=mostActiveComponent,proto3,oneof" json:"most_active_component,omitempty"`
+	FirstCriticalTime      *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=first_critical_time,json=firstCriticalTime,proto3" json:"first_critical_time,omitempty"`
+	LastCriticalTime       *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=last_critical_time,json=lastCriticalTime,proto3" json:"last_critical_time,omitempty"`
+	AvgCriticalIntervalSec *float64               `protobuf:"fixed64,14,opt,name=avg_critical_interval_sec,json=avgCriticalIntervalSec,proto3,oneof" json:"avg_critical_interval_sec,omitempty"`
+	CriticalPercentage     float64                `protobuf:"fixed64,15,opt,name=critical_percentage,json=criticalPercentage,proto3" json:"critical_percentage,omitempty"`
+	OverallVolumeRank      int32                  `protobuf:"varint,16,opt,name=overall_volume_rank,json=overallVolumeRank,proto3" json:"overall_volume_rank,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *MonthReportRow) Reset() {
+	*x = MonthReportRow{}
+	mi := &file_messages_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MonthReportRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MonthReportRow) ProtoMessage() {}
+
+func (x *MonthReportRow) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MonthReportRow.ProtoReflect.Descriptor instead.
+func (*MonthReportRow) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *MonthReportRow) GetDeviceId() int32 {
+	if x != nil {
+		return x.DeviceId
+	}
+	return 0
+}
+
+func (x *MonthReportRow) GetMessageType() string {
+	if x != nil {
+		return x.MessageType
+	}
+	return ""
+}
+
+func (x *MonthReportRow) GetActiveDays() int32 {
+	if x != nil {
+		return x.ActiveDays
+	}
+	return 0
+}
+
+func (x *MonthReportRow) GetTotalMessages() int64 {
+	if x != nil {
+		return x.TotalMessages
+	}
+	return 0
+}
+
+func (x *MonthReportRow) GetAvgDailyMessages() float64 {
+	if x != nil {
+		return x.AvgDailyMessages
+	}
+	return 0
+}
+
+func (x *MonthReportRow) GetMaxDailyMessages() int64 {
+	if x != nil {
+		return x.MaxDailyMessages
+	}
+	return 0
+}
+
+func (x *MonthReportRow) GetMedianDailyMessages() float64 {
+	if x != nil {
+		return x.MedianDailyMessages
+	}
+	return 0
+}
+
+func (x *MonthReportRow) GetTotalCritical() int64 {
+	if x != nil {
+		return x.TotalCritical
+	}
+	return 0
+}
+
+func (x *MonthReportRow) GetMaxDailyCritical() int64 {
+	if x != nil {
+		return x.MaxDailyCritical
+	}
+	return 0
+}
+
+func (x *MonthReportRow) GetMaxDailyComponents() int32 {
+	if x != nil {
+		return x.MaxDailyComponents
+	}
+	return 0
+}
+
+func (x *MonthReportRow) GetMostActiveComponent() string {
+	if x != nil && x.MostActiveComponent != nil {
+		return *x.MostActiveComponent
+	}
+	return ""
+}
+
+func (x *MonthReportRow) GetFirstCriticalTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstCriticalTime
+	}
+	return nil
+}
+
+func (x *MonthReportRow) GetLastCriticalTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastCriticalTime
+	}
+	return nil
+}
+
+func (x *MonthReportRow) GetAvgCriticalIntervalSec() float64 {
+	if x != nil && x.AvgCriticalIntervalSec != nil {
+		return *x.AvgCriticalIntervalSec
+	}
+	return 0
+}
+
+func (x *MonthReportRow) GetCriticalPercentage() float64 {
+	if x != nil {
+		return x.CriticalPercentage
+	}
+	return 0
+}
+
+func (x *MonthReportRow) GetOverallVolumeRank() int32 {
+	if x != nil {
+		return x.OverallVolumeRank
+	}
+	return 0
+}
+
+type MonthReportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rows          []*MonthReportRow      `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MonthReportResponse) Reset() {
+	*x = MonthReportResponse{}
+	mi := &file_messages_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MonthReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MonthReportResponse) ProtoMessage() {}
+
+func (x *MonthReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MonthReportResponse.ProtoReflect.Descriptor instead.
+func (*MonthReportResponse) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *MonthReportResponse) GetRows() []*MonthReportRow {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+var File_messages_proto protoreflect.FileDescriptor
+
+const file_messages_proto_rawDesc = "" +
+	"\n" +
+	"\x0emessages.proto\x12\bmessages\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe2\x01\n" +
+	"\x12SendMessageRequest\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12!\n" +
+	"\fmessage_type\x18\x02 \x01(\tR\vmessageType\x12\x1c\n" +
+	"\tcomponent\x18\x03 \x01(\tR\tcomponent\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x05 \x01(\tR\tmessageId\x128\n" +
+	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"3\n" +
+	"\x13SendMessageResponse\x12\x1c\n" +
+	"\tduplicate\x18\x01 \x01(\bR\tduplicate\"\xb6\x01\n" +
+	"\x14SendMessagesResponse\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\x05R\baccepted\x12\x1a\n" +
+	"\brejected\x18\x02 \x01(\x05R\brejected\x12\x1e\n" +
+	"\n" +
+	"duplicates\x18\x03 \x01(\x05R\n" +
+	"duplicates\x12F\n" +
+	"\x11rejected_messages\x18\x04 \x03(\v2\x19.messages.RejectedMessageR\x10rejectedMessages\"Q\n" +
+	"\x0fRejectedMessage\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\x05R\x04code\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\x83\x01\n" +
+	"\x14WatchMessagesRequest\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x01 \x03(\x05R\tdeviceIds\x12#\n" +
+	"\rmessage_types\x18\x02 \x03(\tR\fmessageTypes\x12'\n" +
+	"\x0fseverity_levels\x18\x03 \x03(\tR\x0eseverityLevels\"\xb5\x02\n" +
+	"\rStoredMessage\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\x05R\bdeviceId\x12\x1b\n" +
+	"\tdevice_ip\x18\x02 \x01(\tR\bdeviceIp\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12!\n" +
+	"\fmessage_type\x18\x04 \x01(\tR\vmessageType\x12%\n" +
+	"\x0eseverity_level\x18\x05 \x01(\tR\rseverityLevel\x12\x1c\n" +
+	"\tcomponent\x18\x06 \x01(\tR\tcomponent\x121\n" +
+	"\x06got_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x05gotAt\x125\n" +
+	"\bevent_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\aeventAt\"\xf5\x01\n" +
+	"\x15GetAllByPeriodRequest\x129\n" +
+	"\n" +
+	"start_time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x1d\n" +
+	"\n" +
+	"time_basis\x18\x03 \x01(\tR\ttimeBasis\x12\x16\n" +
+	"\x06cursor\x18\x04 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x1d\n" +
+	"\n" +
+	"with_total\x18\x06 \x01(\bR\twithTotal\"\x83\x01\n" +
+	"\x17GetAllByDeviceIdRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\x05R\bdeviceId\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x1d\n" +
+	"\n" +
+	"with_total\x18\x04 \x01(\bR\twithTotal\"\xc4\x02\n" +
+	"\rReportMessage\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\x05R\bdeviceId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1f\n" +
+	"\vdevice_type\x18\x03 \x01(\tR\n" +
+	"deviceType\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\x12 \n" +
+	"\vresponsible\x18\x05 \x03(\x05R\vresponsible\x121\n" +
+	"\x06got_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x05gotAt\x125\n" +
+	"\bevent_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\aeventAt\x12\x18\n" +
+	"\amessage\x18\b \x01(\tR\amessage\x12!\n" +
+	"\fmessage_type\x18\t \x01(\tR\vmessageType\"\x93\x01\n" +
+	"\x16ReportMessagesResponse\x123\n" +
+	"\bmessages\x18\x01 \x03(\v2\x17.messages.ReportMessageR\bmessages\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\x12\x19\n" +
+	"\x05total\x18\x03 \x01(\x03H\x00R\x05total\x88\x01\x01B\b\n" +
+	"\x06_total\"A\n" +
+	"\x1cGetCountByMessageTypeRequest\x12!\n" +
+	"\fmessage_type\x18\x01 \x01(\tR\vmessageType\"\xb8\x01\n" +
+	"\x12CountByMessageType\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\x05R\bdeviceId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1f\n" +
+	"\vdevice_type\x18\x03 \x01(\tR\n" +
+	"deviceType\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\x12 \n" +
+	"\vresponsible\x18\x05 \x03(\x05R\vresponsible\x12\x14\n" +
+	"\x05count\x18\x06 \x01(\x05R\x05count\"U\n" +
+	"\x1dGetCountByMessageTypeResponse\x124\n" +
+	"\x06counts\x18\x01 \x03(\v2\x1c.messages.CountByMessageTypeR\x06counts\"3\n" +
+	"\x12MonthReportRequest\x12\x1d\n" +
+	"\n" +
+	"time_basis\x18\x01 \x01(\tR\ttimeBasis\"\xd7\x06\n" +
+	"\x0eMonthReportRow\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\x05R\bdeviceId\x12!\n" +
+	"\fmessage_type\x18\x02 \x01(\tR\vmessageType\x12\x1f\n" +
+	"\vactive_days\x18\x03 \x01(\x05R\n" +
+	"activeDays\x12%\n" +
+	"\x0etotal_messages\x18\x04 \x01(\x03R\rtotalMessages\x12,\n" +
+	"\x12avg_daily_messages\x18\x05 \x01(\x01R\x10avgDailyMessages\x12,\n" +
+	"\x12max_daily_messages\x18\x06 \x01(\x03R\x10maxDailyMessages\x122\n" +
+	"\x15median_daily_messages\x18\a \x01(\x01R\x13medianDailyMessages\x12%\n" +
+	"\x0etotal_critical\x18\b \x01(\x03R\rtotalCritical\x12,\n" +
+	"\x12max_daily_critical\x18\t \x01(\x03R\x10maxDailyCritical\x120\n" +
+	"\x14max_daily_components\x18\n" +
+	" \x01(\x05R\x12maxDailyComponents\x127\n" +
+	"\x15most_active_component\x18\v \x01(\tH\x00R\x13mostActiveComponent\x88\x01\x01\x12J\n" +
+	"\x13first_critical_time\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\x11firstCriticalTime\x12H\n" +
+	"\x12last_critical_time\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\x10lastCriticalTime\x12>\n" +
+	"\x19avg_critical_interval_sec\x18\x0e \x01(\x01H\x01R\x16avgCriticalIntervalSec\x88\x01\x01\x12/\n" +
+	"\x13critical_percentage\x18\x0f \x01(\x01R\x12criticalPercentage\x12.\n" +
+	"\x13overall_volume_rank\x18\x10 \x01(\x05R\x11overallVolumeRankB\x18\n" +
+	"\x16_most_active_componentB\x1c\n" +
+	"\x1a_avg_critical_interval_sec\"C\n" +
+	"\x13MonthReportResponse\x12,\n" +
+	"\x04rows\x18\x01 \x03(\v2\x18.messages.MonthReportRowR\x04rows2\xdd\x04\n" +
+	"\x0fMessagesService\x12J\n" +
+	"\vSendMessage\x12\x1c.messages.SendMessageRequest\x1a\x1d.messages.SendMessageResponse\x12N\n" +
+	"\fSendMessages\x12\x1c.messages.SendMessageRequest\x1a\x1e.messages.SendMessagesResponse(\x01\x12J\n" +
+	"\rWatchMessages\x12\x1e.messages.WatchMessagesRequest\x1a\x17.messages.StoredMessage0\x01\x12S\n" +
+	"\x0eGetAllByPeriod\x12\x1f.messages.GetAllByPeriodRequest\x1a .messages.ReportMessagesResponse\x12W\n" +
+	"\x10GetAllByDeviceId\x12!.messages.GetAllByDeviceIdRequest\x1a .messages.ReportMessagesResponse\x12h\n" +
+	"\x15GetCountByMessageType\x12&.messages.GetCountByMessageTypeRequest\x1a'.messages.GetCountByMessageTypeResponse\x12J\n" +
+	"\vMonthReport\x12\x1c.messages.MonthReportRequest\x1a\x1d.messages.MonthReportResponseB%Z#monolith/proto/api-gateway/messagesb\x06proto3"
+
+var (
+	file_messages_proto_rawDescOnce sync.Once
+	file_messages_proto_rawDescData []byte
+)
+
+func file_messages_proto_rawDescGZIP() []byte {
+	file_messages_proto_rawDescOnce.Do(func() {
+		file_messages_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_messages_proto_rawDesc), len(file_messages_proto_rawDesc)))
+	})
+	return file_messages_proto_rawDescData
+}
+
+var file_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_messages_proto_goTypes = []any{
+	(*SendMessageRequest)(nil),            // 0: messages.SendMessageRequest
+	(*SendMessageResponse)(nil),           // 1: messages.SendMessageResponse
+	(*SendMessagesResponse)(nil),          // 2: messages.SendMessagesResponse
+	(*RejectedMessage)(nil),               // 3: messages.RejectedMessage
+	(*WatchMessagesRequest)(nil),          // 4: messages.WatchMessagesRequest
+	(*StoredMessage)(nil),                 // 5: messages.StoredMessage
+	(*GetAllByPeriodRequest)(nil),         // 6: messages.GetAllByPeriodRequest
+	(*GetAllByDeviceIdRequest)(nil),       // 7: messages.GetAllByDeviceIdRequest
+	(*ReportMessage)(nil),                 // 8: messages.ReportMessage
+	(*ReportMessagesResponse)(nil),        // 9: messages.ReportMessagesResponse
+	(*GetCountByMessageTypeRequest)(nil),  // 10: messages.GetCountByMessageTypeRequest
+	(*CountByMessageType)(nil),            // 11: messages.CountByMessageType
+	(*GetCountByMessageTypeResponse)(nil), // 12: messages.GetCountByMessageTypeResponse
+	(*MonthReportRequest)(nil),            // 13: messages.MonthReportRequest
+	(*MonthReportRow)(nil),                // 14: messages.MonthReportRow
+	(*MonthReportResponse)(nil),           // 15: messages.MonthReportResponse
+	(*timestamppb.Timestamp)(nil),         // 16: google.protobuf.Timestamp
+}
+var file_messages_proto_depIdxs = []int32{
+	16, // 0: messages.SendMessageRequest.timestamp:type_name -> google.protobuf.Timestamp
+	3,  // 1: messages.SendMessagesResponse.rejected_messages:type_name -> messages.RejectedMessage
+	16, // 2: messages.StoredMessage.got_at:type_name -> google.protobuf.Timestamp
+	16, // 3: messages.StoredMessage.event_at:type_name -> google.protobuf.Timestamp
+	16, // 4: messages.GetAllByPeriodRequest.start_time:type_name -> google.protobuf.Timestamp
+	16, // 5: messages.GetAllByPeriodRequest.end_time:type_name -> google.protobuf.Timestamp
+	16, // 6: messages.ReportMessage.got_at:type_name -> google.protobuf.Timestamp
+	16, // 7: messages.ReportMessage.event_at:type_name -> google.protobuf.Timestamp
+	8,  // 8: messages.ReportMessagesResponse.messages:type_name -> messages.ReportMessage
+	11, // 9: messages.GetCountByMessageTypeResponse.counts:type_name -> messages.CountByMessageType
+	16, // 10: messages.MonthReportRow.first_critical_time:type_name -> google.protobuf.Timestamp
+	16, // 11: messages.MonthReportRow.last_critical_time:type_name -> google.protobuf.Timestamp
+	14, // 12: messages.MonthReportResponse.rows:type_name -> messages.MonthReportRow
+	0,  // 13: messages.MessagesService.SendMessage:input_type -> messages.SendMessageRequest
+	0,  // 14: messages.MessagesService.SendMessages:input_type -> messages.SendMessageRequest
+	4,  // 15: messages.MessagesService.WatchMessages:input_type -> messages.WatchMessagesRequest
+	6,  // 16: messages.MessagesService.GetAllByPeriod:input_type -> messages.GetAllByPeriodRequest
+	7,  // 17: messages.MessagesService.GetAllByDeviceId:input_type -> messages.GetAllByDeviceIdRequest
+	10, // 18: messages.MessagesService.GetCountByMessageType:input_type -> messages.GetCountByMessageTypeRequest
+	13, // 19: messages.MessagesService.MonthReport:input_type -> messages.MonthReportRequest
+	1,  // 20: messages.MessagesService.SendMessage:output_type -> messages.SendMessageResponse
+	2,  // 21: messages.MessagesService.SendMessages:output_type -> messages.SendMessagesResponse
+	5,  // 22: messages.MessagesService.WatchMessages:output_type -> messages.StoredMessage
+	9,  // 23: messages.MessagesService.GetAllByPeriod:output_type -> messages.ReportMessagesResponse
+	9,  // 24: messages.MessagesService.GetAllByDeviceId:output_type -> messages.ReportMessagesResponse
+	12, // 25: messages.MessagesService.GetCountByMessageType:output_type -> messages.GetCountByMessageTypeResponse
+	15, // 26: messages.MessagesService.MonthReport:output_type -> messages.MonthReportResponse
+	20, // [20:27] is the sub-list for method output_type
+	13, // [13:20] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_messages_proto_init() }
+func file_messages_proto_init() {
+	if File_messages_proto != nil {
+		return
+	}
+	file_messages_proto_msgTypes[9].OneofWrappers = []any{}
+	file_messages_proto_msgTypes[14].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_messages_proto_rawDesc), len(file_messages_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_messages_proto_goTypes,
+		DependencyIndexes: file_messages_proto_depIdxs,
+		MessageInfos:      file_messages_proto_msgTypes,
+	}.Build()
+	File_messages_proto = out.File
+	file_messages_proto_goTypes = nil
+	file_messages_proto_depIdxs = nil
+}
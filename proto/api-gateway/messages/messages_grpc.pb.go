@@ -0,0 +1,360 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: messages.proto
+
+package messages
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MessagesService_SendMessage_FullMethodName           = "/messages.MessagesService/SendMessage"
+	MessagesService_SendMessages_FullMethodName          = "/messages.MessagesService/SendMessages"
+	MessagesService_WatchMessages_FullMethodName         = "/messages.MessagesService/WatchMessages"
+	MessagesService_GetAllByPeriod_FullMethodName        = "/messages.MessagesService/GetAllByPeriod"
+	MessagesService_GetAllByDeviceId_FullMethodName      = "/messages.MessagesService/GetAllByDeviceId"
+	MessagesService_GetCountByMessageType_FullMethodName = "/messages.MessagesService/GetCountByMessageType"
+	MessagesService_MonthReport_FullMethodName           = "/messages.MessagesService/MonthReport"
+)
+
+// MessagesServiceClient is the client API for MessagesService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MessagesService mirrors /messages and /reports of the HTTP API. Messages
+// are handed over to the same ingest queue as the HTTP and syslog receivers.
+type MessagesServiceClient interface {
+	SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*SendMessageResponse, error)
+	// SendMessages is meant for collectors that forward many messages over one
+	// stream. The response lists only the messages that were not accepted.
+	SendMessages(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[SendMessageRequest, SendMessagesResponse], error)
+	// WatchMessages streams messages as they are stored. A slow client misses
+	// messages rather than holding up ingestion.
+	WatchMessages(ctx context.Context, in *WatchMessagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StoredMessage], error)
+	GetAllByPeriod(ctx context.Context, in *GetAllByPeriodRequest, opts ...grpc.CallOption) (*ReportMessagesResponse, error)
+	GetAllByDeviceId(ctx context.Context, in *GetAllByDeviceIdRequest, opts ...grpc.CallOption) (*ReportMessagesResponse, error)
+	GetCountByMessageType(ctx context.Context, in *GetCountByMessageTypeRequest, opts ...grpc.CallOption) (*GetCountByMessageTypeResponse, error)
+	MonthReport(ctx context.Context, in *MonthReportRequest, opts ...grpc.CallOption) (*MonthReportResponse, error)
+}
+
+type messagesServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMessagesServiceClient(cc grpc.ClientConnInterface) MessagesServiceClient {
+	return &messagesServiceClient{cc}
+}
+
+func (c *messagesServiceClient) SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*SendMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendMessageResponse)
+	err := c.cc.Invoke(ctx, MessagesService_SendMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagesServiceClient) SendMessages(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[SendMessageRequest, SendMessagesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MessagesService_ServiceDesc.Streams[0], MessagesService_SendMessages_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SendMessageRequest, SendMessagesResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MessagesService_SendMessagesClient = grpc.ClientStreamingClient[SendMessageRequest, SendMessagesResponse]
+
+func (c *messagesServiceClient) WatchMessages(ctx context.Context, in *WatchMessagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StoredMessage], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MessagesService_ServiceDesc.Streams[1], MessagesService_WatchMessages_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchMessagesRequest, StoredMessage]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MessagesService_WatchMessagesClient = grpc.ServerStreamingClient[StoredMessage]
+
+func (c *messagesServiceClient) GetAllByPeriod(ctx context.Context, in *GetAllByPeriodRequest, opts ...grpc.CallOption) (*ReportMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportMessagesResponse)
+	err := c.cc.Invoke(ctx, MessagesService_GetAllByPeriod_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagesServiceClient) GetAllByDeviceId(ctx context.Context, in *GetAllByDeviceIdRequest, opts ...grpc.CallOption) (*ReportMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportMessagesResponse)
+	err := c.cc.Invoke(ctx, MessagesService_GetAllByDeviceId_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagesServiceClient) GetCountByMessageType(ctx context.Context, in *GetCountByMessageTypeRequest, opts ...grpc.CallOption) (*GetCountByMessageTypeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCountByMessageTypeResponse)
+	err := c.cc.Invoke(ctx, MessagesService_GetCountByMessageType_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagesServiceClient) MonthReport(ctx context.Context, in *MonthReportRequest, opts ...grpc.CallOption) (*MonthReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MonthReportResponse)
+	err := c.cc.Invoke(ctx, MessagesService_MonthReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MessagesServiceServer is the server API for MessagesService service.
+// All implementations must embed UnimplementedMessagesServiceServer
+// for forward compatibility.
+//
+// MessagesService mirrors /messages and /reports of the HTTP API. Messages
+// are handed over to the same ingest queue as the HTTP and syslog receivers.
+type MessagesServiceServer interface {
+	SendMessage(context.Context, *SendMessageRequest) (*SendMessageResponse, error)
+	// SendMessages is meant for collectors that forward many messages over one
+	// stream. The response lists only the messages that were not accepted.
+	SendMessages(grpc.ClientStreamingServer[SendMessageRequest, SendMessagesResponse]) error
+	// WatchMessages streams messages as they are stored. A slow client misses
+	// messages rather than holding up ingestion.
+	WatchMessages(*WatchMessagesRequest, grpc.ServerStreamingServer[StoredMessage]) error
+	GetAllByPeriod(context.Context, *GetAllByPeriodRequest) (*ReportMessagesResponse, error)
+	GetAllByDeviceId(context.Context, *GetAllByDeviceIdRequest) (*ReportMessagesResponse, error)
+	GetCountByMessageType(context.Context, *GetCountByMessageTypeRequest) (*GetCountByMessageTypeResponse, error)
+	MonthReport(context.Context, *MonthReportRequest) (*MonthReportResponse, error)
+	mustEmbedUnimplementedMessagesServiceServer()
+}
+
+// UnimplementedMessagesServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMessagesServiceServer struct{}
+
+func (UnimplementedMessagesServiceServer) SendMessage(context.Context, *SendMessageRequest) (*SendMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendMessage not implemented")
+}
+func (UnimplementedMessagesServiceServer) SendMessages(grpc.ClientStreamingServer[SendMessageRequest, SendMessagesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method SendMessages not implemented")
+}
+func (UnimplementedMessagesServiceServer) WatchMessages(*WatchMessagesRequest, grpc.ServerStreamingServer[StoredMessage]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMessages not implemented")
+}
+func (UnimplementedMessagesServiceServer) GetAllByPeriod(context.Context, *GetAllByPeriodRequest) (*ReportMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllByPeriod not implemented")
+}
+func (UnimplementedMessagesServiceServer) GetAllByDeviceId(context.Context, *GetAllByDeviceIdRequest) (*ReportMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllByDeviceId not implemented")
+}
+func (UnimplementedMessagesServiceServer) GetCountByMessageType(context.Context, *GetCountByMessageTypeRequest) (*GetCountByMessageTypeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCountByMessageType not implemented")
+}
+func (UnimplementedMessagesServiceServer) MonthReport(context.Context, *MonthReportRequest) (*MonthReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MonthReport not implemented")
+}
+func (UnimplementedMessagesServiceServer) mustEmbedUnimplementedMessagesServiceServer() {}
+func (UnimplementedMessagesServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeMessagesServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MessagesServiceServer will
+// result in compilation errors.
+type UnsafeMessagesServiceServer interface {
+	mustEmbedUnimplementedMessagesServiceServer()
+}
+
+func RegisterMessagesServiceServer(s grpc.ServiceRegistrar, srv MessagesServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMessagesServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MessagesService_ServiceDesc, srv)
+}
+
+func _MessagesService_SendMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagesServiceServer).SendMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessagesService_SendMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagesServiceServer).SendMessage(ctx, req.(*SendMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessagesService_SendMessages_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MessagesServiceServer).SendMessages(&grpc.GenericServerStream[SendMessageRequest, SendMessagesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MessagesService_SendMessagesServer = grpc.ClientStreamingServer[SendMessageRequest, SendMessagesResponse]
+
+func _MessagesService_WatchMessages_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMessagesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MessagesServiceServer).WatchMessages(m, &grpc.GenericServerStream[WatchMessagesRequest, StoredMessage]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MessagesService_WatchMessagesServer = grpc.ServerStreamingServer[StoredMessage]
+
+func _MessagesService_GetAllByPeriod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAllByPeriodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagesServiceServer).GetAllByPeriod(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessagesService_GetAllByPeriod_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagesServiceServer).GetAllByPeriod(ctx, req.(*GetAllByPeriodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessagesService_GetAllByDeviceId_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAllByDeviceIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagesServiceServer).GetAllByDeviceId(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessagesService_GetAllByDeviceId_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagesServiceServer).GetAllByDeviceId(ctx, req.(*GetAllByDeviceIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessagesService_GetCountByMessageType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCountByMessageTypeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagesServiceServer).GetCountByMessageType(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessagesService_GetCountByMessageType_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagesServiceServer).GetCountByMessageType(ctx, req.(*GetCountByMessageTypeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessagesService_MonthReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MonthReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagesServiceServer).MonthReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessagesService_MonthReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagesServiceServer).MonthReport(ctx, req.(*MonthReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MessagesService_ServiceDesc is the grpc.ServiceDesc for MessagesService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MessagesService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "messages.MessagesService",
+	HandlerType: (*MessagesServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SendMessage",
+			Handler:    _MessagesService_SendMessage_Handler,
+		},
+		{
+			MethodName: "GetAllByPeriod",
+			Handler:    _MessagesService_GetAllByPeriod_Handler,
+		},
+		{
+			MethodName: "GetAllByDeviceId",
+			Handler:    _MessagesService_GetAllByDeviceId_Handler,
+		},
+		{
+			MethodName: "GetCountByMessageType",
+			Handler:    _MessagesService_GetCountByMessageType_Handler,
+		},
+		{
+			MethodName: "MonthReport",
+			Handler:    _MessagesService_MonthReport_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SendMessages",
+			Handler:       _MessagesService_SendMessages_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchMessages",
+			Handler:       _MessagesService_WatchMessages_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "messages.proto",
+}
@@ -0,0 +1,611 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: tags.proto
+
+package tags
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Tag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	DeviceId      int32                  `protobuf:"varint,3,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Regexp        string                 `protobuf:"bytes,4,opt,name=regexp,proto3" json:"regexp,omitempty"`
+	CompareType   string                 `protobuf:"bytes,5,opt,name=compare_type,json=compareType,proto3" json:"compare_type,omitempty"`
+	Value         string                 `protobuf:"bytes,6,opt,name=value,proto3" json:"value,omitempty"`
+	ArrayIndex    int32                  `protobuf:"varint,7,opt,name=array_index,json=arrayIndex,proto3" json:"array_index,omitempty"`
+	Subject       string                 `protobuf:"bytes,8,opt,name=subject,proto3" json:"subject,omitempty"`
+	SeverityLevel string                 `protobuf:"bytes,9,opt,name=severity_level,json=severityLevel,proto3" json:"severity_level,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tag) Reset() {
+	*x = Tag{}
+	mi := &file_tags_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tag) ProtoMessage() {}
+
+func (x *Tag) ProtoReflect() protoreflect.Message {
+	mi := &file_tags_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tag.ProtoReflect.Descriptor instead.
+func (*Tag) Descriptor() ([]byte, []int) {
+	return file_tags_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Tag) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Tag) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Tag) GetDeviceId() int32 {
+	if x != nil {
+		return x.DeviceId
+	}
+	return 0
+}
+
+func (x *Tag) GetRegexp() string {
+	if x != nil {
+		return x.Regexp
+	}
+	return ""
+}
+
+func (x *Tag) GetCompareType() string {
+	if x != nil {
+		return x.CompareType
+	}
+	return ""
+}
+
+func (x *Tag) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Tag) GetArrayIndex() int32 {
+	if x != nil {
+		return x.ArrayIndex
+	}
+	return 0
+}
+
+func (x *Tag) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *Tag) GetSeverityLevel() string {
+	if x != nil {
+		return x.SeverityLevel
+	}
+	return ""
+}
+
+func (x *Tag) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Tag) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type CreateTagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DeviceId      int32                  `protobuf:"varint,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Regexp        string                 `protobuf:"bytes,3,opt,name=regexp,proto3" json:"regexp,omitempty"`
+	CompareType   string                 `protobuf:"bytes,4,opt,name=compare_type,json=compareType,proto3" json:"compare_type,omitempty"`
+	Value         string                 `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
+	ArrayIndex    int32                  `protobuf:"varint,6,opt,name=array_index,json=arrayIndex,proto3" json:"array_index,omitempty"`
+	Subject       string                 `protobuf:"bytes,7,opt,name=subject,proto3" json:"subject,omitempty"`
+	SeverityLevel string                 `protobuf:"bytes,8,opt,name=severity_level,json=severityLevel,proto3" json:"severity_level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTagRequest) Reset() {
+	*x = CreateTagRequest{}
+	mi := &file_tags_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTagRequest) ProtoMessage() {}
+
+func (x *CreateTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tags_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTagRequest.ProtoReflect.Descriptor instead.
+func (*CreateTagRequest) Descriptor() ([]byte, []int) {
+	return file_tags_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateTagRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateTagRequest) GetDeviceId() int32 {
+	if x != nil {
+		return x.DeviceId
+	}
+	return 0
+}
+
+func (x *CreateTagRequest) GetRegexp() string {
+	if x != nil {
+		return x.Regexp
+	}
+	return ""
+}
+
+func (x *CreateTagRequest) GetCompareType() string {
+	if x != nil {
+		return x.CompareType
+	}
+	return ""
+}
+
+func (x *CreateTagRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *CreateTagRequest) GetArrayIndex() int32 {
+	if x != nil {
+		return x.ArrayIndex
+	}
+	return 0
+}
+
+func (x *CreateTagRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *CreateTagRequest) GetSeverityLevel() string {
+	if x != nil {
+		return x.SeverityLevel
+	}
+	return ""
+}
+
+type ReadTagsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadTagsRequest) Reset() {
+	*x = ReadTagsRequest{}
+	mi := &file_tags_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadTagsRequest) ProtoMessage() {}
+
+func (x *ReadTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tags_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadTagsRequest.ProtoReflect.Descriptor instead.
+func (*ReadTagsRequest) Descriptor() ([]byte, []int) {
+	return file_tags_proto_rawDescGZIP(), []int{2}
+}
+
+type ReadTagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []*Tag                 `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadTagsResponse) Reset() {
+	*x = ReadTagsResponse{}
+	mi := &file_tags_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadTagsResponse) ProtoMessage() {}
+
+func (x *ReadTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tags_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadTagsResponse.ProtoReflect.Descriptor instead.
+func (*ReadTagsResponse) Descriptor() ([]byte, []int) {
+	return file_tags_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ReadTagsResponse) GetTags() []*Tag {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type UpdateTagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	DeviceId      *int32                 `protobuf:"varint,3,opt,name=device_id,json=deviceId,proto3,oneof" json:"device_id,omitempty"`
+	Regexp        *string                `protobuf:"bytes,4,opt,name=regexp,proto3,oneof" json:"regexp,omitempty"`
+	CompareType   *string                `protobuf:"bytes,5,opt,name=compare_type,json=compareType,proto3,oneof" json:"compare_type,omitempty"`
+	Value         *string                `protobuf:"bytes,6,opt,name=value,proto3,oneof" json:"value,omitempty"`
+	ArrayIndex    *int32                 `protobuf:"varint,7,opt,name=array_index,json=arrayIndex,proto3,oneof" json:"array_index,omitempty"`
+	Subject       *string                `protobuf:"bytes,8,opt,name=subject,proto3,oneof" json:"subject,omitempty"`
+	SeverityLevel *string                `protobuf:"bytes,9,opt,name=severity_level,json=severityLevel,proto3,oneof" json:"severity_level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTagRequest) Reset() {
+	*x = UpdateTagRequest{}
+	mi := &file_tags_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTagRequest) ProtoMessage() {}
+
+func (x *UpdateTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tags_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTagRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTagRequest) Descriptor() ([]byte, []int) {
+	return file_tags_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateTagRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateTagRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateTagRequest) GetDeviceId() int32 {
+	if x != nil && x.DeviceId != nil {
+		return *x.DeviceId
+	}
+	return 0
+}
+
+func (x *UpdateTagRequest) GetRegexp() string {
+	if x != nil && x.Regexp != nil {
+		return *x.Regexp
+	}
+	return ""
+}
+
+func (x *UpdateTagRequest) GetCompareType() string {
+	if x != nil && x.CompareType != nil {
+		return *x.CompareType
+	}
+	return ""
+}
+
+func (x *UpdateTagRequest) GetValue() string {
+	if x != nil && x.Value != nil {
+		return *x.Value
+	}
+	return ""
+}
+
+func (x *UpdateTagRequest) GetArrayIndex() int32 {
+	if x != nil && x.ArrayIndex != nil {
+		return *x.ArrayIndex
+	}
+	return 0
+}
+
+func (x *UpdateTagRequest) GetSubject() string {
+	if x != nil && x.Subject != nil {
+		return *x.Subject
+	}
+	return ""
+}
+
+func (x *UpdateTagRequest) GetSeverityLevel() string {
+	if x != nil && x.SeverityLevel != nil {
+		return *x.SeverityLevel
+	}
+	return ""
+}
+
+type DeleteTagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTagRequest) Reset() {
+	*x = DeleteTagRequest{}
+	mi := &file_tags_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTagRequest) ProtoMessage() {}
+
+func (x *DeleteTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tags_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTagRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTagRequest) Descriptor() ([]byte, []int) {
+	return file_tags_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteTagRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+var File_tags_proto protoreflect.FileDescriptor
+
+const file_tags_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"tags.proto\x12\x04tags\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xef\x02\n" +
+	"\x03Tag\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1b\n" +
+	"\tdevice_id\x18\x03 \x01(\x05R\bdeviceId\x12\x16\n" +
+	"\x06regexp\x18\x04 \x01(\tR\x06regexp\x12!\n" +
+	"\fcompare_type\x18\x05 \x01(\tR\vcompareType\x12\x14\n" +
+	"\x05value\x18\x06 \x01(\tR\x05value\x12\x1f\n" +
+	"\varray_index\x18\a \x01(\x05R\n" +
+	"arrayIndex\x12\x18\n" +
+	"\asubject\x18\b \x01(\tR\asubject\x12%\n" +
+	"\x0eseverity_level\x18\t \x01(\tR\rseverityLevel\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xf6\x01\n" +
+	"\x10CreateTagRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\x05R\bdeviceId\x12\x16\n" +
+	"\x06regexp\x18\x03 \x01(\tR\x06regexp\x12!\n" +
+	"\fcompare_type\x18\x04 \x01(\tR\vcompareType\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\tR\x05value\x12\x1f\n" +
+	"\varray_index\x18\x06 \x01(\x05R\n" +
+	"arrayIndex\x12\x18\n" +
+	"\asubject\x18\a \x01(\tR\asubject\x12%\n" +
+	"\x0eseverity_level\x18\b \x01(\tR\rseverityLevel\"\x11\n" +
+	"\x0fReadTagsRequest\"1\n" +
+	"\x10ReadTagsResponse\x12\x1d\n" +
+	"\x04tags\x18\x01 \x03(\v2\t.tags.TagR\x04tags\"\x9a\x03\n" +
+	"\x10UpdateTagRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12 \n" +
+	"\tdevice_id\x18\x03 \x01(\x05H\x01R\bdeviceId\x88\x01\x01\x12\x1b\n" +
+	"\x06regexp\x18\x04 \x01(\tH\x02R\x06regexp\x88\x01\x01\x12&\n" +
+	"\fcompare_type\x18\x05 \x01(\tH\x03R\vcompareType\x88\x01\x01\x12\x19\n" +
+	"\x05value\x18\x06 \x01(\tH\x04R\x05value\x88\x01\x01\x12$\n" +
+	"\varray_index\x18\a \x01(\x05H\x05R\n" +
+	"arrayIndex\x88\x01\x01\x12\x1d\n" +
+	"\asubject\x18\b \x01(\tH\x06R\asubject\x88\x01\x01\x12*\n" +
+	"\x0eseverity_level\x18\t \x01(\tH\aR\rseverityLevel\x88\x01\x01B\a\n" +
+	"\x05_nameB\f\n" +
+	"\n" +
+	"_device_idB\t\n" +
+	"\a_regexpB\x0f\n" +
+	"\r_compare_typeB\b\n" +
+	"\x06_valueB\x0e\n" +
+	"\f_array_indexB\n" +
+	"\n" +
+	"\b_subjectB\x11\n" +
+	"\x0f_severity_level\"\"\n" +
+	"\x10DeleteTagRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id2\xe5\x01\n" +
+	"\vTagsService\x12+\n" +
+	"\x06Create\x12\x16.tags.CreateTagRequest\x1a\t.tags.Tag\x125\n" +
+	"\x04Read\x12\x15.tags.ReadTagsRequest\x1a\x16.tags.ReadTagsResponse\x128\n" +
+	"\x06Update\x12\x16.tags.UpdateTagRequest\x1a\x16.google.protobuf.Empty\x128\n" +
+	"\x06Delete\x12\x16.tags.DeleteTagRequest\x1a\x16.google.protobuf.EmptyB!Z\x1fmonolith/proto/api-gateway/tagsb\x06proto3"
+
+var (
+	file_tags_proto_rawDescOnce sync.Once
+	file_tags_proto_rawDescData []byte
+)
+
+func file_tags_proto_rawDescGZIP() []byte {
+	file_tags_proto_rawDescOnce.Do(func() {
+		file_tags_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tags_proto_rawDesc), len(file_tags_proto_rawDesc)))
+	})
+	return file_tags_proto_rawDescData
+}
+
+var file_tags_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_tags_proto_goTypes = []any{
+	(*Tag)(nil),                   // 0: tags.Tag
+	(*CreateTagRequest)(nil),      // 1: tags.CreateTagRequest
+	(*ReadTagsRequest)(nil),       // 2: tags.ReadTagsRequest
+	(*ReadTagsResponse)(nil),      // 3: tags.ReadTagsResponse
+	(*UpdateTagRequest)(nil),      // 4: tags.UpdateTagRequest
+	(*DeleteTagRequest)(nil),      // 5: tags.DeleteTagRequest
+	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 7: google.protobuf.Empty
+}
+var file_tags_proto_depIdxs = []int32{
+	6, // 0: tags.Tag.created_at:type_name -> google.protobuf.Timestamp
+	6, // 1: tags.Tag.updated_at:type_name -> google.protobuf.Timestamp
+	0, // 2: tags.ReadTagsResponse.tags:type_name -> tags.Tag
+	1, // 3: tags.TagsService.Create:input_type -> tags.CreateTagRequest
+	2, // 4: tags.TagsService.Read:input_type -> tags.ReadTagsRequest
+	4, // 5: tags.TagsService.Update:input_type -> tags.UpdateTagRequest
+	5, // 6: tags.TagsService.Delete:input_type -> tags.DeleteTagRequest
+	0, // 7: tags.TagsService.Create:output_type -> tags.Tag
+	3, // 8: tags.TagsService.Read:output_type -> tags.ReadTagsResponse
+	7, // 9: tags.TagsService.Update:output_type -> google.protobuf.Empty
+	7, // 10: tags.TagsService.Delete:output_type -> google.protobuf.Empty
+	7, // [7:11] is the sub-list for method output_type
+	3, // [3:7] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_tags_proto_init() }
+func file_tags_proto_init() {
+	if File_tags_proto != nil {
+		return
+	}
+	file_tags_proto_msgTypes[4].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tags_proto_rawDesc), len(file_tags_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tags_proto_goTypes,
+		DependencyIndexes: file_tags_proto_depIdxs,
+		MessageInfos:      file_tags_proto_msgTypes,
+	}.Build()
+	File_tags_proto = out.File
+	file_tags_proto_goTypes = nil
+	file_tags_proto_depIdxs = nil
+}
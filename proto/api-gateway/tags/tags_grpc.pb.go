@@ -0,0 +1,240 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: tags.proto
+
+package tags
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TagsService_Create_FullMethodName = "/tags.TagsService/Create"
+	TagsService_Read_FullMethodName   = "/tags.TagsService/Read"
+	TagsService_Update_FullMethodName = "/tags.TagsService/Update"
+	TagsService_Delete_FullMethodName = "/tags.TagsService/Delete"
+)
+
+// TagsServiceClient is the client API for TagsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TagsService mirrors /tags of the HTTP API.
+type TagsServiceClient interface {
+	Create(ctx context.Context, in *CreateTagRequest, opts ...grpc.CallOption) (*Tag, error)
+	Read(ctx context.Context, in *ReadTagsRequest, opts ...grpc.CallOption) (*ReadTagsResponse, error)
+	Update(ctx context.Context, in *UpdateTagRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Delete(ctx context.Context, in *DeleteTagRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type tagsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTagsServiceClient(cc grpc.ClientConnInterface) TagsServiceClient {
+	return &tagsServiceClient{cc}
+}
+
+func (c *tagsServiceClient) Create(ctx context.Context, in *CreateTagRequest, opts ...grpc.CallOption) (*Tag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tag)
+	err := c.cc.Invoke(ctx, TagsService_Create_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tagsServiceClient) Read(ctx context.Context, in *ReadTagsRequest, opts ...grpc.CallOption) (*ReadTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadTagsResponse)
+	err := c.cc.Invoke(ctx, TagsService_Read_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tagsServiceClient) Update(ctx context.Context, in *UpdateTagRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, TagsService_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tagsServiceClient) Delete(ctx context.Context, in *DeleteTagRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, TagsService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TagsServiceServer is the server API for TagsService service.
+// All implementations must embed UnimplementedTagsServiceServer
+// for forward compatibility.
+//
+// TagsService mirrors /tags of the HTTP API.
+type TagsServiceServer interface {
+	Create(context.Context, *CreateTagRequest) (*Tag, error)
+	Read(context.Context, *ReadTagsRequest) (*ReadTagsResponse, error)
+	Update(context.Context, *UpdateTagRequest) (*emptypb.Empty, error)
+	Delete(context.Context, *DeleteTagRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedTagsServiceServer()
+}
+
+// UnimplementedTagsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTagsServiceServer struct{}
+
+func (UnimplementedTagsServiceServer) Create(context.Context, *CreateTagRequest) (*Tag, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedTagsServiceServer) Read(context.Context, *ReadTagsRequest) (*ReadTagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Read not implemented")
+}
+func (UnimplementedTagsServiceServer) Update(context.Context, *UpdateTagRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedTagsServiceServer) Delete(context.Context, *DeleteTagRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedTagsServiceServer) mustEmbedUnimplementedTagsServiceServer() {}
+func (UnimplementedTagsServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeTagsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TagsServiceServer will
+// result in compilation errors.
+type UnsafeTagsServiceServer interface {
+	mustEmbedUnimplementedTagsServiceServer()
+}
+
+func RegisterTagsServiceServer(s grpc.ServiceRegistrar, srv TagsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTagsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TagsService_ServiceDesc, srv)
+}
+
+func _TagsService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TagsServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TagsService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TagsServiceServer).Create(ctx, req.(*CreateTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TagsService_Read_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TagsServiceServer).Read(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TagsService_Read_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TagsServiceServer).Read(ctx, req.(*ReadTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TagsService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TagsServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TagsService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TagsServiceServer).Update(ctx, req.(*UpdateTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TagsService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TagsServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TagsService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TagsServiceServer).Delete(ctx, req.(*DeleteTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TagsService_ServiceDesc is the grpc.ServiceDesc for TagsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TagsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tags.TagsService",
+	HandlerType: (*TagsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _TagsService_Create_Handler,
+		},
+		{
+			MethodName: "Read",
+			Handler:    _TagsService_Read_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _TagsService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _TagsService_Delete_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "tags.proto",
+}